@@ -0,0 +1,572 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/client/metno"
+	"github.com/mohithbuilds/sky/internal/client/nws"
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/config"
+	"github.com/mohithbuilds/sky/internal/daemon"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/i18n"
+	"github.com/mohithbuilds/sky/internal/notify"
+	"github.com/mohithbuilds/sky/internal/proxy"
+	"github.com/mohithbuilds/sky/internal/render"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// app holds the clients and writers shared by every command.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	geocoding  *openmateo.GeocodingClient
+	forecast   *openmateo.ForecastClient
+	airQuality *openmateo.AirQualityClient
+	// nws is shared so that it remembers the grids of locations.
+	nws *weather.NWS
+	// metno is shared so that it keeps forecasts until they expire.
+	metno *weather.MetNorway
+
+	// lang is the --lang flag shared by every command.
+	lang string
+	// model is the --model flag shared by every command.
+	model string
+	// providers is the --provider flag shared by every command: the
+	// providers to try in order, or nil for the config file's chain.
+	providers []string
+	// failovers are the provider chains built for each model, kept so that
+	// their circuit breakers see every request.
+	failovers map[string]*weather.Failover
+	// configPath is the --config flag of the commands that have one.
+	configPath string
+}
+
+func newApp(stdout, stderr io.Writer) *app {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	a := &app{
+		stdout:     stdout,
+		stderr:     stderr,
+		geocoding:  openmateo.NewGeocodingClient(httpClient),
+		forecast:   openmateo.NewForecastClient(httpClient),
+		airQuality: openmateo.NewAirQualityClient(httpClient),
+		nws:        &weather.NWS{Client: nws.NewClient(httpClient)},
+		metno:      &weather.MetNorway{Client: metno.NewClient(httpClient)},
+		failovers:  make(map[string]*weather.Failover),
+	}
+	// A proxy serves every endpoint under one base URL.
+	if base := os.Getenv(proxy.EnvURL); base != "" {
+		base = strings.TrimSuffix(base, "/") + "/"
+		a.geocoding.BaseURL, a.forecast.BaseURL, a.airQuality.BaseURL = base, base, base
+	}
+	return a
+}
+
+// flagSet creates the flag set for a command with the flags shared by every
+// command already registered.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.lang, "lang", "", "language for descriptions, dates and numbers (default $LANG)")
+	fs.StringVar(&a.model, "model", "", `weather model to forecast with, e.g. "icon_seamless" (default the API's choice)`)
+	fs.Func("provider", `weather providers to try in order, separated by commas: "openmeteo", "nws" for the US National Weather Service and "metno" for MET Norway (default the config file's chain, or "openmeteo")`, func(value string) error {
+		names := strings.Split(value, ",")
+		for i, name := range names {
+			if !slices.Contains(config.ProviderNames, name) {
+				return fmt.Errorf("unknown provider %q", name)
+			}
+			if slices.Contains(names[:i], name) {
+				return fmt.Errorf("provider %q is listed twice", name)
+			}
+		}
+		a.providers = names
+		return nil
+	})
+	return fs
+}
+
+// locale returns the locale selected with --lang or the environment.
+func (a *app) locale() *i18n.Locale {
+	if a.lang != "" {
+		return i18n.Lookup(a.lang)
+	}
+	return i18n.Lookup(i18n.FromEnvironment())
+}
+
+// weatherClient builds a WeatherClient backed by the selected providers that
+// forecasts with the model selected with --model.
+func (a *app) weatherClient() *weather.WeatherClient {
+	return a.weatherClientFor(a.model)
+}
+
+// weatherClientFor builds a WeatherClient that forecasts with a model, or
+// with the API's choice when model is empty. The NWS and MET Norway have a
+// single model each and ignore it.
+func (a *app) weatherClientFor(model string) *weather.WeatherClient {
+	wc := weather.NewWeatherClientFrom(a.weatherProvider(model))
+	wc.AirQualityClient = a.airQuality
+	wc.Language = a.locale().Language()
+	if store := a.recordingHistory(); store != nil {
+		wc.Recorder = a.recorder(store, model)
+	}
+	return wc
+}
+
+// recorder returns a Recorder into store for what is fetched with a model
+// from the selected providers.
+func (a *app) recorder(store *history.Store, model string) *history.Recorder {
+	recorder := history.NewRecorder(store, func(err error) {
+		fmt.Fprintf(a.stderr, "sky: failed to record history: %v\n", err)
+	})
+	recorder.Model = model
+	if settings := a.providerSettings(); len(settings.Chain) == 1 {
+		recorder.Provider = settings.Chain[0].Name
+	}
+	return recorder
+}
+
+// weatherProvider returns the provider selected with --provider or in the
+// config file. Several providers, or one with a timeout, make a Failover.
+func (a *app) weatherProvider(model string) weather.Provider {
+	settings := a.providerSettings()
+	if len(settings.Chain) == 1 && settings.Chain[0].Timeout == 0 {
+		return a.provider(settings.Chain[0].Name, model)
+	}
+	return a.failover(model)
+}
+
+// failover returns the Failover over the selected providers that forecasts
+// with a model. It is built once per model so that its circuit breakers see
+// every request, and reports each failure it moves on from.
+func (a *app) failover(model string) *weather.Failover {
+	if f := a.failovers[model]; f != nil {
+		return f
+	}
+	settings := a.providerSettings()
+	backends := make([]weather.Backend, len(settings.Chain))
+	for i, p := range settings.Chain {
+		backends[i] = weather.Backend{
+			Name:     p.Name,
+			Provider: a.provider(p.Name, model),
+			Timeout:  time.Duration(p.Timeout),
+		}
+	}
+	f := weather.NewFailover(backends...)
+	f.FailureThreshold = settings.FailureThreshold
+	f.Cooldown = time.Duration(settings.Cooldown)
+	f.OnFailure = func(name string, err error) {
+		fmt.Fprintf(a.stderr, "sky: weather provider %s failed: %v\n", name, err)
+	}
+	a.failovers[model] = f
+	return f
+}
+
+// provider returns a single provider by name.
+func (a *app) provider(name, model string) weather.Provider {
+	switch name {
+	case "nws":
+		return a.nws
+	case "metno":
+		return a.metno
+	default:
+		forecast := *a.forecast
+		forecast.Model = model
+		return &weather.OpenMeteo{Client: &forecast}
+	}
+}
+
+// providerSettings returns the config file's provider settings with the
+// chain selected with --provider, if any, taking the timeouts of its
+// providers from the config file. The chain defaults to Open-Meteo alone.
+// Problems with the config file are reported and otherwise ignored.
+func (a *app) providerSettings() config.Providers {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "sky: ignoring the provider settings: %v\n", err)
+		cfg = &config.Config{}
+	}
+	settings := cfg.Providers
+	if a.providers != nil {
+		chain := make([]config.Provider, len(a.providers))
+		for i, name := range a.providers {
+			chain[i] = config.Provider{Name: name}
+			for _, configured := range settings.Chain {
+				if configured.Name == name {
+					chain[i].Timeout = configured.Timeout
+				}
+			}
+		}
+		settings.Chain = chain
+	}
+	if len(settings.Chain) == 0 {
+		settings.Chain = []config.Provider{{Name: "openmeteo"}}
+	}
+	return settings
+}
+
+// openHistory opens the history store set up in the config file, or in
+// $SKY_HISTORY. It returns a nil store when recording is turned off.
+func (a *app) openHistory() (*history.Store, *config.Config, error) {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := cfg.History.Dir
+	if env := os.Getenv(history.EnvDir); env == "off" {
+		return nil, cfg, nil
+	} else if env != "" {
+		dir = env
+	}
+	if cfg.History.Disabled {
+		return nil, cfg, nil
+	}
+	if dir == "" {
+		if dir, err = history.DefaultDir(); err != nil {
+			return nil, nil, err
+		}
+	}
+	store, err := history.Open(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	store.Policy = &cfg.History.Policy
+	return store, cfg, nil
+}
+
+// recordingHistory returns the store fetched weather is recorded in, or nil.
+// Problems are reported and otherwise ignored, since keeping history must not
+// stop a command from working.
+func (a *app) recordingHistory() *history.Store {
+	store, _, err := a.openHistory()
+	if err != nil {
+		fmt.Fprintf(a.stderr, "sky: not recording history: %v\n", err)
+		return nil
+	}
+	return store
+}
+
+// resolvePlace looks up a place name with the geocoding API.
+func (a *app) resolvePlace(name string) (*openmateo.Location, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("a place name is required")
+	}
+	a.geocoding.Language = a.locale().Language()
+	return a.geocoding.Search(name)
+}
+
+// EnvPlace names the environment variable holding the place of commands
+// run without one, such as sky check and sky exec in cron jobs.
+const EnvPlace = "SKY_PLACE"
+
+// resolvePlaceOrDefault looks up a place name, or the default place when the
+// name is empty: $SKY_PLACE, or the daemon location when the config file has
+// exactly one.
+func (a *app) resolvePlaceOrDefault(name string) (*openmateo.Location, error) {
+	if strings.TrimSpace(name) != "" {
+		return a.resolvePlace(name)
+	}
+	if env := os.Getenv(EnvPlace); env != "" {
+		return a.resolvePlace(env)
+	}
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Daemon.Locations) != 1 {
+		return nil, fmt.Errorf("a place name is required: pass one, set $%s or configure a single daemon location", EnvPlace)
+	}
+	location := cfg.Daemon.Locations[0]
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return a.resolvePlace(location.Name)
+	}
+	return &openmateo.Location{
+		Name:      location.Name,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Timezone:  location.Timezone,
+	}, nil
+}
+
+// render writes a report to stdout using the output flags and locale.
+func (a *app) render(o *outputFlags, report render.Report) error {
+	format, err := render.ParseFormat(o.output)
+	if err != nil {
+		return err
+	}
+
+	renderer, err := render.New(render.Options{
+		Format:  format,
+		Compact: o.compact,
+		Emoji:   o.emoji,
+		Locale:  a.locale(),
+	})
+	if err != nil {
+		return err
+	}
+	return renderer.Render(a.stdout, report)
+}
+
+// outputFlags are the flags shared by every command that renders a report.
+type outputFlags struct {
+	output  string
+	compact bool
+	emoji   bool
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "output", "text", "output format: text, json or markdown")
+	fs.BoolVar(&o.compact, "compact", false, "render a compact report (markdown only)")
+	fs.BoolVar(&o.emoji, "emoji", false, "include weather emoji icons")
+}
+
+// unitFlags select the unit system used for API requests.
+type unitFlags struct {
+	units string
+}
+
+func (u *unitFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&u.units, "units", "metric", "unit system: metric or imperial")
+}
+
+// params returns the Open-Meteo temperature, wind speed and precipitation
+// unit parameters for the selected unit system.
+func (u *unitFlags) params() (string, string, string, error) {
+	switch strings.ToLower(u.units) {
+	case "", "metric":
+		return "celsius", "kmh", "mm", nil
+	case "imperial":
+		return "fahrenheit", "mph", "inch", nil
+	default:
+		return "", "", "", fmt.Errorf("unknown unit system %q (want metric or imperial)", u.units)
+	}
+}
+
+// imperial reports whether the imperial unit system is selected.
+func (u *unitFlags) imperial() (bool, error) {
+	tempUnit, _, _, err := u.params()
+	return tempUnit == "fahrenheit", err
+}
+
+// optionalFloat is a float flag that records whether it was given, for
+// thresholds where every value including zero is meaningful.
+type optionalFloat struct {
+	value float64
+	set   bool
+}
+
+func (f *optionalFloat) String() string {
+	if !f.set {
+		return ""
+	}
+	return strconv.FormatFloat(f.value, 'f', -1, 64)
+}
+
+func (f *optionalFloat) Set(value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	f.value, f.set = v, true
+	return nil
+}
+
+// ruleFlags collects repeated --rule flags into alert rules, parsing each
+// as it is given so that mistakes are reported as flag errors.
+type ruleFlags []alerts.Rule
+
+func (r *ruleFlags) String() string {
+	names := make([]string, len(*r))
+	for i, rule := range *r {
+		names[i] = rule.When
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r *ruleFlags) Set(value string) error {
+	rule, err := alerts.NewRule("", value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+// loadConfig loads the config file given with --config, or the default one.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		return config.LoadDefault()
+	}
+	return config.Load(path)
+}
+
+// fetchAlertData fetches what the rules need to be evaluated at a location.
+func (a *app) fetchAlertData(
+	location *openmateo.Location,
+	rules []alerts.Rule,
+	units *unitFlags,
+) (alerts.Data, error) {
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return alerts.Data{}, err
+	}
+	return alerts.Fetch(
+		a.weatherClient(),
+		location.Latitude,
+		location.Longitude,
+		rules,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+}
+
+// notify posts the fired results to the webhooks, remembering what was sent
+// in the state file so that later runs do not repeat it.
+func (a *app) notify(
+	webhooks []notify.Webhook,
+	statePath string,
+	repeatAfter time.Duration,
+	place render.Place,
+	results []alerts.Result,
+) error {
+	if statePath == "" {
+		var err error
+		if statePath, err = notify.DefaultStatePath(); err != nil {
+			return err
+		}
+	}
+	state, err := notify.OpenState(statePath)
+	if err != nil {
+		return err
+	}
+
+	notifier := notify.NewNotifier(webhooks, nil)
+	notifier.State = state
+	notifier.RepeatAfter = repeatAfter
+
+	deliveries, notifyErr := notifier.Notify(notify.Location{
+		Name:      place.Name,
+		Latitude:  place.Latitude,
+		Longitude: place.Longitude,
+	}, results)
+	for _, delivery := range deliveries {
+		if delivery.Error == "" && !delivery.Skipped {
+			fmt.Fprintf(a.stderr, "notified %s of %q\n", delivery.Webhook, delivery.Rule)
+		}
+	}
+
+	// Save even after a failure so that successful deliveries are remembered.
+	if err := state.Save(); err != nil {
+		return errors.Join(notifyErr, err)
+	}
+	return notifyErr
+}
+
+// daemonConfigLoader returns the function the daemon loads its config with.
+// Locations configured by name are looked up once and remembered, so that a
+// reload only geocodes new names.
+func (a *app) daemonConfigLoader(path string) func() (*daemon.Config, error) {
+	found := make(map[string]daemon.Location)
+	return func() (*daemon.Config, error) {
+		cfg, err := loadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Daemon.Locations) == 0 || len(cfg.Daemon.Schedules) == 0 {
+			return nil, fmt.Errorf("no daemon locations or schedules configured")
+		}
+
+		units := unitFlags{units: cfg.Daemon.Units}
+		tempUnit, windUnit, precipUnit, err := units.params()
+		if err != nil {
+			return nil, err
+		}
+
+		locations, err := a.lookupLocations(cfg.Daemon.Locations, found)
+		if err != nil {
+			return nil, err
+		}
+
+		return &daemon.Config{
+			Locations:  locations,
+			Schedules:  cfg.Daemon.Schedules,
+			Rules:      cfg.Rules,
+			Webhooks:   cfg.Webhooks,
+			Models:     cfg.Daemon.Models,
+			TempUnit:   tempUnit,
+			WindUnit:   windUnit,
+			PrecipUnit: precipUnit,
+		}, nil
+	}
+}
+
+// lookupLocations fills in the coordinates of locations configured by name
+// only. Names are looked up once and remembered in found.
+func (a *app) lookupLocations(configured []daemon.Location, found map[string]daemon.Location) ([]daemon.Location, error) {
+	locations := make([]daemon.Location, len(configured))
+	for i, location := range configured {
+		if location.Latitude == 0 && location.Longitude == 0 {
+			resolved, ok := found[location.Name]
+			if !ok {
+				result, err := a.resolvePlace(location.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to look up location %q: %w", location.Name, err)
+				}
+				resolved = daemon.Location{
+					Name:      location.Name,
+					Latitude:  result.Latitude,
+					Longitude: result.Longitude,
+					Timezone:  result.Timezone,
+				}
+				found[location.Name] = resolved
+			}
+			if location.Timezone != "" {
+				resolved.Timezone = location.Timezone
+			}
+			location = resolved
+		}
+		locations[i] = location
+	}
+	return locations, nil
+}
+
+// observe sets the observer and retries of every Open-Meteo client.
+func (a *app) observe(observer openmateo.Observer, retries int) {
+	a.geocoding.Observer, a.geocoding.Retries = observer, retries
+	a.forecast.Observer, a.forecast.Retries = observer, retries
+	a.airQuality.Observer, a.airQuality.Retries = observer, retries
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// placeArg joins the positional arguments into a single place name so that
+// "sky current New York" works without quoting.
+func placeArg(positional []string) string {
+	return strings.Join(positional, " ")
+}
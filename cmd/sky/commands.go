@@ -0,0 +1,1204 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/daemon"
+	"github.com/mohithbuilds/sky/internal/diff"
+	"github.com/mohithbuilds/sky/internal/exporter"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/metrics"
+	"github.com/mohithbuilds/sky/internal/notify"
+	"github.com/mohithbuilds/sky/internal/proxy"
+	"github.com/mohithbuilds/sky/internal/render"
+	"github.com/mohithbuilds/sky/internal/server"
+	"github.com/mohithbuilds/sky/internal/verify"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+func runCurrent(app *app, args []string) error {
+	fs := app.flagSet("current")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	current, err := app.weatherClient().GetCurrentWeather(
+		location.Latitude,
+		location.Longitude,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.CurrentReport{
+		Place:   render.PlaceFromLocation(location),
+		Current: current,
+	})
+}
+
+func runHourly(app *app, args []string) error {
+	fs := app.flagSet("hourly")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	hours := fs.Int64("hours", 12, "number of hours to forecast")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	forecast, err := app.weatherClient().GetHourlyForecast(
+		location.Latitude,
+		location.Longitude,
+		*hours,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.HourlyReport{
+		Place: render.PlaceFromLocation(location),
+		Hours: forecast,
+	})
+}
+
+func runDaily(app *app, args []string) error {
+	fs := app.flagSet("daily")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	days := fs.Int64("days", 7, "number of days to forecast (1-16)")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	forecast, err := app.weatherClient().GetDailyForecast(
+		location.Latitude,
+		location.Longitude,
+		*days,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.DailyReport{
+		Place: render.PlaceFromLocation(location),
+		Days:  forecast,
+	})
+}
+
+func runSummary(app *app, args []string) error {
+	fs := app.flagSet("summary")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	hours := fs.Int64("hours", 24, "number of hours to summarize")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	forecast, err := app.weatherClient().GetHourlyForecast(
+		location.Latitude,
+		location.Longitude,
+		*hours,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.SummaryReport{
+		Place:   render.PlaceFromLocation(location),
+		Summary: weather.SummarizeHourly(forecast),
+	})
+}
+
+func runStars(app *app, args []string) error {
+	fs := app.flagSet("stars")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	hours := fs.Int64("hours", 72, "number of hours to consider")
+	photo := fs.Bool("photo", false, "score golden hours for photography instead")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	forecast, err := app.weatherClient().GetHourlyForecast(
+		location.Latitude,
+		location.Longitude,
+		*hours,
+		tempUnit,
+		windUnit,
+		precipUnit,
+	)
+	if err != nil {
+		return err
+	}
+
+	place := render.PlaceFromLocation(location)
+	if *photo {
+		return app.render(&out, render.PhotoReport{
+			Place:   place,
+			Windows: weather.ScoreGoldenHours(forecast, place.AstroLocation()),
+		})
+	}
+	return app.render(&out, render.StarsReport{
+		Place:  place,
+		Nights: weather.RankObservingNights(forecast, place.AstroLocation()),
+	})
+}
+
+func runAlerts(app *app, args []string) error {
+	fs := app.flagSet("alerts")
+	var out outputFlags
+	var units unitFlags
+	var extra ruleFlags
+	out.register(fs)
+	units.register(fs)
+	configPath := fs.String("config", "", "config file with alert rules (default $SKY_CONFIG or the user config directory)")
+	fs.Var(&extra, "rule", `extra rule to evaluate, e.g. "gusts > 70 within next 6h" (repeatable)`)
+	all := fs.Bool("all", false, "also list rules that did not fire")
+	official := fs.Bool("official", false, `list the alerts the weather service has issued instead of evaluating rules (needs a provider that publishes them, e.g. "nws")`)
+	notifyWebhooks := fs.Bool("notify", false, "post fired alerts to the webhooks in the config file")
+	repeatAfter := fs.Duration("repeat-after", 0, "with --notify, resend alerts still firing after this long (default once until they clear)")
+	statePath := fs.String("state", "", "with --notify, file remembering delivered alerts (default in the user cache directory)")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	app.configPath = *configPath
+	if *official {
+		if *notifyWebhooks || len(extra) > 0 {
+			return fmt.Errorf("--official cannot be combined with --rule or --notify")
+		}
+		return officialAlerts(app, &out, placeArg(positional))
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	rules := append(cfg.Rules, extra...)
+	if len(rules) == 0 {
+		return fmt.Errorf("no alert rules configured: add them to the config file or pass --rule")
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	data, err := app.fetchAlertData(location, rules, &units)
+	if err != nil {
+		return err
+	}
+
+	results := alerts.Evaluate(rules, data)
+	shown := results
+	if !*all {
+		shown = alerts.Fired(results)
+	}
+	place := render.PlaceFromLocation(location)
+	if err := app.render(&out, render.AlertsReport{Place: place, Results: shown}); err != nil {
+		return err
+	}
+
+	if !*notifyWebhooks {
+		return nil
+	}
+	if len(cfg.Webhooks) == 0 {
+		return fmt.Errorf("no webhooks configured: add them to the config file")
+	}
+	return app.notify(cfg.Webhooks, *statePath, *repeatAfter, place, results)
+}
+
+// officialAlerts lists the alerts in effect at a place, as the weather
+// provider publishes them.
+func officialAlerts(app *app, out *outputFlags, place string) error {
+	location, err := app.resolvePlace(place)
+	if err != nil {
+		return err
+	}
+	active, err := app.weatherClient().GetActiveAlerts(location.Latitude, location.Longitude)
+	if err != nil {
+		return err
+	}
+	return app.render(out, render.OfficialAlertsReport{Place: render.PlaceFromLocation(location), Alerts: active})
+}
+
+// runCheck exits 0 when the conditions hold, 1 when they do not and 2 when
+// they could not be checked, so that it can guard cron jobs and CI steps.
+func runCheck(app *app, args []string) error {
+	held, err := check(app, args)
+	switch {
+	case err != nil:
+		return &exitError{code: 2, err: err}
+	case !held:
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func check(app *app, args []string) (bool, error) {
+	fs := app.flagSet("check")
+	var out outputFlags
+	var units unitFlags
+	var conditions conditionFlags
+	var windAbove, gustsAbove, tempBelow, tempAbove optionalFloat
+	out.register(fs)
+	units.register(fs)
+	rainWithin := fs.Duration("rain-within", 0, "rain is likely within this long, e.g. 2h")
+	rainChance := fs.Float64("rain-chance", 50, "precipitation probability that counts as likely rain, in percent")
+	fs.Var(&windAbove, "wind-above", "wind speed exceeds this within --within")
+	fs.Var(&gustsAbove, "gusts-above", "wind gusts exceed this within --within")
+	fs.Var(&tempBelow, "temp-below", "temperature drops below this within --within")
+	fs.Var(&tempAbove, "temp-above", "temperature rises above this within --within")
+	within := fs.Duration("within", 12*time.Hour, "how far ahead the other conditions look")
+	fs.Var(&conditions, "if", "condition that must hold: "+conditionNames()+` or an expression such as "gusts > 70 tomorrow" (repeatable)`)
+	anyHeld := fs.Bool("any", false, "succeed when any condition holds rather than all")
+	quiet := fs.Bool("quiet", false, "print nothing, only set the exit status")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return false, err
+	}
+
+	window, err := windowExpr("within", *within)
+	if err != nil {
+		return false, err
+	}
+	imperial, err := units.imperial()
+	if err != nil {
+		return false, err
+	}
+	rules, err := conditions.rules(window, imperial)
+	if err != nil {
+		return false, err
+	}
+
+	thresholds := []struct {
+		name  string
+		value optionalFloat
+		when  string
+	}{
+		{"wind-above", windAbove, "wind_speed > %s"},
+		{"gusts-above", gustsAbove, "wind_gusts > %s"},
+		{"temp-below", tempBelow, "temperature < %s"},
+		{"temp-above", tempAbove, "temperature > %s"},
+	}
+	for _, threshold := range thresholds {
+		if !threshold.value.set {
+			continue
+		}
+		when := fmt.Sprintf(threshold.when, formatThreshold(threshold.value.value)) + " " + window
+		rule, err := alerts.NewRule(threshold.name, when)
+		if err != nil {
+			return false, err
+		}
+		rules = append(rules, rule)
+	}
+	if *rainWithin != 0 {
+		rainWindow, err := windowExpr("rain-within", *rainWithin)
+		if err != nil {
+			return false, err
+		}
+		when := fmt.Sprintf("precipitation_probability >= %s %s", formatThreshold(*rainChance), rainWindow)
+		rule, err := alerts.NewRule("rain-within", when)
+		if err != nil {
+			return false, err
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return false, fmt.Errorf("no conditions given: pass --rain-within, --wind-above, --temp-below, --if or similar")
+	}
+
+	location, err := app.resolvePlaceOrDefault(placeArg(positional))
+	if err != nil {
+		return false, err
+	}
+
+	results, err := app.evaluateConditions(location, rules, &units)
+	if err != nil {
+		return false, err
+	}
+
+	fired := len(alerts.Fired(results))
+	held := fired == len(results) || (*anyHeld && fired > 0)
+	if *quiet {
+		return held, nil
+	}
+	return held, app.render(&out, render.AlertsReport{
+		Place:   render.PlaceFromLocation(location),
+		Results: results,
+	})
+}
+
+// runExec runs a command when every --if condition holds and no --unless
+// condition does, and exits with the command's status. When the forecast
+// says not to, it exits 0 without running anything.
+func runExec(app *app, args []string) error {
+	var command []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, command = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	fs := app.flagSet("exec")
+	var units unitFlags
+	var ifConditions, unlessConditions conditionFlags
+	units.register(fs)
+	fs.Var(&ifConditions, "if", "only run when this holds: "+conditionNames()+" or an expression (repeatable)")
+	fs.Var(&unlessConditions, "unless", "do not run when this holds (repeatable)")
+	within := fs.Duration("within", 12*time.Hour, "how far ahead named conditions look")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sky exec [flags] [place] -- <command> [args...]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if len(command) == 0 {
+		return &exitError{code: 2, err: fmt.Errorf(`a command to run is required after "--"`)}
+	}
+	if len(ifConditions)+len(unlessConditions) == 0 {
+		return &exitError{code: 2, err: fmt.Errorf("no conditions given: pass --if or --unless")}
+	}
+
+	window, err := windowExpr("within", *within)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	imperial, err := units.imperial()
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	ifRules, err := ifConditions.rules(window, imperial)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	unlessRules, err := unlessConditions.rules(window, imperial)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	location, err := app.resolvePlaceOrDefault(placeArg(positional))
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	results, err := app.evaluateConditions(location, append(ifRules, unlessRules...), &units)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	for i, result := range results {
+		unless := i >= len(ifRules)
+		if result.Fired == unless {
+			verb := "does not hold"
+			if unless {
+				verb = "holds"
+			}
+			fmt.Fprintf(app.stderr, "sky exec: not running %s: %s %s\n", command[0], describe(result), verb)
+			return nil
+		}
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = app.stdout
+	cmd.Stderr = app.stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &exitError{code: exitErr.ExitCode()}
+		}
+		return &exitError{code: 127, err: err}
+	}
+	return nil
+}
+
+// runDaemon polls the locations in the config file on its schedules until it
+// is stopped with SIGTERM or SIGINT. SIGHUP reloads the config file.
+func runDaemon(app *app, args []string) error {
+	fs := app.flagSet("daemon")
+	configPath := fs.String("config", "", "config file with the daemon settings (default $SKY_CONFIG or the user config directory)")
+	storeDir := fs.String("store", "", "directory the results are written to (default the daemon store setting or the user cache directory)")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	app.configPath = *configPath
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *storeDir == "" {
+		*storeDir = cfg.Daemon.Store
+	}
+	if *storeDir == "" {
+		if *storeDir, err = daemon.DefaultStoreDir(); err != nil {
+			return err
+		}
+	}
+
+	statePath, err := notify.DefaultStatePath()
+	if err != nil {
+		return err
+	}
+	state, err := notify.OpenState(statePath)
+	if err != nil {
+		return err
+	}
+
+	d := daemon.New(
+		app.weatherClient(),
+		&daemon.FileStore{Dir: *storeDir},
+		app.daemonConfigLoader(*configPath),
+		log.New(app.stderr, "sky daemon: ", log.LstdFlags),
+	)
+	clients := make(map[string]daemon.Fetcher)
+	d.ClientFor = func(model string) daemon.Fetcher {
+		if clients[model] == nil {
+			clients[model] = app.weatherClientFor(model)
+		}
+		return clients[model]
+	}
+	d.Notifier = notify.NewNotifier(nil, nil)
+	d.Notifier.State = state
+	d.SaveState = state.Save
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	reload := make(chan struct{})
+	go func() {
+		for range hangup {
+			reload <- struct{}{}
+		}
+	}()
+
+	return d.Run(ctx, reload)
+}
+
+func runExporter(app *app, args []string) error {
+	fs := app.flagSet("exporter")
+	configPath := fs.String("config", "", "config file with the exporter settings (default $SKY_CONFIG or the user config directory)")
+	listen := fs.String("listen", "", "address to serve /metrics on (default the exporter listen setting or "+exporter.DefaultListen+")")
+	interval := fs.Duration("interval", 0, "how often to fetch the weather (default the exporter interval setting or 5m)")
+	retries := fs.Int("retries", 2, "times to retry a failed API request")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	app.configPath = *configPath
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *listen == "" {
+		*listen = cmp.Or(cfg.Exporter.Listen, exporter.DefaultListen)
+	}
+	if *interval == 0 {
+		*interval = cmp.Or(time.Duration(cfg.Exporter.Interval), exporter.DefaultInterval)
+	}
+	if *interval < time.Minute {
+		return fmt.Errorf("interval %s is shorter than a minute", *interval)
+	}
+
+	registry := metrics.NewRegistry()
+	app.observe(exporter.NewClientMetrics(registry), *retries)
+
+	// A place on the command line replaces the configured locations.
+	configured := cfg.Exporter.Locations
+	if len(configured) == 0 {
+		configured = cfg.Daemon.Locations
+	}
+	if len(positional) > 0 {
+		configured = []daemon.Location{{Name: placeArg(positional)}}
+	}
+	if len(configured) == 0 {
+		return fmt.Errorf("no locations to export: pass a place or add exporter locations to the config file")
+	}
+	resolved, err := app.lookupLocations(configured, make(map[string]daemon.Location))
+	if err != nil {
+		return err
+	}
+	locations := make([]exporter.Location, len(resolved))
+	for i, location := range resolved {
+		locations[i] = exporter.Location{
+			Name:      location.Name,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		}
+	}
+
+	logger := log.New(app.stderr, "sky exporter: ", log.LstdFlags)
+	e := exporter.New(registry, app.weatherClient(), logger)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", registry.Handler())
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "sky exporter: metrics are at /metrics")
+	})
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	go e.Run(ctx, locations, *interval)
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
+	logger.Printf("serving metrics for %d locations on %s", len(locations), *listen)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Printf("stopping")
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+func runServe(app *app, args []string) error {
+	fs := app.flagSet("serve")
+	listen := fs.String("listen", server.DefaultListen, "address to serve the API on")
+	retries := fs.Int("retries", 2, "times to retry a failed API request")
+	drain := fs.Duration("drain", 5*time.Second, "how long /readyz fails before the server stops")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	app.observe(nil, *retries)
+	app.geocoding.Language = app.locale().Language()
+
+	logger := log.New(app.stderr, "sky serve: ", log.LstdFlags)
+	s := server.New(app.weatherClient(), app.geocoding, logger)
+	if failover, ok := app.weatherProvider(app.model).(*weather.Failover); ok {
+		failover.OnFailure = func(name string, err error) {
+			logger.Printf("weather provider %s failed: %v", name, err)
+		}
+		s.Providers = failover
+	}
+	httpServer := &http.Server{Addr: *listen, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.ListenAndServe() }()
+	logger.Printf("serving the API on %s", *listen)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first so that load balancers stop sending requests,
+	// then let the requests in flight finish.
+	logger.Printf("stopping")
+	s.SetReady(false)
+	time.Sleep(*drain)
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdown)
+}
+
+func runProxy(app *app, args []string) error {
+	fs := app.flagSet("proxy")
+	listen := fs.String("listen", proxy.DefaultListen, "address to serve the proxy on")
+	ttl := fs.Duration("ttl", proxy.DefaultTTL, "how long forecasts and air quality are cached")
+	searchTTL := fs.Duration("search-ttl", proxy.DefaultSearchTTL, "how long geocoding results are cached")
+	maxEntries := fs.Int("max-entries", proxy.DefaultMaxEntries, "most responses to keep in the cache")
+	rate := fs.Float64("rate", 5, "requests per second each client may make (0 for no limit)")
+	burst := fs.Int("burst", 20, "requests a client may make at once")
+
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if *rate < 0 {
+		return fmt.Errorf("rate must not be negative, got %g", *rate)
+	}
+
+	logger := log.New(app.stderr, "sky proxy: ", log.LstdFlags)
+	p := proxy.New(proxy.Options{
+		TTL:        *ttl,
+		SearchTTL:  *searchTTL,
+		MaxEntries: *maxEntries,
+		Rate:       *rate,
+		Burst:      *burst,
+	}, logger)
+	server := &http.Server{Addr: *listen, Handler: p, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
+	logger.Printf("proxying Open-Meteo on %s", *listen)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Printf("stopping")
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+func runHistory(app *app, args []string) error {
+	fs := app.flagSet("history")
+	var out outputFlags
+	out.register(fs)
+	since := fs.String("since", "7d", "how far back to look, e.g. 36h, 7d or 2w")
+	kind := fs.String("kind", "", "only show observation, hourly, daily or analysis points")
+	variable := fs.String("variable", "", `only show one variable, e.g. "temperature"`)
+	compact := fs.Bool("compact-store", false, "apply the retention and compaction policy to the whole store instead")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	store, cfg, err := app.openHistory()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("history is turned off")
+	}
+
+	if *compact {
+		stats, err := store.Apply(cfg.History.Policy, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(
+			app.stdout,
+			"Compacted %d files and removed %d in %d locations: %d points kept of %d.\n",
+			stats.FilesCompacted,
+			stats.FilesRemoved,
+			stats.Locations,
+			stats.PointsAfter,
+			stats.PointsBefore,
+		)
+		return nil
+	}
+
+	age, err := history.ParseAge(*since)
+	if err != nil {
+		return err
+	}
+	query := history.Query{Since: time.Now().Add(-age)}
+	switch k := history.Kind(*kind); k {
+	case "":
+	case history.KindObservation, history.KindHourly, history.KindDaily, history.KindAnalysis:
+		query.Kinds = []history.Kind{k}
+	default:
+		return fmt.Errorf("unknown kind %q (want observation, hourly, daily or analysis)", *kind)
+	}
+	if *variable != "" {
+		query.Variables = []string{*variable}
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+	query.Location = history.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+
+	points, err := store.Query(query)
+	if err != nil {
+		return err
+	}
+	return app.render(&out, render.HistoryReport{
+		Place:  render.PlaceFromLocation(location),
+		Since:  query.Since,
+		Points: points,
+	})
+}
+
+func runDiff(app *app, args []string) error {
+	fs := app.flagSet("diff")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	hourly := fs.Bool("hourly", false, "compare the hourly forecast instead of the daily one")
+	days := fs.Int64("days", 7, "number of days to compare (1-16)")
+	hours := fs.Int64("hours", 48, "number of hours to compare with --hourly")
+	since := fs.String("since", "", "compare with the newest forecast at least this old, e.g. 12h or 1d (default the previous one)")
+	noSave := fs.Bool("no-save", false, "do not record this forecast in the history for later comparisons")
+	var tempChange, rainChange, precipChange, windChange optionalFloat
+	fs.Var(&tempChange, "temp-change", "smallest temperature change to report (default 2 °C or 4 °F)")
+	fs.Var(&rainChange, "rain-change", "smallest change in the chance of rain to report, in percentage points (default 20)")
+	fs.Var(&precipChange, "precip-change", "smallest precipitation change to report (default 2 mm or 0.1 in)")
+	fs.Var(&windChange, "wind-change", "smallest change in gusts to report (default 15 km/h or 10 mph)")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+	imperial, err := units.imperial()
+	if err != nil {
+		return err
+	}
+	before := time.Now()
+	if *since != "" {
+		age, err := history.ParseAge(*since)
+		if err != nil {
+			return err
+		}
+		before = before.Add(-age)
+	}
+
+	thresholds := diff.DefaultThresholds(imperial)
+	for _, override := range []struct {
+		flag  optionalFloat
+		value *float64
+	}{
+		{tempChange, &thresholds.Temperature},
+		{rainChange, &thresholds.PrecipitationProbability},
+		{precipChange, &thresholds.Precipitation},
+		{windChange, &thresholds.Wind},
+	} {
+		if override.flag.set {
+			*override.value = override.flag.value
+		}
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+	store := app.recordingHistory()
+	if store == nil {
+		return fmt.Errorf("sky diff compares with the recorded history, which is turned off")
+	}
+
+	wc := app.weatherClient()
+	if *noSave {
+		wc.Recorder = nil
+	}
+	current := diff.Snapshot{Taken: time.Now(), Kind: diff.KindDaily}
+	if *hourly {
+		current.Kind = diff.KindHourly
+		current.Hourly, err = wc.GetHourlyForecast(
+			location.Latitude,
+			location.Longitude,
+			*hours,
+			tempUnit,
+			windUnit,
+			precipUnit,
+		)
+	} else {
+		current.Daily, err = wc.GetDailyForecast(
+			location.Latitude,
+			location.Longitude,
+			*days,
+			tempUnit,
+			windUnit,
+			precipUnit,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	points := current.Points()
+	app.recorder(store, app.model).Label(points)
+	previous, err := diff.Previous(
+		store,
+		history.Location{Latitude: location.Latitude, Longitude: location.Longitude},
+		points,
+		before,
+		app.locale().Language(),
+	)
+	if err != nil {
+		return err
+	}
+
+	report := render.DiffReport{
+		Place:    render.PlaceFromLocation(location),
+		Kind:     current.Kind,
+		Previous: previous != nil,
+	}
+	if previous != nil {
+		report.Result = diff.Compare(*previous, current, thresholds)
+	}
+	return app.render(&out, report)
+}
+
+// maxPastHours is how far back the forecast API serves past hours.
+const maxPastHours = 92 * 24
+
+func runVerify(app *app, args []string) error {
+	fs := app.flagSet("verify")
+	var out outputFlags
+	out.register(fs)
+	var units unitFlags
+	units.register(fs)
+	since := fs.String("since", "30d", "how far back to verify forecasts, e.g. 7d or 2w")
+	offline := fs.Bool("offline", false, "only use what is already recorded instead of fetching the past hours")
+	rainChance := fs.Float64("rain-chance", 50, "precipitation probability in percent that counts as forecasting rain")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+	age, err := history.ParseAge(*since)
+	if err != nil {
+		return err
+	}
+
+	store, _, err := app.openHistory()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("history is turned off, so there are no forecasts to verify")
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	// What happened always comes from the API's choice of model; --model only
+	// picks the forecasts that are scored.
+	if !*offline {
+		hours := min(int64(age/time.Hour), maxPastHours)
+		_, err := app.weatherClientFor("").GetPastHourly(
+			location.Latitude,
+			location.Longitude,
+			hours,
+			tempUnit,
+			windUnit,
+			precipUnit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	start := time.Now().Add(-age)
+	where := history.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+	forecasts, err := store.Query(history.Query{
+		Location: where,
+		Kinds:    []history.Kind{history.KindHourly, history.KindDaily},
+		Since:    start,
+		Until:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if app.model != "" {
+		forecasts = slices.DeleteFunc(forecasts, func(p history.Point) bool { return p.Model != app.model })
+	}
+	observations, err := store.Query(history.Query{
+		Location: where,
+		Kinds:    []history.Kind{history.KindObservation, history.KindAnalysis},
+		Since:    start,
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.VerifyReport{
+		Place:  render.PlaceFromLocation(location),
+		Since:  start,
+		Report: verify.Verify(forecasts, observations, verify.Options{RainProbability: *rainChance}),
+	})
+}
+
+func runAir(app *app, args []string) error {
+	fs := app.flagSet("air")
+	var out outputFlags
+	out.register(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	airQuality, err := app.weatherClient().GetCurrentAirQuality(
+		location.Latitude,
+		location.Longitude,
+	)
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.AirReport{
+		Place:      render.PlaceFromLocation(location),
+		AirQuality: airQuality,
+	})
+}
+
+func runSearch(app *app, args []string) error {
+	fs := app.flagSet("search")
+	var out outputFlags
+	out.register(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	return app.render(&out, render.SearchReport{
+		Place:      render.PlaceFromLocation(location),
+		Elevation:  location.Elevation,
+		Population: location.Population,
+	})
+}
+
+// runProviders checks every selected weather provider at a place and exits 1
+// when any of them failed.
+func runProviders(app *app, args []string) error {
+	fs := app.flagSet("providers")
+	var out outputFlags
+	out.register(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	health := app.failover(app.model).Check(location.Latitude, location.Longitude)
+	if err := app.render(&out, render.ProvidersReport{
+		Place:     render.PlaceFromLocation(location),
+		Providers: health,
+	}); err != nil {
+		return err
+	}
+	for _, provider := range health {
+		if provider.Failures > 0 {
+			return &exitError{code: 1}
+		}
+	}
+	return nil
+}
+
+// runConsensus blends the hourly forecasts of the selected providers, with
+// Open-Meteo contributing one forecast per model, and flags the hours they
+// disagree on.
+func runConsensus(app *app, args []string) error {
+	fs := app.flagSet("consensus")
+	var out outputFlags
+	var units unitFlags
+	out.register(fs)
+	units.register(fs)
+	hours := fs.Int64("hours", 24, "number of hours to forecast")
+	models := fs.String("models", "icon_seamless,gfs_seamless,ecmwf_ifs025", "Open-Meteo models to blend, separated by commas")
+	median := fs.Bool("median", false, "blend with the median rather than the mean")
+	tempSpread := fs.Float64("temp-spread", weather.DefaultTemperatureSpread, "range of temperatures in °C beyond which the forecasts disagree")
+	precipSpread := fs.Float64("precip-spread", weather.DefaultPrecipitationSpread, "range of hourly precipitation in mm beyond which the forecasts disagree")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	tempUnit, windUnit, precipUnit, err := units.params()
+	if err != nil {
+		return err
+	}
+	if *tempSpread <= 0 || *precipSpread <= 0 {
+		return fmt.Errorf("the spreads must be positive")
+	}
+
+	location, err := app.resolvePlace(placeArg(positional))
+	if err != nil {
+		return err
+	}
+
+	// Each provider is a source, except Open-Meteo, which is a source per
+	// model. Sources that fail are left out.
+	type source struct {
+		name     string
+		provider weather.Provider
+	}
+	var sources []source
+	for _, p := range app.providerSettings().Chain {
+		if p.Name != "openmeteo" {
+			sources = append(sources, source{p.Name, app.provider(p.Name, "")})
+			continue
+		}
+		for _, model := range strings.Split(*models, ",") {
+			model = strings.TrimSpace(model)
+			sources = append(sources, source{strings.TrimSuffix("openmeteo/"+model, "/"), app.provider(p.Name, model)})
+		}
+	}
+
+	var members []weather.Member
+	var names []string
+	for _, s := range sources {
+		wc := weather.NewWeatherClientFrom(s.provider)
+		wc.Language = app.locale().Language()
+		forecast, err := wc.GetHourlyForecast(
+			location.Latitude,
+			location.Longitude,
+			*hours,
+			tempUnit,
+			windUnit,
+			precipUnit,
+		)
+		if err != nil {
+			fmt.Fprintf(app.stderr, "sky: leaving out %s: %v\n", s.name, err)
+			continue
+		}
+		members = append(members, weather.Member{Name: s.name, Hours: forecast})
+		names = append(names, s.name)
+	}
+	if len(members) < 2 {
+		return fmt.Errorf("a consensus needs at least 2 forecasts, got %d of %d", len(members), len(sources))
+	}
+
+	opts := weather.ConsensusOptions{
+		Median:              *median,
+		TemperatureSpread:   *tempSpread,
+		PrecipitationSpread: *precipSpread,
+	}
+	if tempUnit == "fahrenheit" {
+		opts.TemperatureSpread *= 1.8
+	}
+	if precipUnit == "inch" {
+		opts.PrecipitationSpread /= 25.4
+	}
+	blend := "mean"
+	if *median {
+		blend = "median"
+	}
+
+	return app.render(&out, render.ConsensusReport{
+		Place:   render.PlaceFromLocation(location),
+		Sources: names,
+		Blend:   blend,
+		Hours:   weather.Consensus(members, opts),
+	})
+}
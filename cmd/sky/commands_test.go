@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mohithbuilds/sky/internal/client/nws"
+	"github.com/mohithbuilds/sky/internal/client/nws/nwstest"
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
+	"github.com/mohithbuilds/sky/internal/config"
+)
+
+func TestCheck_ExitCodes(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		fault bool
+		code  int
+	}{
+		{"holds", []string{"--temp-above", "-50"}, false, 0},
+		{"does not hold", []string{"--temp-above", "60"}, false, 1},
+		{"all must hold", []string{"--temp-above", "-50", "--temp-below", "-50"}, false, 1},
+		{"any may hold", []string{"--any", "--temp-above", "-50", "--temp-below", "-50"}, false, 0},
+		{"large threshold", []string{"--temp-below", "1e21"}, false, 0},
+		{"expression", []string{"--if", "temperature > 60 today"}, false, 1},
+		{"forecast fails", []string{"--temp-above", "-50"}, true, 2},
+		{"no conditions", nil, false, 2},
+		{"bad window", []string{"--within", "90m", "--temp-above", "-50"}, false, 2},
+		{"bad flag", []string{"--if", "temprature < 0"}, false, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeAPI(t)
+			if tt.fault {
+				server.Inject(openmateotest.Fault{Endpoint: "forecast", Status: 400})
+			}
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"check", "--quiet"}, tt.args...), "Berlin")
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Errorf("Expected exit status %d, got %d: %s", tt.code, code, stderr.String())
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("Expected nothing on stdout with --quiet, got %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestExec_ExitCodes(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("No shell to run commands with")
+	}
+	for _, tt := range []struct {
+		name    string
+		args    []string
+		command string
+		fault   bool
+		code    int
+		ran     bool
+	}{
+		{"runs", []string{"--if", "temperature > -50 within next 6h"}, "echo ran", false, 0, true},
+		{"exit status of the command", []string{"--if", "temperature > -50 within next 6h"}, "echo ran; exit 3", false, 3, true},
+		{"if does not hold", []string{"--if", "temperature > 60 within next 6h"}, "echo ran", false, 0, false},
+		{"unless holds", []string{"--unless", "temperature > -50 within next 6h"}, "echo ran", false, 0, false},
+		{"forecast fails", []string{"--if", "temperature > -50 within next 6h"}, "echo ran", true, 2, false},
+		{"no conditions", nil, "echo ran", false, 2, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeAPI(t)
+			if tt.fault {
+				server.Inject(openmateotest.Fault{Endpoint: "forecast", Status: 400})
+			}
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"exec"}, tt.args...), "Berlin", "--", "sh", "-c", tt.command)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Errorf("Expected exit status %d, got %d: %s", tt.code, code, stderr.String())
+			}
+			if ran := strings.Contains(stdout.String(), "ran"); ran != tt.ran {
+				t.Errorf("Expected the command to run: %t, got output %q", tt.ran, stdout.String())
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	fakeAPI(t)
+	if code := run([]string{"exec", "--if", "rain", "Berlin"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit status 2 without a command, got %d", code)
+	}
+}
+
+func TestCheckAndExec_DefaultPlace(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("No shell to run commands with")
+	}
+	for _, tt := range []struct {
+		name   string
+		env    string
+		config string
+		code   int
+	}{
+		{"no place", "", "", 2},
+		{"from the environment", "Berlin", "", 0},
+		{"single daemon location", "", `{"daemon": {"locations": [{"name": "Berlin"}]}}`, 0},
+		{"daemon location with coordinates", "", `{"daemon": {"locations": [{"name": "Home", "latitude": 52.52, "longitude": 13.41}]}}`, 0},
+		{"several daemon locations", "", `{"daemon": {"locations": [{"name": "Berlin"}, {"name": "Paris"}]}}`, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAPI(t)
+			if tt.env != "" {
+				t.Setenv(EnvPlace, tt.env)
+			}
+			if tt.config != "" {
+				if err := os.WriteFile(os.Getenv(config.EnvPath), []byte(tt.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"check", "--quiet", "--temp-above", "-50"}, &stdout, &stderr); code != tt.code {
+				t.Errorf("Expected check to exit %d, got %d: %s", tt.code, code, stderr.String())
+			}
+			stdout.Reset()
+			stderr.Reset()
+			args := []string{"exec", "--unless", "temperature > 60 within next 6h", "--", "sh", "-c", "echo ran"}
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Errorf("Expected exec to exit %d, got %d: %s", tt.code, code, stderr.String())
+			}
+			if ran := strings.Contains(stdout.String(), "ran"); ran != (tt.code == 0) {
+				t.Errorf("Expected the command to run: %t, got output %q", tt.code == 0, stdout.String())
+			}
+		})
+	}
+}
+
+func TestAlerts_Official(t *testing.T) {
+	fakeAPI(t)
+	fake := nwstest.NewServer()
+	defer fake.Close()
+	fake.Alerts = []nws.Alert{{Event: "Flood Watch", Severity: "Severe", SenderName: "NWS New York NY"}}
+
+	var stdout, stderr bytes.Buffer
+	app := newApp(&stdout, &stderr)
+	client := nws.NewClient(nil)
+	client.BaseURL = fake.BaseURL()
+	app.nws.Client = client
+	if err := runAlerts(app, []string{"--official", "--provider", "nws", "New York"}); err != nil {
+		t.Fatalf("alerts --official failed: %v", err)
+	}
+	for _, expected := range []string{"official alerts", "Flood Watch", "Severe", "NWS New York NY"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, stdout.String())
+		}
+	}
+
+	// Open-Meteo publishes no alerts.
+	app = newApp(&stdout, &stderr)
+	if err := runAlerts(app, []string{"--official", "Berlin"}); err == nil {
+		t.Error("Expected an error for a provider without alerts")
+	}
+	if err := runAlerts(app, []string{"--official", "--notify", "Berlin"}); err == nil || !strings.Contains(err.Error(), "--official") {
+		t.Errorf("Expected --official and --notify to conflict, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+)
+
+// namedConditions are the shorthands accepted by --if and --unless. Each
+// returns an alert expression over the window for the unit system.
+var namedConditions = map[string]func(window string, imperial bool) string{
+	"rain": func(window string, _ bool) string {
+		return "precipitation_probability >= 50 " + window
+	},
+	"snow": func(window string, _ bool) string {
+		return "snowfall > 0 " + window
+	},
+	"freezing": func(window string, imperial bool) string {
+		return fmt.Sprintf("temperature < %s %s", pick(imperial, "32", "0"), window)
+	},
+	"heat": func(window string, imperial bool) string {
+		return fmt.Sprintf("temperature >= %s %s", pick(imperial, "86", "30"), window)
+	},
+	"wind": func(window string, imperial bool) string {
+		return fmt.Sprintf("wind_gusts >= %s %s", pick(imperial, "31", "50"), window)
+	},
+	"cloudy": func(window string, _ bool) string {
+		return "avg cloud_cover >= 75 " + window
+	},
+	"clear": func(window string, _ bool) string {
+		return "avg cloud_cover <= 25 " + window
+	},
+}
+
+func pick(imperial bool, ifImperial, ifMetric string) string {
+	if imperial {
+		return ifImperial
+	}
+	return ifMetric
+}
+
+// formatThreshold formats a threshold for an alert expression, without the
+// exponent %g gives large and small numbers, which expressions cannot hold.
+func formatThreshold(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// conditionFlags collects repeated --if and --unless flags. Conditions are
+// kept as given and turned into rules once the window and units are known.
+type conditionFlags []string
+
+func (c *conditionFlags) String() string {
+	return strings.Join(*c, ", ")
+}
+
+func (c *conditionFlags) Set(value string) error {
+	value = strings.TrimSpace(value)
+	if _, ok := namedConditions[strings.ToLower(value)]; !ok {
+		// Check full expressions now so that mistakes are flag errors.
+		if _, err := alerts.Parse(value); err != nil {
+			return err
+		}
+	}
+	*c = append(*c, value)
+	return nil
+}
+
+// rules turns the conditions into alert rules. Named conditions look at the
+// window; full expressions keep their own.
+func (c conditionFlags) rules(window string, imperial bool) ([]alerts.Rule, error) {
+	rules := make([]alerts.Rule, 0, len(c))
+	for _, condition := range c {
+		name := strings.ToLower(condition)
+		when := condition
+		if build, ok := namedConditions[name]; ok {
+			when = build(window, imperial)
+		} else {
+			name = ""
+		}
+		rule, err := alerts.NewRule(name, when)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// conditionNames returns the names of the built-in conditions, sorted.
+func conditionNames() string {
+	names := make([]string, 0, len(namedConditions))
+	for name := range namedConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// windowExpr returns the alert window for looking d ahead of now.
+func windowExpr(flagName string, d time.Duration) (string, error) {
+	if d < time.Hour || d%time.Hour != 0 {
+		return "", fmt.Errorf("--%s must be a whole number of hours, got %s", flagName, d)
+	}
+	return fmt.Sprintf("within next %dh", d/time.Hour), nil
+}
+
+// evaluateConditions fetches what the rules need and evaluates them. A rule
+// that could not be evaluated is an error, since guessing either way could
+// run or skip something it should not.
+func (a *app) evaluateConditions(
+	location *openmateo.Location,
+	rules []alerts.Rule,
+	units *unitFlags,
+) ([]alerts.Result, error) {
+	data, err := a.fetchAlertData(location, rules, units)
+	if err != nil {
+		return nil, err
+	}
+
+	results := alerts.Evaluate(rules, data)
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to evaluate %q: %s", result.Rule, result.Error)
+		}
+	}
+	return results, nil
+}
+
+// describe returns a short account of a result such as
+// "rain: 80 % at Mon 14:00 (precipitation_probability >= 50 within next 12h)".
+func describe(result alerts.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", result.Rule, strconv.FormatFloat(math.Round(result.Value*10)/10, 'f', -1, 64))
+	if result.Unit != "" {
+		b.WriteString(" " + result.Unit)
+	}
+	if !result.First.IsZero() {
+		b.WriteString(" at " + result.First.Format("Mon 15:04"))
+	}
+	if result.Rule != result.Expression {
+		b.WriteString(" (" + result.Expression + ")")
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
+	"github.com/mohithbuilds/sky/internal/config"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/proxy"
+)
+
+// fakeAPI starts a fake Open-Meteo API at the current time and points sky's
+// clients at it, with the history, config file and default place out of the
+// way.
+func fakeAPI(t *testing.T) *openmateotest.Server {
+	t.Helper()
+	server := openmateotest.NewServer()
+	t.Cleanup(server.Close)
+	server.Now = time.Now()
+	t.Setenv(proxy.EnvURL, server.BaseURL())
+	t.Setenv(history.EnvDir, "off")
+	t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv(EnvPlace, "")
+	return server
+}
+
+func TestWindowExpr(t *testing.T) {
+	for _, tt := range []struct {
+		within   time.Duration
+		expected string
+	}{
+		{time.Hour, "within next 1h"},
+		{12 * time.Hour, "within next 12h"},
+		{72 * time.Hour, "within next 72h"},
+		{0, ""},
+		{30 * time.Minute, ""},
+		{90 * time.Minute, ""},
+	} {
+		window, err := windowExpr("within", tt.within)
+		if tt.expected == "" {
+			if err == nil || !strings.Contains(err.Error(), "--within") {
+				t.Errorf("Expected an error naming the flag for %s, got %q, %v", tt.within, window, err)
+			}
+			continue
+		}
+		if err != nil || window != tt.expected {
+			t.Errorf("Expected %q for %s, got %q, %v", tt.expected, tt.within, window, err)
+		}
+	}
+}
+
+func TestFormatThreshold(t *testing.T) {
+	for _, tt := range []struct {
+		value    float64
+		expected string
+	}{
+		{50, "50"},
+		{-2.5, "-2.5"},
+		{1e21, "1000000000000000000000"},
+		{0.00001, "0.00001"},
+	} {
+		got := formatThreshold(tt.value)
+		if got != tt.expected {
+			t.Errorf("Expected %q for %v, got %q", tt.expected, tt.value, got)
+		}
+		if _, err := alerts.Parse("temperature > " + got); err != nil {
+			t.Errorf("Expected %q to parse, got %v", got, err)
+		}
+	}
+}
+
+func TestConditionFlags_Rules(t *testing.T) {
+	for _, tt := range []struct {
+		condition string
+		imperial  bool
+		expected  string
+	}{
+		{"rain", false, "precipitation_probability >= 50 within next 6h"},
+		{"Freezing", false, "temperature < 0 within next 6h"},
+		{"freezing", true, "temperature < 32 within next 6h"},
+		{"wind", true, "wind_gusts >= 31 within next 6h"},
+		{"gusts > 70 tomorrow", false, "gusts > 70 tomorrow"},
+	} {
+		var conditions conditionFlags
+		if err := conditions.Set(tt.condition); err != nil {
+			t.Fatalf("Set(%q) failed: %v", tt.condition, err)
+		}
+		rules, err := conditions.rules("within next 6h", tt.imperial)
+		if err != nil {
+			t.Fatalf("rules failed for %q: %v", tt.condition, err)
+		}
+		if len(rules) != 1 || rules[0].When != tt.expected {
+			t.Errorf("Expected %q for %q, got %+v", tt.expected, tt.condition, rules)
+		}
+	}
+
+	var conditions conditionFlags
+	if err := conditions.Set("temprature < 0"); err == nil {
+		t.Error("Expected an error for an unknown metric")
+	}
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	server := fakeAPI(t)
+	var stdout, stderr bytes.Buffer
+	app := newApp(&stdout, &stderr)
+	location, err := app.resolvePlace("Berlin")
+	if err != nil {
+		t.Fatalf("resolvePlace failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		when  string
+		fired bool
+	}{
+		{"temperature > -50 within next 6h", true},
+		{"temperature > 60 within next 6h", false},
+		{"avg humidity >= 0 today", true},
+		{"precipitation_sum < 0 tomorrow", false},
+	} {
+		rule, err := alerts.NewRule("", tt.when)
+		if err != nil {
+			t.Fatalf("NewRule(%q) failed: %v", tt.when, err)
+		}
+		results, err := app.evaluateConditions(location, []alerts.Rule{rule}, &unitFlags{})
+		if err != nil {
+			t.Fatalf("evaluateConditions failed for %q: %v", tt.when, err)
+		}
+		if len(results) != 1 || results[0].Fired != tt.fired {
+			t.Errorf("Expected %q to fire: %t, got %+v", tt.when, tt.fired, results)
+		}
+	}
+
+	// A forecast that cannot be fetched is an error, not a condition that
+	// does not hold.
+	server.Inject(openmateotest.Fault{Endpoint: "forecast", Status: 400})
+	rule, _ := alerts.NewRule("", "temperature > -50 within next 6h")
+	if _, err := app.evaluateConditions(location, []alerts.Rule{rule}, &unitFlags{}); err == nil {
+		t.Error("Expected an error when the forecast fails")
+	}
+}
@@ -1,7 +1,109 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// exitError makes run exit with a specific status, for commands whose outcome
+// is reported through the exit status. A nil err exits without a message.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// command is a single sky subcommand.
+type command struct {
+	summary string
+	run     func(app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"current":   {"Show the current weather for a place", runCurrent},
+	"hourly":    {"Show the hourly forecast for a place", runHourly},
+	"daily":     {"Show the daily forecast for a place", runDaily},
+	"summary":   {"Describe the coming hours in plain English", runSummary},
+	"consensus": {"Blend the forecasts of several providers and models for a place", runConsensus},
+	"stars":     {"Rank the coming nights for stargazing or photography", runStars},
+	"alerts":    {"Evaluate alert rules against the forecast for a place", runAlerts},
+	"check":     {"Exit 0 when forecast conditions hold, 1 when not", runCheck},
+	"exec":      {"Run a command only when forecast conditions allow", runExec},
+	"daemon":    {"Poll configured locations on schedules until stopped", runDaemon},
+	"exporter":  {"Serve the weather at configured locations as Prometheus metrics", runExporter},
+	"serve":     {"Serve current weather, forecasts and air quality as a JSON API", runServe},
+	"proxy":     {"Serve a caching Open-Meteo proxy for other machines running sky", runProxy},
+	"providers": {"Check which weather providers are answering for a place", runProviders},
+	"history":   {"Show recorded observations and forecasts for a place", runHistory},
+	"diff":      {"Show how the forecast for a place changed since it was last fetched", runDiff},
+	"verify":    {"Score recorded forecasts for a place against what happened", runVerify},
+	"air":       {"Show the current air quality for a place", runAir},
+	"search":    {"Look up the coordinates of a place", runSearch},
+}
 
 func main() {
-	fmt.Println("Hello, World!")
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "sky: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	app := newApp(stdout, stderr)
+	if err := cmd.run(app, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		var exit *exitError
+		if errors.As(err, &exit) {
+			if exit.err != nil {
+				fmt.Fprintf(stderr, "sky %s: %v\n", args[0], exit.err)
+			}
+			return exit.code
+		}
+		fmt.Fprintf(stderr, "sky %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: sky <command> [flags] <place>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "sky <command> -h" for the flags of a command.`)
 }
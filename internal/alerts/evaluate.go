@@ -0,0 +1,253 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Data is what rules are evaluated against. Any of the sources may be left
+// empty when no rule needs it.
+type Data struct {
+	Now    time.Time // Defaults to time.Now
+	Hourly []weather.HourlyForecast
+	Daily  []weather.DailyForecast
+	Air    *weather.AirQuality
+}
+
+// Result is the outcome of evaluating one rule.
+type Result struct {
+	Rule       string  `json:"rule"`
+	Expression string  `json:"expression"`
+	Severity   string  `json:"severity,omitempty"`
+	Source     Source  `json:"source"`
+	Fired      bool    `json:"fired"`
+	Value      float64 `json:"value"` // The aggregate, or the most extreme value in the window
+	Threshold  float64 `json:"threshold"`
+	Unit       string  `json:"unit,omitempty"`
+	// Margin is how far Value is past the threshold: positive when the rule
+	// fired, negative by how much it missed.
+	Margin float64 `json:"margin"`
+
+	// First and Last are the first and last times the condition held, or for
+	// aggregates the span the value was computed over.
+	First   time.Time `json:"first,omitzero"`
+	Last    time.Time `json:"last,omitzero"`
+	Matches int       `json:"matches"` // Number of values that satisfied the comparison
+
+	Error string `json:"error,omitempty"` // Set when the rule could not be evaluated
+}
+
+// Evaluate evaluates every rule against the data.
+func Evaluate(rules []Rule, data Data) []Result {
+	results := make([]Result, len(rules))
+	for i, rule := range rules {
+		results[i] = rule.Evaluate(data)
+	}
+	return results
+}
+
+// Fired returns the results whose rule fired.
+func Fired(results []Result) []Result {
+	var fired []Result
+	for _, result := range results {
+		if result.Fired {
+			fired = append(fired, result)
+		}
+	}
+	return fired
+}
+
+// sample is one value read out of the data.
+type sample struct {
+	time  time.Time
+	value float64
+	unit  string
+}
+
+// Evaluate evaluates the rule against the data.
+func (r Rule) Evaluate(data Data) Result {
+	result := Result{Rule: r.Name, Severity: r.Severity}
+	if r.expr == nil {
+		result.Error = "rule has not been parsed"
+		return result
+	}
+	expr := r.expr
+	result.Expression = expr.String()
+	result.Source = expr.Source()
+	result.Threshold = expr.Threshold
+
+	if data.Now.IsZero() {
+		data.Now = time.Now()
+	}
+	samples, err := expr.samples(data)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Unit = samples[0].unit
+
+	for _, s := range samples {
+		if expr.Operator.compare(s.value, expr.Threshold) {
+			if result.Matches == 0 {
+				result.First = s.time
+			}
+			result.Last = s.time
+			result.Matches++
+		}
+	}
+
+	if expr.Aggregation == AggregateAny {
+		extreme := expr.extreme(samples)
+		result.Value = extreme.value
+		result.Fired = result.Matches > 0
+		if !result.Fired {
+			result.First, result.Last = extreme.time, extreme.time
+		}
+	} else {
+		aggregate := expr.aggregate(samples)
+		result.Value = aggregate.value
+		result.Fired = expr.Operator.compare(aggregate.value, expr.Threshold)
+		if aggregate.time.IsZero() {
+			result.First, result.Last = samples[0].time, samples[len(samples)-1].time
+		} else {
+			result.First, result.Last = aggregate.time, aggregate.time
+		}
+	}
+	result.Margin = expr.margin(result.Value)
+	return result
+}
+
+// margin returns how far value is past the threshold in the direction the
+// operator looks.
+func (e *Expression) margin(value float64) float64 {
+	switch e.Operator {
+	case OpGreater, OpGreaterEqual:
+		return value - e.Threshold
+	case OpLess, OpLessEqual:
+		return e.Threshold - value
+	case OpEqual:
+		return -math.Abs(value - e.Threshold)
+	default:
+		return math.Abs(value - e.Threshold)
+	}
+}
+
+// extreme returns the sample furthest in the direction the operator looks,
+// e.g. the highest value for ">".
+func (e *Expression) extreme(samples []sample) sample {
+	best := samples[0]
+	for _, s := range samples[1:] {
+		if e.margin(s.value) > e.margin(best.value) {
+			best = s
+		}
+	}
+	return best
+}
+
+// aggregate combines the samples. The time is set for min and max only.
+func (e *Expression) aggregate(samples []sample) sample {
+	switch e.Aggregation {
+	case AggregateMin, AggregateMax:
+		best := samples[0]
+		for _, s := range samples[1:] {
+			if (e.Aggregation == AggregateMin && s.value < best.value) ||
+				(e.Aggregation == AggregateMax && s.value > best.value) {
+				best = s
+			}
+		}
+		return best
+	default:
+		var sum float64
+		for _, s := range samples {
+			sum += s.value
+		}
+		if e.Aggregation == AggregateAvg {
+			sum /= float64(len(samples))
+		}
+		return sample{value: sum}
+	}
+}
+
+// samples reads the expression's metric out of the data within its window.
+func (e *Expression) samples(data Data) ([]sample, error) {
+	var samples []sample
+	switch e.metric.source {
+	case SourceHourly:
+		if len(data.Hourly) == 0 {
+			return nil, fmt.Errorf("no hourly forecast available")
+		}
+		for _, hour := range data.Hourly {
+			if e.Window.containsHour(hour.DateTime, data.Now) {
+				value, unit := e.metric.hourly(hour)
+				samples = append(samples, sample{hour.DateTime, value, unit})
+			}
+		}
+	case SourceDaily:
+		if len(data.Daily) == 0 {
+			return nil, fmt.Errorf("no daily forecast available")
+		}
+		for _, day := range data.Daily {
+			if e.Window.containsDay(day.Date, data.Now) {
+				value, unit := e.metric.daily(day)
+				samples = append(samples, sample{day.Date, value, unit})
+			}
+		}
+	case SourceAir:
+		if data.Air == nil {
+			return nil, fmt.Errorf("no air quality reading available")
+		}
+		value, unit := e.metric.air(*data.Air)
+		samples = append(samples, sample{data.Air.Time, value, unit})
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no %s forecast covers %q", e.metric.source, e.Window)
+	}
+	return samples, nil
+}
+
+// containsHour reports whether the forecast hour starting at t falls in the
+// window. Calendar days are those of the forecast's time zone.
+func (w Window) containsHour(t, now time.Time) bool {
+	// The hour that is under way counts as part of the future.
+	ended := !t.Add(time.Hour).After(now)
+	switch w.Kind {
+	case WindowNow:
+		return !ended && !t.After(now)
+	case WindowNext:
+		return !ended && t.Before(now.Add(w.Duration))
+	case WindowToday:
+		return !ended && sameDay(t, now.In(t.Location()))
+	case WindowTomorrow:
+		return sameDay(t, now.In(t.Location()).AddDate(0, 0, 1))
+	default:
+		return true
+	}
+}
+
+// containsDay reports whether the forecast day starting at date falls in the
+// window.
+func (w Window) containsDay(date, now time.Time) bool {
+	today := now.In(date.Location())
+	switch w.Kind {
+	case WindowNow, WindowToday:
+		return sameDay(date, today)
+	case WindowTomorrow:
+		return sameDay(date, today.AddDate(0, 0, 1))
+	case WindowNext:
+		year, month, day := today.Date()
+		start := time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+		return !date.Before(start) && date.Before(now.Add(w.Duration))
+	default:
+		return true
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
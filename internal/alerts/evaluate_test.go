@@ -0,0 +1,165 @@
+package alerts
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+var testUnits = weather.Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"}
+
+// testData returns 48 hours from midnight on 1 June 2023 with "now" at
+// 09:30, a 2°C drop per hour overnight into the second day and a rain spike
+// at 11:00.
+func testData() Data {
+	start := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	hours := make([]weather.HourlyForecast, 48)
+	for i := range hours {
+		hours[i] = weather.HourlyForecast{
+			DateTime:          start.Add(time.Duration(i) * time.Hour),
+			Temperature:       15,
+			PrecipitationProb: 10,
+			Units:             testUnits,
+		}
+	}
+	hours[11].PrecipitationProb = 75
+	hours[12].PrecipitationProb = 65
+	for i := 24; i < 30; i++ {
+		hours[i].Temperature = 4 - 2*float64(i-24)
+	}
+
+	return Data{
+		Now:    time.Date(2023, 6, 1, 9, 30, 0, 0, time.UTC),
+		Hourly: hours,
+		Daily: []weather.DailyForecast{
+			{Date: start, MinTemperature: 10, PrecipitationSum: 2, Units: testUnits},
+			{Date: start.AddDate(0, 0, 1), MinTemperature: -6, PrecipitationSum: 12, Units: testUnits},
+		},
+		Air: &weather.AirQuality{
+			Time:  time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC),
+			PM25:  40,
+			Units: weather.AirQualityUnits{PM25: "μg/m³"},
+		},
+	}
+}
+
+func mustRule(t *testing.T, when string) Rule {
+	t.Helper()
+	rule, err := NewRule("", when)
+	if err != nil {
+		t.Fatalf("NewRule(%q) failed: %v", when, err)
+	}
+	return rule
+}
+
+func TestEvaluate_AnyValueInWindow(t *testing.T) {
+	data := testData()
+
+	result := mustRule(t, "precipitation_probability > 60 within next 3h").Evaluate(data)
+	if !result.Fired {
+		t.Fatalf("Expected the rule to fire, got %+v", result)
+	}
+	if result.Value != 75 || result.Margin != 15 || result.Unit != "%" {
+		t.Errorf("Expected 75%% (15 over), got %v%s (%v over)", result.Value, result.Unit, result.Margin)
+	}
+	if result.First.Hour() != 11 || result.Last.Hour() != 12 || result.Matches != 2 {
+		t.Errorf("Expected matches from 11:00 to 12:00, got %+v", result)
+	}
+
+	// The hour from 09:30 only covers the forecast hours 09:00 and 10:00.
+	result = mustRule(t, "precipitation_probability > 60 within next 1h").Evaluate(data)
+	if result.Fired {
+		t.Errorf("Expected the rule not to fire, got %+v", result)
+	}
+	if result.Margin != -50 {
+		t.Errorf("Expected to miss by 50, got %v", result.Margin)
+	}
+}
+
+func TestEvaluate_AggregateTomorrow(t *testing.T) {
+	result := mustRule(t, "min temp < 0 tomorrow").Evaluate(testData())
+
+	if !result.Fired || result.Value != -6 || result.Margin != 6 {
+		t.Errorf("Expected a minimum of -6 (6 below), got %+v", result)
+	}
+	expected := time.Date(2023, 6, 2, 5, 0, 0, 0, time.UTC)
+	if !result.First.Equal(expected) || !result.Last.Equal(expected) {
+		t.Errorf("Expected the minimum at %s, got %s", expected, result.First)
+	}
+	if result.Matches != 3 {
+		t.Errorf("Expected 3 hours below zero, got %d", result.Matches)
+	}
+
+	avg := mustRule(t, "avg temp < 10 today").Evaluate(testData())
+	if avg.Fired || avg.Value != 15 {
+		t.Errorf("Expected today's average of 15 not to fire, got %+v", avg)
+	}
+}
+
+func TestEvaluate_DailyAndAir(t *testing.T) {
+	data := testData()
+
+	daily := mustRule(t, "precipitation_sum >= 10 tomorrow").Evaluate(data)
+	if !daily.Fired || daily.Value != 12 || daily.Unit != "mm" {
+		t.Errorf("Expected 12 mm tomorrow, got %+v", daily)
+	}
+	if !daily.First.Equal(data.Daily[1].Date) {
+		t.Errorf("Expected tomorrow's date, got %s", daily.First)
+	}
+
+	data.Hourly[10].SnowFall = 2
+	snow := mustRule(t, "snowfall > 1 within next 3h").Evaluate(data)
+	if !snow.Fired || snow.Unit != "cm" {
+		t.Errorf("Expected 2 cm of snow, got %+v", snow)
+	}
+
+	air := mustRule(t, "pm2_5 > 35").Evaluate(data)
+	if !air.Fired || air.Margin != 5 || air.Unit != "μg/m³" {
+		t.Errorf("Expected PM2.5 5 over the limit, got %+v", air)
+	}
+}
+
+func TestEvaluate_MissingData(t *testing.T) {
+	data := testData()
+	data.Air = nil
+
+	results := Evaluate([]Rule{
+		mustRule(t, "pm2_5 > 35"),
+		mustRule(t, "temp > 30 within next 3h"),
+	}, data)
+
+	if results[0].Error == "" || results[0].Fired {
+		t.Errorf("Expected an error without air quality data, got %+v", results[0])
+	}
+	if results[1].Error != "" || results[1].Fired {
+		t.Errorf("Expected the temperature rule to evaluate quietly, got %+v", results[1])
+	}
+	if len(Fired(results)) != 0 {
+		t.Errorf("Expected no fired rules, got %+v", Fired(results))
+	}
+
+	beyond := mustRule(t, "temp > 30 tomorrow").Evaluate(Data{Now: data.Now, Hourly: data.Hourly[:12]})
+	if beyond.Error == "" {
+		t.Error("Expected an error when the forecast does not reach tomorrow")
+	}
+}
+
+func TestEvaluate_CurrentHourAcrossOffsets(t *testing.T) {
+	// Forecast hours in India start on the half hour UTC.
+	india := time.FixedZone("IST", 5*60*60+30*60)
+	start := time.Date(2023, 6, 1, 0, 0, 0, 0, india)
+	hours := []weather.HourlyForecast{
+		{DateTime: start, Temperature: 30, Units: testUnits},
+		{DateTime: start.Add(time.Hour), Temperature: 35, Units: testUnits},
+	}
+
+	result := mustRule(t, "temp > 32 now").Evaluate(Data{
+		Now:    start.Add(90 * time.Minute).UTC(),
+		Hourly: hours,
+	})
+	if !result.Fired || math.Abs(result.Margin-3) > 1e-9 {
+		t.Errorf("Expected the 01:00 hour to fire by 3, got %+v", result)
+	}
+}
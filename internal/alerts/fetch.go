@@ -0,0 +1,56 @@
+package alerts
+
+import (
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Fetcher fetches the data rules are evaluated against.
+// *weather.WeatherClient implements it.
+type Fetcher interface {
+	GetHourlyForecast(
+		latitude, longitude float64,
+		numHours int64,
+		tempUnit, windUnit, precipUnit string,
+	) ([]weather.HourlyForecast, error)
+	GetDailyForecast(
+		latitude, longitude float64,
+		numDays int64,
+		tempUnit, windUnit, precipUnit string,
+	) ([]weather.DailyForecast, error)
+	GetCurrentAirQuality(latitude, longitude float64) (*weather.AirQuality, error)
+}
+
+// Fetch fetches what the rules need to be evaluated at a location, and
+// nothing more.
+func Fetch(
+	f Fetcher,
+	latitude, longitude float64,
+	rules []Rule,
+	tempUnit, windUnit, precipUnit string,
+) (Data, error) {
+	req := Require(rules)
+	data := Data{Now: time.Now()}
+
+	var err error
+	if req.Hours > 0 {
+		data.Hourly, err = f.GetHourlyForecast(latitude, longitude, req.Hours, tempUnit, windUnit, precipUnit)
+		if err != nil {
+			return Data{}, err
+		}
+	}
+	if req.Days > 0 {
+		data.Daily, err = f.GetDailyForecast(latitude, longitude, req.Days, tempUnit, windUnit, precipUnit)
+		if err != nil {
+			return Data{}, err
+		}
+	}
+	if req.Air {
+		data.Air, err = f.GetCurrentAirQuality(latitude, longitude)
+		if err != nil {
+			return Data{}, err
+		}
+	}
+	return data, nil
+}
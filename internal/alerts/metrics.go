@@ -0,0 +1,161 @@
+package alerts
+
+import (
+	"sort"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Source is the kind of data a metric is read from.
+type Source string
+
+const (
+	SourceHourly Source = "hourly"
+	SourceDaily  Source = "daily"
+	SourceAir    Source = "air"
+)
+
+// metric reads one variable out of a forecast or air quality reading. Exactly
+// one of the value functions is set, matching source.
+type metric struct {
+	name   string
+	source Source
+
+	hourly func(weather.HourlyForecast) (float64, string)
+	daily  func(weather.DailyForecast) (float64, string)
+	air    func(weather.AirQuality) (float64, string)
+}
+
+var metrics = indexMetrics(
+	hourlyMetric("temperature", func(h weather.HourlyForecast) (float64, string) {
+		return h.Temperature, h.Units.Temperature
+	}),
+	hourlyMetric("apparent_temperature", func(h weather.HourlyForecast) (float64, string) {
+		return h.ApparentTemperature, h.Units.Temperature
+	}),
+	hourlyMetric("dew_point", func(h weather.HourlyForecast) (float64, string) {
+		return h.Comfort().DewPoint, h.Units.Temperature
+	}),
+	hourlyMetric("humidity", func(h weather.HourlyForecast) (float64, string) {
+		return h.Humidity, "%"
+	}),
+	hourlyMetric("cloud_cover", func(h weather.HourlyForecast) (float64, string) {
+		return h.Cloudy, "%"
+	}),
+	hourlyMetric("precipitation", func(h weather.HourlyForecast) (float64, string) {
+		return h.Precipitation, h.Units.Precipitation
+	}),
+	hourlyMetric("precipitation_probability", func(h weather.HourlyForecast) (float64, string) {
+		return h.PrecipitationProb, "%"
+	}),
+	hourlyMetric("snowfall", func(h weather.HourlyForecast) (float64, string) {
+		return h.SnowFall, h.Units.Snowfall()
+	}),
+	hourlyMetric("wind_speed", func(h weather.HourlyForecast) (float64, string) {
+		return h.WindSpeed, h.Units.WindSpeed
+	}),
+	hourlyMetric("wind_gusts", func(h weather.HourlyForecast) (float64, string) {
+		return h.WindGusts, h.Units.WindSpeed
+	}),
+
+	dailyMetric("temperature_max", func(d weather.DailyForecast) (float64, string) {
+		return d.MaxTemperature, d.Units.Temperature
+	}),
+	dailyMetric("temperature_min", func(d weather.DailyForecast) (float64, string) {
+		return d.MinTemperature, d.Units.Temperature
+	}),
+	dailyMetric("precipitation_sum", func(d weather.DailyForecast) (float64, string) {
+		return d.PrecipitationSum, d.Units.Precipitation
+	}),
+	dailyMetric("precipitation_probability_mean", func(d weather.DailyForecast) (float64, string) {
+		return d.PrecipitationProb, "%"
+	}),
+	dailyMetric("wind_speed_max", func(d weather.DailyForecast) (float64, string) {
+		return d.MaxWindSpeed, d.Units.WindSpeed
+	}),
+	dailyMetric("wind_gusts_max", func(d weather.DailyForecast) (float64, string) {
+		return d.WindGusts, d.Units.WindSpeed
+	}),
+
+	airMetric("pm2_5", func(a weather.AirQuality) (float64, string) {
+		return a.PM25, a.Units.PM25
+	}),
+	airMetric("pm10", func(a weather.AirQuality) (float64, string) {
+		return a.PM10, a.Units.PM10
+	}),
+	airMetric("carbon_monoxide", func(a weather.AirQuality) (float64, string) {
+		return a.CarbonMonoxide, a.Units.CarbonMonoxide
+	}),
+	airMetric("nitrogen_dioxide", func(a weather.AirQuality) (float64, string) {
+		return a.NitrogenDioxide, a.Units.NitrogenDioxide
+	}),
+	airMetric("ozone", func(a weather.AirQuality) (float64, string) {
+		return a.Ozone, a.Units.Ozone
+	}),
+	airMetric("uv_index", func(a weather.AirQuality) (float64, string) {
+		return a.UVIndex, ""
+	}),
+	airMetric("us_aqi", func(a weather.AirQuality) (float64, string) {
+		return a.USAQI, ""
+	}),
+	airMetric("european_aqi", func(a weather.AirQuality) (float64, string) {
+		return a.EuropeanAQI, ""
+	}),
+)
+
+func hourlyMetric(name string, value func(weather.HourlyForecast) (float64, string)) metric {
+	return metric{name: name, source: SourceHourly, hourly: value}
+}
+
+func dailyMetric(name string, value func(weather.DailyForecast) (float64, string)) metric {
+	return metric{name: name, source: SourceDaily, daily: value}
+}
+
+func airMetric(name string, value func(weather.AirQuality) (float64, string)) metric {
+	return metric{name: name, source: SourceAir, air: value}
+}
+
+func indexMetrics(list ...metric) map[string]metric {
+	index := make(map[string]metric, len(list))
+	for _, m := range list {
+		index[m.name] = m
+	}
+	return index
+}
+
+// metricAliases are the shorter names accepted in expressions.
+var metricAliases = map[string]string{
+	"temp":            "temperature",
+	"feels_like":      "apparent_temperature",
+	"clouds":          "cloud_cover",
+	"rain":            "precipitation",
+	"rain_chance":     "precipitation_probability",
+	"snow":            "snowfall",
+	"wind":            "wind_speed",
+	"gusts":           "wind_gusts",
+	"max_temperature": "temperature_max",
+	"min_temperature": "temperature_min",
+	"max_temp":        "temperature_max",
+	"min_temp":        "temperature_min",
+	"pm25":            "pm2_5",
+	"aqi":             "us_aqi",
+}
+
+// lookupMetric resolves a metric name or alias.
+func lookupMetric(name string) (metric, bool) {
+	if canonical, ok := metricAliases[name]; ok {
+		name = canonical
+	}
+	m, ok := metrics[name]
+	return m, ok
+}
+
+// Metrics returns the names of every metric, sorted.
+func Metrics() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,350 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Aggregation combines the values in a window before comparing them. Without
+// one, a rule fires when any single value satisfies the comparison.
+type Aggregation string
+
+const (
+	AggregateAny Aggregation = ""
+	AggregateMin Aggregation = "min"
+	AggregateMax Aggregation = "max"
+	AggregateAvg Aggregation = "avg"
+	AggregateSum Aggregation = "sum"
+)
+
+var aggregations = map[string]Aggregation{
+	"any":  AggregateAny,
+	"min":  AggregateMin,
+	"max":  AggregateMax,
+	"avg":  AggregateAvg,
+	"mean": AggregateAvg,
+	"sum":  AggregateSum,
+}
+
+// Operator compares a value with the threshold.
+type Operator string
+
+const (
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpEqual        Operator = "=="
+	OpNotEqual     Operator = "!="
+)
+
+// compare reports whether value op threshold holds.
+func (op Operator) compare(value, threshold float64) bool {
+	switch op {
+	case OpGreater:
+		return value > threshold
+	case OpGreaterEqual:
+		return value >= threshold
+	case OpLess:
+		return value < threshold
+	case OpLessEqual:
+		return value <= threshold
+	case OpEqual:
+		return value == threshold
+	default:
+		return value != threshold
+	}
+}
+
+// WindowKind selects which forecast times a rule looks at.
+type WindowKind string
+
+const (
+	WindowAll      WindowKind = ""         // Everything that was fetched
+	WindowNow      WindowKind = "now"      // The current hour or day
+	WindowToday    WindowKind = "today"    // The rest of the local calendar day
+	WindowTomorrow WindowKind = "tomorrow" // The next local calendar day
+	WindowNext     WindowKind = "next"     // From now for Duration
+)
+
+// Window is the span of forecast times a rule is evaluated over.
+type Window struct {
+	Kind     WindowKind
+	Duration time.Duration // Only for WindowNext
+}
+
+func (w Window) String() string {
+	switch w.Kind {
+	case WindowNext:
+		if w.Duration%(24*time.Hour) == 0 {
+			return fmt.Sprintf("within next %dd", w.Duration/(24*time.Hour))
+		}
+		return fmt.Sprintf("within next %dh", w.Duration/time.Hour)
+	default:
+		return string(w.Kind)
+	}
+}
+
+// Horizon returns how far ahead of now the window reaches, which tells
+// callers how much forecast to fetch. It is zero for WindowAll.
+func (w Window) Horizon() time.Duration {
+	switch w.Kind {
+	case WindowNow:
+		return time.Hour
+	case WindowToday:
+		return 24 * time.Hour
+	case WindowTomorrow:
+		return 48 * time.Hour
+	case WindowNext:
+		return w.Duration
+	default:
+		return 0
+	}
+}
+
+// Expression is a parsed rule condition such as
+// "max wind_gusts > 60 within next 6h".
+type Expression struct {
+	Aggregation Aggregation
+	Metric      string
+	Operator    Operator
+	Threshold   float64
+	Window      Window
+
+	metric metric
+}
+
+// Source returns the kind of data the expression is evaluated against.
+func (e *Expression) Source() Source {
+	return e.metric.source
+}
+
+// String returns the expression in its canonical form.
+func (e *Expression) String() string {
+	var b strings.Builder
+	if e.Aggregation != AggregateAny {
+		b.WriteString(string(e.Aggregation) + " ")
+	}
+	fmt.Fprintf(&b, "%s %s %s", e.Metric, e.Operator, strconv.FormatFloat(e.Threshold, 'f', -1, 64))
+	if e.Window.Kind != WindowAll {
+		b.WriteString(" " + e.Window.String())
+	}
+	return b.String()
+}
+
+// ParseError reports where an expression could not be parsed.
+type ParseError struct {
+	Expression string
+	Position   int // Byte offset of the offending token
+	Message    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d in %q", e.Message, e.Position+1, e.Expression)
+}
+
+// Parse parses an expression of the form
+//
+//	[min|max|avg|sum] <metric> <op> <number> [<window>]
+//
+// where op is one of > >= < <= == != and window is one of "now", "today",
+// "tomorrow" or "within [next] <n>h|d". Without a window the rule looks at
+// every value that was fetched.
+func Parse(expression string) (*Expression, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: expression, tokens: tokens}
+	return p.parse()
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenOperator
+	tokenEOF
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+	pos   int
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || r == '_'):
+			for i < len(input) && isIdentChar(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(input[start:i]), pos: start})
+		case r < utf8.RuneSelf && (unicode.IsDigit(r) || r == '-' || r == '+' || r == '.'):
+			i++
+			for i < len(input) && (isDigit(input[i]) || input[i] == '.') {
+				i++
+			}
+			value, err := strconv.ParseFloat(input[start:i], 64)
+			if err != nil {
+				return nil, &ParseError{input, start, fmt.Sprintf("invalid number %q", input[start:i])}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:i], value: value, pos: start})
+		case strings.ContainsRune("<>=!", r):
+			i++
+			if i < len(input) && input[i] == '=' {
+				i++
+			}
+			op := input[start:i]
+			if op == "=" {
+				op = "=="
+			}
+			if op == "!" {
+				return nil, &ParseError{input, start, `unexpected "!"`}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+		default:
+			return nil, &ParseError{input, start, fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &ParseError{p.input, t.pos, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parse() (*Expression, error) {
+	expr := &Expression{}
+
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, p.errorf(t, "expected a metric")
+	}
+	if agg, ok := aggregations[t.text]; ok && p.peek().kind == tokenIdent {
+		expr.Aggregation = agg
+		t = p.next()
+	}
+	m, ok := lookupMetric(t.text)
+	if !ok {
+		return nil, p.errorf(t, "unknown metric %q (known: %s)", t.text, strings.Join(Metrics(), ", "))
+	}
+	expr.metric = m
+	expr.Metric = m.name
+
+	t = p.next()
+	if t.kind != tokenOperator {
+		return nil, p.errorf(t, "expected a comparison operator after %q", expr.Metric)
+	}
+	expr.Operator = Operator(t.text)
+
+	t = p.next()
+	if t.kind != tokenNumber {
+		return nil, p.errorf(t, "expected a number after %q", expr.Operator)
+	}
+	expr.Threshold = t.value
+
+	windowToken := p.peek()
+	window, err := p.parseWindow()
+	if err != nil {
+		return nil, err
+	}
+	expr.Window = window
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+
+	if m.source == SourceAir && window.Kind != WindowAll && window.Kind != WindowNow {
+		return nil, p.errorf(
+			windowToken,
+			"%s is only available for the current hour, not %q",
+			expr.Metric,
+			window,
+		)
+	}
+	return expr, nil
+}
+
+func (p *parser) parseWindow() (Window, error) {
+	t := p.peek()
+	if t.kind != tokenIdent {
+		return Window{}, nil
+	}
+	p.next()
+
+	switch t.text {
+	case "now":
+		return Window{Kind: WindowNow}, nil
+	case "today":
+		return Window{Kind: WindowToday}, nil
+	case "tomorrow":
+		return Window{Kind: WindowTomorrow}, nil
+	case "within", "in", "over", "next":
+		if t.text != "next" && p.peek().kind == tokenIdent && p.peek().text == "next" {
+			p.next()
+		}
+		duration, err := p.parseDuration()
+		if err != nil {
+			return Window{}, err
+		}
+		return Window{Kind: WindowNext, Duration: duration}, nil
+	default:
+		return Window{}, p.errorf(t, `unknown window %q (want "now", "today", "tomorrow" or "within <n>h")`, t.text)
+	}
+}
+
+func (p *parser) parseDuration() (time.Duration, error) {
+	t := p.next()
+	if t.kind != tokenNumber || t.value <= 0 || t.value != float64(int(t.value)) {
+		return 0, p.errorf(t, "expected a whole number of hours or days")
+	}
+	unit := p.next()
+	switch unit.text {
+	case "h", "hour", "hours":
+		return time.Duration(t.value) * time.Hour, nil
+	case "d", "day", "days":
+		return time.Duration(t.value) * 24 * time.Hour, nil
+	default:
+		return 0, p.errorf(unit, `expected "h" or "d" after %s`, t.text)
+	}
+}
@@ -0,0 +1,110 @@
+package alerts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Forms(t *testing.T) {
+	tests := map[string]string{
+		"precipitation_probability > 60 within next 3h": "precipitation_probability > 60 within next 3h",
+		"min temp < 0 tomorrow":                         "min temperature < 0 tomorrow",
+		"pm2_5 > 35":                                    "pm2_5 > 35",
+		"MAX gusts>=80 in next 2 days":                  "max wind_gusts >= 80 within next 2d",
+		"avg humidity = 100 today":                      "avg humidity == 100 today",
+		"temperature_min <= -5.5 next 48h":              "temperature_min <= -5.5 within next 2d",
+		"aqi != 0 now":                                  "us_aqi != 0 now",
+	}
+	for input, canonical := range tests {
+		expr, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", input, err)
+			continue
+		}
+		if expr.String() != canonical {
+			t.Errorf("Expected Parse(%q) to be %q, got %q", input, canonical, expr.String())
+		}
+	}
+}
+
+func TestParse_Fields(t *testing.T) {
+	expr, err := Parse("max wind_gusts > 60 within next 6h")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if expr.Aggregation != AggregateMax || expr.Metric != "wind_gusts" || expr.Operator != OpGreater {
+		t.Errorf("Unexpected expression %+v", expr)
+	}
+	if expr.Threshold != 60 || expr.Window.Kind != WindowNext || expr.Window.Duration != 6*time.Hour {
+		t.Errorf("Unexpected threshold or window %+v", expr)
+	}
+	if expr.Source() != SourceHourly || expr.Window.Horizon() != 6*time.Hour {
+		t.Errorf("Expected an hourly rule with a 6h horizon, got %s and %s", expr.Source(), expr.Window.Horizon())
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		message  string
+		position int
+	}{
+		{"temprature < 0", `unknown metric "temprature"`, 1},
+		{"temp 0", "expected a comparison operator", 6},
+		{"temp < cold", "expected a number", 8},
+		{"temp < 0 yesterday", `unknown window "yesterday"`, 10},
+		{"temp < 0 within 3", `expected "h" or "d"`, 18},
+		{"temp < 0 within 1.5h", "whole number", 17},
+		{"pm2_5 > 35 tomorrow", "only available for the current hour", 12},
+		{"temp < 0 today and more", `unexpected "and"`, 16},
+		{"temp ≈ 0", "unexpected character '≈'", 6},
+		{"", "expected a metric", 1},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Expected a ParseError for %q, got %v", test.input, err)
+			continue
+		}
+		if !strings.Contains(parseErr.Message, test.message) {
+			t.Errorf("Expected %q to fail with %q, got %q", test.input, test.message, parseErr.Message)
+		}
+		if parseErr.Position+1 != test.position {
+			t.Errorf("Expected %q to fail at position %d, got %d", test.input, test.position, parseErr.Position+1)
+		}
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	rule, err := NewRule("", "gusts > 70")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	if rule.Name != "wind_gusts > 70" {
+		t.Errorf("Expected the rule to be named after its condition, got '%s'", rule.Name)
+	}
+
+	if _, err := NewRule("broken", "gusts >"); err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Errorf("Expected an error naming the rule, got %v", err)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	rules := []Rule{
+		mustRule(t, "precipitation_probability > 60 within next 3h"),
+		mustRule(t, "min temp < 0 tomorrow"),
+		mustRule(t, "precipitation_sum > 10 within next 3d"),
+		mustRule(t, "pm2_5 > 35"),
+	}
+
+	req := Require(rules)
+	if req.Hours != 49 || req.Days != 4 || !req.Air {
+		t.Errorf("Expected 49 hours, 4 days and air quality, got %+v", req)
+	}
+	if req := Require(rules[:1]); req.Hours != 4 || req.Days != 0 || req.Air {
+		t.Errorf("Expected only 4 hours, got %+v", req)
+	}
+}
@@ -0,0 +1,110 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rule is a named alert condition, e.g.
+//
+//	{"name": "frost", "when": "min temp < 0 tomorrow", "severity": "warning"}
+//
+// Rules are parsed when they are created or unmarshalled, so an invalid
+// expression is reported when the configuration is loaded rather than when
+// the rule is first evaluated.
+type Rule struct {
+	Name     string `json:"name"`
+	When     string `json:"when"`
+	Severity string `json:"severity,omitempty"`
+
+	expr *Expression
+}
+
+// NewRule parses the expression and returns the rule. An empty name defaults
+// to the expression itself.
+func NewRule(name, when string) (Rule, error) {
+	rule := Rule{Name: name, When: when}
+	if err := rule.compile(); err != nil {
+		return Rule{}, err
+	}
+	return rule, nil
+}
+
+// UnmarshalJSON decodes and validates a rule.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	type plain Rule
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = Rule(decoded)
+	return r.compile()
+}
+
+func (r *Rule) compile() error {
+	if strings.TrimSpace(r.When) == "" {
+		return fmt.Errorf("rule %q has no condition", r.Name)
+	}
+	expr, err := Parse(r.When)
+	if err != nil {
+		if r.Name != "" {
+			return fmt.Errorf("invalid rule %q: %w", r.Name, err)
+		}
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+	if r.Name == "" {
+		r.Name = expr.String()
+	}
+	r.expr = expr
+	return nil
+}
+
+// Expression returns the parsed condition, or nil for a zero Rule.
+func (r Rule) Expression() *Expression {
+	return r.expr
+}
+
+// Requirements describes the data a set of rules needs to be evaluated.
+type Requirements struct {
+	Hours int64 // Hourly forecast hours to fetch, 0 when none are needed
+	Days  int64 // Daily forecast days to fetch, 0 when none are needed
+	Air   bool  // Whether the current air quality is needed
+}
+
+// Default forecast lengths for rules without a window.
+const (
+	defaultHours = 48
+	defaultDays  = 7
+	maxDays      = 16
+)
+
+// Require returns the data needed to evaluate the rules.
+func Require(rules []Rule) Requirements {
+	var req Requirements
+	for _, rule := range rules {
+		if rule.expr == nil {
+			continue
+		}
+		horizon := rule.expr.Window.Horizon()
+		switch rule.expr.Source() {
+		case SourceHourly:
+			hours := int64(defaultHours)
+			if horizon > 0 {
+				// One extra hour covers the hour that is under way.
+				hours = int64(horizon/time.Hour) + 1
+			}
+			req.Hours = max(req.Hours, hours)
+		case SourceDaily:
+			days := int64(defaultDays)
+			if horizon > 0 {
+				days = int64(horizon/(24*time.Hour)) + 1
+			}
+			req.Days = min(max(req.Days, days), maxDays)
+		case SourceAir:
+			req.Air = true
+		}
+	}
+	return req
+}
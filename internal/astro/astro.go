@@ -0,0 +1,150 @@
+// Package astro calculates sun and moon events locally, without any network
+// access. The formulas follow Jean Meeus' "Astronomical Algorithms" in the
+// simplified form popularised by the SunCalc library, which is accurate to
+// about a minute for the sun and a few minutes for the moon.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Location is a point on the Earth's surface in decimal degrees.
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Day bundles the sun and moon events of one local calendar day.
+type Day struct {
+	Sun  SunTimes  `json:"sun"`
+	Moon MoonTimes `json:"moon"`
+}
+
+// ForDay calculates the sun and moon events for the calendar day containing
+// date, in date's time zone. All returned times are in that time zone.
+func ForDay(loc Location, date time.Time) Day {
+	return Day{
+		Sun:  Sun(loc, date),
+		Moon: Moon(loc, date),
+	}
+}
+
+// Interval is a span of time. Both ends are zero when it does not occur.
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// IsZero reports whether the interval does not occur.
+func (i Interval) IsZero() bool {
+	return i.Start.IsZero() && i.End.IsZero()
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	if i.IsZero() {
+		return 0
+	}
+	return i.End.Sub(i.Start)
+}
+
+// Position is the position of the sun or moon in the sky.
+type Position struct {
+	Elevation float64 `json:"elevation"`          // Degrees above the horizon
+	Azimuth   float64 `json:"azimuth"`            // Degrees clockwise from north
+	Distance  float64 `json:"distance,omitempty"` // km from the Earth's centre, moon only
+}
+
+const (
+	rad       = math.Pi / 180
+	obliquity = rad * 23.4397 // Obliquity of the ecliptic
+
+	julian1970 = 2440588.0
+	julian2000 = 2451545.0
+	dayMillis  = 24 * 60 * 60 * 1000
+)
+
+func toJulian(t time.Time) float64 {
+	return float64(t.UnixMilli())/dayMillis - 0.5 + julian1970
+}
+
+func fromJulian(j float64) time.Time {
+	return time.UnixMilli(int64(math.Round((j + 0.5 - julian1970) * dayMillis)))
+}
+
+// toDays returns the days since the J2000.0 epoch.
+func toDays(t time.Time) float64 {
+	return toJulian(t) - julian2000
+}
+
+// equatorial coordinates in radians, plus the distance in km for the moon.
+type equatorial struct {
+	rightAscension float64
+	declination    float64
+	distance       float64
+}
+
+func rightAscension(longitude, latitude float64) float64 {
+	return math.Atan2(
+		math.Sin(longitude)*math.Cos(obliquity)-math.Tan(latitude)*math.Sin(obliquity),
+		math.Cos(longitude),
+	)
+}
+
+func declination(longitude, latitude float64) float64 {
+	return math.Asin(
+		math.Sin(latitude)*math.Cos(obliquity) +
+			math.Cos(latitude)*math.Sin(obliquity)*math.Sin(longitude),
+	)
+}
+
+func siderealTime(days, westLongitude float64) float64 {
+	return rad*(280.16+360.9856235*days) - westLongitude
+}
+
+func altitude(hourAngle, latitude, declination float64) float64 {
+	return math.Asin(
+		math.Sin(latitude)*math.Sin(declination) +
+			math.Cos(latitude)*math.Cos(declination)*math.Cos(hourAngle),
+	)
+}
+
+// azimuth returns the azimuth in radians clockwise from north.
+func azimuth(hourAngle, latitude, declination float64) float64 {
+	south := math.Atan2(
+		math.Sin(hourAngle),
+		math.Cos(hourAngle)*math.Sin(latitude)-math.Tan(declination)*math.Cos(latitude),
+	)
+	return math.Mod(south+math.Pi+2*math.Pi, 2*math.Pi)
+}
+
+// refraction approximates atmospheric refraction in radians for an altitude
+// in radians (Sæmundsson, 1986).
+func refraction(altitude float64) float64 {
+	if altitude < 0 {
+		altitude = 0
+	}
+	return 0.0002967 / math.Tan(altitude+0.00312536/(altitude+0.08901179))
+}
+
+// localNoon returns 12:00 on date's calendar day in date's time zone.
+func localNoon(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 12, 0, 0, 0, date.Location())
+}
+
+// localMidnight returns the start of date's calendar day in date's time zone.
+func localMidnight(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+}
+
+// inZone converts t to the time zone, leaving the zero time untouched so that
+// it still marshals as the zero time.
+func inZone(t time.Time, zone *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(zone)
+}
@@ -0,0 +1,223 @@
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// SynodicMonth is the mean time between two new moons, in days.
+const SynodicMonth = 29.530588853
+
+// MoonTimes are the moon events of one day together with its phase at local
+// noon. Rise and Set are the zero time when the moon does not rise or set
+// that day, which happens about once a month even at mid latitudes.
+type MoonTimes struct {
+	Rise       time.Time `json:"rise"`
+	Set        time.Time `json:"set"`
+	AlwaysUp   bool      `json:"always_up"`
+	AlwaysDown bool      `json:"always_down"`
+
+	MoonPhase
+}
+
+// MoonPhase describes how much of the moon is lit.
+type MoonPhase struct {
+	Phase        float64 `json:"phase"`        // 0 new, 0.25 first quarter, 0.5 full, 0.75 last quarter
+	Illumination float64 `json:"illumination"` // Percentage of the disc that is lit
+	Age          float64 `json:"age"`          // Days since the last new moon
+	Name         string  `json:"name"`
+}
+
+// Moon calculates the moonrise and moonset for the calendar day containing
+// date, in date's time zone, and the moon's phase at local noon.
+func Moon(loc Location, date time.Time) MoonTimes {
+	times := moonTimes(loc, localMidnight(date))
+	times.MoonPhase = Phase(localNoon(date))
+	return times
+}
+
+// Phase returns the moon's phase at the given instant.
+func Phase(t time.Time) MoonPhase {
+	days := toDays(t)
+	sun := sunCoords(days)
+	moon := moonCoords(days)
+
+	const sunDistance = 149598000 // km
+	elongation := math.Acos(
+		math.Sin(sun.declination)*math.Sin(moon.declination) +
+			math.Cos(sun.declination)*math.Cos(moon.declination)*
+				math.Cos(sun.rightAscension-moon.rightAscension),
+	)
+	inclination := math.Atan2(
+		sunDistance*math.Sin(elongation),
+		moon.distance-sunDistance*math.Cos(elongation),
+	)
+	angle := math.Atan2(
+		math.Cos(sun.declination)*math.Sin(sun.rightAscension-moon.rightAscension),
+		math.Sin(sun.declination)*math.Cos(moon.declination)-
+			math.Cos(sun.declination)*math.Sin(moon.declination)*
+				math.Cos(sun.rightAscension-moon.rightAscension),
+	)
+
+	sign := 1.0
+	if angle < 0 {
+		sign = -1
+	}
+	phase := 0.5 + 0.5*inclination*sign/math.Pi
+
+	return MoonPhase{
+		Phase:        phase,
+		Illumination: (1 + math.Cos(inclination)) / 2 * 100,
+		Age:          phase * SynodicMonth,
+		Name:         PhaseName(phase),
+	}
+}
+
+// phaseNames are the names of the eight phases, starting at the new moon.
+var phaseNames = [8]string{
+	"New moon",
+	"Waxing crescent",
+	"First quarter",
+	"Waxing gibbous",
+	"Full moon",
+	"Waning gibbous",
+	"Last quarter",
+	"Waning crescent",
+}
+
+// PhaseName names a phase between 0 and 1. The principal phases (new, first
+// quarter, full and last quarter) cover the day either side of the exact
+// instant; the intermediate phases cover the rest.
+func PhaseName(phase float64) string {
+	return phaseNames[PhaseIndex(phase)]
+}
+
+// PhaseIndex returns the index of the phase in the sequence new moon, waxing
+// crescent, first quarter, waxing gibbous, full moon, waning gibbous, last
+// quarter and waning crescent.
+func PhaseIndex(phase float64) int {
+	phase -= math.Floor(phase)
+	const principal = 1 / SynodicMonth // One day either side
+
+	for quarter := 0; quarter <= 4; quarter++ {
+		if math.Abs(phase-float64(quarter)/4) <= principal {
+			return (quarter * 2) % 8
+		}
+	}
+	return int(phase*4)*2 + 1
+}
+
+// MoonPosition returns the moon's apparent position, including refraction,
+// and its distance at the given instant.
+func MoonPosition(loc Location, t time.Time) Position {
+	westLongitude := rad * -loc.Longitude
+	latitude := rad * loc.Latitude
+	days := toDays(t)
+
+	coords := moonCoords(days)
+	hourAngle := siderealTime(days, westLongitude) - coords.rightAscension
+	elevation := altitude(hourAngle, latitude, coords.declination)
+	elevation += refraction(elevation)
+
+	return Position{
+		Elevation: elevation / rad,
+		Azimuth:   azimuth(hourAngle, latitude, coords.declination) / rad,
+		Distance:  coords.distance,
+	}
+}
+
+// moonCoords returns the moon's geocentric equatorial coordinates from its
+// mean orbital elements and the largest periodic terms.
+func moonCoords(days float64) equatorial {
+	meanLongitude := rad * (218.316 + 13.176396*days)
+	meanAnomaly := rad * (134.963 + 13.064993*days)
+	meanDistance := rad * (93.272 + 13.229350*days)
+
+	longitude := meanLongitude + rad*6.289*math.Sin(meanAnomaly)
+	latitude := rad * 5.128 * math.Sin(meanDistance)
+
+	return equatorial{
+		rightAscension: rightAscension(longitude, latitude),
+		declination:    declination(longitude, latitude),
+		distance:       385001 - 20905*math.Cos(meanAnomaly),
+	}
+}
+
+// moonriseElevation is the elevation in degrees of the moon's centre when
+// its upper limb touches the horizon.
+const moonriseElevation = 0.133
+
+// moonTimes finds the moonrise and moonset in the 24 hours from start by
+// fitting a parabola through the moon's elevation every hour.
+func moonTimes(loc Location, start time.Time) MoonTimes {
+	elevationAt := func(hours float64) float64 {
+		t := start.Add(time.Duration(hours * float64(time.Hour)))
+		return MoonPosition(loc, t).Elevation - moonriseElevation
+	}
+
+	var rise, set, extremum float64
+	var hasRise, hasSet bool
+
+	h0 := elevationAt(0)
+	for i := 1.0; i <= 24; i += 2 {
+		h1 := elevationAt(i)
+		h2 := elevationAt(i + 1)
+
+		a := (h0+h2)/2 - h1
+		b := (h2 - h0) / 2
+		xe := -b / (2 * a)
+		extremum = (a*xe+b)*xe + h1
+		discriminant := b*b - 4*a*h1
+
+		roots := 0
+		var x1, x2 float64
+		if discriminant >= 0 {
+			dx := math.Sqrt(discriminant) / (math.Abs(a) * 2)
+			x1 = xe - dx
+			x2 = xe + dx
+			if math.Abs(x1) <= 1 {
+				roots++
+			}
+			if math.Abs(x2) <= 1 {
+				roots++
+			}
+			if x1 < -1 {
+				x1 = x2
+			}
+		}
+
+		switch roots {
+		case 1:
+			if h0 < 0 {
+				rise, hasRise = i+x1, true
+			} else {
+				set, hasSet = i+x1, true
+			}
+		case 2:
+			if extremum < 0 {
+				rise, set = i+x2, i+x1
+			} else {
+				rise, set = i+x1, i+x2
+			}
+			hasRise, hasSet = true, true
+		}
+
+		if hasRise && hasSet {
+			break
+		}
+		h0 = h2
+	}
+
+	var times MoonTimes
+	if hasRise {
+		times.Rise = start.Add(time.Duration(rise * float64(time.Hour)))
+	}
+	if hasSet {
+		times.Set = start.Add(time.Duration(set * float64(time.Hour)))
+	}
+	if !hasRise && !hasSet {
+		times.AlwaysUp = extremum > 0
+		times.AlwaysDown = !times.AlwaysUp
+	}
+	return times
+}
@@ -0,0 +1,93 @@
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPhase_PrincipalPhases(t *testing.T) {
+	tests := []struct {
+		at           time.Time
+		name         string
+		illumination float64
+	}{
+		{time.Date(2024, 4, 8, 18, 21, 0, 0, time.UTC), "New moon", 0},
+		{time.Date(2024, 4, 15, 19, 13, 0, 0, time.UTC), "First quarter", 50},
+		{time.Date(2024, 4, 23, 23, 49, 0, 0, time.UTC), "Full moon", 100},
+		{time.Date(2024, 5, 1, 11, 27, 0, 0, time.UTC), "Last quarter", 50},
+	}
+	for _, test := range tests {
+		phase := Phase(test.at)
+		if phase.Name != test.name {
+			t.Errorf("Expected %s on %s, got '%s'", test.name, test.at.Format(time.DateOnly), phase.Name)
+		}
+		if math.Abs(phase.Illumination-test.illumination) > 2 {
+			t.Errorf(
+				"Expected %.0f%% illumination on %s, got %.1f%%",
+				test.illumination,
+				test.at.Format(time.DateOnly),
+				phase.Illumination,
+			)
+		}
+	}
+}
+
+func TestPhaseName_Intermediate(t *testing.T) {
+	tests := map[float64]string{
+		0.1:  "Waxing crescent",
+		0.4:  "Waxing gibbous",
+		0.6:  "Waning gibbous",
+		0.9:  "Waning crescent",
+		0.99: "New moon",
+	}
+	for phase, expected := range tests {
+		if name := PhaseName(phase); name != expected {
+			t.Errorf("Expected phase %.2f to be '%s', got '%s'", phase, expected, name)
+		}
+	}
+}
+
+func TestMoon_RiseAndSet(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	loc := Location{Latitude: 40.7128, Longitude: -74.006}
+	day := time.Date(2024, 4, 23, 0, 0, 0, 0, newYork)
+
+	times := Moon(loc, day)
+	if times.Rise.IsZero() || times.Set.IsZero() {
+		t.Fatalf("Expected a moonrise and a moonset, got %+v", times)
+	}
+	// The full moon rises around sunset and sets around sunrise.
+	sun := Sun(loc, day)
+	if diff := times.Rise.Sub(sun.Sunset); diff < -time.Hour || diff > time.Hour {
+		t.Errorf("Expected the full moon to rise near sunset (%s), got %s", sun.Sunset, times.Rise)
+	}
+	if diff := times.Set.Sub(sun.Sunrise); diff < -time.Hour || diff > time.Hour {
+		t.Errorf("Expected the full moon to set near sunrise (%s), got %s", sun.Sunrise, times.Set)
+	}
+
+	for name, at := range map[string]time.Time{"rise": times.Rise, "set": times.Set} {
+		if elevation := MoonPosition(loc, at).Elevation; math.Abs(elevation-moonriseElevation) > 0.3 {
+			t.Errorf("Expected the moon on the horizon at moon%s, got %.2f°", name, elevation)
+		}
+	}
+}
+
+func TestMoon_AlwaysUpOrDown(t *testing.T) {
+	// Near the north pole the moon stays up or down for days at a time.
+	pole := Location{Latitude: 89, Longitude: 0}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var up, down bool
+	for i := 0; i < 30; i++ {
+		times := Moon(pole, start.AddDate(0, 0, i))
+		up = up || times.AlwaysUp
+		down = down || times.AlwaysDown
+		if times.AlwaysUp && times.AlwaysDown {
+			t.Fatalf("Expected the moon to be either always up or always down, got %+v", times)
+		}
+	}
+	if !up || !down {
+		t.Errorf("Expected both always-up and always-down days in a month, got up=%v down=%v", up, down)
+	}
+}
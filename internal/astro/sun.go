@@ -0,0 +1,217 @@
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Sun elevations in degrees that define the events of a day. Sunrise and
+// sunset account for refraction and the radius of the solar disc.
+const (
+	ElevationSunrise      = -0.833
+	ElevationCivil        = -6.0
+	ElevationNautical     = -12.0
+	ElevationAstronomical = -18.0
+
+	// The golden hour runs while the sun is between -4° and 6°, the blue hour
+	// while it is between -6° and -4°.
+	ElevationGoldenHourHigh = 6.0
+	ElevationBlueHourHigh   = -4.0
+)
+
+// SunTimes are the sun events of one day. An event is the zero time when the
+// sun does not cross its elevation that day, e.g. during the midnight sun or
+// the polar night.
+type SunTimes struct {
+	SolarNoon     time.Time `json:"solar_noon"`
+	NoonElevation float64   `json:"noon_elevation"` // Degrees above the horizon at solar noon
+
+	Sunrise time.Time `json:"sunrise"`
+	Sunset  time.Time `json:"sunset"`
+
+	CivilDawn        time.Time `json:"civil_dawn"`
+	CivilDusk        time.Time `json:"civil_dusk"`
+	NauticalDawn     time.Time `json:"nautical_dawn"`
+	NauticalDusk     time.Time `json:"nautical_dusk"`
+	AstronomicalDawn time.Time `json:"astronomical_dawn"`
+	AstronomicalDusk time.Time `json:"astronomical_dusk"`
+
+	MorningGoldenHour Interval `json:"morning_golden_hour"`
+	EveningGoldenHour Interval `json:"evening_golden_hour"`
+	MorningBlueHour   Interval `json:"morning_blue_hour"`
+	EveningBlueHour   Interval `json:"evening_blue_hour"`
+
+	MidnightSun bool `json:"midnight_sun"` // The sun stays above the horizon all day
+	PolarNight  bool `json:"polar_night"`  // The sun stays below the horizon all day
+}
+
+// DayLength returns the time between sunrise and sunset, which is 24 hours
+// during the midnight sun and zero during the polar night.
+func (s SunTimes) DayLength() time.Duration {
+	switch {
+	case s.MidnightSun:
+		return 24 * time.Hour
+	case s.Sunrise.IsZero() || s.Sunset.IsZero():
+		return 0
+	default:
+		return s.Sunset.Sub(s.Sunrise)
+	}
+}
+
+// Sun calculates the sun events for the calendar day containing date, in
+// date's time zone.
+func Sun(loc Location, date time.Time) SunTimes {
+	zone := date.Location()
+	transit := newSolarTransit(loc, localNoon(date))
+
+	times := SunTimes{
+		SolarNoon:     inZone(fromJulian(transit.noon), zone),
+		NoonElevation: 90 - math.Abs(loc.Latitude-transit.declination/rad),
+	}
+
+	crossing := func(elevation float64) (rise, set time.Time, state int) {
+		rise, set, state = transit.crossing(elevation)
+		return inZone(rise, zone), inZone(set, zone), state
+	}
+
+	var state int
+	times.Sunrise, times.Sunset, state = crossing(ElevationSunrise)
+	times.MidnightSun = state > 0
+	times.PolarNight = state < 0
+	times.CivilDawn, times.CivilDusk, _ = crossing(ElevationCivil)
+	times.NauticalDawn, times.NauticalDusk, _ = crossing(ElevationNautical)
+	times.AstronomicalDawn, times.AstronomicalDusk, _ = crossing(ElevationAstronomical)
+
+	goldenLowRise, goldenLowSet, goldenLowState := crossing(ElevationBlueHourHigh)
+	goldenHighRise, goldenHighSet, goldenHighState := crossing(ElevationGoldenHourHigh)
+
+	if goldenLowState == 0 {
+		times.MorningBlueHour = spanning(times.CivilDawn, goldenLowRise)
+		times.EveningBlueHour = spanning(goldenLowSet, times.CivilDusk)
+
+		// When the sun never climbs above the golden hour, the light stays
+		// golden from its start in the morning until it ends in the evening.
+		if goldenHighState < 0 {
+			goldenHighRise, goldenHighSet = times.SolarNoon, times.SolarNoon
+		}
+		times.MorningGoldenHour = spanning(goldenLowRise, goldenHighRise)
+		times.EveningGoldenHour = spanning(goldenHighSet, goldenLowSet)
+	}
+
+	return times
+}
+
+// spanning returns the interval between two events, or the zero interval when
+// either does not occur.
+func spanning(start, end time.Time) Interval {
+	if start.IsZero() || end.IsZero() {
+		return Interval{}
+	}
+	return Interval{Start: start, End: end}
+}
+
+// SunElevation returns the sun's geometric elevation in degrees above the
+// horizon at the given instant, without atmospheric refraction.
+func SunElevation(loc Location, t time.Time) float64 {
+	return SunPosition(loc, t).Elevation
+}
+
+// SunPosition returns the sun's geometric position at the given instant.
+func SunPosition(loc Location, t time.Time) Position {
+	westLongitude := rad * -loc.Longitude
+	latitude := rad * loc.Latitude
+	days := toDays(t)
+
+	coords := sunCoords(days)
+	hourAngle := siderealTime(days, westLongitude) - coords.rightAscension
+
+	return Position{
+		Elevation: altitude(hourAngle, latitude, coords.declination) / rad,
+		Azimuth:   azimuth(hourAngle, latitude, coords.declination) / rad,
+	}
+}
+
+func solarMeanAnomaly(days float64) float64 {
+	return rad * (357.5291 + 0.98560028*days)
+}
+
+func eclipticLongitude(meanAnomaly float64) float64 {
+	center := rad * (1.9148*math.Sin(meanAnomaly) +
+		0.02*math.Sin(2*meanAnomaly) +
+		0.0003*math.Sin(3*meanAnomaly))
+	perihelion := rad * 102.9372
+	return meanAnomaly + center + perihelion + math.Pi
+}
+
+func sunCoords(days float64) equatorial {
+	longitude := eclipticLongitude(solarMeanAnomaly(days))
+	return equatorial{
+		rightAscension: rightAscension(longitude, 0),
+		declination:    declination(longitude, 0),
+	}
+}
+
+const julian0 = 0.0009
+
+// solarTransit holds what is needed to find when the sun crosses a given
+// elevation on one day.
+type solarTransit struct {
+	latitude      float64
+	westLongitude float64
+	cycle         float64
+	meanAnomaly   float64
+	longitude     float64
+	declination   float64
+	noon          float64 // Julian date of solar noon
+}
+
+func newSolarTransit(loc Location, near time.Time) solarTransit {
+	westLongitude := rad * -loc.Longitude
+	days := toDays(near)
+	cycle := math.Round(days - julian0 - westLongitude/(2*math.Pi))
+
+	approx := approxTransit(0, westLongitude, cycle)
+	meanAnomaly := solarMeanAnomaly(approx)
+	longitude := eclipticLongitude(meanAnomaly)
+
+	return solarTransit{
+		latitude:      rad * loc.Latitude,
+		westLongitude: westLongitude,
+		cycle:         cycle,
+		meanAnomaly:   meanAnomaly,
+		longitude:     longitude,
+		declination:   declination(longitude, 0),
+		noon:          solarTransitJulian(approx, meanAnomaly, longitude),
+	}
+}
+
+func approxTransit(hourAngle, westLongitude, cycle float64) float64 {
+	return julian0 + (hourAngle+westLongitude)/(2*math.Pi) + cycle
+}
+
+func solarTransitJulian(days, meanAnomaly, longitude float64) float64 {
+	return julian2000 + days + 0.0053*math.Sin(meanAnomaly) - 0.0069*math.Sin(2*longitude)
+}
+
+// crossing returns when the sun rises through and sets below the elevation
+// in degrees. state is 1 when the sun stays above it all day, -1 when it
+// stays below, and 0 when it crosses, in which case rise and set are set.
+func (s solarTransit) crossing(elevation float64) (rise, set time.Time, state int) {
+	cosHourAngle := (math.Sin(rad*elevation) - math.Sin(s.latitude)*math.Sin(s.declination)) /
+		(math.Cos(s.latitude) * math.Cos(s.declination))
+	switch {
+	case cosHourAngle < -1:
+		return time.Time{}, time.Time{}, 1
+	case cosHourAngle > 1:
+		return time.Time{}, time.Time{}, -1
+	}
+
+	hourAngle := math.Acos(cosHourAngle)
+	setJulian := solarTransitJulian(
+		approxTransit(hourAngle, s.westLongitude, s.cycle),
+		s.meanAnomaly,
+		s.longitude,
+	)
+	riseJulian := s.noon - (setJulian - s.noon)
+	return fromJulian(riseJulian), fromJulian(setJulian), 0
+}
@@ -0,0 +1,139 @@
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var greenwich = Location{Latitude: 51.4769, Longitude: -0.0005}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return zone
+}
+
+// expectClock checks that an event falls within two minutes of the expected
+// local time, the accuracy of the published almanac tables.
+func expectClock(t *testing.T, name string, got time.Time, hour, minute int) {
+	t.Helper()
+	if got.IsZero() {
+		t.Errorf("Expected %s at %02d:%02d, got none", name, hour, minute)
+		return
+	}
+	expected := time.Date(got.Year(), got.Month(), got.Day(), hour, minute, 0, 0, got.Location())
+	if diff := got.Sub(expected); diff < -2*time.Minute || diff > 2*time.Minute {
+		t.Errorf("Expected %s at %02d:%02d, got %s", name, hour, minute, got.Format("15:04:05"))
+	}
+}
+
+func TestSun_GreenwichSolstice(t *testing.T) {
+	london := mustLoadLocation(t, "Europe/London")
+	times := Sun(greenwich, time.Date(2023, 6, 21, 0, 0, 0, 0, london))
+
+	// NOAA solar calculator, 21 June 2023 at the Royal Observatory.
+	expectClock(t, "sunrise", times.Sunrise, 4, 43)
+	expectClock(t, "sunset", times.Sunset, 21, 21)
+	expectClock(t, "solar noon", times.SolarNoon, 13, 2)
+	expectClock(t, "civil dawn", times.CivilDawn, 3, 56)
+	expectClock(t, "civil dusk", times.CivilDusk, 22, 9)
+
+	if times.SolarNoon.Location() != london {
+		t.Errorf("Expected times in Europe/London, got %s", times.SolarNoon.Location())
+	}
+	if math.Abs(times.NoonElevation-61.96) > 0.05 {
+		t.Errorf("Expected a noon elevation of 61.96°, got %.2f°", times.NoonElevation)
+	}
+	// The sun never sinks to -18° at midsummer in London.
+	if !times.AstronomicalDawn.IsZero() || !times.AstronomicalDusk.IsZero() {
+		t.Errorf(
+			"Expected no astronomical twilight, got %s and %s",
+			times.AstronomicalDawn,
+			times.AstronomicalDusk,
+		)
+	}
+}
+
+func TestSun_GoldenAndBlueHours(t *testing.T) {
+	london := mustLoadLocation(t, "Europe/London")
+	times := Sun(greenwich, time.Date(2023, 6, 21, 0, 0, 0, 0, london))
+
+	if !times.MorningBlueHour.End.Equal(times.MorningGoldenHour.Start) {
+		t.Errorf(
+			"Expected the blue hour to lead into the golden hour, got %+v and %+v",
+			times.MorningBlueHour,
+			times.MorningGoldenHour,
+		)
+	}
+	if !times.MorningBlueHour.Start.Equal(times.CivilDawn) {
+		t.Errorf("Expected the morning blue hour to start at civil dawn, got %s", times.MorningBlueHour.Start)
+	}
+	if !times.EveningGoldenHour.End.Equal(times.EveningBlueHour.Start) {
+		t.Errorf(
+			"Expected the golden hour to lead into the blue hour, got %+v and %+v",
+			times.EveningGoldenHour,
+			times.EveningBlueHour,
+		)
+	}
+
+	checks := []struct {
+		name      string
+		at        time.Time
+		low, high float64
+	}{
+		{"morning golden hour", times.MorningGoldenHour.Start, -4, -4},
+		{"morning golden hour end", times.MorningGoldenHour.End, 6, 6},
+		{"evening golden hour", times.EveningGoldenHour.Start, 6, 6},
+		{"evening blue hour", times.EveningBlueHour.End, -6, -6},
+	}
+	for _, check := range checks {
+		elevation := SunElevation(greenwich, check.at)
+		if elevation < check.low-0.2 || elevation > check.high+0.2 {
+			t.Errorf("Expected the sun at %v° at the %s, got %.2f°", check.low, check.name, elevation)
+		}
+	}
+}
+
+func TestSun_PolarDayAndNight(t *testing.T) {
+	oslo := mustLoadLocation(t, "Europe/Oslo")
+	tromso := Location{Latitude: 69.65, Longitude: 18.96}
+
+	summer := Sun(tromso, time.Date(2023, 6, 21, 0, 0, 0, 0, oslo))
+	if !summer.MidnightSun || !summer.Sunrise.IsZero() || !summer.Sunset.IsZero() {
+		t.Errorf("Expected the midnight sun in Tromsø, got %+v", summer)
+	}
+	if summer.DayLength() != 24*time.Hour {
+		t.Errorf("Expected a 24 hour day, got %s", summer.DayLength())
+	}
+
+	winter := Sun(tromso, time.Date(2023, 12, 21, 0, 0, 0, 0, oslo))
+	if !winter.PolarNight || winter.DayLength() != 0 {
+		t.Errorf("Expected the polar night in Tromsø, got %+v", winter)
+	}
+	if winter.CivilDawn.IsZero() || winter.CivilDusk.IsZero() {
+		t.Error("Expected civil twilight around noon during the polar night")
+	}
+	// The sun stays below 6°, so the golden hours meet at solar noon.
+	if !winter.MorningGoldenHour.End.Equal(winter.SolarNoon) {
+		t.Errorf("Expected the golden hour to last until solar noon, got %+v", winter.MorningGoldenHour)
+	}
+}
+
+func TestSunElevation_EquatorEquinox(t *testing.T) {
+	equator := Location{Latitude: 0, Longitude: 0}
+	times := Sun(equator, time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+
+	if elevation := SunElevation(equator, times.SolarNoon); elevation < 89.5 {
+		t.Errorf("Expected the sun overhead at noon on the equinox, got %.2f°", elevation)
+	}
+	if elevation := SunElevation(equator, times.SolarNoon.Add(6*time.Hour)); math.Abs(elevation) > 1 {
+		t.Errorf("Expected the sun on the horizon six hours after noon, got %.2f°", elevation)
+	}
+	if length := times.DayLength(); length < 12*time.Hour || length > 12*time.Hour+10*time.Minute {
+		t.Errorf("Expected a day of just over 12 hours, got %s", length)
+	}
+}
@@ -0,0 +1,157 @@
+// Package metno is a client for MET Norway's Locationforecast 2.0 API at
+// api.met.no. The API's terms ask clients to identify themselves, to keep
+// responses until they expire and to revalidate them with
+// If-Modified-Since rather than fetch them again, which the Client does.
+package metno
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const baseURL = "https://api.met.no/weatherapi/locationforecast/2.0/"
+
+// DefaultUserAgent identifies sky to the API, which rejects requests
+// without a User-Agent.
+const DefaultUserAgent = "sky github.com/mohithbuilds/sky"
+
+// Client is a client for the Locationforecast API. It caches responses
+// until they expire and is safe for concurrent use.
+type Client struct {
+	httpClient *http.Client
+	BaseURL    string
+	// UserAgent is sent with every request; DefaultUserAgent when empty.
+	// The API asks for contact details in it.
+	UserAgent string
+
+	mu    sync.Mutex
+	cache map[string]*cached
+	now   func() time.Time
+}
+
+// cached is a response kept until it expires.
+type cached struct {
+	forecast     *Forecast
+	lastModified string
+	expires      time.Time
+}
+
+// NewClient creates a Client. A nil httpClient uses one with a timeout.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{
+		httpClient: httpClient,
+		BaseURL:    baseURL,
+		cache:      make(map[string]*cached),
+		now:        time.Now,
+	}
+}
+
+// GetCompact returns the compact forecast for a location: the most used
+// variables.
+func (c *Client) GetCompact(latitude, longitude float64) (*Forecast, error) {
+	forecast, err := c.getForecast("compact", latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compact forecast: %w", err)
+	}
+	return forecast, nil
+}
+
+// GetComplete returns the complete forecast for a location, which adds
+// gusts, dew point, fog, UV and precipitation probabilities.
+func (c *Client) GetComplete(latitude, longitude float64) (*Forecast, error) {
+	forecast, err := c.getForecast("complete", latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complete forecast: %w", err)
+	}
+	return forecast, nil
+}
+
+func (c *Client) getForecast(product string, latitude, longitude float64) (*Forecast, error) {
+	params := url.Values{}
+	// The API rejects coordinates with more than four decimals.
+	params.Set("lat", fmt.Sprintf("%.4f", latitude))
+	params.Set("lon", fmt.Sprintf("%.4f", longitude))
+	fullURL := c.BaseURL + product + "?" + params.Encode()
+
+	c.mu.Lock()
+	entry := c.cache[fullURL]
+	c.mu.Unlock()
+	if entry != nil && c.now().Before(entry.expires) {
+		return entry.forecast, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if entry != nil && entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to GET URL: %s: %w", fullURL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		c.store(fullURL, &cached{
+			forecast:     entry.forecast,
+			lastModified: entry.lastModified,
+			expires:      c.expires(resp),
+		})
+		return entry.forecast, nil
+	// 203 marks a product that is deprecated but still served.
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNonAuthoritativeInfo:
+		return nil, fmt.Errorf(
+			"API returned non-OK status: %s, body: %s",
+			resp.Status,
+			string(data),
+		)
+	}
+
+	var forecast Forecast
+	if err := json.Unmarshal(data, &forecast); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal forecast: %w", err)
+	}
+	c.store(fullURL, &cached{
+		forecast:     &forecast,
+		lastModified: resp.Header.Get("Last-Modified"),
+		expires:      c.expires(resp),
+	})
+	return &forecast, nil
+}
+
+func (c *Client) store(key string, entry *cached) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[key] = entry
+}
+
+// expires returns when a response expires, or now when it does not say.
+func (c *Client) expires(resp *http.Response) time.Time {
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil {
+		return c.now()
+	}
+	return expires
+}
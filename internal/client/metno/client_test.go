@@ -0,0 +1,186 @@
+package metno
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testForecast = `{
+	"type": "Feature",
+	"geometry": {"type": "Point", "coordinates": [10.75, 59.91, 12]},
+	"properties": {
+		"meta": {
+			"updated_at": "2026-06-15T11:32:14Z",
+			"units": {"air_temperature": "celsius", "wind_speed": "m/s", "precipitation_amount": "mm"}
+		},
+		"timeseries": [
+			{
+				"time": "2026-06-15T12:00:00Z",
+				"data": {
+					"instant": {"details": {
+						"air_temperature": 18.4,
+						"relative_humidity": 61.2,
+						"wind_speed": 4.1,
+						"wind_speed_of_gust": 8.3,
+						"wind_from_direction": 210.5,
+						"cloud_area_fraction": 45.3
+					}},
+					"next_1_hours": {
+						"summary": {"symbol_code": "partlycloudy_day"},
+						"details": {"precipitation_amount": 0, "probability_of_precipitation": 3.1}
+					},
+					"next_6_hours": {
+						"summary": {"symbol_code": "lightrainshowers_day"},
+						"details": {"precipitation_amount": 0.6, "air_temperature_max": 19.8, "air_temperature_min": 15.1}
+					}
+				}
+			},
+			{
+				"time": "2026-06-21T18:00:00Z",
+				"data": {
+					"instant": {"details": {"air_temperature": 14.0, "wind_speed": 2.0}},
+					"next_6_hours": {
+						"summary": {"symbol_code": "cloudy"},
+						"details": {"precipitation_amount": 0}
+					}
+				}
+			}
+		]
+	}
+}`
+
+func TestGetComplete_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/complete" {
+			t.Errorf("Expected path '/complete', got '%s'", r.URL.Path)
+		}
+		if r.URL.Query().Get("lat") != "59.9139" || r.URL.Query().Get("lon") != "10.7522" {
+			t.Errorf("Expected coordinates with four decimals, got '%s'", r.URL.RawQuery)
+		}
+		if r.UserAgent() != DefaultUserAgent {
+			t.Errorf("Expected User-Agent '%s', got '%s'", DefaultUserAgent, r.UserAgent())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, testForecast)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	forecast, err := client.GetComplete(59.913868, 10.752245)
+	if err != nil {
+		t.Fatalf("GetComplete failed: %v", err)
+	}
+
+	if forecast.Units["wind_speed"] != "m/s" || forecast.UpdatedAt.Minute() != 32 {
+		t.Errorf("Expected the meta data, got %+v", forecast)
+	}
+	if len(forecast.Timeseries) != 2 {
+		t.Fatalf("Expected 2 timesteps, got %d", len(forecast.Timeseries))
+	}
+	first := forecast.Timeseries[0]
+	if first.Data.Instant.Details.AirTemperature != 18.4 || *first.Data.Instant.Details.WindSpeedOfGust != 8.3 {
+		t.Errorf("Expected the instant values, got %+v", first.Data.Instant.Details)
+	}
+	if first.Data.Next1Hours.Summary.SymbolCode != "partlycloudy_day" || *first.Data.Next6Hours.Details.AirTemperatureMax != 19.8 {
+		t.Errorf("Expected the periods, got %+v", first.Data)
+	}
+	last := forecast.Timeseries[1]
+	if last.Data.Next1Hours != nil || last.Data.Instant.Details.WindSpeedOfGust != nil {
+		t.Errorf("Expected no next hour or gusts, got %+v", last.Data)
+	}
+}
+
+func TestGetCompact_Cache(t *testing.T) {
+	current := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	lastModified := current.Add(-30 * time.Minute).Format(http.TimeFormat)
+	requests := 0
+	modified := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Expires", current.Add(30*time.Minute).Format(http.TimeFormat))
+		if !modified && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = fmt.Fprint(w, testForecast)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	client.now = func() time.Time { return current }
+
+	first, err := client.GetCompact(59.91, 10.75)
+	if err != nil {
+		t.Fatalf("GetCompact failed: %v", err)
+	}
+	if _, err := client.GetCompact(59.91, 10.75); err != nil || requests != 1 {
+		t.Errorf("Expected a cached forecast before it expires, got %d requests, %v", requests, err)
+	}
+
+	// Once expired it is revalidated.
+	current = current.Add(time.Hour)
+	second, err := client.GetCompact(59.91, 10.75)
+	if err != nil {
+		t.Fatalf("GetCompact failed: %v", err)
+	}
+	if requests != 2 || second != first {
+		t.Errorf("Expected the cached forecast to be revalidated, got %d requests", requests)
+	}
+	if _, err := client.GetCompact(59.91, 10.75); err != nil || requests != 2 {
+		t.Errorf("Expected a revalidated forecast to be cached until the new expiry, got %d requests, %v", requests, err)
+	}
+
+	current = current.Add(time.Hour)
+	modified = true
+	third, err := client.GetCompact(59.91, 10.75)
+	if err != nil {
+		t.Fatalf("GetCompact failed: %v", err)
+	}
+	if requests != 3 || third == first {
+		t.Errorf("Expected a modified forecast to replace the cached one, got %d requests", requests)
+	}
+
+	// Other locations are cached apart.
+	if _, err := client.GetCompact(60.39, 5.32); err != nil || requests != 4 {
+		t.Errorf("Expected another location to be fetched, got %d requests, %v", requests, err)
+	}
+}
+
+func TestGetComplete_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, "Missing or invalid User-Agent")
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	_, err := client.GetComplete(59.91, 10.75)
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "User-Agent") {
+		t.Errorf("Expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestSplitSymbol(t *testing.T) {
+	tests := []struct {
+		code, weather, variant string
+	}{
+		{"clearsky_day", "clearsky", "day"},
+		{"fair_night", "fair", "night"},
+		{"lightsnowshowers_polartwilight", "lightsnowshowers", "polartwilight"},
+		{"heavyrainandthunder", "heavyrainandthunder", ""},
+	}
+	for _, tt := range tests {
+		weather, variant := SplitSymbol(tt.code)
+		if weather != tt.weather || variant != tt.variant {
+			t.Errorf("SplitSymbol(%q): expected %q, %q, got %q, %q", tt.code, tt.weather, tt.variant, weather, variant)
+		}
+	}
+}
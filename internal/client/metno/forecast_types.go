@@ -0,0 +1,96 @@
+package metno
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Forecast is a Locationforecast response. Times are in UTC.
+type Forecast struct {
+	UpdatedAt time.Time
+	// Units maps variable names to their units, e.g. "air_temperature" to
+	// "celsius" and "wind_speed" to "m/s".
+	Units      map[string]string
+	Timeseries []Timestep
+}
+
+// UnmarshalJSON decodes the GeoJSON feature the API returns.
+func (f *Forecast) UnmarshalJSON(data []byte) error {
+	var feature struct {
+		Properties struct {
+			Meta struct {
+				UpdatedAt time.Time         `json:"updated_at"`
+				Units     map[string]string `json:"units"`
+			} `json:"meta"`
+			Timeseries []Timestep `json:"timeseries"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &feature); err != nil {
+		return err
+	}
+	f.UpdatedAt = feature.Properties.Meta.UpdatedAt
+	f.Units = feature.Properties.Meta.Units
+	f.Timeseries = feature.Properties.Timeseries
+	return nil
+}
+
+// Timestep is the forecast at one time: the instant values, and summaries
+// of the next hour, six and twelve hours. Timesteps are hourly for the first
+// days, then six-hourly, and the later ones have no next hour.
+type Timestep struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		Instant struct {
+			Details Instant `json:"details"`
+		} `json:"instant"`
+		Next1Hours  *Period `json:"next_1_hours"`
+		Next6Hours  *Period `json:"next_6_hours"`
+		Next12Hours *Period `json:"next_12_hours"`
+	} `json:"data"`
+}
+
+// Instant holds the values at a Timestep's time. The pointers are only
+// set in the complete forecast.
+type Instant struct {
+	AirPressureAtSeaLevel float64  `json:"air_pressure_at_sea_level"`
+	AirTemperature        float64  `json:"air_temperature"`
+	CloudAreaFraction     float64  `json:"cloud_area_fraction"`
+	RelativeHumidity      float64  `json:"relative_humidity"`
+	WindFromDirection     float64  `json:"wind_from_direction"`
+	WindSpeed             float64  `json:"wind_speed"`
+	WindSpeedOfGust       *float64 `json:"wind_speed_of_gust"`
+	DewPointTemperature   *float64 `json:"dew_point_temperature"`
+	FogAreaFraction       *float64 `json:"fog_area_fraction"`
+	UltravioletIndex      *float64 `json:"ultraviolet_index_clear_sky"`
+}
+
+// Period summarises the hours following a Timestep.
+type Period struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details PeriodDetails `json:"details"`
+}
+
+// PeriodDetails holds the values of a Period. Only the precipitation
+// amount is always set.
+type PeriodDetails struct {
+	PrecipitationAmount        float64  `json:"precipitation_amount"`
+	ProbabilityOfPrecipitation *float64 `json:"probability_of_precipitation"`
+	ProbabilityOfThunder       *float64 `json:"probability_of_thunder"`
+	AirTemperatureMax          *float64 `json:"air_temperature_max"`
+	AirTemperatureMin          *float64 `json:"air_temperature_min"`
+}
+
+// SplitSymbol splits a symbol code into the weather and the variant for
+// the time of day, e.g. "partlycloudy_day" into "partlycloudy" and "day".
+// The variant is "day", "night", "polartwilight" or empty.
+func SplitSymbol(code string) (string, string) {
+	for _, variant := range []string{"day", "night", "polartwilight"} {
+		if weather, ok := strings.CutSuffix(code, "_"+variant); ok {
+			return weather, variant
+		}
+	}
+	return code, ""
+}
@@ -0,0 +1,47 @@
+package nws
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Alert is an active watch, warning or advisory, from /alerts/active.
+type Alert struct {
+	ID          string    `json:"id"`
+	Event       string    `json:"event"` // e.g. "Heat Advisory"
+	Headline    string    `json:"headline"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction"`
+	Severity    string    `json:"severity"` // Extreme, Severe, Moderate, Minor or Unknown
+	Certainty   string    `json:"certainty"`
+	Urgency     string    `json:"urgency"`
+	AreaDesc    string    `json:"areaDesc"`
+	SenderName  string    `json:"senderName"`
+	Effective   time.Time `json:"effective"`
+	Onset       time.Time `json:"onset"`
+	Expires     time.Time `json:"expires"`
+	Ends        time.Time `json:"ends"`
+}
+
+// GetActiveAlerts returns the alerts in effect at a location.
+func (c *Client) GetActiveAlerts(latitude, longitude float64) ([]Alert, error) {
+	params := url.Values{}
+	params.Set("point", fmt.Sprintf("%.4f,%.4f", latitude, longitude))
+	fullURL := c.BaseURL + "alerts/active?" + params.Encode()
+
+	var result struct {
+		Features []struct {
+			Properties Alert `json:"properties"`
+		} `json:"features"`
+	}
+	if err := c.get(fullURL, &result); err != nil {
+		return nil, fmt.Errorf("failed to get active alerts: %w", err)
+	}
+
+	alerts := make([]Alert, 0, len(result.Features))
+	for _, feature := range result.Features {
+		alerts = append(alerts, feature.Properties)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,92 @@
+// Package nws is a client for the US National Weather Service API at
+// api.weather.gov. A forecast takes two steps: GetPoint resolves a
+// location to the forecast office grid that covers it, and the point's URLs
+// then serve the forecast periods and the raw gridpoint data.
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const baseURL = "https://api.weather.gov/"
+
+// DefaultUserAgent identifies sky to the API, which rejects requests
+// without a User-Agent.
+const DefaultUserAgent = "sky (github.com/mohithbuilds/sky)"
+
+// ErrorResponse is the problem document the API returns with errors.
+type ErrorResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Client is a client for the NWS API.
+type Client struct {
+	httpClient *http.Client
+	BaseURL    string
+	// UserAgent is sent with every request; DefaultUserAgent when empty.
+	// The API asks for contact details in it.
+	UserAgent string
+}
+
+// NewClient creates a Client. A nil httpClient uses one with a timeout.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{
+		httpClient: httpClient,
+		BaseURL:    baseURL,
+	}
+}
+
+// get fetches a URL and decodes the JSON response into v.
+func (c *Client) get(url string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/geo+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to GET URL: %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Unable to read the response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr ErrorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Title != "" {
+			if apiErr.Detail != "" {
+				return fmt.Errorf("API error (%s): %s: %s", resp.Status, apiErr.Title, apiErr.Detail)
+			}
+			return fmt.Errorf("API error (%s): %s", resp.Status, apiErr.Title)
+		}
+		return fmt.Errorf(
+			"API returned non-OK status: %s, body: %s",
+			resp.Status,
+			string(data),
+		)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
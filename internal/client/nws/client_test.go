@@ -0,0 +1,166 @@
+package nws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetPoint_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/points/40.7128,-74.0060" {
+			t.Errorf("Expected the point path with four decimals, got '%s'", r.URL.Path)
+		}
+		if r.UserAgent() != DefaultUserAgent {
+			t.Errorf("Expected User-Agent '%s', got '%s'", DefaultUserAgent, r.UserAgent())
+		}
+		if r.Header.Get("Accept") != "application/geo+json" {
+			t.Errorf("Expected Accept 'application/geo+json', got '%s'", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		_, _ = fmt.Fprintln(w, `{
+			"properties": {
+				"gridId": "OKX",
+				"gridX": 33,
+				"gridY": 35,
+				"forecast": "https://api.weather.gov/gridpoints/OKX/33,35/forecast",
+				"forecastHourly": "https://api.weather.gov/gridpoints/OKX/33,35/forecast/hourly",
+				"forecastGridData": "https://api.weather.gov/gridpoints/OKX/33,35",
+				"timeZone": "America/New_York"
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	point, err := client.GetPoint(40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("GetPoint failed: %v", err)
+	}
+	if point.GridID != "OKX" || point.GridX != 33 || point.GridY != 35 || point.TimeZone != "America/New_York" {
+		t.Errorf("Expected the OKX grid, got %+v", point)
+	}
+}
+
+func TestGetPoint_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintln(w, `{
+			"type": "https://api.weather.gov/problems/InvalidPoint",
+			"title": "Data Unavailable For Requested Point",
+			"status": 404,
+			"detail": "Unable to provide data for requested point 51.5,-0.12"
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	client.UserAgent = "test (test@example.com)"
+	_, err := client.GetPoint(51.5, -0.12)
+	if err == nil || !strings.Contains(err.Error(), "Data Unavailable For Requested Point") {
+		t.Errorf("Expected the problem title in the error, got %v", err)
+	}
+}
+
+func TestGetHourlyForecast_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("units") != "si" {
+			t.Errorf("Expected units to be 'si', got '%s'", r.URL.Query().Get("units"))
+		}
+		_, _ = fmt.Fprintln(w, `{
+			"properties": {
+				"updateTime": "2026-06-15T11:00:00+00:00",
+				"units": "si",
+				"periods": [{
+					"number": 1,
+					"startTime": "2026-06-15T08:00:00-04:00",
+					"endTime": "2026-06-15T09:00:00-04:00",
+					"isDaytime": true,
+					"temperature": 21,
+					"temperatureUnit": "C",
+					"probabilityOfPrecipitation": {"unitCode": "wmoUnit:percent", "value": null},
+					"relativeHumidity": {"unitCode": "wmoUnit:percent", "value": 70},
+					"windSpeed": "15 km/h",
+					"windDirection": "SW",
+					"icon": "https://api.weather.gov/icons/land/day/sct?size=small",
+					"shortForecast": "Partly Sunny"
+				}]
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	forecast, err := client.GetHourlyForecast(&Point{ForecastHourly: server.URL + "/gridpoints/OKX/33,35/forecast/hourly"})
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(forecast.Periods) != 1 {
+		t.Fatalf("Expected 1 period, got %d", len(forecast.Periods))
+	}
+	period := forecast.Periods[0]
+	if !period.StartTime.Equal(time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)) || period.Temperature != 21 {
+		t.Errorf("Expected 21 °C at 12:00 UTC, got %+v", period)
+	}
+	if period.ProbabilityOfPrecipitation.Value != nil || *period.RelativeHumidity.Value != 70 {
+		t.Errorf("Expected a null probability and 70%% humidity, got %+v", period)
+	}
+}
+
+func TestGetActiveAlerts_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/alerts/active" || r.URL.Query().Get("point") != "40.7128,-74.0060" {
+			t.Errorf("Expected the active alerts at the point, got '%s'", r.URL.String())
+		}
+		_, _ = fmt.Fprintln(w, `{
+			"type": "FeatureCollection",
+			"features": [{
+				"properties": {
+					"id": "urn:oid:2.49.0.1.840.0.1",
+					"event": "Heat Advisory",
+					"headline": "Heat Advisory issued June 15 at 4:00AM EDT",
+					"severity": "Moderate",
+					"urgency": "Expected",
+					"senderName": "NWS New York NY",
+					"onset": "2026-06-15T12:00:00-04:00",
+					"expires": "2026-06-15T20:00:00-04:00",
+					"ends": null
+				}
+			}]
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.BaseURL = server.URL + "/"
+	alerts, err := client.GetActiveAlerts(40.7128, -74.006)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Event != "Heat Advisory" || alerts[0].Severity != "Moderate" {
+		t.Fatalf("Expected the heat advisory, got %+v", alerts)
+	}
+	if alerts[0].Onset.Hour() != 12 || !alerts[0].Ends.IsZero() {
+		t.Errorf("Expected the onset and no end, got %+v", alerts[0])
+	}
+}
+
+func TestGetGridData_NonJSONError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprint(w, "upstream timeout")
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	_, err := client.GetGridData(&Point{ForecastGridData: server.URL + "/gridpoints/OKX/33,35"})
+	if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "upstream timeout") {
+		t.Errorf("Expected the status and body in the error, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package nws
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GetPoint returns the forecast grid covering a location. The API only
+// covers the United States and its territories.
+func (c *Client) GetPoint(latitude, longitude float64) (*Point, error) {
+	// The API redirects requests with more than four decimals.
+	fullURL := fmt.Sprintf("%spoints/%.4f,%.4f", c.BaseURL, latitude, longitude)
+
+	var result struct {
+		Properties Point `json:"properties"`
+	}
+	if err := c.get(fullURL, &result); err != nil {
+		return nil, fmt.Errorf("failed to get point: %w", err)
+	}
+	if result.Properties.Forecast == "" || result.Properties.ForecastGridData == "" {
+		return nil, fmt.Errorf("no forecast grid for %.2f, %.2f", latitude, longitude)
+	}
+	return &result.Properties, nil
+}
+
+// GetForecast returns the twelve-hour periods of a point's forecast, in
+// SI units.
+func (c *Client) GetForecast(point *Point) (*Forecast, error) {
+	forecast, err := c.getForecast(point.Forecast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast: %w", err)
+	}
+	return forecast, nil
+}
+
+// GetHourlyForecast returns the hourly periods of a point's forecast, in
+// SI units.
+func (c *Client) GetHourlyForecast(point *Point) (*Forecast, error) {
+	forecast, err := c.getForecast(point.ForecastHourly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly forecast: %w", err)
+	}
+	return forecast, nil
+}
+
+func (c *Client) getForecast(rawURL string) (*Forecast, error) {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	var result struct {
+		Properties Forecast `json:"properties"`
+	}
+	if err := c.get(rawURL+separator+url.Values{"units": {"si"}}.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return &result.Properties, nil
+}
+
+// GetGridData returns the raw gridpoint forecast of a point.
+func (c *Client) GetGridData(point *Point) (*GridData, error) {
+	var result struct {
+		Properties GridData `json:"properties"`
+	}
+	if err := c.get(point.ForecastGridData, &result); err != nil {
+		return nil, fmt.Errorf("failed to get gridpoint data: %w", err)
+	}
+	return &result.Properties, nil
+}
@@ -0,0 +1,196 @@
+package nws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is the forecast grid covering a location, from /points.
+type Point struct {
+	GridID           string `json:"gridId"`
+	GridX            int    `json:"gridX"`
+	GridY            int    `json:"gridY"`
+	Forecast         string `json:"forecast"`
+	ForecastHourly   string `json:"forecastHourly"`
+	ForecastGridData string `json:"forecastGridData"`
+	TimeZone         string `json:"timeZone"`
+}
+
+// Forecast is a forecast of periods: twelve-hour days and nights from the
+// forecast endpoint, or hours from the hourly one.
+type Forecast struct {
+	Updated string   `json:"updateTime"`
+	Units   string   `json:"units"` // "us" or "si"
+	Periods []Period `json:"periods"`
+}
+
+// Period is a period of a Forecast.
+type Period struct {
+	Number                     int       `json:"number"`
+	Name                       string    `json:"name"`
+	StartTime                  time.Time `json:"startTime"`
+	EndTime                    time.Time `json:"endTime"`
+	IsDaytime                  bool      `json:"isDaytime"`
+	Temperature                float64   `json:"temperature"`
+	TemperatureUnit            string    `json:"temperatureUnit"` // "F" or "C"
+	ProbabilityOfPrecipitation Value     `json:"probabilityOfPrecipitation"`
+	RelativeHumidity           Value     `json:"relativeHumidity"`
+	WindSpeed                  string    `json:"windSpeed"`     // e.g. "10 mph" or "5 to 10 km/h"
+	WindDirection              string    `json:"windDirection"` // e.g. "NW"
+	Icon                       string    `json:"icon"`
+	ShortForecast              string    `json:"shortForecast"`
+	DetailedForecast           string    `json:"detailedForecast"`
+}
+
+// Value is a quantity with its unit, e.g. {"unitCode": "wmoUnit:percent",
+// "value": 20}. Value is nil when the API has none.
+type Value struct {
+	UnitCode string   `json:"unitCode"`
+	Value    *float64 `json:"value"`
+}
+
+// Wind returns the highest speed of the period's wind and its unit, e.g.
+// 10 and "mph" for "5 to 10 mph".
+func (p Period) Wind() (float64, string, error) {
+	fields := strings.Fields(p.WindSpeed)
+	if len(fields) < 2 {
+		return 0, "", fmt.Errorf("invalid wind speed %q", p.WindSpeed)
+	}
+	speed, err := strconv.ParseFloat(fields[len(fields)-2], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid wind speed %q", p.WindSpeed)
+	}
+	return speed, fields[len(fields)-1], nil
+}
+
+// GridData is the raw forecast of a gridpoint: layers of values over time,
+// most of them hourly, in the units named by each layer.
+type GridData struct {
+	UpdateTime                 string `json:"updateTime"`
+	Temperature                Layer  `json:"temperature"`
+	Dewpoint                   Layer  `json:"dewpoint"`
+	MaxTemperature             Layer  `json:"maxTemperature"`
+	MinTemperature             Layer  `json:"minTemperature"`
+	RelativeHumidity           Layer  `json:"relativeHumidity"`
+	ApparentTemperature        Layer  `json:"apparentTemperature"`
+	SkyCover                   Layer  `json:"skyCover"`
+	WindDirection              Layer  `json:"windDirection"`
+	WindSpeed                  Layer  `json:"windSpeed"`
+	WindGust                   Layer  `json:"windGust"`
+	ProbabilityOfPrecipitation Layer  `json:"probabilityOfPrecipitation"`
+	QuantitativePrecipitation  Layer  `json:"quantitativePrecipitation"`
+	SnowfallAmount             Layer  `json:"snowfallAmount"`
+}
+
+// Layer is one quantity of GridData, e.g. {"uom": "wmoUnit:degC",
+// "values": [...]}.
+type Layer struct {
+	UOM    string       `json:"uom"`
+	Values []LayerValue `json:"values"`
+}
+
+// LayerValue is the value of a Layer over an ISO 8601 interval, e.g.
+// "2026-06-15T12:00:00+00:00/PT3H".
+type LayerValue struct {
+	ValidTime string   `json:"validTime"`
+	Value     *float64 `json:"value"`
+}
+
+// Interval returns the start and end of the value's validTime.
+func (v LayerValue) Interval() (time.Time, time.Time, error) {
+	start, duration, ok := strings.Cut(v.ValidTime, "/")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid valid time %q", v.ValidTime)
+	}
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid valid time %q: %w", v.ValidTime, err)
+	}
+	d, err := ParseDuration(duration)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid valid time %q: %w", v.ValidTime, err)
+	}
+	return t, t.Add(d), nil
+}
+
+// At returns the value valid at t, and false when there is none.
+func (l Layer) At(t time.Time) (float64, bool) {
+	for _, v := range l.Values {
+		start, end, err := v.Interval()
+		if err != nil || v.Value == nil {
+			continue
+		}
+		if !t.Before(start) && t.Before(end) {
+			return *v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Amount returns how much of an accumulated quantity, like precipitation,
+// falls between from and to. A value is spread evenly over its interval,
+// so a quarter of a six-hour total falls in any ninety minutes of it. It
+// returns false when no value overlaps the period.
+func (l Layer) Amount(from, to time.Time) (float64, bool) {
+	total, found := 0.0, false
+	for _, v := range l.Values {
+		start, end, err := v.Interval()
+		if err != nil || v.Value == nil || !end.After(start) {
+			continue
+		}
+		overlapStart, overlapEnd := start, end
+		if from.After(overlapStart) {
+			overlapStart = from
+		}
+		if to.Before(overlapEnd) {
+			overlapEnd = to
+		}
+		overlap := overlapEnd.Sub(overlapStart)
+		if overlap <= 0 {
+			continue
+		}
+		total += *v.Value * float64(overlap) / float64(end.Sub(start))
+		found = true
+	}
+	return total, found
+}
+
+// ParseDuration parses the ISO 8601 durations the API uses, e.g. "PT1H",
+// "P1D" or "P1DT6H".
+func ParseDuration(s string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		n, _ := strconv.Atoi(rest[:i])
+		unit := rest[i]
+		rest = rest[i+1:]
+		switch {
+		case unit == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case unit == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case unit == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case unit == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	return d, nil
+}
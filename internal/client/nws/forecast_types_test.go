@@ -0,0 +1,110 @@
+package nws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"PT1H", time.Hour, true},
+		{"PT13H", 13 * time.Hour, true},
+		{"P1D", 24 * time.Hour, true},
+		{"P1DT6H", 30 * time.Hour, true},
+		{"PT30M", 30 * time.Minute, true},
+		{"P", 0, false},
+		{"1H", 0, false},
+		{"PT1D", 0, false},
+		{"P1H", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseDuration(%q): expected valid %v, got error %v", tt.input, tt.valid, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseDuration(%q): expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func value(v float64) *float64 {
+	return &v
+}
+
+func TestLayer_At(t *testing.T) {
+	layer := Layer{
+		UOM: "wmoUnit:degC",
+		Values: []LayerValue{
+			{ValidTime: "2026-06-15T10:00:00+00:00/PT2H", Value: value(20)},
+			{ValidTime: "2026-06-15T12:00:00+00:00/PT1H", Value: nil},
+			{ValidTime: "2026-06-15T13:00:00+00:00/PT1H", Value: value(23)},
+		},
+	}
+
+	if v, ok := layer.At(time.Date(2026, 6, 15, 11, 30, 0, 0, time.UTC)); !ok || v != 20 {
+		t.Errorf("Expected 20 within a two-hour value, got %v, %v", v, ok)
+	}
+	if _, ok := layer.At(time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Expected no value for a null")
+	}
+	if v, ok := layer.At(time.Date(2026, 6, 15, 9, 0, 0, 0, time.FixedZone("EDT", -4*3600))); !ok || v != 23 {
+		t.Errorf("Expected 23 at 13:00 UTC given in another zone, got %v, %v", v, ok)
+	}
+	if _, ok := layer.At(time.Date(2026, 6, 15, 14, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Expected no value after the last interval")
+	}
+}
+
+func TestLayer_Amount(t *testing.T) {
+	layer := Layer{
+		UOM: "wmoUnit:mm",
+		Values: []LayerValue{
+			{ValidTime: "2026-06-15T06:00:00+00:00/PT6H", Value: value(6)},
+			{ValidTime: "2026-06-15T12:00:00+00:00/PT6H", Value: value(3)},
+		},
+	}
+	at := func(hour int) time.Time { return time.Date(2026, 6, 15, hour, 0, 0, 0, time.UTC) }
+
+	if v, ok := layer.Amount(at(7), at(8)); !ok || v != 1 {
+		t.Errorf("Expected 1 mm of a six-hour 6 mm, got %v, %v", v, ok)
+	}
+	if v, ok := layer.Amount(at(11), at(13)); !ok || v != 1.5 {
+		t.Errorf("Expected 1.5 mm across two values, got %v, %v", v, ok)
+	}
+	if v, ok := layer.Amount(at(0), at(24)); !ok || v != 9 {
+		t.Errorf("Expected the 9 mm of the day, got %v, %v", v, ok)
+	}
+	if _, ok := layer.Amount(at(19), at(20)); ok {
+		t.Errorf("Expected no amount outside the values")
+	}
+}
+
+func TestPeriod_Wind(t *testing.T) {
+	tests := []struct {
+		input string
+		speed float64
+		unit  string
+		valid bool
+	}{
+		{"10 mph", 10, "mph", true},
+		{"5 to 15 km/h", 15, "km/h", true},
+		{"", 0, "", false},
+		{"calm mph", 0, "", false},
+	}
+	for _, tt := range tests {
+		speed, unit, err := Period{WindSpeed: tt.input}.Wind()
+		if (err == nil) != tt.valid {
+			t.Errorf("Wind(%q): expected valid %v, got error %v", tt.input, tt.valid, err)
+			continue
+		}
+		if speed != tt.speed || unit != tt.unit {
+			t.Errorf("Wind(%q): expected %v %s, got %v %s", tt.input, tt.speed, tt.unit, speed, unit)
+		}
+	}
+}
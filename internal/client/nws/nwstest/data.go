@@ -0,0 +1,83 @@
+package nwstest
+
+import (
+	"math"
+	"time"
+)
+
+// hour is the generated weather of an hour, in SI units.
+type hour struct {
+	temperature              float64 // °C
+	humidity                 float64 // %
+	windSpeed                float64 // km/h
+	skyCover                 float64 // %
+	precipitation            float64 // mm
+	precipitationProbability float64 // %
+	daytime                  bool
+}
+
+// generate returns the weather at t: a daily cycle of temperature, humidity
+// and wind, with clear, cloudy and rainy days taking turns. The day and
+// time of day are those of t's location.
+func generate(t time.Time) hour {
+	cycle := 2 * math.Pi * float64(t.Hour()-9) / 24
+	h := hour{
+		temperature: 20 + 7*math.Sin(cycle) + float64(t.YearDay()%3),
+		humidity:    round(65 - 20*math.Sin(cycle)),
+		windSpeed:   round(12 + 6*math.Sin(cycle)),
+		daytime:     t.Hour() >= 6 && t.Hour() < 18,
+	}
+	switch t.YearDay() % 3 {
+	case 0:
+		h.skyCover = 10
+	case 1:
+		h.skyCover = 60
+		h.precipitationProbability = 10
+	default:
+		h.skyCover = 95
+		h.precipitationProbability = 40
+		if t.Hour() >= 12 && t.Hour() < 18 {
+			h.precipitation = 1.2
+			h.precipitationProbability = 80
+		}
+	}
+	return h
+}
+
+// icon returns the name of the API's icon for the hour, e.g. "bkn".
+func (h hour) icon() string {
+	switch {
+	case h.precipitation > 0:
+		return "rain"
+	case h.skyCover < 25:
+		return "skc"
+	case h.skyCover < 50:
+		return "sct"
+	case h.skyCover < 88:
+		return "bkn"
+	default:
+		return "ovc"
+	}
+}
+
+func (h hour) shortForecast() string {
+	switch h.icon() {
+	case "rain":
+		return "Rain"
+	case "skc":
+		if h.daytime {
+			return "Sunny"
+		}
+		return "Clear"
+	case "sct":
+		return "Partly Cloudy"
+	case "bkn":
+		return "Mostly Cloudy"
+	default:
+		return "Cloudy"
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v)
+}
@@ -0,0 +1,322 @@
+// Package nwstest provides a fake National Weather Service API for tests.
+// It resolves points in the United States to a grid, serves forecasts,
+// hourly forecasts and gridpoint data generated deterministically around
+// Now, and serves the active alerts set in Alerts.
+//
+//	fake := nwstest.NewServer()
+//	defer fake.Close()
+//	client := nws.NewClient(nil)
+//	client.BaseURL = fake.BaseURL()
+package nwstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/nws"
+)
+
+// DefaultNow is the time a new Server pretends it is.
+var DefaultNow = time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+// DefaultTimeZone is the time zone of every point the Server resolves.
+const DefaultTimeZone = "America/New_York"
+
+// Request is a request the Server received.
+type Request struct {
+	// Endpoint is "points", "forecast", "hourly", "gridpoints" or "alerts".
+	Endpoint  string
+	UserAgent string
+}
+
+// Server is a fake NWS API. Set Now and Alerts before making requests.
+type Server struct {
+	*httptest.Server
+	// Now is the current time of the fake. Forecasts start around it like
+	// the real ones.
+	Now time.Time
+	// Alerts are the alerts active at every point.
+	Alerts []nws.Alert
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewServer starts a fake NWS API.
+func NewServer() *Server {
+	s := &Server{Now: DefaultNow}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the URL to set as the BaseURL of an nws.Client.
+func (s *Server) BaseURL() string {
+	return s.URL + "/"
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	endpoint := parts[0]
+	if endpoint == "gridpoints" && len(parts) == 4 {
+		endpoint = "forecast"
+	} else if endpoint == "gridpoints" && len(parts) == 5 {
+		endpoint = "hourly"
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Endpoint: endpoint, UserAgent: r.UserAgent()})
+	s.mu.Unlock()
+	if r.UserAgent() == "" {
+		problem(w, http.StatusForbidden, "Forbidden", "A User Agent is required to identify your application.")
+		return
+	}
+
+	switch {
+	case endpoint == "points" && len(parts) == 2:
+		s.point(w, parts[1])
+	case endpoint == "forecast" || endpoint == "hourly":
+		s.forecast(w, endpoint == "hourly")
+	case endpoint == "gridpoints" && len(parts) == 3:
+		s.gridData(w)
+	case endpoint == "alerts" && len(parts) == 2 && parts[1] == "active":
+		s.alerts(w)
+	default:
+		problem(w, http.StatusNotFound, "Not Found", "")
+	}
+}
+
+func (s *Server) point(w http.ResponseWriter, coordinates string) {
+	lat, lon, ok := strings.Cut(coordinates, ",")
+	latitude, err1 := strconv.ParseFloat(lat, 64)
+	longitude, err2 := strconv.ParseFloat(lon, 64)
+	if !ok || err1 != nil || err2 != nil {
+		problem(w, http.StatusBadRequest, "Invalid Parameter", "Parameter \"point\" is invalid")
+		return
+	}
+	if !inUS(latitude, longitude) {
+		problem(w, http.StatusNotFound, "Data Unavailable For Requested Point", "Unable to provide data for requested point "+coordinates)
+		return
+	}
+
+	x, y := int((longitude+180)*4), int((latitude+90)*4)
+	grid := fmt.Sprintf("%sgridpoints/OKX/%d,%d", s.BaseURL(), x, y)
+	writeJSON(w, map[string]any{
+		"properties": map[string]any{
+			"gridId":           "OKX",
+			"gridX":            x,
+			"gridY":            y,
+			"forecast":         grid + "/forecast",
+			"forecastHourly":   grid + "/forecast/hourly",
+			"forecastGridData": grid,
+			"timeZone":         DefaultTimeZone,
+		},
+	})
+}
+
+// inUS reports whether a location is roughly in the area the API covers.
+func inUS(latitude, longitude float64) bool {
+	return latitude >= 17 && latitude <= 72 && longitude >= -180 && longitude <= -64
+}
+
+// forecast serves a forecast in SI units, which is all nws.Client asks for.
+func (s *Server) forecast(w http.ResponseWriter, hourly bool) {
+	loc := location()
+
+	var periods []map[string]any
+	if hourly {
+		start := s.Now.In(loc).Truncate(time.Hour)
+		for i := range 156 {
+			periods = append(periods, s.period(i+1, "", start.Add(time.Duration(i)*time.Hour), time.Hour))
+		}
+	} else {
+		// Twelve-hour periods start at 6:00 and 18:00, the first one being
+		// the rest of the current one.
+		now := s.Now.In(loc)
+		start := time.Date(now.Year(), now.Month(), now.Day(), 6, 0, 0, 0, loc)
+		if now.Hour() < 6 {
+			start = start.Add(-12 * time.Hour)
+		} else if now.Hour() >= 18 {
+			start = start.Add(12 * time.Hour)
+		}
+		for i := range 14 {
+			periodStart := start.Add(time.Duration(i) * 12 * time.Hour)
+			name := periodStart.Weekday().String()
+			if periodStart.Hour() >= 18 {
+				name += " Night"
+			}
+			period := s.period(i+1, name, periodStart, 12*time.Hour)
+			if i == 0 {
+				period["startTime"] = now.Truncate(time.Hour).Format(time.RFC3339)
+			}
+			periods = append(periods, period)
+		}
+	}
+
+	writeJSON(w, map[string]any{
+		"properties": map[string]any{
+			"updateTime": s.Now.Add(-time.Hour).Format(time.RFC3339),
+			"units":      "si",
+			"periods":    periods,
+		},
+	})
+}
+
+// period returns a forecast period starting at start, described by the
+// weather at its middle, or its warmest or coldest hour for days and
+// nights.
+func (s *Server) period(number int, name string, start time.Time, length time.Duration) map[string]any {
+	middle := generate(start.Add(length / 2))
+	daytime := start.Hour() >= 6 && start.Hour() < 18
+	temperature := middle.temperature
+	if length > time.Hour {
+		for h := range int(length / time.Hour) {
+			t := generate(start.Add(time.Duration(h) * time.Hour)).temperature
+			if daytime && t > temperature || !daytime && t < temperature {
+				temperature = t
+			}
+		}
+	}
+
+	timeOfDay := "night"
+	if daytime {
+		timeOfDay = "day"
+	}
+	icon := middle.icon()
+	return map[string]any{
+		"number":          number,
+		"name":            name,
+		"startTime":       start.Format(time.RFC3339),
+		"endTime":         start.Add(length).Format(time.RFC3339),
+		"isDaytime":       daytime,
+		"temperature":     int(round(temperature)),
+		"temperatureUnit": "C",
+		"probabilityOfPrecipitation": map[string]any{
+			"unitCode": "wmoUnit:percent",
+			"value":    middle.precipitationProbability,
+		},
+		"relativeHumidity": map[string]any{
+			"unitCode": "wmoUnit:percent",
+			"value":    int(middle.humidity),
+		},
+		"windSpeed":        fmt.Sprintf("%d km/h", int(round(middle.windSpeed))),
+		"windDirection":    "SW",
+		"icon":             fmt.Sprintf("%sicons/land/%s/%s?size=medium", s.BaseURL(), timeOfDay, icon),
+		"shortForecast":    middle.shortForecast(),
+		"detailedForecast": middle.shortForecast() + ".",
+	}
+}
+
+func (s *Server) gridData(w http.ResponseWriter) {
+	loc := location()
+	now := s.Now.In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).Add(-24 * time.Hour)
+	const days = 8
+
+	hourly := func(uom string, value func(hour) float64) map[string]any {
+		var values []map[string]any
+		for h := range days * 24 {
+			t := start.Add(time.Duration(h) * time.Hour)
+			values = append(values, layerValue(t, "PT1H", value(generate(t))))
+		}
+		return map[string]any{"uom": uom, "values": values}
+	}
+	// Precipitation accumulates over six hours, like the real layer.
+	sixHourly := func(value func(hour) float64) map[string]any {
+		var values []map[string]any
+		for h := 0; h < days*24; h += 6 {
+			t := start.Add(time.Duration(h) * time.Hour)
+			total := 0.0
+			for i := range 6 {
+				total += value(generate(t.Add(time.Duration(i) * time.Hour)))
+			}
+			values = append(values, layerValue(t, "PT6H", round(total*10)/10))
+		}
+		return map[string]any{"uom": "wmoUnit:mm", "values": values}
+	}
+	extreme := func(startHour int, length int, warmest bool) map[string]any {
+		var values []map[string]any
+		for d := range days {
+			t := start.Add(time.Duration(d*24+startHour) * time.Hour)
+			best := generate(t).temperature
+			for h := range length {
+				v := generate(t.Add(time.Duration(h) * time.Hour)).temperature
+				if warmest && v > best || !warmest && v < best {
+					best = v
+				}
+			}
+			values = append(values, layerValue(t, fmt.Sprintf("PT%dH", length), best))
+		}
+		return map[string]any{"uom": "wmoUnit:degC", "values": values}
+	}
+
+	writeJSON(w, map[string]any{
+		"properties": map[string]any{
+			"updateTime":                 s.Now.Add(-time.Hour).Format(time.RFC3339),
+			"temperature":                hourly("wmoUnit:degC", func(h hour) float64 { return h.temperature }),
+			"dewpoint":                   hourly("wmoUnit:degC", func(h hour) float64 { return h.temperature - (100-h.humidity)/5 }),
+			"maxTemperature":             extreme(7, 13, true),
+			"minTemperature":             extreme(19, 14, false),
+			"relativeHumidity":           hourly("wmoUnit:percent", func(h hour) float64 { return h.humidity }),
+			"apparentTemperature":        hourly("wmoUnit:degC", func(h hour) float64 { return h.temperature - h.windSpeed/10 }),
+			"skyCover":                   hourly("wmoUnit:percent", func(h hour) float64 { return h.skyCover }),
+			"windDirection":              hourly("wmoUnit:degree_(angle)", func(h hour) float64 { return 225 }),
+			"windSpeed":                  hourly("wmoUnit:km_h-1", func(h hour) float64 { return h.windSpeed }),
+			"windGust":                   hourly("wmoUnit:km_h-1", func(h hour) float64 { return h.windSpeed * 1.6 }),
+			"probabilityOfPrecipitation": hourly("wmoUnit:percent", func(h hour) float64 { return h.precipitationProbability }),
+			"quantitativePrecipitation":  sixHourly(func(h hour) float64 { return h.precipitation }),
+			"snowfallAmount":             sixHourly(func(h hour) float64 { return 0 }),
+		},
+	})
+}
+
+func layerValue(start time.Time, duration string, value float64) map[string]any {
+	return map[string]any{
+		"validTime": start.UTC().Format(time.RFC3339) + "/" + duration,
+		"value":     round(value*10) / 10,
+	}
+}
+
+func (s *Server) alerts(w http.ResponseWriter) {
+	features := make([]map[string]any, 0, len(s.Alerts))
+	for _, alert := range s.Alerts {
+		features = append(features, map[string]any{"properties": alert})
+	}
+	writeJSON(w, map[string]any{"type": "FeatureCollection", "features": features})
+}
+
+func location() *time.Location {
+	loc, err := time.LoadLocation(DefaultTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func problem(w http.ResponseWriter, status int, title, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(nws.ErrorResponse{
+		Type:   "https://api.weather.gov/problems/" + strings.ReplaceAll(title, " ", ""),
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,131 @@
+package nwstest_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/nws"
+	"github.com/mohithbuilds/sky/internal/client/nws/nwstest"
+)
+
+func newClient(fake *nwstest.Server) *nws.Client {
+	client := nws.NewClient(nil)
+	client.BaseURL = fake.BaseURL()
+	return client
+}
+
+func TestServer_Forecast(t *testing.T) {
+	fake := nwstest.NewServer()
+	defer fake.Close()
+	client := newClient(fake)
+
+	point, err := client.GetPoint(40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetPoint failed: %v", err)
+	}
+	if point.TimeZone != nwstest.DefaultTimeZone || !strings.HasPrefix(point.Forecast, fake.URL) {
+		t.Errorf("Expected a grid served by the fake, got %+v", point)
+	}
+
+	forecast, err := client.GetForecast(point)
+	if err != nil {
+		t.Fatalf("GetForecast failed: %v", err)
+	}
+	if len(forecast.Periods) != 14 || forecast.Units != "si" {
+		t.Fatalf("Expected 14 periods in SI units, got %d in %q", len(forecast.Periods), forecast.Units)
+	}
+	first := forecast.Periods[0]
+	if !first.StartTime.Equal(nwstest.DefaultNow) || !first.IsDaytime || first.TemperatureUnit != "C" {
+		t.Errorf("Expected the first period to start now by day, got %+v", first)
+	}
+	if forecast.Periods[1].IsDaytime || !strings.HasSuffix(forecast.Periods[1].Name, "Night") {
+		t.Errorf("Expected a night to follow, got %+v", forecast.Periods[1])
+	}
+
+	hourly, err := client.GetHourlyForecast(point)
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(hourly.Periods) != 156 || !hourly.Periods[1].StartTime.Equal(nwstest.DefaultNow.Add(time.Hour)) {
+		t.Errorf("Expected 156 hours from now, got %d", len(hourly.Periods))
+	}
+	if speed, unit, err := hourly.Periods[0].Wind(); err != nil || unit != "km/h" || speed == 0 {
+		t.Errorf("Expected a wind speed in km/h, got %v %s, %v", speed, unit, err)
+	}
+
+	grid, err := client.GetGridData(point)
+	if err != nil {
+		t.Fatalf("GetGridData failed: %v", err)
+	}
+	temperature, ok := grid.Temperature.At(nwstest.DefaultNow)
+	if !ok || grid.Temperature.UOM != "wmoUnit:degC" {
+		t.Fatalf("Expected the temperature now in °C, got %+v", grid.Temperature.UOM)
+	}
+	if diff := temperature - hourly.Periods[0].Temperature; diff < -1 || diff > 1 {
+		t.Errorf("Expected the gridpoint and hourly temperatures to agree, got %v and %v", temperature, hourly.Periods[0].Temperature)
+	}
+	if first.Temperature < temperature {
+		t.Errorf("Expected the day's temperature to be its high, got %v below %v", first.Temperature, temperature)
+	}
+	if _, ok := grid.QuantitativePrecipitation.Amount(nwstest.DefaultNow, nwstest.DefaultNow.Add(24*time.Hour)); !ok {
+		t.Errorf("Expected precipitation amounts")
+	}
+}
+
+func TestServer_UserAgent(t *testing.T) {
+	fake := nwstest.NewServer()
+	defer fake.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, fake.URL+"/points/40.71,-74.01", nil)
+	// An empty value keeps the client from sending its default.
+	req.Header["User-Agent"] = []string{""}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a request without a User-Agent to be forbidden, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_OutsideUS(t *testing.T) {
+	fake := nwstest.NewServer()
+	defer fake.Close()
+
+	_, err := newClient(fake).GetPoint(51.5, -0.12)
+	if err == nil || !strings.Contains(err.Error(), "Data Unavailable For Requested Point") {
+		t.Errorf("Expected a point outside the US to fail, got %v", err)
+	}
+}
+
+func TestServer_Alerts(t *testing.T) {
+	fake := nwstest.NewServer()
+	defer fake.Close()
+	fake.Alerts = []nws.Alert{{Event: "Flood Watch", Severity: "Severe", Onset: nwstest.DefaultNow}}
+
+	alerts, err := newClient(fake).GetActiveAlerts(40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Event != "Flood Watch" || !alerts[0].Onset.Equal(nwstest.DefaultNow) {
+		t.Errorf("Expected the flood watch, got %+v", alerts)
+	}
+}
+
+func TestServer_Requests(t *testing.T) {
+	fake := nwstest.NewServer()
+	defer fake.Close()
+	client := newClient(fake)
+	client.UserAgent = "test (test@example.com)"
+
+	if _, err := client.GetPoint(40.71, -74.01); err != nil {
+		t.Fatalf("GetPoint failed: %v", err)
+	}
+	requests := fake.Requests()
+	if len(requests) != 1 || requests[0].Endpoint != "points" || requests[0].UserAgent != client.UserAgent {
+		t.Errorf("Expected a point request with the User-Agent, got %+v", requests)
+	}
+}
@@ -32,6 +32,8 @@ type AirQualityHourly struct {
 	MugwortPollen       []float64 `json:"mugwort_pollen"`
 	OlivePollen         []float64 `json:"olive_pollen"`
 	RagweedPollen       []float64 `json:"ragweed_pollen"`
+	EuropeanAQI         []float64 `json:"european_aqi"`
+	USAQI               []float64 `json:"us_aqi"`
 }
 
 // HourlyUnits contains the units for each hourly air quality parameter
@@ -52,6 +54,8 @@ type AirQualityHourlyUnits struct {
 	MugwortPollen       string `json:"mugwort_pollen"`
 	OlivePollen         string `json:"olive_pollen"`
 	RagweedPollen       string `json:"ragweed_pollen"`
+	EuropeanAQI         string `json:"european_aqi"`
+	USAQI               string `json:"us_aqi"`
 }
 
 // GetAirQuality fetches air quality data for a given latitude and longitude.
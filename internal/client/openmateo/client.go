@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,36 +19,136 @@ type APIError struct {
 
 type baseClient struct {
 	httpClient *http.Client
+	// Observer, when set, is told about every request, e.g. to export
+	// metrics.
+	Observer Observer
+	// Retries is how many times a request that failed with a network error,
+	// 429 or 5xx status is tried again, waiting RetryBackoff and then twice
+	// as long each time, or longer when a 429 asks for it with Retry-After.
+	Retries      int
+	RetryBackoff time.Duration
+
+	sleep func(time.Duration)
 }
 
-func (bc *baseClient) doRequest(url string) ([]byte, error) {
+// defaultRetryBackoff is the first wait between attempts when RetryBackoff
+// is not set.
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// maxRetryAfter caps how long a Retry-After header can delay a retry.
+const maxRetryAfter = time.Minute
+
+// RequestStats describes one request to the API.
+type RequestStats struct {
+	Endpoint string        // Last element of the URL path, e.g. "forecast"
+	Status   int           // HTTP status; 0 when no response arrived
+	Duration time.Duration // Time until the body was read
+	Retries  int           // Attempts before the one reported
+	CacheHit bool          // Served by a cache in between, which sets X-Cache: HIT
+	// GenerationTime is how long the API reported spending on the
+	// response, from its generationtime_ms field.
+	GenerationTime time.Duration
+	Err            error
+}
+
+// Observer receives the stats of each request a client makes.
+type Observer interface {
+	ObserveRequest(RequestStats)
+}
+
+func (bc *baseClient) doRequest(rawURL string) ([]byte, error) {
+	start := time.Now()
+	stats := RequestStats{Endpoint: endpoint(rawURL)}
+	data, err := bc.getWithRetries(rawURL, &stats)
+	if bc.Observer != nil {
+		stats.Duration = time.Since(start)
+		stats.Err = err
+		if err == nil {
+			stats.GenerationTime = generationTime(data)
+		}
+		bc.Observer.ObserveRequest(stats)
+	}
+	return data, err
+}
+
+func (bc *baseClient) getWithRetries(url string, stats *RequestStats) ([]byte, error) {
+	backoff := bc.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for {
+		data, retryAfter, err := bc.get(url, stats)
+		retryable := stats.Status == 0 || stats.Status == http.StatusTooManyRequests || stats.Status >= 500
+		if err == nil || !retryable || stats.Retries >= bc.Retries {
+			return data, err
+		}
+		// Honour Retry-After, but not so far that a command hangs on it.
+		bc.sleep(max(backoff, min(retryAfter, maxRetryAfter)))
+		backoff *= 2
+		stats.Retries++
+	}
+}
+
+// get sends one request and returns the body, the delay a 429 response
+// asks for with Retry-After and an error for anything but a 200 response.
+func (bc *baseClient) get(url string, stats *RequestStats) ([]byte, time.Duration, error) {
+	stats.Status = 0
 	resp, err := bc.httpClient.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to GET URL: %s: %w", url, err)
+		return nil, 0, fmt.Errorf("Failed to GET URL: %s: %w", url, err)
 	}
 
 	defer resp.Body.Close()
+	stats.Status = resp.StatusCode
+	stats.CacheHit = strings.HasPrefix(strings.ToUpper(resp.Header.Get("X-Cache")), "HIT")
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to read the response body: %w", err)
+		return nil, 0, fmt.Errorf("Unable to read the response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		var retryAfter time.Duration
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+
 		var apiErr APIError
 
 		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error {
-			return nil, fmt.Errorf("API error (%s): %s", resp.Status, apiErr.Reason)
+			return nil, retryAfter, fmt.Errorf("API error (%s): %s", resp.Status, apiErr.Reason)
 		}
 
-		return nil, fmt.Errorf(
+		return nil, retryAfter, fmt.Errorf(
 			"API returned non-OK status: %s, body: %s",
 			resp.Status,
 			string(data),
 		)
 	}
 
-	return data, nil
+	return data, 0, nil
+}
+
+// endpoint returns the last element of a URL's path.
+func endpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "unknown"
+	}
+	return path.Base(u.Path)
+}
+
+// generationTime reads the generationtime_ms field of a response.
+func generationTime(data []byte) time.Duration {
+	var body struct {
+		GenerationTimeMs float64 `json:"generationtime_ms"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return 0
+	}
+	return time.Duration(body.GenerationTimeMs * float64(time.Millisecond))
 }
 
 // GEOCODING CLIENT
@@ -53,6 +157,9 @@ const geocodingBaseURL = "https://geocoding-api.open-meteo.com/v1/"
 type GeocodingClient struct {
 	*baseClient
 	BaseURL string
+	// Language is the ISO 639-1 code used for translated place names.
+	// The API defaults to English when it is empty.
+	Language string
 }
 
 func NewGeocodingClient(httpClient *http.Client) *GeocodingClient {
@@ -62,6 +169,7 @@ func NewGeocodingClient(httpClient *http.Client) *GeocodingClient {
 	return &GeocodingClient{
 		baseClient: &baseClient{
 			httpClient: httpClient,
+			sleep:      time.Sleep,
 		},
 		BaseURL: geocodingBaseURL,
 	}
@@ -73,6 +181,9 @@ const forecastBaseURL = "https://api.open-meteo.com/v1/"
 type ForecastClient struct {
 	*baseClient
 	BaseURL string
+	// Model selects the weather model, e.g. "icon_seamless". The API picks
+	// the best model for the location when it is empty.
+	Model string
 }
 
 func NewForecastClient(httpClient *http.Client) *ForecastClient {
@@ -82,6 +193,7 @@ func NewForecastClient(httpClient *http.Client) *ForecastClient {
 	return &ForecastClient{
 		baseClient: &baseClient{
 			httpClient: httpClient,
+			sleep:      time.Sleep,
 		},
 		BaseURL: forecastBaseURL,
 	}
@@ -102,6 +214,7 @@ func NewAirQualityClient(httpClient *http.Client) *AirQualityClient {
 	return &AirQualityClient{
 		baseClient: &baseClient{
 			httpClient: httpClient,
+			sleep:      time.Sleep,
 		},
 		BaseURL: airQualityBaseURL,
 	}
@@ -0,0 +1,134 @@
+package openmateo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordingObserver keeps the stats it is given.
+type recordingObserver struct {
+	stats []RequestStats
+}
+
+func (o *recordingObserver) ObserveRequest(stats RequestStats) {
+	o.stats = append(o.stats, stats)
+}
+
+func TestDoRequest_Observer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Cache", "HIT from proxy")
+		_, _ = fmt.Fprintln(w, `{"generationtime_ms": 1.5}`)
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := NewForecastClient(server.Client())
+	client.Observer = observer
+
+	if _, err := client.doRequest(server.URL + "/v1/forecast?latitude=52.52"); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if len(observer.stats) != 1 {
+		t.Fatalf("Expected 1 observed request, got %d", len(observer.stats))
+	}
+	stats := observer.stats[0]
+	if stats.Endpoint != "forecast" || stats.Status != http.StatusOK {
+		t.Errorf("Expected a 200 from forecast, got %+v", stats)
+	}
+	if stats.GenerationTime != 1500*time.Microsecond {
+		t.Errorf("Expected a generation time of 1.5ms, got %v", stats.GenerationTime)
+	}
+	if !stats.CacheHit || stats.Retries != 0 || stats.Err != nil {
+		t.Errorf("Expected a cache hit without retries or error, got %+v", stats)
+	}
+}
+
+func TestDoRequest_Retries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintln(w, `{}`)
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := NewAirQualityClient(server.Client())
+	client.Observer = observer
+	client.Retries = 2
+	client.RetryBackoff = time.Millisecond
+
+	if _, err := client.doRequest(server.URL + "/v1/air-quality"); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if stats := observer.stats[0]; stats.Retries != 2 || stats.Endpoint != "air-quality" {
+		t.Errorf("Expected 2 retries of air-quality, got %+v", stats)
+	}
+}
+
+func TestDoRequest_RetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		retryAfter string
+		expected   time.Duration
+	}{
+		{"2", 2 * time.Second},
+		{"3600", maxRetryAfter},
+		{"", time.Millisecond},
+		{"Wed, 21 Oct 2026 07:28:00 GMT", time.Millisecond},
+	} {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = fmt.Fprintln(w, `{}`)
+		}))
+
+		var waits []time.Duration
+		client := NewForecastClient(server.Client())
+		client.Retries = 1
+		client.RetryBackoff = time.Millisecond
+		client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+		if _, err := client.doRequest(server.URL + "/v1/forecast"); err != nil {
+			t.Errorf("doRequest failed with Retry-After %q: %v", tt.retryAfter, err)
+		}
+		if len(waits) != 1 || waits[0] != tt.expected {
+			t.Errorf("Expected to wait %v with Retry-After %q, got %v", tt.expected, tt.retryAfter, waits)
+		}
+		server.Close()
+	}
+}
+
+func TestDoRequest_NoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintln(w, `{"error": true, "reason": "Latitude must be in range of -90 to 90°."}`)
+	}))
+	defer server.Close()
+
+	client := NewForecastClient(server.Client())
+	client.Retries = 3
+	client.RetryBackoff = time.Millisecond
+
+	if _, err := client.doRequest(server.URL + "/v1/forecast"); err == nil {
+		t.Fatal("Expected an error for a 400 response")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt for a 400, got %d", attempts)
+	}
+}
@@ -76,6 +76,10 @@ func (fc *ForecastClient) GetWeather(
 		params.Set("forecast_hours", strconv.FormatInt(forecastHours, 10))
 	}
 
+	if fc.Model != "" {
+		params.Set("models", fc.Model)
+	}
+
 	fullURL := fc.BaseURL + "forecast?" + params.Encode()
 
 	data, err := fc.doRequest(fullURL)
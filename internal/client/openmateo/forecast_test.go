@@ -238,3 +238,22 @@ func TestGetWeather_NoParameters(t *testing.T) {
 		t.Errorf("Expected Daily to be nil, got '%+v'", result.Daily)
 	}
 }
+
+func TestGetWeather_Model(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("models") != "icon_seamless" {
+			t.Errorf("Expected models to be 'icon_seamless', got '%s'", r.URL.Query().Get("models"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintln(w, `{"latitude": 52.52, "longitude": 13.41, "timezone": "Europe/Berlin"}`)
+	}))
+	defer server.Close()
+
+	client := NewForecastClient(server.Client())
+	client.BaseURL = server.URL + "/"
+	client.Model = "icon_seamless"
+
+	if _, err := client.GetWeather(52.52, 13.41, []string{"temperature_2m"}, nil, nil, "celsius", "kmh", "mm", 0, 0, 0, 0); err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+}
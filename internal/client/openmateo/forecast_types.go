@@ -25,6 +25,7 @@ type ForecastCurrent struct {
 	WeatherCode         int     `json:"weather_code"`
 	WindSpeed10m        float64 `json:"wind_speed_10m"`
 	WindDirection10m    float64 `json:"wind_direction_10m"`
+	WindGusts10m        float64 `json:"wind_gusts_10m"`
 	IsDay               int     `json:"is_day"`
 	ApparentTemperature float64 `json:"apparent_temperature"`
 }
@@ -39,6 +40,7 @@ type ForecastCurrentUnits struct {
 	WeatherCode         string `json:"weather_code"`
 	WindSpeed10m        string `json:"wind_speed_10m"`
 	WindDirection10m    string `json:"wind_direction_10m"`
+	WindGusts10m        string `json:"wind_gusts_10m"`
 	IsDay               string `json:"is_day"`
 	ApparentTemperature string `json:"apparent_temperature"`
 }
@@ -54,6 +56,7 @@ type ForecastHourly struct {
 	ApparentTemperature      []float64 `json:"apparent_temperature"`
 	CloudCover               []float64 `json:"cloud_cover"`
 	WindDirection10m         []float64 `json:"wind_direction_10m"`
+	WindGusts10m             []float64 `json:"wind_gusts_10m"`
 	Snowfall                 []float64 `json:"snowfall"`
 	PrecipitationProbability []float64 `json:"precipitation_probability"`
 	SnowDepth                []float64 `json:"snow_depth"`
@@ -71,6 +74,7 @@ type ForecastHourlyUnits struct {
 	ApparentTemperature      string `json:"apparent_temperature"`
 	CloudCover               string `json:"cloud_cover"`
 	WindDirection10m         string `json:"wind_direction_10m"`
+	WindGusts10m             string `json:"wind_gusts_10m"`
 	Snowfall                 string `json:"snowfall"`
 	PrecipitationProbability string `json:"precipitation_probability"`
 	SnowDepth                string `json:"snow_depth"`
@@ -90,6 +94,8 @@ type ForecastDaily struct {
 	PrecipitationProbabilityMean []float64 `json:"precipitation_probability_mean"`
 	WeatherCode                  []int     `json:"weather_code"`
 	WindSpeed10mMax              []float64 `json:"wind_speed_10m_max"`
+	WindGusts10mMax              []float64 `json:"wind_gusts_10m_max"`
+	WindDirection10mDominant     []float64 `json:"wind_direction_10m_dominant"`
 	ApparentTemperatureMax       []float64 `json:"apparent_temperature_max"`
 	ApparentTemperatureMin       []float64 `json:"apparent_temperature_min"`
 }
@@ -107,6 +113,8 @@ type ForecastDailyUnits struct {
 	PrecipitationProbabilityMean string `json:"precipitation_probability_mean"`
 	WeatherCode                  string `json:"weather_code"`
 	WindSpeed10mMax              string `json:"wind_speed_10m_max"`
+	WindGusts10mMax              string `json:"wind_gusts_10m_max"`
+	WindDirection10mDominant     string `json:"wind_direction_10m_dominant"`
 	ApparentTemperatureMax       string `json:"apparent_temperature_max"`
 	ApparentTemperatureMin       string `json:"apparent_temperature_min"`
 }
@@ -0,0 +1,125 @@
+package openmateo
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
+)
+
+// goldenClient returns an HTTP client that replays the responses of the
+// real API recorded in testdata/fixtures. Record them again with
+//
+//	SKY_RECORD=1 go test ./internal/client/openmateo -run Golden
+//
+// to check the clients against the API as it is today.
+func goldenClient(t *testing.T) *http.Client {
+	t.Helper()
+	transport := openmateotest.NewTransport("testdata/fixtures", openmateotest.ModeFromEnv())
+	if transport.Mode == openmateotest.Replay && !transport.HasFixtures() {
+		t.Fatalf("No fixtures in testdata/fixtures; record them with %s=1", openmateotest.EnvRecord)
+	}
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}
+}
+
+// checkSeries fails when a requested series is missing or its length
+// differs from the times', which is how schema drift shows up.
+func checkSeries(t *testing.T, section string, times int, series any) {
+	t.Helper()
+	v := reflect.ValueOf(series).Elem()
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if field.Kind() == reflect.Slice && field.Len() != times {
+			t.Errorf("Expected %d values of %s.%s, got %d", times, section, v.Type().Field(i).Name, field.Len())
+		}
+	}
+}
+
+func TestGolden_Forecast(t *testing.T) {
+	client := NewForecastClient(goldenClient(t))
+	result, err := client.GetWeather(
+		52.52,
+		13.41,
+		[]string{
+			"temperature_2m", "relative_humidity_2m", "apparent_temperature", "precipitation", "snowfall",
+			"weather_code", "wind_speed_10m", "wind_direction_10m", "wind_gusts_10m", "is_day",
+		},
+		[]string{
+			"temperature_2m", "relative_humidity_2m", "precipitation", "weather_code", "wind_speed_10m",
+			"apparent_temperature", "cloud_cover", "wind_direction_10m", "wind_gusts_10m", "snowfall",
+			"precipitation_probability", "snow_depth", "is_day",
+		},
+		[]string{
+			"temperature_2m_max", "temperature_2m_min", "sunrise", "sunset", "daylight_duration",
+			"precipitation_sum", "snowfall_sum", "precipitation_probability_mean", "weather_code",
+			"wind_speed_10m_max", "wind_gusts_10m_max", "wind_direction_10m_dominant",
+			"apparent_temperature_max", "apparent_temperature_min",
+		},
+		"celsius",
+		"kmh",
+		"mm",
+		0,
+		3,
+		0,
+		0,
+	)
+	if err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+
+	if result.Timezone == "" || result.Current == nil || result.Current.Time == "" {
+		t.Fatalf("Expected the timezone and current weather, got %+v", result)
+	}
+	if result.CurrentUnits.Temperature2m != "°C" || result.CurrentUnits.WindSpeed10m != "km/h" {
+		t.Errorf("Expected metric units, got %+v", result.CurrentUnits)
+	}
+	if len(result.Hourly.Time) != 3*24 {
+		t.Errorf("Expected 72 hours, got %d", len(result.Hourly.Time))
+	}
+	checkSeries(t, "hourly", len(result.Hourly.Time), result.Hourly)
+	if len(result.Daily.Time) != 3 {
+		t.Errorf("Expected 3 days, got %d", len(result.Daily.Time))
+	}
+	checkSeries(t, "daily", len(result.Daily.Time), result.Daily)
+}
+
+func TestGolden_Search(t *testing.T) {
+	client := NewGeocodingClient(goldenClient(t))
+	location, err := client.Search("Berlin")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if location.Name != "Berlin" || location.CountryCode != "DE" || location.Timezone != "Europe/Berlin" {
+		t.Errorf("Expected Berlin, got %+v", location)
+	}
+}
+
+func TestGolden_AirQuality(t *testing.T) {
+	client := NewAirQualityClient(goldenClient(t))
+	result, err := client.GetAirQuality(52.52, 13.41, []string{
+		"pm10", "pm2_5", "carbon_monoxide", "nitrogen_dioxide", "ozone", "uv_index", "european_aqi", "us_aqi",
+	})
+	if err != nil {
+		t.Fatalf("GetAirQuality failed: %v", err)
+	}
+	if len(result.Hourly.Time) == 0 || result.HourlyUnits.PM25 == "" {
+		t.Fatalf("Expected hourly air quality with units, got %+v", result)
+	}
+	// Only the requested pollutants are filled in.
+	for name, series := range map[string][]float64{
+		"pm10":             result.Hourly.PM10,
+		"pm2_5":            result.Hourly.PM25,
+		"carbon_monoxide":  result.Hourly.CarbonMonoxide,
+		"nitrogen_dioxide": result.Hourly.NitrogenDioxide,
+		"ozone":            result.Hourly.Ozone,
+		"uv_index":         result.Hourly.UVIndex,
+		"european_aqi":     result.Hourly.EuropeanAQI,
+		"us_aqi":           result.Hourly.USAQI,
+	} {
+		if len(series) != len(result.Hourly.Time) {
+			t.Errorf("Expected %d values of %s, got %d", len(result.Hourly.Time), name, len(series))
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package openmateotest
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// timeLayout is the format of times in responses.
+const timeLayout = "2006-01-02T15:04"
+
+// weather is the generated weather at one place and hour, in metric units:
+// Celsius, km/h, millimetres and centimetres of snow.
+type weather struct {
+	temperature, apparent, humidity, dewPoint float64
+	cloudCover, precipitationProbability      float64
+	precipitation, snowfall, snowDepth        float64
+	windSpeed, windGusts, windDirection       float64
+	weatherCode                               int
+	isDay                                     int
+	hour                                      int
+}
+
+// generate returns the weather at a place and time. Values follow a daily
+// cycle around a mean set by the latitude, with cloud and rain changing
+// from day to day, so they look plausible while depending only on the
+// arguments.
+func generate(latitude, longitude float64, t time.Time) weather {
+	hour := float64(t.Hour())
+	day := float64(t.YearDay())
+	// Varies between places but not with time.
+	seed := math.Mod(math.Abs(latitude*7+longitude*3), 10)
+	cycle := math.Sin(2 * math.Pi * (hour - 9) / 24) // Peaks at 15:00
+
+	w := weather{hour: t.Hour()}
+	w.cloudCover = math.Mod(day*37+seed*11, 101)
+	w.precipitationProbability = math.Mod(day*53+seed*7, 101)
+	w.temperature = 25 - 0.5*math.Abs(latitude) + seed/2 + 5*cycle - w.cloudCover/50
+	w.humidity = math.Min(100, 65-20*cycle+w.cloudCover/5)
+	w.dewPoint = w.temperature - (100-w.humidity)/5
+	w.windSpeed = 8 + seed + 4*math.Sin(2*math.Pi*(hour+day)/24)
+	w.windGusts = w.windSpeed * 1.6
+	w.windDirection = math.Mod(day*45+hour*5, 360)
+	w.apparent = w.temperature - w.windSpeed/10
+	if hour >= 6 && hour < 20 {
+		w.isDay = 1
+	}
+	// Rain in the afternoon of likely days; snow when it is freezing.
+	if w.precipitationProbability >= 60 && hour >= 12 && hour < 18 {
+		w.precipitation = (w.precipitationProbability - 50) / 20
+		if w.temperature < 0 {
+			w.snowfall = w.precipitation * 0.7
+			w.snowDepth = 0.05
+		}
+	}
+
+	switch {
+	case w.snowfall > 0:
+		w.weatherCode = 73
+	case w.precipitation >= 2:
+		w.weatherCode = 63
+	case w.precipitation > 0:
+		w.weatherCode = 61
+	case w.cloudCover > 85:
+		w.weatherCode = 3
+	case w.cloudCover > 50:
+		w.weatherCode = 2
+	case w.cloudCover > 20:
+		w.weatherCode = 1
+	}
+	return w
+}
+
+// units are the units a request asked for.
+type units struct {
+	temperature, windSpeed, precipitation string
+}
+
+func (u units) temperatureSymbol() string {
+	if u.temperature == "fahrenheit" {
+		return "°F"
+	}
+	return "°C"
+}
+
+func (u units) windSymbol() string {
+	switch u.windSpeed {
+	case "ms":
+		return "m/s"
+	case "mph":
+		return "mp/h"
+	case "kn":
+		return "kn"
+	}
+	return "km/h"
+}
+
+func (u units) precipitationSymbol() string {
+	if u.precipitation == "inch" {
+		return "inch"
+	}
+	return "mm"
+}
+
+func (u units) snowSymbol() string {
+	if u.precipitation == "inch" {
+		return "inch"
+	}
+	return "cm"
+}
+
+func (u units) convertTemperature(c float64) float64 {
+	if u.temperature == "fahrenheit" {
+		return c*9/5 + 32
+	}
+	return c
+}
+
+func (u units) convertWind(kmh float64) float64 {
+	switch u.windSpeed {
+	case "ms":
+		return kmh / 3.6
+	case "mph":
+		return kmh / 1.609344
+	case "kn":
+		return kmh / 1.852
+	}
+	return kmh
+}
+
+func (u units) convertPrecipitation(mm float64) float64 {
+	if u.precipitation == "inch" {
+		return mm / 25.4
+	}
+	return mm
+}
+
+func (u units) convertSnow(cm float64) float64 {
+	if u.precipitation == "inch" {
+		return cm / 2.54
+	}
+	return cm
+}
+
+// variable is a forecast variable: its unit and value in the requested
+// units.
+type variable struct {
+	unit  func(units) string
+	value func(weather, units) any
+}
+
+func fixed(unit string) func(units) string {
+	return func(units) string { return unit }
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// hourlyVariables are the variables of the current and hourly sections.
+var hourlyVariables = map[string]variable{
+	"temperature_2m": {units.temperatureSymbol, func(w weather, u units) any {
+		return round(u.convertTemperature(w.temperature))
+	}},
+	"apparent_temperature": {units.temperatureSymbol, func(w weather, u units) any {
+		return round(u.convertTemperature(w.apparent))
+	}},
+	"dew_point_2m": {units.temperatureSymbol, func(w weather, u units) any {
+		return round(u.convertTemperature(w.dewPoint))
+	}},
+	"relative_humidity_2m": {fixed("%"), func(w weather, u units) any {
+		return math.Round(w.humidity)
+	}},
+	"cloud_cover": {fixed("%"), func(w weather, u units) any {
+		return math.Round(w.cloudCover)
+	}},
+	"precipitation_probability": {fixed("%"), func(w weather, u units) any {
+		return math.Round(w.precipitationProbability)
+	}},
+	"precipitation": {units.precipitationSymbol, func(w weather, u units) any {
+		return round(u.convertPrecipitation(w.precipitation))
+	}},
+	"rain": {units.precipitationSymbol, func(w weather, u units) any {
+		if w.snowfall > 0 {
+			return 0.0
+		}
+		return round(u.convertPrecipitation(w.precipitation))
+	}},
+	"snowfall": {units.snowSymbol, func(w weather, u units) any {
+		return round(u.convertSnow(w.snowfall))
+	}},
+	"snow_depth": {fixed("m"), func(w weather, u units) any {
+		return w.snowDepth
+	}},
+	"weather_code": {fixed("wmo code"), func(w weather, u units) any {
+		return w.weatherCode
+	}},
+	"wind_speed_10m": {units.windSymbol, func(w weather, u units) any {
+		return round(u.convertWind(w.windSpeed))
+	}},
+	"wind_gusts_10m": {units.windSymbol, func(w weather, u units) any {
+		return round(u.convertWind(w.windGusts))
+	}},
+	"wind_direction_10m": {fixed("°"), func(w weather, u units) any {
+		return math.Round(w.windDirection)
+	}},
+	"is_day": {fixed(""), func(w weather, u units) any {
+		return w.isDay
+	}},
+}
+
+// dailyVariable aggregates the weather of the 24 hours of a day.
+type dailyVariable struct {
+	unit      func(units) string
+	aggregate func(date time.Time, hours []weather, u units) any
+}
+
+func maxOf(hours []weather, value func(weather) float64) float64 {
+	m := math.Inf(-1)
+	for _, w := range hours {
+		m = math.Max(m, value(w))
+	}
+	return m
+}
+
+func minOf(hours []weather, value func(weather) float64) float64 {
+	m := math.Inf(1)
+	for _, w := range hours {
+		m = math.Min(m, value(w))
+	}
+	return m
+}
+
+func sumOf(hours []weather, value func(weather) float64) float64 {
+	var sum float64
+	for _, w := range hours {
+		sum += value(w)
+	}
+	return sum
+}
+
+// dailyVariables are the variables of the daily section.
+var dailyVariables = map[string]dailyVariable{
+	"temperature_2m_max": {units.temperatureSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertTemperature(maxOf(hours, func(w weather) float64 { return w.temperature })))
+	}},
+	"temperature_2m_min": {units.temperatureSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertTemperature(minOf(hours, func(w weather) float64 { return w.temperature })))
+	}},
+	"apparent_temperature_max": {units.temperatureSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertTemperature(maxOf(hours, func(w weather) float64 { return w.apparent })))
+	}},
+	"apparent_temperature_min": {units.temperatureSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertTemperature(minOf(hours, func(w weather) float64 { return w.apparent })))
+	}},
+	"precipitation_sum": {units.precipitationSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertPrecipitation(sumOf(hours, func(w weather) float64 { return w.precipitation })))
+	}},
+	"snowfall_sum": {units.snowSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertSnow(sumOf(hours, func(w weather) float64 { return w.snowfall })))
+	}},
+	"snow_depth": {fixed("m"), func(_ time.Time, hours []weather, u units) any {
+		return sumOf(hours, func(w weather) float64 { return w.snowDepth }) / float64(len(hours))
+	}},
+	"snow_depth_max": {fixed("m"), func(_ time.Time, hours []weather, u units) any {
+		return maxOf(hours, func(w weather) float64 { return w.snowDepth })
+	}},
+	"precipitation_probability_max": {fixed("%"), func(_ time.Time, hours []weather, u units) any {
+		return math.Round(maxOf(hours, func(w weather) float64 { return w.precipitationProbability }))
+	}},
+	"precipitation_probability_mean": {fixed("%"), func(_ time.Time, hours []weather, u units) any {
+		return math.Round(sumOf(hours, func(w weather) float64 { return w.precipitationProbability }) / float64(len(hours)))
+	}},
+	"weather_code": {fixed("wmo code"), func(_ time.Time, hours []weather, u units) any {
+		return int(maxOf(hours, func(w weather) float64 { return float64(w.weatherCode) }))
+	}},
+	"wind_speed_10m_max": {units.windSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertWind(maxOf(hours, func(w weather) float64 { return w.windSpeed })))
+	}},
+	"wind_gusts_10m_max": {units.windSymbol, func(_ time.Time, hours []weather, u units) any {
+		return round(u.convertWind(maxOf(hours, func(w weather) float64 { return w.windGusts })))
+	}},
+	"wind_direction_10m_dominant": {fixed("°"), func(_ time.Time, hours []weather, u units) any {
+		return math.Round(hours[12].windDirection)
+	}},
+	"sunrise": {fixed("iso8601"), func(date time.Time, _ []weather, _ units) any {
+		return date.Add(6 * time.Hour).Format(timeLayout)
+	}},
+	"sunset": {fixed("iso8601"), func(date time.Time, _ []weather, _ units) any {
+		return date.Add(20 * time.Hour).Format(timeLayout)
+	}},
+	"daylight_duration": {fixed("s"), func(time.Time, []weather, units) any {
+		return (14 * time.Hour).Seconds()
+	}},
+}
+
+// airVariables are the variables of the air-quality API. Pollutants are in
+// μg/m³.
+var airVariables = map[string]variable{
+	"pm10":                  {fixed("μg/m³"), airValue(18, 8)},
+	"pm2_5":                 {fixed("μg/m³"), airValue(9, 5)},
+	"carbon_monoxide":       {fixed("μg/m³"), airValue(200, 60)},
+	"nitrogen_dioxide":      {fixed("μg/m³"), airValue(15, 10)},
+	"sulphur_dioxide":       {fixed("μg/m³"), airValue(2, 1)},
+	"ozone":                 {fixed("μg/m³"), airValue(60, 30)},
+	"dust":                  {fixed("μg/m³"), airValue(1, 1)},
+	"aerosol_optical_depth": {fixed(""), airValue(0.15, 0.1)},
+	"alder_pollen":          {fixed("grains/m³"), airValue(2, 2)},
+	"birch_pollen":          {fixed("grains/m³"), airValue(5, 5)},
+	"grass_pollen":          {fixed("grains/m³"), airValue(10, 10)},
+	"mugwort_pollen":        {fixed("grains/m³"), airValue(1, 1)},
+	"olive_pollen":          {fixed("grains/m³"), airValue(0, 0)},
+	"ragweed_pollen":        {fixed("grains/m³"), airValue(0, 0)},
+	"european_aqi":          {fixed("EAQI"), airValue(30, 15)},
+	"us_aqi":                {fixed("USAQI"), airValue(40, 20)},
+	"uv_index": {fixed(""), func(w weather, u units) any {
+		if w.isDay == 0 {
+			return 0.0
+		}
+		return round(math.Max(0, 6*(1-w.cloudCover/150)*math.Sin(math.Pi*(float64(w.hour)-6)/14)))
+	}},
+}
+
+// airValue is a pollutant that rises with the morning and evening traffic
+// around mean, by up to swing.
+func airValue(mean, swing float64) func(weather, units) any {
+	return func(w weather, _ units) any {
+		hour := float64(w.hour)
+		rush := math.Exp(-math.Pow(hour-8, 2)/8) + math.Exp(-math.Pow(hour-18, 2)/8)
+		return round(mean + swing*(rush-0.5))
+	}
+}
+
+// unknownVariable is the reason the API gives for a variable it does not
+// know.
+func unknownVariable(name string) string {
+	return fmt.Sprintf("Cannot initialize WeatherVariable from invalid String value %s.", name)
+}
@@ -0,0 +1,46 @@
+package openmateotest
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Place is a result of the geocoding API.
+type Place struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Elevation   float64 `json:"elevation"`
+	Timezone    string  `json:"timezone"`
+	Population  int     `json:"population,omitempty"`
+	CountryCode string  `json:"country_code"`
+	Country     string  `json:"country"`
+}
+
+// DefaultPlaces are the places a new Server knows, from the real API.
+var DefaultPlaces = []Place{
+	{2950159, "Berlin", 52.52437, 13.41053, 74, "Europe/Berlin", 3426354, "DE", "Germany"},
+	{2988507, "Paris", 48.85341, 2.3488, 42, "Europe/Paris", 2138551, "FR", "France"},
+	{2643743, "London", 51.50853, -0.12574, 25, "Europe/London", 8961989, "GB", "United Kingdom"},
+	{5128581, "New York", 40.71427, -74.00597, 10, "America/New_York", 8804190, "US", "United States"},
+	{1850147, "Tokyo", 35.6895, 139.69171, 44, "Asia/Tokyo", 9733276, "JP", "Japan"},
+	{2147714, "Sydney", -33.86785, 151.20732, 58, "Australia/Sydney", 4627345, "AU", "Australia"},
+	{3413829, "Reykjavík", 64.13548, -21.89541, 24, "Atlantic/Reykjavik", 118918, "IS", "Iceland"},
+}
+
+// search returns up to count places whose name starts with name, ignoring
+// case, most populous first as the API orders them.
+func search(places []Place, name string, count int) []Place {
+	var found []Place
+	for _, place := range places {
+		if strings.HasPrefix(strings.ToLower(place.Name), strings.ToLower(name)) {
+			found = append(found, place)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b Place) int {
+		return cmp.Compare(b.Population, a.Population)
+	})
+	return found[:min(count, len(found))]
+}
@@ -0,0 +1,114 @@
+package openmateotest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
+)
+
+// offline fails every request, standing in for a missing network.
+type offline struct{}
+
+func (offline) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is unreachable")
+}
+
+func TestTransport_RecordAndReplay(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	dir := t.TempDir()
+
+	recorder := openmateotest.NewTransport(dir, openmateotest.Record)
+	client := openmateo.NewForecastClient(&http.Client{Transport: recorder})
+	client.BaseURL = fake.BaseURL()
+	recorded, err := client.GetWeather(52.52, 13.41, []string{"temperature_2m"}, []string{"temperature_2m"}, nil, "", "", "", 0, 2, 0, 0)
+	if err != nil {
+		t.Fatalf("GetWeather failed while recording: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "forecast-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 fixture, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	var fixture openmateotest.Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	if fixture.Status != http.StatusOK || !strings.Contains(fixture.URL, "/forecast?current=temperature_2m&forecast_days=2&hourly=temperature_2m&latitude=") {
+		t.Errorf("Expected the sorted URL and status, got %+v", fixture)
+	}
+	if !strings.Contains(string(data), "\n      \"temperature_2m\"") {
+		t.Errorf("Expected an indented body, got:\n%s", data)
+	}
+
+	// Replaying needs no network.
+	fake.Close()
+	replayer := openmateotest.NewTransport(dir, openmateotest.Replay)
+	replayer.Base = offline{}
+	client = openmateo.NewForecastClient(&http.Client{Transport: replayer})
+	client.BaseURL = fake.BaseURL()
+	replayed, err := client.GetWeather(52.52, 13.41, []string{"temperature_2m"}, []string{"temperature_2m"}, nil, "", "", "", 0, 2, 0, 0)
+	if err != nil {
+		t.Fatalf("GetWeather failed while replaying: %v", err)
+	}
+	if *replayed.Current != *recorded.Current || len(replayed.Hourly.Temperature2m) != 48 {
+		t.Errorf("Expected the recorded response, got %+v", replayed)
+	}
+
+	// Another request has no fixture.
+	_, err = client.GetWeather(48.85, 2.35, []string{"temperature_2m"}, nil, nil, "", "", "", 0, 2, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "no fixture") || !strings.Contains(err.Error(), openmateotest.EnvRecord) {
+		t.Errorf("Expected a missing fixture error, got %v", err)
+	}
+}
+
+func TestTransport_QueryOrder(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream down"))
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+
+	recorder := &http.Client{Transport: openmateotest.NewTransport(dir, openmateotest.Record)}
+	resp, err := recorder.Get(upstream.URL + "/v1/search?name=Berlin&count=1")
+	if err != nil {
+		t.Fatalf("GET failed while recording: %v", err)
+	}
+	resp.Body.Close()
+
+	replayer := &http.Client{Transport: openmateotest.NewTransport(dir, openmateotest.Replay)}
+	resp, err = replayer.Get(upstream.URL + "/v1/search?count=1&name=Berlin")
+	if err != nil {
+		t.Fatalf("Expected the fixture to match with the parameters in another order, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadGateway || string(body) != "upstream down" {
+		t.Errorf("Expected the recorded error and text body, got %d %q", resp.StatusCode, body)
+	}
+	if !openmateotest.NewTransport(dir, openmateotest.Replay).HasFixtures() {
+		t.Errorf("Expected HasFixtures to find the fixture")
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv(openmateotest.EnvRecord, "1")
+	if openmateotest.ModeFromEnv() != openmateotest.Record {
+		t.Errorf("Expected Record with %s=1", openmateotest.EnvRecord)
+	}
+	t.Setenv(openmateotest.EnvRecord, "")
+	if openmateotest.ModeFromEnv() != openmateotest.Replay {
+		t.Errorf("Expected Replay by default")
+	}
+}
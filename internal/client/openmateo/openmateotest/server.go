@@ -0,0 +1,453 @@
+// Package openmateotest provides a fake Open-Meteo API for tests. It serves
+// the forecast, geocoding and air-quality endpoints with deterministic
+// weather generated for the requested variables, units and horizon, can
+// inject errors, latency and nulls, and records the requests it receives.
+//
+//	fake := openmateotest.NewServer()
+//	defer fake.Close()
+//	client := openmateo.NewForecastClient(nil)
+//	client.BaseURL = fake.BaseURL()
+package openmateotest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNow is the time a new Server pretends it is.
+var DefaultNow = time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+// Request is a request the Server received.
+type Request struct {
+	Endpoint string // "forecast", "search" or "air-quality"
+	Query    url.Values
+}
+
+// Fault changes the responses to matching requests.
+type Fault struct {
+	// Endpoint is the endpoint it applies to; empty for every endpoint.
+	Endpoint string
+	// Status, when set, is returned with an API error instead of data.
+	Status int
+	// Reason is the reason of the error; the status text when empty.
+	Reason string
+	// Latency is how long to wait before responding.
+	Latency time.Duration
+	// Nulls are variables whose values are all null.
+	Nulls []string
+	// Times is how many requests it applies to; 0 for every request.
+	Times int
+}
+
+// Server is a fake Open-Meteo API. Times in responses are in GMT, as when
+// the API is asked for timezone=GMT. Set Now and Places before making
+// requests.
+type Server struct {
+	*httptest.Server
+	// Now is the current time of the fake. Forecasts start at its day or
+	// hour like the real ones.
+	Now time.Time
+	// Places are the places the geocoding endpoint finds.
+	Places []Place
+
+	mu       sync.Mutex
+	requests []Request
+	faults   []*Fault
+}
+
+// NewServer starts a Server. Close it when done.
+func NewServer() *Server {
+	s := &Server{Now: DefaultNow, Places: slices.Clone(DefaultPlaces)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// BaseURL is the base URL to give the openmateo clients.
+func (s *Server) BaseURL() string {
+	return s.URL + "/v1/"
+}
+
+// Inject adds a fault. Faults apply in the order they were added; the
+// first that matches a request is used.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Reset forgets the requests received and removes every fault.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests, s.faults = nil, nil
+}
+
+// take records a request and returns the fault that applies to it, if any.
+func (s *Server) take(request Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, request)
+	for i, f := range s.faults {
+		if f.Endpoint != "" && f.Endpoint != request.Endpoint {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f
+	}
+	return &Fault{}
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	status int
+	reason string
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	request := Request{Endpoint: path.Base(path.Clean("/" + r.URL.Path)), Query: r.URL.Query()}
+	fault := s.take(request)
+
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault.Status != 0 {
+		writeError(w, apiError{fault.Status, cmp.Or(fault.Reason, http.StatusText(fault.Status))})
+		return
+	}
+
+	var body map[string]any
+	var err *apiError
+	switch request.Endpoint {
+	case "forecast":
+		body, err = s.forecast(request.Query, fault.Nulls)
+	case "air-quality":
+		body, err = s.airQuality(request.Query, fault.Nulls)
+	case "search":
+		body, err = s.search(request.Query)
+	default:
+		err = &apiError{http.StatusNotFound, "Not Found"}
+	}
+	if err != nil {
+		writeError(w, *err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, e apiError) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": true, "reason": e.reason})
+}
+
+func badRequest(format string, args ...any) *apiError {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// coordinates reads the latitude and longitude of a request.
+func coordinates(query url.Values) (float64, float64, *apiError) {
+	latitude, err := strconv.ParseFloat(query.Get("latitude"), 64)
+	if err != nil {
+		return 0, 0, badRequest("Parameter 'latitude' is required and must be a number.")
+	}
+	if latitude < -90 || latitude > 90 {
+		return 0, 0, badRequest("Latitude must be in range of -90 to 90°. Given: %v.", latitude)
+	}
+	longitude, err := strconv.ParseFloat(query.Get("longitude"), 64)
+	if err != nil {
+		return 0, 0, badRequest("Parameter 'longitude' is required and must be a number.")
+	}
+	if longitude < -180 || longitude > 180 {
+		return 0, 0, badRequest("Longitude must be in range of -180 to 180°. Given: %v.", longitude)
+	}
+	return latitude, longitude, nil
+}
+
+// integer reads a whole number parameter that defaults to fallback.
+func integer(query url.Values, name string, fallback, max int) (int, *apiError) {
+	value := query.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > max {
+		return 0, badRequest("Parameter '%s' must be between 0 and %d. Given: %s.", name, max, value)
+	}
+	return n, nil
+}
+
+// list splits a comma-separated parameter.
+func list(query url.Values, name string) []string {
+	if query.Get(name) == "" {
+		return nil
+	}
+	return strings.Split(query.Get(name), ",")
+}
+
+// window is the hours a section of a response covers.
+type window struct {
+	start time.Time
+	hours int
+}
+
+// hourlyWindow follows the API: past_hours and forecast_hours count from
+// the current hour, and otherwise past_days and forecast_days from
+// midnight.
+func (s *Server) hourlyWindow(query url.Values, defaultDays, maxDays int) (window, *apiError) {
+	pastDays, err := integer(query, "past_days", 0, 92)
+	if err != nil {
+		return window{}, err
+	}
+	forecastDays, err := integer(query, "forecast_days", defaultDays, maxDays)
+	if err != nil {
+		return window{}, err
+	}
+	pastHours, err := integer(query, "past_hours", 0, 92*24)
+	if err != nil {
+		return window{}, err
+	}
+	forecastHours, err := integer(query, "forecast_hours", 0, maxDays*24)
+	if err != nil {
+		return window{}, err
+	}
+
+	midnight := s.Now.UTC().Truncate(24 * time.Hour)
+	days := window{start: midnight.AddDate(0, 0, -pastDays), hours: (pastDays + forecastDays) * 24}
+	if pastHours == 0 && forecastHours == 0 {
+		return days, nil
+	}
+	hour := s.Now.UTC().Truncate(time.Hour)
+	start := hour.Add(-time.Duration(pastHours) * time.Hour)
+	end := days.start.Add(time.Duration(days.hours) * time.Hour)
+	if forecastHours > 0 {
+		end = hour.Add(time.Duration(forecastHours) * time.Hour)
+	}
+	return window{start: start, hours: int(end.Sub(start) / time.Hour)}, nil
+}
+
+// requestUnits reads and checks the unit parameters.
+func requestUnits(query url.Values) (units, *apiError) {
+	u := units{
+		temperature:   cmp.Or(query.Get("temperature_unit"), "celsius"),
+		windSpeed:     cmp.Or(query.Get("wind_speed_unit"), "kmh"),
+		precipitation: cmp.Or(query.Get("precipitation_unit"), "mm"),
+	}
+	if u.temperature != "celsius" && u.temperature != "fahrenheit" {
+		return u, badRequest("Cannot initialize TemperatureUnit from invalid String value %s.", u.temperature)
+	}
+	if !slices.Contains([]string{"kmh", "ms", "mph", "kn"}, u.windSpeed) {
+		return u, badRequest("Cannot initialize WindspeedUnit from invalid String value %s.", u.windSpeed)
+	}
+	if u.precipitation != "mm" && u.precipitation != "inch" {
+		return u, badRequest("Cannot initialize PrecipitationUnit from invalid String value %s.", u.precipitation)
+	}
+	return u, nil
+}
+
+// header returns the fields every forecast and air-quality response has.
+func header(latitude, longitude float64) map[string]any {
+	return map[string]any{
+		"latitude":              latitude,
+		"longitude":             longitude,
+		"generationtime_ms":     0.1,
+		"utc_offset_seconds":    0,
+		"timezone":              "GMT",
+		"timezone_abbreviation": "GMT",
+		"elevation":             38.0,
+	}
+}
+
+// value returns a variable's value, or nil when it is to be null.
+func value(name string, v variable, w weather, u units, nulls []string) any {
+	if slices.Contains(nulls, name) {
+		return nil
+	}
+	return v.value(w, u)
+}
+
+// current adds the current and current_units sections.
+func (s *Server) current(body map[string]any, names []string, known map[string]variable, latitude, longitude float64, u units, nulls []string) *apiError {
+	if len(names) == 0 {
+		return nil
+	}
+	now := s.Now.UTC().Truncate(15 * time.Minute)
+	w := generate(latitude, longitude, now)
+	values := map[string]any{"time": now.Format(timeLayout), "interval": 900}
+	unitsOf := map[string]any{"time": "iso8601", "interval": "seconds"}
+	for _, name := range names {
+		v, ok := known[name]
+		if !ok {
+			return badRequest("%s", unknownVariable(name))
+		}
+		values[name] = value(name, v, w, u, nulls)
+		unitsOf[name] = v.unit(u)
+	}
+	body["current"], body["current_units"] = values, unitsOf
+	return nil
+}
+
+// hourly adds the hourly and hourly_units sections.
+func hourly(body map[string]any, names []string, known map[string]variable, latitude, longitude float64, win window, u units, nulls []string) *apiError {
+	if len(names) == 0 {
+		return nil
+	}
+	times := make([]any, win.hours)
+	hours := make([]weather, win.hours)
+	for i := range win.hours {
+		t := win.start.Add(time.Duration(i) * time.Hour)
+		times[i] = t.Format(timeLayout)
+		hours[i] = generate(latitude, longitude, t)
+	}
+	values := map[string]any{"time": times}
+	unitsOf := map[string]any{"time": "iso8601"}
+	for _, name := range names {
+		v, ok := known[name]
+		if !ok {
+			return badRequest("%s", unknownVariable(name))
+		}
+		series := make([]any, len(hours))
+		for i, w := range hours {
+			series[i] = value(name, v, w, u, nulls)
+		}
+		values[name] = series
+		unitsOf[name] = v.unit(u)
+	}
+	body["hourly"], body["hourly_units"] = values, unitsOf
+	return nil
+}
+
+// daily adds the daily and daily_units sections.
+func daily(body map[string]any, names []string, latitude, longitude float64, win window, u units, nulls []string) *apiError {
+	if len(names) == 0 {
+		return nil
+	}
+	days := win.hours / 24
+	times := make([]any, days)
+	hours := make([][]weather, days)
+	for d := range days {
+		date := win.start.AddDate(0, 0, d)
+		times[d] = date.Format("2006-01-02")
+		hours[d] = make([]weather, 24)
+		for h := range 24 {
+			hours[d][h] = generate(latitude, longitude, date.Add(time.Duration(h)*time.Hour))
+		}
+	}
+	values := map[string]any{"time": times}
+	unitsOf := map[string]any{"time": "iso8601"}
+	for _, name := range names {
+		v, ok := dailyVariables[name]
+		if !ok {
+			return badRequest("%s", unknownVariable(name))
+		}
+		series := make([]any, days)
+		for d := range days {
+			if !slices.Contains(nulls, name) {
+				series[d] = v.aggregate(win.start.AddDate(0, 0, d), hours[d], u)
+			}
+		}
+		values[name] = series
+		unitsOf[name] = v.unit(u)
+	}
+	body["daily"], body["daily_units"] = values, unitsOf
+	return nil
+}
+
+func (s *Server) forecast(query url.Values, nulls []string) (map[string]any, *apiError) {
+	latitude, longitude, err := coordinates(query)
+	if err != nil {
+		return nil, err
+	}
+	u, err := requestUnits(query)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.hourlyWindow(query, 7, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	body := header(latitude, longitude)
+	if err := s.current(body, list(query, "current"), hourlyVariables, latitude, longitude, u, nulls); err != nil {
+		return nil, err
+	}
+	if err := hourly(body, list(query, "hourly"), hourlyVariables, latitude, longitude, hours, u, nulls); err != nil {
+		return nil, err
+	}
+	// Daily values always cover whole days, whatever the hours asked for.
+	days, _ := s.hourlyWindow(url.Values{
+		"past_days":     {query.Get("past_days")},
+		"forecast_days": {query.Get("forecast_days")},
+	}, 7, 16)
+	if err := daily(body, list(query, "daily"), latitude, longitude, days, u, nulls); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) airQuality(query url.Values, nulls []string) (map[string]any, *apiError) {
+	latitude, longitude, err := coordinates(query)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := s.hourlyWindow(query, 5, 7)
+	if err != nil {
+		return nil, err
+	}
+
+	body := header(latitude, longitude)
+	if err := s.current(body, list(query, "current"), airVariables, latitude, longitude, units{}, nulls); err != nil {
+		return nil, err
+	}
+	if err := hourly(body, list(query, "hourly"), airVariables, latitude, longitude, hours, units{}, nulls); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) search(query url.Values) (map[string]any, *apiError) {
+	name := query.Get("name")
+	if name == "" {
+		return nil, badRequest("Parameter 'name' is required.")
+	}
+	count, err := integer(query, "count", 10, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{"generationtime_ms": 0.1}
+	// Like the API, names of one letter find nothing.
+	if len([]rune(name)) < 2 {
+		return body, nil
+	}
+	if found := search(s.Places, name, count); len(found) > 0 {
+		body["results"] = found
+	}
+	return body, nil
+}
@@ -0,0 +1,266 @@
+package openmateotest_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
+)
+
+func newForecastClient(fake *openmateotest.Server) *openmateo.ForecastClient {
+	client := openmateo.NewForecastClient(nil)
+	client.BaseURL = fake.BaseURL()
+	return client
+}
+
+func TestServer_Forecast(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+
+	result, err := newForecastClient(fake).GetWeather(
+		52.52,
+		13.41,
+		[]string{"temperature_2m", "weather_code", "is_day"},
+		[]string{"temperature_2m", "precipitation", "wind_speed_10m"},
+		[]string{"temperature_2m_max", "temperature_2m_min", "sunrise"},
+		"celsius",
+		"kmh",
+		"mm",
+		0,
+		3,
+		0,
+		0,
+	)
+	if err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+
+	if result.Current == nil || result.Current.Time != "2026-06-15T12:00" || result.Current.IsDay != 1 {
+		t.Errorf("Expected the current weather at noon, got %+v", result.Current)
+	}
+	if result.Hourly == nil || len(result.Hourly.Time) != 72 || len(result.Hourly.Temperature2m) != 72 {
+		t.Fatalf("Expected 72 hours, got %+v", result.Hourly)
+	}
+	if result.Hourly.Time[0] != "2026-06-15T00:00" {
+		t.Errorf("Expected hours to start at midnight, got %s", result.Hourly.Time[0])
+	}
+	if result.HourlyUnits.Temperature2m != "°C" || result.HourlyUnits.WindSpeed10m != "km/h" {
+		t.Errorf("Expected metric units, got %+v", result.HourlyUnits)
+	}
+	if len(result.Daily.Time) != 3 || result.Daily.Sunrise[0] != "2026-06-15T06:00" {
+		t.Errorf("Expected 3 days, got %+v", result.Daily)
+	}
+	for i := range result.Daily.Time {
+		if result.Daily.Temperature2mMax[i] <= result.Daily.Temperature2mMin[i] {
+			t.Errorf("Expected the maximum above the minimum, got %+v", result.Daily)
+		}
+	}
+
+	// The same request returns the same weather.
+	again, err := newForecastClient(fake).GetWeather(
+		52.52,
+		13.41,
+		[]string{"temperature_2m", "weather_code", "is_day"},
+		nil,
+		nil,
+		"celsius",
+		"kmh",
+		"mm",
+		0,
+		3,
+		0,
+		0,
+	)
+	if err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+	if *again.Current != *result.Current {
+		t.Errorf("Expected the same current weather, got %+v and %+v", result.Current, again.Current)
+	}
+}
+
+func TestServer_Units(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	client := newForecastClient(fake)
+
+	get := func(tempUnit, windUnit, precipUnit string) *openmateo.ForecastResult {
+		result, err := client.GetWeather(
+			52.52,
+			13.41,
+			[]string{"temperature_2m", "wind_speed_10m"},
+			nil,
+			nil,
+			tempUnit,
+			windUnit,
+			precipUnit,
+			0,
+			0,
+			0,
+			0,
+		)
+		if err != nil {
+			t.Fatalf("GetWeather failed: %v", err)
+		}
+		return result
+	}
+	metric := get("celsius", "kmh", "mm")
+	imperial := get("fahrenheit", "mph", "inch")
+
+	fahrenheit := metric.Current.Temperature2m*9/5 + 32
+	if diff := imperial.Current.Temperature2m - fahrenheit; diff < -0.2 || diff > 0.2 {
+		t.Errorf("Expected %.1f°F, got %v", fahrenheit, imperial.Current.Temperature2m)
+	}
+	if imperial.CurrentUnits.Temperature2m != "°F" || imperial.CurrentUnits.WindSpeed10m != "mp/h" {
+		t.Errorf("Expected imperial units, got %+v", imperial.CurrentUnits)
+	}
+	if imperial.Current.WindSpeed10m >= metric.Current.WindSpeed10m {
+		t.Errorf("Expected fewer mph than km/h, got %v and %v", imperial.Current.WindSpeed10m, metric.Current.WindSpeed10m)
+	}
+
+	_, err := client.GetWeather(52.52, 13.41, []string{"temperature_2m"}, nil, nil, "kelvin", "", "", 0, 0, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "TemperatureUnit") {
+		t.Errorf("Expected an unknown unit to fail, got %v", err)
+	}
+}
+
+func TestServer_Horizon(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+
+	result, err := newForecastClient(fake).GetWeather(
+		52.52,
+		13.41,
+		nil,
+		[]string{"temperature_2m"},
+		nil,
+		"",
+		"",
+		"",
+		0,
+		0,
+		2,
+		4,
+	)
+	if err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+	expected := []string{"2026-06-15T10:00", "2026-06-15T11:00", "2026-06-15T12:00", "2026-06-15T13:00", "2026-06-15T14:00", "2026-06-15T15:00"}
+	if strings.Join(result.Hourly.Time, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected hours %v, got %v", expected, result.Hourly.Time)
+	}
+
+	_, err = newForecastClient(fake).GetWeather(52.52, 13.41, nil, []string{"fog"}, nil, "", "", "", 0, 0, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "invalid String value fog") {
+		t.Errorf("Expected an unknown variable to fail, got %v", err)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	client := newForecastClient(fake)
+	get := func() (*openmateo.ForecastResult, error) {
+		return client.GetWeather(52.52, 13.41, []string{"temperature_2m", "wind_speed_10m"}, nil, nil, "", "", "", 0, 0, 0, 0)
+	}
+
+	fake.Inject(openmateotest.Fault{Endpoint: "forecast", Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := get(); err == nil || !strings.Contains(err.Error(), "Service Unavailable") {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+	if _, err := get(); err != nil {
+		t.Errorf("Expected the fault to apply once, got %v", err)
+	}
+
+	fake.Inject(openmateotest.Fault{Nulls: []string{"temperature_2m"}, Latency: 50 * time.Millisecond, Times: 1})
+	start := time.Now()
+	result, err := get()
+	if err != nil {
+		t.Fatalf("GetWeather failed: %v", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Expected the response to be delayed")
+	}
+	if result.Current.Temperature2m != 0 || result.Current.WindSpeed10m == 0 {
+		t.Errorf("Expected only the temperature to be null, got %+v", result.Current)
+	}
+
+	// A client timeout fires during injected latency.
+	fake.Inject(openmateotest.Fault{Latency: 200 * time.Millisecond, Times: 1})
+	slow := openmateo.NewForecastClient(&http.Client{Timeout: 20 * time.Millisecond})
+	slow.BaseURL = fake.BaseURL()
+	if _, err := slow.GetWeather(52.52, 13.41, []string{"temperature_2m"}, nil, nil, "", "", "", 0, 0, 0, 0); err == nil {
+		t.Errorf("Expected the request to time out")
+	}
+}
+
+func TestServer_Requests(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+
+	client := newForecastClient(fake)
+	client.Model = "icon_seamless"
+	_, _ = client.GetWeather(48.85, 2.35, []string{"temperature_2m"}, nil, nil, "fahrenheit", "", "", 0, 0, 0, 0)
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	if requests[0].Endpoint != "forecast" || requests[0].Query.Get("models") != "icon_seamless" || requests[0].Query.Get("temperature_unit") != "fahrenheit" {
+		t.Errorf("Expected the forecast request with its parameters, got %+v", requests[0])
+	}
+
+	fake.Reset()
+	if len(fake.Requests()) != 0 {
+		t.Errorf("Expected Reset to forget the requests")
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	client := openmateo.NewGeocodingClient(nil)
+	client.BaseURL = fake.BaseURL()
+
+	location, err := client.Search("berlin")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if location.Name != "Berlin" || location.Timezone != "Europe/Berlin" || location.CountryCode != "DE" {
+		t.Errorf("Expected Berlin, got %+v", location)
+	}
+
+	if _, err := client.Search("Atlantis"); !errors.Is(err, openmateo.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	fake.Places = append(fake.Places, openmateotest.Place{Name: "Atlantis", Latitude: 31, Longitude: -24})
+	if location, err := client.Search("Atlantis"); err != nil || location.Latitude != 31 {
+		t.Errorf("Expected an added place to be found, got %+v, %v", location, err)
+	}
+}
+
+func TestServer_AirQuality(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	client := openmateo.NewAirQualityClient(nil)
+	client.BaseURL = fake.BaseURL()
+
+	result, err := client.GetAirQuality(52.52, 13.41, []string{"pm2_5", "us_aqi", "uv_index"})
+	if err != nil {
+		t.Fatalf("GetAirQuality failed: %v", err)
+	}
+	if len(result.Hourly.Time) != 5*24 || len(result.Hourly.PM25) != 5*24 {
+		t.Fatalf("Expected 5 days of hours, got %d", len(result.Hourly.Time))
+	}
+	if result.HourlyUnits.PM25 != "μg/m³" {
+		t.Errorf("Expected μg/m³, got %q", result.HourlyUnits.PM25)
+	}
+	if result.Hourly.UVIndex[0] != 0 || result.Hourly.UVIndex[13] == 0 {
+		t.Errorf("Expected UV only by day, got %v at midnight and %v at 13:00", result.Hourly.UVIndex[0], result.Hourly.UVIndex[13])
+	}
+}
@@ -2,10 +2,14 @@ package openmateo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 )
 
+// ErrNotFound is returned by Search when no place matches the name.
+var ErrNotFound = errors.New("no location found")
+
 type Results struct {
 	Locations []Location `json:"results"`
 }
@@ -24,6 +28,9 @@ type Location struct {
 
 func (gc *GeocodingClient) Search(locationName string) (*Location, error) {
 	var searchURL string = fmt.Sprintf("%s/search?name=%s&count=1", gc.BaseURL, url.QueryEscape(locationName))
+	if gc.Language != "" {
+		searchURL += "&language=" + url.QueryEscape(gc.Language)
+	}
 
 	data, err := gc.doRequest(searchURL)
 	if err != nil {
@@ -37,7 +44,7 @@ func (gc *GeocodingClient) Search(locationName string) (*Location, error) {
 	}
 
 	if len(result.Locations) == 0 {
-		return nil, fmt.Errorf("no location found for %s", locationName)
+		return nil, fmt.Errorf("%w for %s", ErrNotFound, locationName)
 	}
 
 	return &result.Locations[0], nil
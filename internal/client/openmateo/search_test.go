@@ -1,6 +1,7 @@
 package openmateo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -80,6 +81,9 @@ func TestSearch_NoLocationFound(t *testing.T) {
 	if !strings.Contains(err.Error(), expectedErrMsg) {
 		t.Errorf("Expected error message to contain '%s', got '%s'", expectedErrMsg, err.Error())
 	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the error to wrap ErrNotFound, got %v", err)
+	}
 }
 
 func TestSearch_APIError(t *testing.T) {
@@ -128,3 +132,26 @@ func TestSearch_MalformedJSON(t *testing.T) {
 		t.Errorf("Expected error message to contain '%s', got '%s'", expectedErrMsg, err.Error())
 	}
 }
+
+func TestSearch_Language(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("language") != "de" {
+			t.Errorf("Expected language to be 'de', got '%s'", r.URL.Query().Get("language"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintln(w, `{"results": [{"name": "München", "country": "Deutschland"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewGeocodingClient(server.Client())
+	client.BaseURL = server.URL
+	client.Language = "de"
+
+	location, err := client.Search("Munich")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if location.Name != "München" {
+		t.Errorf("Expected location name 'München', got '%s'", location.Name)
+	}
+}
@@ -0,0 +1,184 @@
+// Package config loads sky's JSON configuration file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/daemon"
+	"github.com/mohithbuilds/sky/internal/exporter"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/notify"
+)
+
+// EnvPath is the environment variable that overrides the config file path.
+const EnvPath = "SKY_CONFIG"
+
+// Config is the contents of the configuration file, e.g.
+//
+//	{
+//	  "rules": [
+//	    {"name": "rain soon", "when": "precipitation_probability > 60 within next 3h"},
+//	    {"name": "frost", "when": "min temp < 0 tomorrow", "severity": "warning"}
+//	  ],
+//	  "webhooks": [
+//	    {"url": "https://hooks.slack.com/services/...", "format": "slack"}
+//	  ],
+//	  "daemon": {
+//	    "locations": [{"name": "Berlin"}],
+//	    "schedules": [{"fetch": "current", "every": "15m"}, {"fetch": "daily", "at": "06:00"}]
+//	  },
+//	  "providers": {
+//	    "chain": [{"name": "openmeteo", "timeout": "5s"}, {"name": "metno"}]
+//	  }
+//	}
+type Config struct {
+	Rules     []alerts.Rule     `json:"rules"`
+	Webhooks  []notify.Webhook  `json:"webhooks,omitempty"`
+	Daemon    daemon.Settings   `json:"daemon,omitzero"`
+	History   history.Settings  `json:"history,omitzero"`
+	Exporter  exporter.Settings `json:"exporter,omitzero"`
+	Providers Providers         `json:"providers,omitzero"`
+}
+
+// ProviderNames are the weather providers sky can fetch from.
+var ProviderNames = []string{"openmeteo", "nws", "metno"}
+
+// Providers is the "providers" section of the config file: the weather
+// providers to try in order, and the circuit breaker settings of the chain.
+// Zero values take the defaults of weather.Failover.
+type Providers struct {
+	Chain            []Provider      `json:"chain,omitempty"`
+	FailureThreshold int             `json:"failure_threshold,omitempty"`
+	Cooldown         daemon.Duration `json:"cooldown,omitzero"`
+}
+
+// Provider is a weather provider in the chain.
+type Provider struct {
+	Name    string          `json:"name"`
+	Timeout daemon.Duration `json:"timeout,omitzero"`
+}
+
+// Validate checks that the chain names each known provider at most once.
+func (p Providers) Validate() error {
+	seen := make(map[string]bool, len(p.Chain))
+	for _, provider := range p.Chain {
+		if !slices.Contains(ProviderNames, provider.Name) {
+			return fmt.Errorf("unknown provider %q", provider.Name)
+		}
+		if seen[provider.Name] {
+			return fmt.Errorf("duplicate provider %q", provider.Name)
+		}
+		seen[provider.Name] = true
+		if provider.Timeout < 0 {
+			return fmt.Errorf("provider %q has a negative timeout", provider.Name)
+		}
+	}
+	if p.FailureThreshold < 0 {
+		return fmt.Errorf("failure threshold must not be negative, got %d", p.FailureThreshold)
+	}
+	if p.Cooldown < 0 {
+		return fmt.Errorf("cooldown must not be negative, got %s", time.Duration(p.Cooldown))
+	}
+	return nil
+}
+
+// DefaultPath returns $SKY_CONFIG, or config.json in the user's sky config
+// directory (e.g. ~/.config/sky/config.json on Linux).
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory: %w", err)
+	}
+	return filepath.Join(dir, "sky", "config.json"), nil
+}
+
+// Load reads and validates the config file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadDefault loads the config from DefaultPath. A missing file is not an
+// error and yields an empty config.
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return cfg, err
+}
+
+// Parse decodes and validates a config. Unknown fields are rejected so that
+// typos do not silently disable a setting.
+func Parse(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the settings that cannot be checked while decoding.
+func (c *Config) Validate() error {
+	names := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	webhooks := make(map[string]bool, len(c.Webhooks))
+	for _, webhook := range c.Webhooks {
+		if err := webhook.Validate(); err != nil {
+			return err
+		}
+		if webhooks[webhook.ID()] {
+			return fmt.Errorf("duplicate webhook %q", webhook.ID())
+		}
+		webhooks[webhook.ID()] = true
+	}
+
+	if err := c.Daemon.Validate(); err != nil {
+		return fmt.Errorf("invalid daemon settings: %w", err)
+	}
+	if err := c.Exporter.Validate(); err != nil {
+		return fmt.Errorf("invalid exporter settings: %w", err)
+	}
+	if err := c.Providers.Validate(); err != nil {
+		return fmt.Errorf("invalid provider settings: %w", err)
+	}
+	if err := c.History.Validate(); err != nil {
+		return fmt.Errorf("invalid history settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Rules(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"rules": [
+			{"name": "rain soon", "when": "precipitation_probability > 60 within next 3h"},
+			{"when": "pm2_5 > 35", "severity": "warning"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(cfg.Rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(cfg.Rules))
+	}
+	if cfg.Rules[0].Expression() == nil {
+		t.Error("Expected the rule to be parsed while loading")
+	}
+	if cfg.Rules[1].Name != "pm2_5 > 35" {
+		t.Errorf("Expected an unnamed rule to be named after its condition, got '%s'", cfg.Rules[1].Name)
+	}
+}
+
+func TestParse_InvalidRule(t *testing.T) {
+	_, err := Parse([]byte(`{"rules": [{"name": "typo", "when": "temprature < 0"}]}`))
+	if err == nil {
+		t.Fatal("Expected an error for an unknown metric")
+	}
+	if !strings.Contains(err.Error(), `invalid rule "typo"`) || !strings.Contains(err.Error(), "temprature") {
+		t.Errorf("Expected the error to name the rule and metric, got: %v", err)
+	}
+}
+
+func TestParse_RejectsUnknownFieldsAndDuplicates(t *testing.T) {
+	if _, err := Parse([]byte(`{"rulez": []}`)); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	_, err := Parse([]byte(`{"rules": [{"name": "a", "when": "temp < 0"}, {"name": "a", "when": "temp > 30"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Expected a duplicate rule error, got: %v", err)
+	}
+}
+
+func TestParse_Webhooks(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"webhooks": [
+			{"name": "team", "url": "https://hooks.slack.com/services/x", "format": "slack"},
+			{"url": "https://example.com/hook", "secret": "s3cret"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(cfg.Webhooks) != 2 || cfg.Webhooks[0].Format != "slack" || cfg.Webhooks[1].Secret != "s3cret" {
+		t.Errorf("Unexpected webhooks: %+v", cfg.Webhooks)
+	}
+
+	_, err = Parse([]byte(`{"webhooks": [{"url": "https://example.com", "format": "pager"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "pager") {
+		t.Errorf("Expected an unknown format error, got: %v", err)
+	}
+}
+
+func TestParse_Daemon(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"daemon": {
+			"locations": [{"name": "Berlin"}, {"name": "Home", "latitude": 51.5, "longitude": -0.1, "timezone": "Europe/London"}],
+			"schedules": [{"fetch": "current", "every": "15m"}, {"fetch": "daily", "at": "06:00"}]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(cfg.Daemon.Locations) != 2 || len(cfg.Daemon.Schedules) != 2 {
+		t.Fatalf("Unexpected daemon settings: %+v", cfg.Daemon)
+	}
+	if got := time.Duration(cfg.Daemon.Schedules[0].Every); got != 15*time.Minute {
+		t.Errorf("Expected every to be 15m, got %s", got)
+	}
+
+	_, err = Parse([]byte(`{"daemon": {"schedules": [{"fetch": "daily", "at": "6am"}]}}`))
+	if err == nil || !strings.Contains(err.Error(), "6am") {
+		t.Errorf("Expected an invalid time error, got: %v", err)
+	}
+}
+
+func TestParse_Exporter(t *testing.T) {
+	cfg, err := Parse([]byte(`{"exporter": {"listen": ":9100", "interval": "10m", "locations": [{"name": "Berlin"}]}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg.Exporter.Listen != ":9100" || time.Duration(cfg.Exporter.Interval) != 10*time.Minute {
+		t.Errorf("Unexpected exporter settings: %+v", cfg.Exporter)
+	}
+
+	_, err = Parse([]byte(`{"exporter": {"interval": "5s"}}`))
+	if err == nil || !strings.Contains(err.Error(), "shorter than") {
+		t.Errorf("Expected an interval error, got: %v", err)
+	}
+}
+
+func TestParse_Providers(t *testing.T) {
+	cfg, err := Parse([]byte(`{"providers": {
+		"chain": [{"name": "openmeteo", "timeout": "5s"}, {"name": "metno"}],
+		"failure_threshold": 5,
+		"cooldown": "2m"
+	}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	chain := cfg.Providers.Chain
+	if len(chain) != 2 || chain[0].Name != "openmeteo" || time.Duration(chain[0].Timeout) != 5*time.Second || chain[1].Timeout != 0 {
+		t.Errorf("Unexpected provider chain: %+v", chain)
+	}
+	if cfg.Providers.FailureThreshold != 5 || time.Duration(cfg.Providers.Cooldown) != 2*time.Minute {
+		t.Errorf("Unexpected circuit breaker settings: %+v", cfg.Providers)
+	}
+
+	for _, tt := range []struct{ config, message string }{
+		{`{"providers": {"chain": [{"name": "accuweather"}]}}`, `unknown provider "accuweather"`},
+		{`{"providers": {"chain": [{"name": "nws"}, {"name": "nws"}]}}`, `duplicate provider "nws"`},
+		{`{"providers": {"chain": [{"name": "nws", "timeout": "-1s"}]}}`, "negative timeout"},
+	} {
+		if _, err := Parse([]byte(tt.config)); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected an error containing %q for %s, got: %v", tt.message, tt.config, err)
+		}
+	}
+}
+
+func TestParse_History(t *testing.T) {
+	cfg, err := Parse([]byte(`{"history": {"retention": "90d", "resolution": "3h"}, "providers": {"cooldown": "1w"}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if time.Duration(cfg.History.Retention) != 90*24*time.Hour || time.Duration(cfg.History.Resolution) != 3*time.Hour {
+		t.Errorf("Unexpected history settings: %+v", cfg.History)
+	}
+	// Every duration in the config file takes days and weeks.
+	if time.Duration(cfg.Providers.Cooldown) != 7*24*time.Hour {
+		t.Errorf("Expected a cooldown of a week, got %s", time.Duration(cfg.Providers.Cooldown))
+	}
+
+	_, err = Parse([]byte(`{"history": {"retention": "-2d"}}`))
+	if err == nil || !strings.Contains(err.Error(), "retention must not be negative") {
+		t.Errorf("Expected a negative retention error, got: %v", err)
+	}
+}
+
+func TestLoadDefault_MissingFile(t *testing.T) {
+	t.Setenv(EnvPath, filepath.Join(t.TempDir(), "missing.json"))
+
+	cfg, err := LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault failed: %v", err)
+	}
+	if len(cfg.Rules) != 0 {
+		t.Errorf("Expected an empty config, got %+v", cfg)
+	}
+}
+
+func TestLoad_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"rules": [{"when": "wind_gusts > 70"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Rules) != 1 || cfg.Rules[0].Expression().Metric != "wind_gusts" {
+		t.Errorf("Expected a wind gust rule, got %+v", cfg.Rules)
+	}
+}
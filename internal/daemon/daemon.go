@@ -0,0 +1,369 @@
+// Package daemon polls locations on schedules, storing what it fetches and
+// evaluating alert rules.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/notify"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Location is a place the daemon polls. Locations configured by name only
+// are looked up before they reach the daemon.
+type Location struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Timezone  string  `json:"timezone,omitempty"` // IANA name; the system zone when empty
+}
+
+// zone returns the location's time zone.
+func (l Location) zone() (*time.Location, error) {
+	if l.Timezone == "" {
+		return time.Local, nil
+	}
+	zone, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("location %q: %w", l.Name, err)
+	}
+	return zone, nil
+}
+
+// Settings is the "daemon" section of the config file.
+type Settings struct {
+	Locations []Location `json:"locations"`
+	Schedules []Schedule `json:"schedules"`
+	Units     string     `json:"units,omitempty"` // metric or imperial
+	Store     string     `json:"store,omitempty"` // Directory for results
+	// Models fetches every forecast once per weather model, e.g.
+	// "icon_seamless", so that sky verify can compare them. The API picks
+	// one when empty.
+	Models []string `json:"models,omitempty"`
+}
+
+// Validate checks the schedules and locations.
+func (s Settings) Validate() error {
+	for _, schedule := range s.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(s.Locations))
+	for _, location := range s.Locations {
+		if location.Name == "" {
+			return fmt.Errorf("daemon location without a name")
+		}
+		if names[location.Name] {
+			return fmt.Errorf("duplicate daemon location %q", location.Name)
+		}
+		names[location.Name] = true
+		if _, err := location.zone(); err != nil {
+			return err
+		}
+	}
+	models := make(map[string]bool, len(s.Models))
+	for _, model := range s.Models {
+		if model == "" {
+			return fmt.Errorf("empty daemon model name")
+		}
+		if models[model] {
+			return fmt.Errorf("duplicate daemon model %q", model)
+		}
+		models[model] = true
+	}
+	return nil
+}
+
+// Config is everything one run of the schedules needs. It is loaded when
+// the daemon starts and again on each reload.
+type Config struct {
+	Locations []Location
+	Schedules []Schedule
+	Rules     []alerts.Rule
+	Webhooks  []notify.Webhook
+	Models    []string // One job per model for each schedule; none for the API's choice
+
+	TempUnit, WindUnit, PrecipUnit string
+}
+
+// Fetcher fetches weather data. *weather.WeatherClient implements it.
+type Fetcher interface {
+	alerts.Fetcher
+	GetCurrentWeather(
+		latitude, longitude float64,
+		tempUnit, windUnit, precipUnit string,
+	) (*weather.CurrentWeather, error)
+}
+
+// Daemon runs the schedules of its config until it is stopped.
+type Daemon struct {
+	Client Fetcher
+	Store  Store
+	Log    *log.Logger
+
+	// ClientFor returns the client that fetches from a weather model. It is
+	// needed only when the config lists models.
+	ClientFor func(model string) Fetcher
+
+	// Load returns the config. It is called on start and on every reload; a
+	// failed reload keeps the previous config.
+	Load func() (*Config, error)
+
+	// Notifier, when set, receives the results of alert schedules. Its
+	// webhooks are replaced by the config's on every load.
+	Notifier *notify.Notifier
+	// SaveState is called after each alert schedule, e.g. to persist the
+	// notifier's deduplication state.
+	SaveState func() error
+
+	now func() time.Time
+}
+
+// New returns a Daemon that logs to logger.
+func New(client Fetcher, store Store, load func() (*Config, error), logger *log.Logger) *Daemon {
+	return &Daemon{
+		Client: client,
+		Store:  store,
+		Load:   load,
+		Log:    logger,
+		now:    time.Now,
+	}
+}
+
+// job is one schedule at one location.
+type job struct {
+	location Location
+	zone     *time.Location
+	schedule Schedule
+	model    string
+	next     time.Time
+}
+
+// key identifies a job across reloads.
+func (j *job) key() string {
+	return j.location.Name + "|" + j.schedule.String() + "|" + j.model
+}
+
+// name describes the job in log lines.
+func (j *job) name() string {
+	if j.model == "" {
+		return fmt.Sprintf("%s %s", j.location.Name, j.schedule)
+	}
+	return fmt.Sprintf("%s %s (%s)", j.location.Name, j.schedule, j.model)
+}
+
+// Run runs the schedules until ctx is done, reloading the config whenever a
+// value arrives on reload. A job that is running when ctx is done finishes
+// first. Run returns an error only if the config cannot be loaded at start.
+func (d *Daemon) Run(ctx context.Context, reload <-chan struct{}) error {
+	cfg, err := d.Load()
+	if err != nil {
+		return err
+	}
+	jobs, err := d.plan(cfg, nil)
+	if err != nil {
+		return err
+	}
+	d.Log.Printf("started with %d locations and %d schedules", len(cfg.Locations), len(cfg.Schedules))
+
+	for {
+		// With no jobs there is nothing to wait for but a reload or a stop.
+		var wait <-chan time.Time
+		stop := func() {}
+		if next := earliest(jobs); next != nil {
+			timer := time.NewTimer(next.next.Sub(d.now()))
+			wait, stop = timer.C, func() { timer.Stop() }
+		}
+
+		select {
+		case <-ctx.Done():
+			stop()
+			d.Log.Printf("stopping")
+			return nil
+		case <-reload:
+			stop()
+			cfg, jobs = d.reload(cfg, jobs)
+		case <-wait:
+			for _, j := range jobs {
+				if ctx.Err() != nil {
+					break
+				}
+				if now := d.now(); !j.next.After(now) {
+					d.run(cfg, j)
+					j.next = j.schedule.Next(now, j.zone)
+				}
+			}
+		}
+	}
+}
+
+// reload loads the config again, keeping the current one if that fails.
+func (d *Daemon) reload(cfg *Config, jobs []*job) (*Config, []*job) {
+	reloaded, err := d.Load()
+	if err != nil {
+		d.Log.Printf("reload failed, keeping the previous config: %v", err)
+		return cfg, jobs
+	}
+	replanned, err := d.plan(reloaded, jobs)
+	if err != nil {
+		d.Log.Printf("reload failed, keeping the previous config: %v", err)
+		return cfg, jobs
+	}
+	d.Log.Printf("reloaded with %d locations and %d schedules", len(reloaded.Locations), len(reloaded.Schedules))
+	return reloaded, replanned
+}
+
+// plan builds the jobs of a config. Jobs that were already scheduled keep
+// their next run, so a reload does not run every interval schedule again.
+func (d *Daemon) plan(cfg *Config, previous []*job) ([]*job, error) {
+	next := make(map[string]time.Time, len(previous))
+	for _, j := range previous {
+		next[j.key()] = j.next
+	}
+
+	models := cfg.Models
+	if len(models) == 0 {
+		models = []string{""}
+	} else if d.ClientFor == nil {
+		return nil, fmt.Errorf("weather models are configured but the daemon cannot select them")
+	}
+
+	now := d.now()
+	var jobs []*job
+	for _, location := range cfg.Locations {
+		zone, err := location.zone()
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range cfg.Schedules {
+			if err := schedule.Validate(); err != nil {
+				return nil, err
+			}
+			// Alerts are evaluated once, against the API's choice of model.
+			scheduleModels := models
+			if schedule.Fetch == KindAlerts {
+				scheduleModels = []string{""}
+			}
+			for _, model := range scheduleModels {
+				j := &job{location: location, zone: zone, schedule: schedule, model: model}
+				if t, ok := next[j.key()]; ok {
+					j.next = t
+				} else {
+					j.next = schedule.First(now, zone)
+				}
+				jobs = append(jobs, j)
+			}
+		}
+	}
+
+	if d.Notifier != nil {
+		d.Notifier.Webhooks = cfg.Webhooks
+	}
+	return jobs, nil
+}
+
+func earliest(jobs []*job) *job {
+	var first *job
+	for _, j := range jobs {
+		if first == nil || j.next.Before(first.next) {
+			first = j
+		}
+	}
+	return first
+}
+
+// run runs a job once and stores the result. Failures are logged; the job
+// runs again at its next time.
+func (d *Daemon) run(cfg *Config, j *job) {
+	data, err := d.fetch(cfg, j)
+	if err != nil {
+		d.Log.Printf("%s: %v", j.name(), err)
+		return
+	}
+	record := Record{
+		Time:     d.now(),
+		Location: j.location.Name,
+		Kind:     j.schedule.Fetch,
+		Model:    j.model,
+		Data:     data,
+	}
+	if err := d.Store.Write(record); err != nil {
+		d.Log.Printf("%s: %v", j.name(), err)
+	}
+}
+
+func (d *Daemon) fetch(cfg *Config, j *job) (any, error) {
+	lat, lon := j.location.Latitude, j.location.Longitude
+	client := d.Client
+	if j.model != "" {
+		client = d.ClientFor(j.model)
+	}
+	switch j.schedule.Fetch {
+	case KindCurrent:
+		return client.GetCurrentWeather(lat, lon, cfg.TempUnit, cfg.WindUnit, cfg.PrecipUnit)
+	case KindHourly:
+		hours := j.schedule.Hours
+		if hours == 0 {
+			hours = 24
+		}
+		return client.GetHourlyForecast(lat, lon, hours, cfg.TempUnit, cfg.WindUnit, cfg.PrecipUnit)
+	case KindDaily:
+		days := j.schedule.Days
+		if days == 0 {
+			days = 7
+		}
+		return client.GetDailyForecast(lat, lon, days, cfg.TempUnit, cfg.WindUnit, cfg.PrecipUnit)
+	case KindAir:
+		return client.GetCurrentAirQuality(lat, lon)
+	case KindAlerts:
+		return d.evaluate(cfg, j.location)
+	default:
+		return nil, fmt.Errorf("unknown fetch %q", j.schedule.Fetch)
+	}
+}
+
+// evaluate evaluates the rules at a location and notifies the webhooks of
+// those that fired.
+func (d *Daemon) evaluate(cfg *Config, location Location) ([]alerts.Result, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("no alert rules configured")
+	}
+	data, err := alerts.Fetch(
+		d.Client,
+		location.Latitude,
+		location.Longitude,
+		cfg.Rules,
+		cfg.TempUnit,
+		cfg.WindUnit,
+		cfg.PrecipUnit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	results := alerts.Evaluate(cfg.Rules, data)
+	for _, result := range alerts.Fired(results) {
+		d.Log.Printf("%s: %s fired (%s)", location.Name, result.Rule, result.Expression)
+	}
+
+	if d.Notifier != nil && len(cfg.Webhooks) > 0 {
+		_, err := d.Notifier.Notify(notify.Location{
+			Name:      location.Name,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		}, results)
+		if err != nil {
+			d.Log.Printf("%s: %v", location.Name, err)
+		}
+		if d.SaveState != nil {
+			if err := d.SaveState(); err != nil {
+				d.Log.Printf("%v", err)
+			}
+		}
+	}
+	return results, nil
+}
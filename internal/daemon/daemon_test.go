@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// mockFetcher returns fixed data and counts the calls for each kind.
+type mockFetcher struct {
+	mu    sync.Mutex
+	calls map[Kind]int
+}
+
+func (m *mockFetcher) count(kind Kind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[Kind]int)
+	}
+	m.calls[kind]++
+}
+
+func (m *mockFetcher) GetCurrentWeather(lat, lon float64, tempUnit, windUnit, precipUnit string) (*weather.CurrentWeather, error) {
+	m.count(KindCurrent)
+	return &weather.CurrentWeather{Temperature: 21, Units: weather.Units{Temperature: tempUnit}}, nil
+}
+
+func (m *mockFetcher) GetHourlyForecast(lat, lon float64, hours int64, tempUnit, windUnit, precipUnit string) ([]weather.HourlyForecast, error) {
+	m.count(KindHourly)
+	start := time.Now().Truncate(time.Hour)
+	forecasts := make([]weather.HourlyForecast, hours)
+	for i := range forecasts {
+		forecasts[i] = weather.HourlyForecast{
+			DateTime:          start.Add(time.Duration(i) * time.Hour),
+			PrecipitationProb: 80,
+		}
+	}
+	return forecasts, nil
+}
+
+func (m *mockFetcher) GetDailyForecast(lat, lon float64, days int64, tempUnit, windUnit, precipUnit string) ([]weather.DailyForecast, error) {
+	m.count(KindDaily)
+	return make([]weather.DailyForecast, days), nil
+}
+
+func (m *mockFetcher) GetCurrentAirQuality(lat, lon float64) (*weather.AirQuality, error) {
+	m.count(KindAir)
+	return &weather.AirQuality{PM25: 12}, nil
+}
+
+// memoryStore sends every record it receives on a channel.
+type memoryStore struct {
+	records chan Record
+}
+
+func (s *memoryStore) Write(record Record) error {
+	s.records <- record
+	return nil
+}
+
+func receive(t *testing.T, store *memoryStore) Record {
+	t.Helper()
+	select {
+	case record := <-store.records:
+		return record
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a record")
+		return Record{}
+	}
+}
+
+func TestDaemon_RunsSchedulesAndStops(t *testing.T) {
+	rule, err := alerts.NewRule("rain", "precipitation_probability > 60 within next 3h")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	cfg := &Config{
+		Locations: []Location{{Name: "Berlin", Latitude: 52.52, Longitude: 13.41, Timezone: "Europe/Berlin"}},
+		Schedules: []Schedule{
+			{Fetch: KindCurrent, Every: Duration(time.Hour)},
+			{Fetch: KindAlerts, Every: Duration(time.Hour)},
+			{Fetch: KindDaily, At: "06:00"},
+		},
+		Rules:    []alerts.Rule{rule},
+		TempUnit: "celsius",
+	}
+
+	fetcher := &mockFetcher{}
+	store := &memoryStore{records: make(chan Record, 10)}
+	d := New(fetcher, store, func() (*Config, error) { return cfg, nil }, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx, nil) }()
+
+	// The interval schedules run immediately; the daily one waits for 06:00.
+	current, alert := receive(t, store), receive(t, store)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if current.Kind != KindCurrent || current.Location != "Berlin" {
+		t.Errorf("Expected a current record for Berlin, got %+v", current)
+	}
+	if cw, ok := current.Data.(*weather.CurrentWeather); !ok || cw.Units.Temperature != "celsius" {
+		t.Errorf("Expected the current weather in celsius, got %#v", current.Data)
+	}
+
+	results, ok := alert.Data.([]alerts.Result)
+	if alert.Kind != KindAlerts || !ok || len(results) != 1 || !results[0].Fired {
+		t.Errorf("Expected the rain rule to fire, got %+v", alert)
+	}
+	if fetcher.calls[KindDaily] != 0 {
+		t.Errorf("Expected the daily schedule not to run yet, got %d calls", fetcher.calls[KindDaily])
+	}
+}
+
+func TestDaemon_Reload(t *testing.T) {
+	every := []Schedule{{Fetch: KindAir, Every: Duration(time.Hour)}}
+	configs := []*Config{
+		{Locations: []Location{{Name: "Berlin"}}, Schedules: every},
+		{Locations: []Location{{Name: "Berlin"}, {Name: "Paris"}}, Schedules: every},
+	}
+	var loads int
+	load := func() (*Config, error) {
+		cfg := configs[min(loads, len(configs)-1)]
+		loads++
+		return cfg, nil
+	}
+
+	store := &memoryStore{records: make(chan Record, 10)}
+	d := New(&mockFetcher{}, store, load, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reload := make(chan struct{})
+	go d.Run(ctx, reload)
+
+	if record := receive(t, store); record.Location != "Berlin" {
+		t.Fatalf("Expected a record for Berlin first, got %+v", record)
+	}
+	reload <- struct{}{}
+
+	// Only the new location runs; Berlin keeps its place in the schedule.
+	if record := receive(t, store); record.Location != "Paris" {
+		t.Errorf("Expected the reload to add Paris, got %+v", record)
+	}
+	select {
+	case record := <-store.records:
+		t.Errorf("Expected no other record after the reload, got %+v", record)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDaemon_Models(t *testing.T) {
+	cfg := &Config{
+		Locations: []Location{{Name: "Berlin"}},
+		Schedules: []Schedule{{Fetch: KindCurrent, Every: Duration(time.Hour)}},
+		Models:    []string{"gfs_seamless", "icon_seamless"},
+	}
+	store := &memoryStore{records: make(chan Record, 10)}
+	d := New(&mockFetcher{}, store, func() (*Config, error) { return cfg, nil }, log.New(io.Discard, "", 0))
+
+	var mu sync.Mutex
+	clients := make(map[string]*mockFetcher)
+	d.ClientFor = func(model string) Fetcher {
+		mu.Lock()
+		defer mu.Unlock()
+		if clients[model] == nil {
+			clients[model] = &mockFetcher{}
+		}
+		return clients[model]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx, nil) }()
+	first, second := receive(t, store), receive(t, store)
+	cancel()
+	<-done
+
+	if first.Model != "gfs_seamless" || second.Model != "icon_seamless" {
+		t.Errorf("Expected a record for each model, got %q and %q", first.Model, second.Model)
+	}
+	for _, model := range cfg.Models {
+		if clients[model] == nil || clients[model].calls[KindCurrent] != 1 {
+			t.Errorf("Expected one fetch from the %s client", model)
+		}
+	}
+}
+
+func TestFileStore_Write(t *testing.T) {
+	store := &FileStore{Dir: t.TempDir()}
+	at := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 2 {
+		err := store.Write(Record{
+			Time:     at.Add(time.Duration(i) * time.Hour),
+			Location: "São Paulo",
+			Kind:     KindCurrent,
+			Data:     map[string]float64{"temperature": 20 + float64(i)},
+		})
+		if err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(store.Dir, "são-paulo", "current.jsonl"))
+	if err != nil {
+		t.Fatalf("Expected a file per location and kind: %v", err)
+	}
+	defer f.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record struct {
+			Time time.Time          `json:"time"`
+			Data map[string]float64 `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to decode line %d: %v", lines+1, err)
+		}
+		if record.Data["temperature"] != 20+float64(lines) {
+			t.Errorf("Unexpected record on line %d: %+v", lines+1, record)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", lines)
+	}
+}
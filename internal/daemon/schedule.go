@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is what a schedule fetches.
+type Kind string
+
+const (
+	KindCurrent Kind = "current" // Current weather
+	KindHourly  Kind = "hourly"  // Hourly forecast
+	KindDaily   Kind = "daily"   // Daily forecast
+	KindAir     Kind = "air"     // Current air quality
+	KindAlerts  Kind = "alerts"  // Evaluate the alert rules and notify the webhooks
+)
+
+// Duration is a time.Duration written as a string such as "15m" or "7d" in
+// JSON, in the grammar of ParseDuration.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15m\" or \"7d\": %w", err)
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// ParseDuration parses a duration such as "15m" or "36h", which may also be
+// given in whole days or weeks, such as "7d" or "2w".
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (want e.g. 15m, 36h, 7d or 2w)", s)
+	}
+	return d, nil
+}
+
+// minEvery keeps schedules from hammering the API.
+const minEvery = time.Minute
+
+// Schedule says what to fetch and when: either Every interval, starting when
+// the daemon starts, or daily At a local time of each location, e.g.
+//
+//	{"fetch": "current", "every": "15m"}
+//	{"fetch": "daily", "at": "06:00", "days": 7}
+type Schedule struct {
+	Fetch Kind     `json:"fetch"`
+	Every Duration `json:"every,omitempty"`
+	At    string   `json:"at,omitempty"` // "15:04" in the location's time zone
+
+	Hours int64 `json:"hours,omitempty"` // Hours of hourly forecast, 24 by default
+	Days  int64 `json:"days,omitempty"`  // Days of daily forecast, 7 by default
+}
+
+// Validate checks that the schedule has a known kind and exactly one of
+// Every and At.
+func (s Schedule) Validate() error {
+	switch s.Fetch {
+	case KindCurrent, KindHourly, KindDaily, KindAir, KindAlerts:
+	default:
+		return fmt.Errorf("unknown fetch %q (want current, hourly, daily, air or alerts)", s.Fetch)
+	}
+	switch {
+	case s.Every != 0 && s.At != "":
+		return fmt.Errorf("%s schedule has both every and at", s.Fetch)
+	case s.Every == 0 && s.At == "":
+		return fmt.Errorf("%s schedule needs every or at", s.Fetch)
+	case s.Every != 0 && time.Duration(s.Every) < minEvery:
+		return fmt.Errorf("%s schedule runs every %s, more often than every %s", s.Fetch, time.Duration(s.Every), minEvery)
+	}
+	if s.At != "" {
+		if _, err := time.Parse("15:04", s.At); err != nil {
+			return fmt.Errorf("%s schedule: invalid time %q (want HH:MM)", s.Fetch, s.At)
+		}
+	}
+	if s.Hours < 0 || s.Days < 0 || s.Days > 16 {
+		return fmt.Errorf("%s schedule: hours must be positive and days between 1 and 16", s.Fetch)
+	}
+	return nil
+}
+
+// String describes the schedule, e.g. "daily at 06:00".
+func (s Schedule) String() string {
+	if s.At != "" {
+		return fmt.Sprintf("%s at %s", s.Fetch, s.At)
+	}
+	return fmt.Sprintf("%s every %s", s.Fetch, time.Duration(s.Every))
+}
+
+// First returns when the schedule first runs once the daemon starts at now:
+// immediately for interval schedules, at the next matching local time for
+// daily ones.
+func (s Schedule) First(now time.Time, zone *time.Location) time.Time {
+	if s.At == "" {
+		return now
+	}
+	return s.Next(now, zone)
+}
+
+// Next returns the first run strictly after last. Daily times follow the
+// location's clock, so they stay at the same local time across daylight
+// saving changes.
+func (s Schedule) Next(last time.Time, zone *time.Location) time.Time {
+	if s.At == "" {
+		return last.Add(time.Duration(s.Every))
+	}
+	at, _ := time.Parse("15:04", s.At)
+	local := last.In(zone)
+	year, month, day := local.Date()
+	next := time.Date(year, month, day, at.Hour(), at.Minute(), 0, 0, zone)
+	if !next.After(last) {
+		next = time.Date(year, month, day+1, at.Hour(), at.Minute(), 0, 0, zone)
+	}
+	return next
+}
@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	valid := []Schedule{
+		{Fetch: KindCurrent, Every: Duration(15 * time.Minute)},
+		{Fetch: KindDaily, At: "06:00", Days: 7},
+		{Fetch: KindAlerts, Every: Duration(time.Hour)},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", s, err)
+		}
+	}
+
+	invalid := []Schedule{
+		{Fetch: "radar", Every: Duration(time.Hour)},
+		{Fetch: KindCurrent},
+		{Fetch: KindCurrent, Every: Duration(time.Hour), At: "06:00"},
+		{Fetch: KindCurrent, Every: Duration(10 * time.Second)},
+		{Fetch: KindDaily, At: "25:00"},
+		{Fetch: KindDaily, At: "06:00", Days: 30},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", s)
+		}
+	}
+}
+
+func TestSchedule_Every(t *testing.T) {
+	s := Schedule{Fetch: KindCurrent, Every: Duration(15 * time.Minute)}
+	now := time.Date(2024, 6, 1, 9, 7, 0, 0, time.UTC)
+
+	if first := s.First(now, time.UTC); !first.Equal(now) {
+		t.Errorf("Expected an interval schedule to run immediately, got %v", first)
+	}
+	if next := s.Next(now, time.UTC); !next.Equal(now.Add(15 * time.Minute)) {
+		t.Errorf("Expected the next run 15m later, got %v", next)
+	}
+}
+
+func TestSchedule_AtLocalTime(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	s := Schedule{Fetch: KindDaily, At: "06:00"}
+
+	// 20:00 UTC is 05:00 the next morning in Tokyo, so the run is an hour away.
+	now := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	want := time.Date(2024, 6, 2, 6, 0, 0, 0, tokyo)
+	if first := s.First(now, tokyo); !first.Equal(want) {
+		t.Errorf("Expected the first run at %v, got %v", want, first)
+	}
+
+	// Right after a run, the next one is the following day.
+	want = time.Date(2024, 6, 3, 6, 0, 0, 0, tokyo)
+	if next := s.Next(time.Date(2024, 6, 2, 6, 0, 0, 0, tokyo), tokyo); !next.Equal(want) {
+		t.Errorf("Expected the next run at %v, got %v", want, next)
+	}
+}
+
+func TestSchedule_AtAcrossDaylightSaving(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	s := Schedule{Fetch: KindDaily, At: "06:00"}
+
+	// Clocks go forward on 31 March 2024; the run stays at 06:00 local time.
+	last := time.Date(2024, 3, 30, 6, 0, 0, 0, berlin)
+	next := s.Next(last, berlin)
+	if next.In(berlin).Hour() != 6 {
+		t.Errorf("Expected the run to stay at 06:00 local time, got %v", next.In(berlin))
+	}
+	if gap := next.Sub(last); gap != 23*time.Hour {
+		t.Errorf("Expected 23h between runs across the change, got %s", gap)
+	}
+}
+
+func TestDuration_JSON(t *testing.T) {
+	var s Schedule
+	if err := json.Unmarshal([]byte(`{"fetch": "current", "every": "1h30m"}`), &s); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if time.Duration(s.Every) != 90*time.Minute {
+		t.Errorf("Expected 1h30m, got %s", time.Duration(s.Every))
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"fetch":"current","every":"1h30m0s"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"fetch": "current", "every": 900}`), &s); err == nil {
+		t.Error("Expected an error for a numeric duration")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"15m":   15 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"7d":    7 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		"-1s":   -time.Second,
+	}
+	for input, want := range tests {
+		got, err := ParseDuration(input)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "d", "1.5d", "week", "15"} {
+		if _, err := ParseDuration(input); err == nil {
+			t.Errorf("Expected ParseDuration(%q) to fail", input)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Record is one result written by the daemon.
+type Record struct {
+	Time     time.Time `json:"time"` // When it was fetched
+	Location string    `json:"location"`
+	Kind     Kind      `json:"kind"`
+	Model    string    `json:"model,omitempty"`
+	Data     any       `json:"data"`
+}
+
+// Store receives the daemon's results.
+type Store interface {
+	Write(Record) error
+}
+
+// FileStore appends records as JSON lines to one file per location and kind
+// in Dir, e.g. berlin/current.jsonl.
+type FileStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// DefaultStoreDir returns the daemon directory in the user's sky cache
+// directory (e.g. ~/.cache/sky/daemon on Linux).
+func DefaultStoreDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	return filepath.Join(dir, "sky", "daemon"), nil
+}
+
+// Write appends the record to its file.
+func (s *FileStore) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record: %w", record.Kind, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.Dir, slug(record.Location))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, string(record.Kind)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open store file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s record: %w", record.Kind, err)
+	}
+	return f.Close()
+}
+
+// slug turns a location name into a file name, e.g. "São Paulo" into
+// "são-paulo".
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+	return "unnamed"
+}
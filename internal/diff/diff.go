@@ -0,0 +1,169 @@
+// Package diff compares a forecast with earlier fetches of the same forecast
+// recorded in the history, listing what changed by more than a threshold.
+package diff
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Thresholds are the smallest changes worth reporting. A zero threshold
+// reports every change of that variable.
+type Thresholds struct {
+	Temperature              float64 // Degrees in the forecast's unit
+	PrecipitationProbability float64 // Percentage points
+	Precipitation            float64 // Amount in the forecast's unit
+	Wind                     float64 // Gust speed in the forecast's unit
+}
+
+// DefaultThresholds returns the thresholds for the metric or imperial unit
+// system.
+func DefaultThresholds(imperial bool) Thresholds {
+	if imperial {
+		return Thresholds{Temperature: 4, PrecipitationProbability: 20, Precipitation: 0.1, Wind: 10}
+	}
+	return Thresholds{Temperature: 2, PrecipitationProbability: 20, Precipitation: 2, Wind: 15}
+}
+
+// VariableCondition is the variable of changes to the weather condition.
+const VariableCondition = "condition"
+
+// Change is a variable that changed between two forecasts of the same hour
+// or day.
+type Change struct {
+	Time     time.Time `json:"time"` // The hour or day the forecasts are for
+	Variable string    `json:"variable"`
+	Before   float64   `json:"before"` // For conditions, the weather codes
+	After    float64   `json:"after"`
+	Unit     string    `json:"unit,omitempty"`
+
+	// BeforeCondition and AfterCondition describe the weather codes of
+	// condition changes.
+	BeforeCondition string `json:"before_condition,omitempty"`
+	AfterCondition  string `json:"after_condition,omitempty"`
+}
+
+// Delta returns how much the value changed.
+func (c Change) Delta() float64 {
+	return c.After - c.Before
+}
+
+// Result lists the changes between two snapshots.
+type Result struct {
+	Before   time.Time `json:"before"`   // When the earlier forecast was fetched
+	After    time.Time `json:"after"`    // When the later forecast was fetched
+	Compared int       `json:"compared"` // Hours or days both forecasts cover
+	Changes  []Change  `json:"changes"`
+}
+
+// Compare compares two snapshots of the same kind.
+func Compare(before, after Snapshot, thresholds Thresholds) Result {
+	result := Result{Before: before.Taken, After: after.Taken}
+	if after.Kind == KindHourly {
+		result.Compared, result.Changes = compareHourly(before.Hourly, after.Hourly, thresholds)
+	} else {
+		result.Compared, result.Changes = compareDaily(before.Daily, after.Daily, thresholds)
+	}
+	return result
+}
+
+// changes collects the changes of one hour or day.
+type changes struct {
+	time time.Time
+	list *[]Change
+}
+
+func (c changes) value(variable string, before, after float64, unit string, threshold float64) {
+	delta := math.Abs(after - before)
+	if delta == 0 || delta < threshold {
+		return
+	}
+	*c.list = append(*c.list, Change{Time: c.time, Variable: variable, Before: before, After: after, Unit: unit})
+}
+
+func (c changes) condition(before, after weather.Condition) {
+	if !significant(before, after) {
+		return
+	}
+	*c.list = append(*c.list, Change{
+		Time:            c.time,
+		Variable:        VariableCondition,
+		Before:          float64(before.Code),
+		After:           float64(after.Code),
+		BeforeCondition: before.Description,
+		AfterCondition:  after.Description,
+	})
+}
+
+// significant reports whether a condition changed in kind, ignoring small
+// steps in cloud cover such as "mainly clear" to "partly cloudy".
+func significant(before, after weather.Condition) bool {
+	if before.Category == after.Category {
+		return before.PrecipitationType != after.PrecipitationType
+	}
+	sky := func(c weather.Condition) bool {
+		return c.Category == weather.CategoryClear || c.Category == weather.CategoryCloud
+	}
+	if sky(before) && sky(after) {
+		return math.Abs(float64(after.Code-before.Code)) >= 2
+	}
+	return true
+}
+
+func compareHourly(before, after []weather.HourlyForecast, t Thresholds) (int, []Change) {
+	earlier := make(map[int64]weather.HourlyForecast, len(before))
+	for _, h := range before {
+		earlier[h.DateTime.Unix()] = h
+	}
+
+	var list []Change
+	compared := 0
+	for _, a := range after {
+		b, ok := earlier[a.DateTime.Unix()]
+		if !ok || b.Units != a.Units {
+			continue
+		}
+		compared++
+		c := changes{time: a.DateTime, list: &list}
+		c.value("temperature", b.Temperature, a.Temperature, a.Units.Temperature, t.Temperature)
+		c.value("precipitation_probability", b.PrecipitationProb, a.PrecipitationProb, "%", t.PrecipitationProbability)
+		c.value("precipitation", b.Precipitation, a.Precipitation, a.Units.Precipitation, t.Precipitation)
+		c.value("wind_gusts", b.WindGusts, a.WindGusts, a.Units.WindSpeed, t.Wind)
+		c.condition(b.Condition, a.Condition)
+	}
+	sortChanges(list)
+	return compared, list
+}
+
+func compareDaily(before, after []weather.DailyForecast, t Thresholds) (int, []Change) {
+	earlier := make(map[int64]weather.DailyForecast, len(before))
+	for _, d := range before {
+		earlier[d.Date.Unix()] = d
+	}
+
+	var list []Change
+	compared := 0
+	for _, a := range after {
+		b, ok := earlier[a.Date.Unix()]
+		if !ok || b.Units != a.Units {
+			continue
+		}
+		compared++
+		c := changes{time: a.Date, list: &list}
+		c.value("temperature_max", b.MaxTemperature, a.MaxTemperature, a.Units.Temperature, t.Temperature)
+		c.value("temperature_min", b.MinTemperature, a.MinTemperature, a.Units.Temperature, t.Temperature)
+		c.value("precipitation_probability", b.PrecipitationProb, a.PrecipitationProb, "%", t.PrecipitationProbability)
+		c.value("precipitation_sum", b.PrecipitationSum, a.PrecipitationSum, a.Units.Precipitation, t.Precipitation)
+		c.value("wind_gusts_max", b.WindGusts, a.WindGusts, a.Units.WindSpeed, t.Wind)
+		c.condition(b.Condition, a.Condition)
+	}
+	sortChanges(list)
+	return compared, list
+}
+
+func sortChanges(list []Change) {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+}
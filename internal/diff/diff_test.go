@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+var metric = weather.Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"}
+
+func day(date time.Time, max, rain float64, code int) weather.DailyForecast {
+	return weather.DailyForecast{
+		Date:              date,
+		MaxTemperature:    max,
+		MinTemperature:    10,
+		PrecipitationProb: rain,
+		Condition:         weather.ConditionForCode(code, "en"),
+		Units:             metric,
+	}
+}
+
+func TestCompare_Daily(t *testing.T) {
+	saturday := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	sunday := saturday.AddDate(0, 0, 1)
+	before := Snapshot{
+		Taken: saturday.AddDate(0, 0, -2),
+		Kind:  KindDaily,
+		Daily: []weather.DailyForecast{
+			day(saturday.AddDate(0, 0, -1), 20, 0, 0), // Only in the earlier forecast
+			day(saturday, 22, 10, 1),
+			day(sunday, 18, 30, 2),
+		},
+	}
+	after := Snapshot{
+		Taken: saturday.AddDate(0, 0, -1),
+		Kind:  KindDaily,
+		Daily: []weather.DailyForecast{
+			day(saturday, 18, 70, 63),
+			// Small changes are not reported.
+			day(sunday, 19, 40, 3),
+		},
+	}
+
+	result := Compare(before, after, DefaultThresholds(false))
+	if result.Compared != 2 {
+		t.Errorf("Expected 2 days compared, got %d", result.Compared)
+	}
+	if len(result.Changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", result.Changes)
+	}
+	for _, c := range result.Changes {
+		if !c.Time.Equal(saturday) {
+			t.Errorf("Expected only Saturday to change, got %+v", c)
+		}
+	}
+
+	temperature, rain, condition := result.Changes[0], result.Changes[1], result.Changes[2]
+	if temperature.Variable != "temperature_max" || temperature.Delta() != -4 || temperature.Unit != "°C" {
+		t.Errorf("Expected the maximum to drop by 4 °C, got %+v", temperature)
+	}
+	if rain.Variable != "precipitation_probability" || rain.Before != 10 || rain.After != 70 {
+		t.Errorf("Expected the chance of rain to go from 10 to 70, got %+v", rain)
+	}
+	if condition.Variable != VariableCondition || condition.BeforeCondition != "Mainly clear" || condition.AfterCondition != "Moderate rain" {
+		t.Errorf("Expected the condition to change to rain, got %+v", condition)
+	}
+}
+
+func TestCompare_Hourly(t *testing.T) {
+	hour := time.Date(2024, 6, 15, 14, 0, 0, 0, time.UTC)
+	before := Snapshot{Kind: KindHourly, Hourly: []weather.HourlyForecast{
+		{DateTime: hour, Temperature: 20, WindGusts: 20, Units: metric},
+	}}
+	after := Snapshot{Kind: KindHourly, Hourly: []weather.HourlyForecast{
+		{DateTime: hour, Temperature: 21, WindGusts: 55, Units: metric},
+	}}
+
+	result := Compare(before, after, DefaultThresholds(false))
+	if len(result.Changes) != 1 || result.Changes[0].Variable != "wind_gusts" || result.Changes[0].Delta() != 35 {
+		t.Errorf("Expected only the gusts to change, by 35, got %+v", result.Changes)
+	}
+
+	// A zero threshold reports every change.
+	result = Compare(before, after, Thresholds{})
+	if len(result.Changes) != 2 {
+		t.Errorf("Expected 2 changes without thresholds, got %+v", result.Changes)
+	}
+}
+
+func TestSignificant(t *testing.T) {
+	for _, test := range []struct {
+		before, after int
+		expected      bool
+	}{
+		{1, 2, false}, // Mainly clear to partly cloudy
+		{0, 3, true},  // Clear to overcast
+		{3, 61, true}, // Overcast to rain
+		{61, 63, false},
+		{61, 66, true}, // Rain to freezing rain
+	} {
+		before, after := weather.ConditionForCode(test.before, "en"), weather.ConditionForCode(test.after, "en")
+		if got := significant(before, after); got != test.expected {
+			t.Errorf("Expected significant(%d, %d) to be %t, got %t", test.before, test.after, test.expected, got)
+		}
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	store, err := history.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin := history.Location{Latitude: 52.52, Longitude: 13.41}
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	current := Snapshot{Taken: now, Kind: KindDaily, Daily: []weather.DailyForecast{day(saturday, 20, 0, 0)}}.Points()
+
+	if snapshot, err := Previous(store, berlin, current, now, "en"); err != nil || snapshot != nil {
+		t.Fatalf("Expected no forecast in an empty history, got %+v, %v", snapshot, err)
+	}
+
+	fetch := func(taken time.Time, max float64, provider string, units weather.Units) {
+		d := day(saturday, max, 0, 1)
+		d.Units = units
+		points := Snapshot{Taken: taken, Kind: KindDaily, Daily: []weather.DailyForecast{d}}.Points()
+		for i := range points {
+			points[i].Provider = provider
+		}
+		if err := store.Append(berlin, points); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	fetch(now.Add(-48*time.Hour), 18, "", metric)
+	fetch(now.Add(-24*time.Hour), 22, "", metric)
+	// Other providers and units, and fetches that are too recent, are left out.
+	fetch(now.Add(-13*time.Hour), 30, "nws", metric)
+	fetch(now.Add(-13*time.Hour), 72, "", weather.Units{Temperature: "°F", WindSpeed: "mp/h", Precipitation: "inch"})
+	fetch(now.Add(-time.Hour), 25, "", metric)
+
+	snapshot, err := Previous(store, berlin, current, now.Add(-12*time.Hour), "en")
+	if err != nil {
+		t.Fatalf("Previous failed: %v", err)
+	}
+	if snapshot == nil || !snapshot.Taken.Equal(now.Add(-24*time.Hour)) || len(snapshot.Daily) != 1 {
+		t.Fatalf("Expected the forecast from a day ago, got %+v", snapshot)
+	}
+	previous := snapshot.Daily[0]
+	if previous.MaxTemperature != 22 || previous.Units != metric || previous.Condition.Description != "Mainly clear" {
+		t.Errorf("Expected a mainly clear 22 °C, got %+v", previous)
+	}
+
+	result := Compare(*snapshot, Snapshot{Taken: now, Kind: KindDaily, Daily: []weather.DailyForecast{day(saturday, 20, 0, 0)}}, Thresholds{})
+	if result.Compared != 1 || len(result.Changes) != 1 || result.Changes[0].Delta() != -2 {
+		t.Errorf("Expected the maximum to drop by 2, got %+v", result)
+	}
+
+	// An older fetch reaching further ahead is not mixed into the last one.
+	sunday := saturday.AddDate(0, 0, 1)
+	older := Snapshot{
+		Taken: now.Add(-36 * time.Hour),
+		Kind:  KindDaily,
+		Daily: []weather.DailyForecast{day(saturday, 19, 0, 0), day(sunday, 24, 0, 0)},
+	}
+	if err := store.Append(berlin, older.Points()); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	current = Snapshot{Taken: now, Kind: KindDaily, Daily: []weather.DailyForecast{day(saturday, 20, 0, 0), day(sunday, 21, 0, 0)}}.Points()
+	snapshot, err = Previous(store, berlin, current, now.Add(-12*time.Hour), "en")
+	if err != nil {
+		t.Fatalf("Previous failed: %v", err)
+	}
+	if snapshot == nil || !snapshot.Taken.Equal(now.Add(-24*time.Hour)) || len(snapshot.Daily) != 1 {
+		t.Errorf("Expected only the days of the forecast from a day ago, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Kind is the forecast a snapshot holds.
+type Kind string
+
+const (
+	KindHourly Kind = "hourly"
+	KindDaily  Kind = "daily"
+)
+
+// Snapshot is one fetched forecast. Only the field matching Kind is set.
+type Snapshot struct {
+	Taken  time.Time                `json:"taken"`
+	Kind   Kind                     `json:"kind"`
+	Hourly []weather.HourlyForecast `json:"hourly,omitempty"`
+	Daily  []weather.DailyForecast  `json:"daily,omitempty"`
+}
+
+// Points returns the history points of the snapshot, as a
+// history.Recorder records them.
+func (s Snapshot) Points() []history.Point {
+	if s.Kind == KindHourly {
+		return history.FromHourly(s.Hourly, s.Taken)
+	}
+	return history.FromDaily(s.Daily, s.Taken)
+}
+
+// Previous returns the last forecast store holds from before a time for the
+// hours or days of current, the points of a fetch labelled by a
+// history.Recorder, or nil when it holds none. Only fetches with the same
+// provider, model and units as current count, so that a forecast is compared
+// with earlier issues of itself, and only the last of them is used, so that
+// every value compared was fetched when the snapshot says. Conditions are
+// described in language.
+func Previous(
+	store *history.Store,
+	location history.Location,
+	current []history.Point,
+	before time.Time,
+	language string,
+) (*Snapshot, error) {
+	if len(current) == 0 {
+		return nil, nil
+	}
+	first := current[0]
+	units := make(map[string]string)
+	since := first.Valid
+	for _, p := range current {
+		units[p.Variable] = p.Unit
+		if p.Valid.Before(since) {
+			since = p.Valid
+		}
+	}
+
+	points, err := store.Query(history.Query{
+		Location:    location,
+		Kinds:       []history.Kind{first.Kind},
+		Since:       since,
+		IssuedUntil: before,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Fetches in other units are left out whole, since variables like the
+	// chance of rain have the same unit in each.
+	otherUnits := make(map[int64]bool)
+	for _, p := range points {
+		if unit, ok := units[p.Variable]; ok && p.Unit != unit {
+			otherUnits[p.Issued.UnixNano()] = true
+		}
+	}
+	points = slices.DeleteFunc(points, func(p history.Point) bool {
+		_, ok := units[p.Variable]
+		return !ok || otherUnits[p.Issued.UnixNano()] || p.Provider != first.Provider || p.Model != first.Model
+	})
+	var last time.Time
+	for _, p := range points {
+		if p.Issued.After(last) {
+			last = p.Issued
+		}
+	}
+	points = slices.DeleteFunc(points, func(p history.Point) bool {
+		return !p.Issued.Equal(last)
+	})
+	return fromPoints(points, Kind(first.Kind), language), nil
+}
+
+// fromPoints rebuilds a forecast from the points of one fetch sorted by valid
+// time, taking the last value of each variable should a time repeat.
+func fromPoints(points []history.Point, kind Kind, language string) *Snapshot {
+	if len(points) == 0 {
+		return nil
+	}
+	type key struct {
+		valid    int64
+		variable string
+	}
+	newest := make(map[key]history.Point)
+	for _, p := range points {
+		newest[key{p.Valid.Unix(), p.Variable}] = p
+	}
+
+	snapshot := &Snapshot{Kind: kind}
+	hours := make(map[int64]*weather.HourlyForecast)
+	days := make(map[int64]*weather.DailyForecast)
+	for _, p := range newest {
+		if p.Issued.After(snapshot.Taken) {
+			snapshot.Taken = p.Issued
+		}
+		valid := p.Valid.Unix()
+		if kind == KindHourly {
+			if hours[valid] == nil {
+				hours[valid] = &weather.HourlyForecast{DateTime: p.Valid}
+			}
+			setHourly(hours[valid], p, language)
+		} else {
+			if days[valid] == nil {
+				days[valid] = &weather.DailyForecast{Date: p.Valid}
+			}
+			setDaily(days[valid], p, language)
+		}
+	}
+	for _, valid := range slices.Sorted(maps.Keys(hours)) {
+		snapshot.Hourly = append(snapshot.Hourly, *hours[valid])
+	}
+	for _, valid := range slices.Sorted(maps.Keys(days)) {
+		snapshot.Daily = append(snapshot.Daily, *days[valid])
+	}
+	return snapshot
+}
+
+// setHourly sets the field of an hour that a point records.
+func setHourly(h *weather.HourlyForecast, p history.Point, language string) {
+	switch p.Variable {
+	case "temperature":
+		h.Temperature, h.Units.Temperature = p.Value, p.Unit
+	case "apparent_temperature":
+		h.ApparentTemperature = p.Value
+	case "humidity":
+		h.Humidity = p.Value
+	case "cloud_cover":
+		h.Cloudy = p.Value
+	case "precipitation":
+		h.Precipitation, h.Units.Precipitation = p.Value, p.Unit
+	case "precipitation_probability":
+		h.PrecipitationProb = p.Value
+	case "snowfall":
+		h.SnowFall = p.Value
+	case "wind_speed":
+		h.WindSpeed, h.Units.WindSpeed = p.Value, p.Unit
+	case "wind_gusts":
+		h.WindGusts = p.Value
+	case "wind_direction":
+		h.WindDirection = weather.Direction(p.Value)
+	case "weather_code":
+		h.Condition = weather.ConditionForCode(int(p.Value), language)
+		h.WeatherDescription = h.Condition.Description
+	}
+}
+
+// setDaily sets the field of a day that a point records.
+func setDaily(d *weather.DailyForecast, p history.Point, language string) {
+	switch p.Variable {
+	case "temperature_max":
+		d.MaxTemperature, d.Units.Temperature = p.Value, p.Unit
+	case "temperature_min":
+		d.MinTemperature = p.Value
+	case "precipitation_sum":
+		d.PrecipitationSum, d.Units.Precipitation = p.Value, p.Unit
+	case "precipitation_probability":
+		d.PrecipitationProb = p.Value
+	case "wind_speed_max":
+		d.MaxWindSpeed, d.Units.WindSpeed = p.Value, p.Unit
+	case "wind_gusts_max":
+		d.WindGusts = p.Value
+	case "weather_code":
+		d.Condition = weather.ConditionForCode(int(p.Value), language)
+		d.WeatherDescription = d.Condition.Description
+	}
+}
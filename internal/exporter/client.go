@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"strconv"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/metrics"
+)
+
+// ClientMetrics records the requests of the Open-Meteo clients. Set it as
+// their Observer.
+type ClientMetrics struct {
+	requests       *metrics.Counter
+	duration       *metrics.Histogram
+	retries        *metrics.Counter
+	cacheHits      *metrics.Counter
+	generationTime *metrics.Histogram
+}
+
+// generationBuckets suit the API's own processing times, in seconds.
+var generationBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1}
+
+// NewClientMetrics registers the client metrics in registry.
+func NewClientMetrics(registry *metrics.Registry) *ClientMetrics {
+	return &ClientMetrics{
+		requests: registry.Counter("sky_client_requests_total",
+			`API requests by endpoint and HTTP status ("error" when no response arrived).`, "endpoint", "status"),
+		duration: registry.Histogram("sky_client_request_duration_seconds",
+			"Time taken by API requests, including retries.", metrics.DefaultBuckets, "endpoint"),
+		retries: registry.Counter("sky_client_retries_total",
+			"API requests that were tried again.", "endpoint"),
+		cacheHits: registry.Counter("sky_client_cache_hits_total",
+			"API requests answered by a cache in between.", "endpoint"),
+		generationTime: registry.Histogram("sky_client_generation_time_seconds",
+			"Processing time reported by the API in generationtime_ms.", generationBuckets, "endpoint"),
+	}
+}
+
+// ObserveRequest implements openmateo.Observer.
+func (m *ClientMetrics) ObserveRequest(stats openmateo.RequestStats) {
+	status := "error"
+	if stats.Status != 0 {
+		status = strconv.Itoa(stats.Status)
+	}
+	m.requests.Inc(stats.Endpoint, status)
+	m.duration.Observe(stats.Duration.Seconds(), stats.Endpoint)
+	if stats.Retries > 0 {
+		m.retries.Add(float64(stats.Retries), stats.Endpoint)
+	}
+	if stats.CacheHit {
+		m.cacheHits.Inc(stats.Endpoint)
+	}
+	if stats.GenerationTime > 0 {
+		m.generationTime.Observe(stats.GenerationTime.Seconds(), stats.Endpoint)
+	}
+}
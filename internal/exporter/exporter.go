@@ -0,0 +1,227 @@
+// Package exporter publishes the weather at a set of locations, and the
+// health of the API clients, as Prometheus metrics.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/daemon"
+	"github.com/mohithbuilds/sky/internal/metrics"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// DefaultListen is the address the exporter listens on by default.
+const DefaultListen = ":9192"
+
+// DefaultInterval is how often the weather is fetched by default.
+const DefaultInterval = 5 * time.Minute
+
+// minInterval keeps the exporter from hammering the API.
+const minInterval = time.Minute
+
+// Settings is the "exporter" section of the config file. Without locations
+// the daemon's are exported.
+type Settings struct {
+	Listen    string            `json:"listen,omitempty"`
+	Interval  daemon.Duration   `json:"interval,omitzero"`
+	Locations []daemon.Location `json:"locations,omitempty"`
+}
+
+// Validate checks the interval and locations.
+func (s Settings) Validate() error {
+	if s.Interval != 0 && time.Duration(s.Interval) < minInterval {
+		return fmt.Errorf("exporter interval %s is shorter than %s", time.Duration(s.Interval), minInterval)
+	}
+	return daemon.Settings{Locations: s.Locations}.Validate()
+}
+
+// Location is a place whose weather is exported.
+type Location struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+func (l Location) labels() []string {
+	return []string{
+		l.Name,
+		strconv.FormatFloat(l.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(l.Longitude, 'f', -1, 64),
+	}
+}
+
+// Fetcher fetches the current weather and air quality.
+// *weather.WeatherClient implements it.
+type Fetcher interface {
+	GetCurrentWeather(
+		latitude, longitude float64,
+		tempUnit, windUnit, precipUnit string,
+	) (*weather.CurrentWeather, error)
+	GetCurrentAirQuality(latitude, longitude float64) (*weather.AirQuality, error)
+}
+
+// gauge is one exported value of the current weather or air quality.
+type gauge[T any] struct {
+	*metrics.Gauge
+	value func(*T) float64
+}
+
+// Exporter fetches the weather at its locations and keeps the gauges up to
+// date. Metrics are in SI units: Celsius, metres per second and millimetres.
+type Exporter struct {
+	Client Fetcher
+	Log    *log.Logger
+
+	current []gauge[weather.CurrentWeather]
+	air     []gauge[weather.AirQuality]
+	up      *metrics.Gauge
+	updated *metrics.Gauge
+	errors  *metrics.Counter
+
+	exported []Location
+}
+
+// New returns an Exporter that registers its metrics in registry.
+func New(registry *metrics.Registry, client Fetcher, logger *log.Logger) *Exporter {
+	labels := []string{"name", "latitude", "longitude"}
+	currentGauge := func(name, help string, value func(*weather.CurrentWeather) float64) gauge[weather.CurrentWeather] {
+		return gauge[weather.CurrentWeather]{registry.Gauge(name, help, labels...), value}
+	}
+	airGauge := func(name, help string, value func(*weather.AirQuality) float64) gauge[weather.AirQuality] {
+		return gauge[weather.AirQuality]{registry.Gauge(name, help, labels...), value}
+	}
+
+	return &Exporter{
+		Client: client,
+		Log:    logger,
+		current: []gauge[weather.CurrentWeather]{
+			currentGauge("sky_temperature_celsius", "Air temperature at 2 m.",
+				func(c *weather.CurrentWeather) float64 { return c.Temperature }),
+			currentGauge("sky_apparent_temperature_celsius", "Feels-like temperature.",
+				func(c *weather.CurrentWeather) float64 { return c.ApparentTemperature }),
+			currentGauge("sky_relative_humidity_percent", "Relative humidity at 2 m.",
+				func(c *weather.CurrentWeather) float64 { return c.Humidity }),
+			currentGauge("sky_precipitation_millimeters", "Precipitation in the current hour.",
+				func(c *weather.CurrentWeather) float64 { return c.Precipitation }),
+			currentGauge("sky_wind_speed_meters_per_second", "Wind speed at 10 m.",
+				func(c *weather.CurrentWeather) float64 { return c.WindSpeed }),
+			currentGauge("sky_wind_gusts_meters_per_second", "Wind gusts at 10 m.",
+				func(c *weather.CurrentWeather) float64 { return c.WindGusts }),
+			currentGauge("sky_wind_direction_degrees", "Direction the wind comes from.",
+				func(c *weather.CurrentWeather) float64 { return float64(c.WindDirection) }),
+			currentGauge("sky_weather_code", "WMO weather code.",
+				func(c *weather.CurrentWeather) float64 { return float64(c.Condition.Code) }),
+			currentGauge("sky_is_day", "1 during daylight, 0 at night.",
+				func(c *weather.CurrentWeather) float64 { return float64(c.IsDay) }),
+		},
+		air: []gauge[weather.AirQuality]{
+			airGauge("sky_air_pm2_5_micrograms_per_cubic_meter", "Particulate matter under 2.5 µm.",
+				func(a *weather.AirQuality) float64 { return a.PM25 }),
+			airGauge("sky_air_pm10_micrograms_per_cubic_meter", "Particulate matter under 10 µm.",
+				func(a *weather.AirQuality) float64 { return a.PM10 }),
+			airGauge("sky_air_carbon_monoxide_micrograms_per_cubic_meter", "Carbon monoxide.",
+				func(a *weather.AirQuality) float64 { return a.CarbonMonoxide }),
+			airGauge("sky_air_nitrogen_dioxide_micrograms_per_cubic_meter", "Nitrogen dioxide.",
+				func(a *weather.AirQuality) float64 { return a.NitrogenDioxide }),
+			airGauge("sky_air_ozone_micrograms_per_cubic_meter", "Ozone.",
+				func(a *weather.AirQuality) float64 { return a.Ozone }),
+			airGauge("sky_air_us_aqi", "US air quality index.",
+				func(a *weather.AirQuality) float64 { return a.USAQI }),
+			airGauge("sky_air_european_aqi", "European air quality index.",
+				func(a *weather.AirQuality) float64 { return a.EuropeanAQI }),
+			airGauge("sky_uv_index", "UV index.",
+				func(a *weather.AirQuality) float64 { return a.UVIndex }),
+		},
+		up: registry.Gauge("sky_up",
+			"1 when the last fetch for the location succeeded.", labels...),
+		updated: registry.Gauge("sky_last_update_timestamp_seconds",
+			"Unix time of the last successful fetch for the location.", labels...),
+		errors: registry.Counter("sky_fetch_errors_total",
+			"Failed fetches by location and source.", "name", "source"),
+	}
+}
+
+// Update fetches the weather at the locations and sets the gauges. Gauges
+// of locations that were exported before but are not any more are removed.
+// Failures are logged and reported through sky_up.
+func (e *Exporter) Update(locations []Location) {
+	for _, gone := range e.exported {
+		if !contains(locations, gone) {
+			e.remove(gone)
+		}
+	}
+	e.exported = locations
+
+	for _, location := range locations {
+		labels := location.labels()
+		ok := true
+
+		current, err := e.Client.GetCurrentWeather(location.Latitude, location.Longitude, "celsius", "ms", "mm")
+		if err != nil {
+			ok = false
+			e.errors.Inc(location.Name, "current")
+			e.Log.Printf("%s: %v", location.Name, err)
+		} else {
+			for _, g := range e.current {
+				g.Set(g.value(current), labels...)
+			}
+		}
+
+		air, err := e.Client.GetCurrentAirQuality(location.Latitude, location.Longitude)
+		if err != nil {
+			ok = false
+			e.errors.Inc(location.Name, "air")
+			e.Log.Printf("%s: %v", location.Name, err)
+		} else {
+			for _, g := range e.air {
+				g.Set(g.value(air), labels...)
+			}
+		}
+
+		if ok {
+			e.up.Set(1, labels...)
+			e.updated.Set(float64(time.Now().Unix()), labels...)
+		} else {
+			e.up.Set(0, labels...)
+		}
+	}
+}
+
+// Run updates the gauges now and then every interval until ctx is done.
+func (e *Exporter) Run(ctx context.Context, locations []Location, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.Update(locations)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) remove(location Location) {
+	labels := location.labels()
+	for _, g := range e.current {
+		g.Delete(labels...)
+	}
+	for _, g := range e.air {
+		g.Delete(labels...)
+	}
+	e.up.Delete(labels...)
+	e.updated.Delete(labels...)
+}
+
+func contains(locations []Location, location Location) bool {
+	for _, l := range locations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
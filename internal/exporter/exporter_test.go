@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/metrics"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// mockFetcher returns fixed readings, failing for the latitude in failAt.
+type mockFetcher struct {
+	failAt   float64
+	windUnit string
+}
+
+func (m *mockFetcher) GetCurrentWeather(lat, lon float64, tempUnit, windUnit, precipUnit string) (*weather.CurrentWeather, error) {
+	m.windUnit = windUnit
+	if lat == m.failAt {
+		return nil, errors.New("API error (500 Internal Server Error)")
+	}
+	return &weather.CurrentWeather{Temperature: 21.5, WindSpeed: 3.2, Condition: weather.Condition{Code: 61}}, nil
+}
+
+func (m *mockFetcher) GetCurrentAirQuality(lat, lon float64) (*weather.AirQuality, error) {
+	return &weather.AirQuality{PM25: 8, USAQI: 33}, nil
+}
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := registry.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestExporter_Update(t *testing.T) {
+	registry := metrics.NewRegistry()
+	fetcher := &mockFetcher{failAt: 48.85}
+	e := New(registry, fetcher, log.New(io.Discard, "", 0))
+
+	berlin := Location{Name: "Berlin", Latitude: 52.52, Longitude: 13.41}
+	paris := Location{Name: "Paris", Latitude: 48.85, Longitude: 2.35}
+	e.Update([]Location{berlin, paris})
+
+	output := scrape(t, registry)
+	for _, expected := range []string{
+		`sky_temperature_celsius{name="Berlin",latitude="52.52",longitude="13.41"} 21.5`,
+		`sky_wind_speed_meters_per_second{name="Berlin",latitude="52.52",longitude="13.41"} 3.2`,
+		`sky_weather_code{name="Berlin",latitude="52.52",longitude="13.41"} 61`,
+		`sky_air_pm2_5_micrograms_per_cubic_meter{name="Berlin",latitude="52.52",longitude="13.41"} 8`,
+		`sky_up{name="Berlin",latitude="52.52",longitude="13.41"} 1`,
+		// Paris got its air quality but not its weather.
+		`sky_air_us_aqi{name="Paris",latitude="48.85",longitude="2.35"} 33`,
+		`sky_up{name="Paris",latitude="48.85",longitude="2.35"} 0`,
+		`sky_fetch_errors_total{name="Paris",source="current"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, `sky_temperature_celsius{name="Paris"`) {
+		t.Errorf("Expected no temperature for Paris, got:\n%s", output)
+	}
+	if fetcher.windUnit != "ms" {
+		t.Errorf("Expected wind in metres per second, got %q", fetcher.windUnit)
+	}
+
+	// Locations that are no longer exported lose their gauges.
+	e.Update([]Location{berlin})
+	if output := scrape(t, registry); strings.Contains(output, `sky_up{name="Paris"`) {
+		t.Errorf("Expected Paris to be removed, got:\n%s", output)
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewClientMetrics(registry)
+	m.ObserveRequest(openmateo.RequestStats{
+		Endpoint:       "forecast",
+		Status:         200,
+		Duration:       120 * time.Millisecond,
+		Retries:        2,
+		GenerationTime: 800 * time.Microsecond,
+	})
+	m.ObserveRequest(openmateo.RequestStats{Endpoint: "forecast", CacheHit: true, Status: 200})
+	m.ObserveRequest(openmateo.RequestStats{Endpoint: "search", Err: errors.New("timeout")})
+
+	output := scrape(t, registry)
+	for _, expected := range []string{
+		`sky_client_requests_total{endpoint="forecast",status="200"} 2`,
+		`sky_client_requests_total{endpoint="search",status="error"} 1`,
+		`sky_client_request_duration_seconds_bucket{endpoint="forecast",le="0.25"} 2`,
+		`sky_client_retries_total{endpoint="forecast"} 2`,
+		`sky_client_cache_hits_total{endpoint="forecast"} 1`,
+		`sky_client_generation_time_seconds_bucket{endpoint="forecast",le="0.001"} 1`,
+		`sky_client_generation_time_seconds_count{endpoint="forecast"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+}
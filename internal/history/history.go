@@ -0,0 +1,304 @@
+// Package history keeps a local time series of the weather sky fetches.
+//
+// Points are appended to JSON-lines segment files, one directory per
+// location and one file per UTC day the points were fetched on, e.g.
+//
+//	52.52_13.41/2024-06-01.jsonl
+//
+// Compaction merges old day files into one file per month (2024-06.jsonl),
+// thinning repeated forecasts, and retention deletes the oldest points.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is what a point was taken from.
+type Kind string
+
+const (
+	KindObservation Kind = "observation" // Current weather
+	KindHourly      Kind = "hourly"      // Hourly forecast
+	KindDaily       Kind = "daily"       // Daily forecast
+	KindAnalysis    Kind = "analysis"    // Model values for hours that have passed
+)
+
+// Point is one value of one variable.
+type Point struct {
+	Kind     Kind      `json:"kind"`
+	Variable string    `json:"variable"`
+	Issued   time.Time `json:"issued"` // When it was fetched
+	Valid    time.Time `json:"valid"`  // When it applies
+	Value    float64   `json:"value"`
+	Unit     string    `json:"unit,omitempty"`
+	Model    string    `json:"model,omitempty"` // The API's choice when empty
+	// Provider is the provider the point came from, e.g. "nws", and empty
+	// for Open-Meteo. Only Open-Meteo points have a Model.
+	Provider string `json:"provider,omitempty"`
+}
+
+// Source names the forecast a point belongs to: its provider, or its model
+// for Open-Meteo.
+func (p Point) Source() string {
+	if p.Provider != "" {
+		return p.Provider
+	}
+	return p.Model
+}
+
+// Lead returns how far ahead of its fetch the point applies.
+func (p Point) Lead() time.Duration {
+	return p.Valid.Sub(p.Issued)
+}
+
+// Location identifies where points were recorded. Coordinates are rounded to
+// two decimals, about a kilometre, so that the same place found by name or
+// given by coordinates shares its history.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func (l Location) key() string {
+	return fmt.Sprintf("%.2f_%.2f", l.Latitude, l.Longitude)
+}
+
+// maxLead is how far valid times can be from the time they were fetched: 16
+// days of forecast ahead, and the start of the current day behind.
+const (
+	maxLeadAhead  = 17 * 24 * time.Hour
+	maxLeadBehind = 24 * time.Hour
+)
+
+// Store is a history kept in a directory. It is safe for concurrent use, and
+// separate processes may append to the same directory.
+type Store struct {
+	Dir string
+	// Policy, when set, is applied by Append once every ApplyEvery, so that
+	// a store kept by a long-running daemon or exporter stays bounded.
+	Policy *Policy
+
+	mu sync.Mutex
+}
+
+// EnvDir is the environment variable that overrides the history directory.
+// Setting it to "off" stops recording.
+const EnvDir = "SKY_HISTORY"
+
+// DefaultDir returns the history directory in the user's sky cache directory
+// (e.g. ~/.cache/sky/history on Linux).
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	return filepath.Join(dir, "sky", "history"), nil
+}
+
+// Open returns the store in dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	return &Store{Dir: dir}, nil
+}
+
+const (
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// Append adds points to the location's history. Each point goes to the file
+// of the day it was issued. It then applies the Policy, if set and due.
+func (s *Store) Append(location Location, points []Point) error {
+	byDay := make(map[string][]byte)
+	for _, p := range points {
+		line, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to encode history point: %w", err)
+		}
+		day := p.Issued.UTC().Format(dayLayout)
+		byDay[day] = append(append(byDay[day], line...), '\n')
+	}
+	if err := s.appendDays(location, byDay); err != nil {
+		return err
+	}
+	return s.applyIfDue(time.Now())
+}
+
+// appendDays appends the lines of each day to the day's file.
+func (s *Store) appendDays(location Location, byDay map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.Dir, location.key())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	for day, data := range byDay {
+		if err := appendFile(filepath.Join(dir, day+".jsonl"), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendFile appends data in a single write, so that lines from processes
+// appending at the same time do not interleave.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Query selects points of one location. Zero fields match everything.
+type Query struct {
+	Location  Location
+	Kinds     []Kind
+	Variables []string
+
+	Since, Until             time.Time // Valid time range, Until exclusive
+	IssuedSince, IssuedUntil time.Time // Issue time range, IssuedUntil exclusive
+}
+
+func (q Query) matches(p Point) bool {
+	return (len(q.Kinds) == 0 || slices.Contains(q.Kinds, p.Kind)) &&
+		(len(q.Variables) == 0 || slices.Contains(q.Variables, p.Variable)) &&
+		(q.Since.IsZero() || !p.Valid.Before(q.Since)) &&
+		(q.Until.IsZero() || p.Valid.Before(q.Until)) &&
+		(q.IssuedSince.IsZero() || !p.Issued.Before(q.IssuedSince)) &&
+		(q.IssuedUntil.IsZero() || p.Issued.Before(q.IssuedUntil))
+}
+
+// issueRange returns the issue times that can hold matching points, using
+// the valid time range when it is narrower.
+func (q Query) issueRange() (time.Time, time.Time) {
+	since, until := q.IssuedSince, q.IssuedUntil
+	if !q.Since.IsZero() {
+		if earliest := q.Since.Add(-maxLeadAhead); since.IsZero() || earliest.After(since) {
+			since = earliest
+		}
+	}
+	if !q.Until.IsZero() {
+		if latest := q.Until.Add(maxLeadBehind); until.IsZero() || latest.Before(until) {
+			until = latest
+		}
+	}
+	return since, until
+}
+
+// Query returns the matching points ordered by valid and then issue time.
+func (s *Store) Query(q Query) ([]Point, error) {
+	segments, err := s.segments(q.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	since, until := q.issueRange()
+	var points []Point
+	for _, seg := range segments {
+		if (!since.IsZero() && !seg.end.After(since)) || (!until.IsZero() && !seg.start.Before(until)) {
+			continue
+		}
+		err := readSegment(seg.path, func(p Point) {
+			if q.matches(p) {
+				points = append(points, p)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortPoints(points)
+	return points, nil
+}
+
+func sortPoints(points []Point) {
+	sort.SliceStable(points, func(i, j int) bool {
+		if !points[i].Valid.Equal(points[j].Valid) {
+			return points[i].Valid.Before(points[j].Valid)
+		}
+		return points[i].Issued.Before(points[j].Issued)
+	})
+}
+
+// segment is one file of a location's history.
+type segment struct {
+	path       string
+	monthly    bool
+	start, end time.Time // Issue times the file covers
+}
+
+// segments lists the files of a location, oldest first.
+func (s *Store) segments(location Location) ([]segment, error) {
+	dir := filepath.Join(s.Dir, location.key())
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var segments []segment
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		seg := segment{path: filepath.Join(dir, entry.Name())}
+		if start, err := time.Parse(dayLayout, name); err == nil {
+			seg.start, seg.end = start, start.AddDate(0, 0, 1)
+		} else if start, err := time.Parse(monthLayout, name); err == nil {
+			seg.start, seg.end, seg.monthly = start, start.AddDate(0, 1, 0), true
+		} else {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].start.Before(segments[j].start)
+	})
+	return segments, nil
+}
+
+// readSegment calls fn with every point in a file. A torn last line, left by
+// a write that was cut short, is skipped.
+func readSegment(path string, fn func(Point)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var p Point
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		fn(p)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history %s: %w", path, err)
+	}
+	return nil
+}
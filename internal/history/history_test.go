@@ -0,0 +1,232 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+var berlin = Location{Latitude: 52.52437, Longitude: 13.41053}
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return store
+}
+
+func hourlyPoints(issued time.Time, hours int, temperature float64) []Point {
+	forecasts := make([]weather.HourlyForecast, hours)
+	for i := range forecasts {
+		forecasts[i] = weather.HourlyForecast{
+			DateTime:    issued.Truncate(time.Hour).Add(time.Duration(i+1) * time.Hour),
+			Temperature: temperature + float64(i),
+			Units:       weather.Units{Temperature: "°C"},
+		}
+	}
+	return FromHourly(forecasts, issued)
+}
+
+func TestRecorder_Provider(t *testing.T) {
+	store := openTestStore(t)
+	hour := []weather.HourlyForecast{{DateTime: time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC), Temperature: 20}}
+	for _, provider := range []string{"", OpenMeteo, "nws"} {
+		recorder := NewRecorder(store, nil)
+		recorder.Model, recorder.Provider = "icon_seamless", provider
+		recorder.RecordHourly(berlin.Latitude, berlin.Longitude, hour)
+	}
+
+	points, err := store.Query(Query{Location: berlin, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var sources []string
+	for _, p := range points {
+		sources = append(sources, p.Provider+"/"+p.Model)
+	}
+	if want := []string{"/icon_seamless", "/icon_seamless", "nws/"}; !slices.Equal(sources, want) {
+		t.Errorf("Expected providers and models %v, got %v", want, sources)
+	}
+	if points[2].Source() != "nws" || points[0].Source() != "icon_seamless" {
+		t.Errorf("Expected the sources to be the provider or the model, got %+v", points)
+	}
+}
+
+func TestRecorder_Source(t *testing.T) {
+	store := openTestStore(t)
+	recorder := NewRecorder(store, nil)
+	recorder.Model = "icon_seamless"
+	valid := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	// A Failover sets the Source of what it returns.
+	recorder.RecordHourly(berlin.Latitude, berlin.Longitude, []weather.HourlyForecast{
+		{DateTime: valid, Temperature: 20, Source: "metno"},
+	})
+	recorder.RecordCurrent(berlin.Latitude, berlin.Longitude, &weather.CurrentWeather{
+		ObservationTime: valid,
+		Temperature:     19,
+		Source:          OpenMeteo,
+	})
+
+	points, err := store.Query(Query{Location: berlin, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %+v", points)
+	}
+	if points[0].Provider != "metno" || points[0].Model != "" {
+		t.Errorf("Expected the forecast to come from metno, got %+v", points[0])
+	}
+	if points[1].Provider != "" || points[1].Model != "icon_seamless" {
+		t.Errorf("Expected the observation to come from Open-Meteo, got %+v", points[1])
+	}
+}
+
+func TestStore_AppendAndQuery(t *testing.T) {
+	store := openTestStore(t)
+	issued := time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)
+
+	// The second fetch is issued on the next day and lands in its own file.
+	if err := store.Append(berlin, hourlyPoints(issued, 3, 15)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append(berlin, hourlyPoints(issued.Add(time.Hour), 3, 16)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	for _, name := range []string{"2024-06-01.jsonl", "2024-06-02.jsonl"} {
+		if _, err := os.Stat(filepath.Join(store.Dir, "52.52_13.41", name)); err != nil {
+			t.Errorf("Expected segment %s: %v", name, err)
+		}
+	}
+
+	points, err := store.Query(Query{Location: berlin, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 6 {
+		t.Fatalf("Expected 6 temperature points, got %d", len(points))
+	}
+	for i := 1; i < len(points); i++ {
+		if points[i].Valid.Before(points[i-1].Valid) {
+			t.Errorf("Expected points ordered by valid time, got %v before %v", points[i-1].Valid, points[i].Valid)
+		}
+	}
+
+	// 01:00 on 2 June was forecast by both fetches, with leads of 2h and 1h.
+	valid := time.Date(2024, 6, 2, 1, 0, 0, 0, time.UTC)
+	points, err = store.Query(Query{
+		Location:  berlin,
+		Variables: []string{"temperature"},
+		Since:     valid,
+		Until:     valid.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 2 || points[0].Lead() != 90*time.Minute || points[1].Lead() != 30*time.Minute {
+		t.Errorf("Expected two forecasts for %v with leads of 90m and 30m, got %+v", valid, points)
+	}
+
+	points, err = store.Query(Query{Location: berlin, IssuedSince: issued.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 33 {
+		t.Errorf("Expected the 33 points of the second fetch, got %d", len(points))
+	}
+}
+
+func TestStore_QueryUnknownLocation(t *testing.T) {
+	points, err := openTestStore(t).Query(Query{Location: Location{Latitude: 1, Longitude: 2}})
+	if err != nil || len(points) != 0 {
+		t.Errorf("Expected no points and no error, got %d points, %v", len(points), err)
+	}
+}
+
+func TestStore_SkipsTornLine(t *testing.T) {
+	store := openTestStore(t)
+	issued := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Append(berlin, hourlyPoints(issued, 1, 15)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	path := filepath.Join(store.Dir, berlin.key(), "2024-06-01.jsonl")
+	if err := appendFile(path, []byte(`{"kind":"hourly","vari`)); err != nil {
+		t.Fatalf("appendFile failed: %v", err)
+	}
+
+	points, err := store.Query(Query{Location: berlin})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 11 {
+		t.Errorf("Expected the 11 complete points, got %d", len(points))
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	store := openTestStore(t)
+	var errs []error
+	recorder := NewRecorder(store, func(err error) { errs = append(errs, err) })
+	fetched := time.Date(2024, 6, 1, 12, 5, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return fetched }
+
+	recorder.RecordCurrent(berlin.Latitude, berlin.Longitude, &weather.CurrentWeather{
+		Temperature:     21.5,
+		Humidity:        40,
+		ObservationTime: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Units:           weather.Units{Temperature: "°C"},
+	})
+	recorder.RecordDaily(berlin.Latitude, berlin.Longitude, []weather.DailyForecast{{
+		Date:           time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		MaxTemperature: 25,
+	}})
+
+	observed, err := store.Query(Query{Location: berlin, Kinds: []Kind{KindObservation}, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(observed) != 1 || observed[0].Value != 21.5 || observed[0].Unit != "°C" || !observed[0].Issued.Equal(fetched) {
+		t.Errorf("Expected the observed temperature, got %+v", observed)
+	}
+
+	daily, err := store.Query(Query{Location: berlin, Kinds: []Kind{KindDaily}, Variables: []string{"temperature_max"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(daily) != 1 || daily[0].Value != 25 {
+		t.Errorf("Expected the forecast maximum, got %+v", daily)
+	}
+
+	// A store that cannot be written reports the error without panicking.
+	if err := os.RemoveAll(store.Dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.Dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder.RecordDaily(berlin.Latitude, berlin.Longitude, nil)
+	if len(errs) != 1 || errors.Unwrap(errs[0]) == nil {
+		t.Errorf("Expected one write error, got %v", errs)
+	}
+}
+
+func TestFromHourly_Units(t *testing.T) {
+	for _, tt := range []struct{ precipitation, snowfall string }{{"mm", "cm"}, {"inch", "inch"}} {
+		points := FromHourly([]weather.HourlyForecast{{
+			Units: weather.Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: tt.precipitation},
+		}}, time.Now())
+		for _, p := range points {
+			if p.Variable == "snowfall" && p.Unit != tt.snowfall {
+				t.Errorf("Expected snowfall in %s with precipitation in %s, got %s", tt.snowfall, tt.precipitation, p.Unit)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package history
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// FromCurrent returns the points of an observation fetched at issued.
+func FromCurrent(current *weather.CurrentWeather, issued time.Time) []Point {
+	p := pointMaker{kind: KindObservation, provider: current.Source, issued: issued, valid: current.ObservationTime}
+	return []Point{
+		p.make("temperature", current.Temperature, current.Units.Temperature),
+		p.make("apparent_temperature", current.ApparentTemperature, current.Units.Temperature),
+		p.make("humidity", current.Humidity, "%"),
+		p.make("precipitation", current.Precipitation, current.Units.Precipitation),
+		p.make("wind_speed", current.WindSpeed, current.Units.WindSpeed),
+		p.make("wind_gusts", current.WindGusts, current.Units.WindSpeed),
+		p.make("wind_direction", float64(current.WindDirection), "°"),
+		p.make("weather_code", float64(current.Condition.Code), ""),
+	}
+}
+
+// FromHourly returns the points of an hourly forecast fetched at issued.
+func FromHourly(hourly []weather.HourlyForecast, issued time.Time) []Point {
+	return fromHourly(KindHourly, hourly, issued)
+}
+
+// FromPast returns the points of past hours fetched at issued.
+func FromPast(past []weather.HourlyForecast, issued time.Time) []Point {
+	return fromHourly(KindAnalysis, past, issued)
+}
+
+func fromHourly(kind Kind, hourly []weather.HourlyForecast, issued time.Time) []Point {
+	points := make([]Point, 0, len(hourly)*11)
+	for _, h := range hourly {
+		p := pointMaker{kind: kind, provider: h.Source, issued: issued, valid: h.DateTime}
+		points = append(points,
+			p.make("temperature", h.Temperature, h.Units.Temperature),
+			p.make("apparent_temperature", h.ApparentTemperature, h.Units.Temperature),
+			p.make("humidity", h.Humidity, "%"),
+			p.make("cloud_cover", h.Cloudy, "%"),
+			p.make("precipitation", h.Precipitation, h.Units.Precipitation),
+			p.make("precipitation_probability", h.PrecipitationProb, "%"),
+			p.make("snowfall", h.SnowFall, h.Units.Snowfall()),
+			p.make("wind_speed", h.WindSpeed, h.Units.WindSpeed),
+			p.make("wind_gusts", h.WindGusts, h.Units.WindSpeed),
+			p.make("wind_direction", float64(h.WindDirection), "°"),
+			p.make("weather_code", float64(h.Condition.Code), ""),
+		)
+	}
+	return points
+}
+
+// FromDaily returns the points of a daily forecast fetched at issued.
+func FromDaily(daily []weather.DailyForecast, issued time.Time) []Point {
+	points := make([]Point, 0, len(daily)*7)
+	for _, d := range daily {
+		p := pointMaker{kind: KindDaily, provider: d.Source, issued: issued, valid: d.Date}
+		points = append(points,
+			p.make("temperature_max", d.MaxTemperature, d.Units.Temperature),
+			p.make("temperature_min", d.MinTemperature, d.Units.Temperature),
+			p.make("precipitation_sum", d.PrecipitationSum, d.Units.Precipitation),
+			p.make("precipitation_probability", d.PrecipitationProb, "%"),
+			p.make("wind_speed_max", d.MaxWindSpeed, d.Units.WindSpeed),
+			p.make("wind_gusts_max", d.WindGusts, d.Units.WindSpeed),
+			p.make("weather_code", float64(d.Condition.Code), ""),
+		)
+	}
+	return points
+}
+
+type pointMaker struct {
+	kind          Kind
+	provider      string
+	issued, valid time.Time
+}
+
+func (m pointMaker) make(variable string, value float64, unit string) Point {
+	return Point{
+		Kind:     m.kind,
+		Provider: m.provider,
+		Variable: variable,
+		Issued:   m.issued,
+		Valid:    m.valid,
+		Value:    value,
+		Unit:     unit,
+	}
+}
+
+// OpenMeteo is the name of the Open-Meteo provider. Its points leave
+// Provider empty, like those recorded before there were other providers.
+const OpenMeteo = "openmeteo"
+
+// Recorder records everything a weather.WeatherClient fetches into a Store.
+type Recorder struct {
+	Store *Store
+	// Model is the weather model the client asks Open-Meteo for, recorded
+	// with each point.
+	Model string
+	// Provider is the provider the client fetches from, recorded with points
+	// whose weather has no Source of its own, as set by a weather.Failover.
+	// Empty or OpenMeteo means Open-Meteo.
+	Provider string
+
+	// OnError is called when points cannot be written. Recording never fails
+	// the fetch itself.
+	OnError func(error)
+
+	now func() time.Time
+}
+
+// NewRecorder returns a Recorder for the store.
+func NewRecorder(store *Store, onError func(error)) *Recorder {
+	return &Recorder{Store: store, OnError: onError, now: time.Now}
+}
+
+func (r *Recorder) RecordCurrent(latitude, longitude float64, current *weather.CurrentWeather) {
+	r.append(latitude, longitude, FromCurrent(current, r.now()))
+}
+
+func (r *Recorder) RecordHourly(latitude, longitude float64, hourly []weather.HourlyForecast) {
+	r.append(latitude, longitude, FromHourly(hourly, r.now()))
+}
+
+func (r *Recorder) RecordDaily(latitude, longitude float64, daily []weather.DailyForecast) {
+	r.append(latitude, longitude, FromDaily(daily, r.now()))
+}
+
+func (r *Recorder) RecordPast(latitude, longitude float64, past []weather.HourlyForecast) {
+	r.append(latitude, longitude, FromPast(past, r.now()))
+}
+
+func (r *Recorder) append(latitude, longitude float64, points []Point) {
+	r.Label(points)
+	err := r.Store.Append(Location{Latitude: latitude, Longitude: longitude}, points)
+	if err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Label sets the Provider and Model of points as they are recorded.
+func (r *Recorder) Label(points []Point) {
+	for i := range points {
+		provider, model := cmp.Or(points[i].Provider, r.Provider), r.Model
+		if provider == OpenMeteo {
+			provider = ""
+		}
+		if provider != "" {
+			model = ""
+		}
+		points[i].Provider, points[i].Model = provider, model
+	}
+}
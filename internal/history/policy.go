@@ -0,0 +1,308 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/daemon"
+)
+
+// ParseAge parses an age such as "7d", "2w" or "36h", in the grammar of
+// daemon.ParseDuration.
+func ParseAge(s string) (time.Duration, error) {
+	d, err := daemon.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", s)
+	}
+	return d, nil
+}
+
+// Default policy settings.
+const (
+	DefaultRetention    = 365 * 24 * time.Hour
+	DefaultCompactAfter = 7 * 24 * time.Hour
+	DefaultResolution   = 6 * time.Hour
+)
+
+// Policy says how long history is kept and how it is compacted. Zero fields
+// take the defaults.
+type Policy struct {
+	// Retention is how long points are kept after they were fetched.
+	Retention daemon.Duration `json:"retention,omitempty"`
+	// CompactAfter is the age at which day files are merged into month files.
+	// It is at least a day, so that files still being appended to are left
+	// alone.
+	CompactAfter daemon.Duration `json:"compact_after,omitempty"`
+	// Resolution thins compacted forecasts: of the values of a variable for
+	// one valid time fetched within the same Resolution, only the last one
+	// is kept. Observations and analyses keep the last value fetched.
+	Resolution daemon.Duration `json:"resolution,omitempty"`
+}
+
+// Validate checks that no duration is negative.
+func (p Policy) Validate() error {
+	for _, setting := range []struct {
+		name string
+		d    daemon.Duration
+	}{
+		{"retention", p.Retention},
+		{"compact_after", p.CompactAfter},
+		{"resolution", p.Resolution},
+	} {
+		if setting.d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", setting.name, time.Duration(setting.d))
+		}
+	}
+	return nil
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Retention == 0 {
+		p.Retention = daemon.Duration(DefaultRetention)
+	}
+	if p.CompactAfter == 0 {
+		p.CompactAfter = daemon.Duration(DefaultCompactAfter)
+	}
+	p.CompactAfter = max(p.CompactAfter, daemon.Duration(24*time.Hour))
+	if p.Resolution == 0 {
+		p.Resolution = daemon.Duration(DefaultResolution)
+	}
+	return p
+}
+
+// Settings is the "history" section of the config file, e.g.
+//
+//	{"retention": "90d", "resolution": "3h"}
+type Settings struct {
+	Dir      string `json:"dir,omitempty"`      // The DefaultDir when empty
+	Disabled bool   `json:"disabled,omitempty"` // Stop recording what is fetched
+	Policy
+}
+
+// ApplyEvery is how often Append applies the Store's Policy.
+const ApplyEvery = 24 * time.Hour
+
+// appliedFile is the file in the store whose modification time is when the
+// policy was last applied, by any process sharing the store.
+const appliedFile = ".applied"
+
+// applyIfDue applies the store's Policy when it was last applied ApplyEvery
+// or more before now. The time is claimed before applying, so that processes
+// appending at the same time do not all apply it.
+func (s *Store) applyIfDue(now time.Time) error {
+	if s.Policy == nil {
+		return nil
+	}
+	path := filepath.Join(s.Dir, appliedFile)
+	if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) < ApplyEvery {
+		return nil
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		return fmt.Errorf("failed to apply the history policy: %w", err)
+	}
+	if err := os.Chtimes(path, now, now); err != nil {
+		return fmt.Errorf("failed to apply the history policy: %w", err)
+	}
+	if _, err := s.Apply(*s.Policy, now); err != nil {
+		return fmt.Errorf("failed to apply the history policy: %w", err)
+	}
+	return nil
+}
+
+// Stats reports what Apply did.
+type Stats struct {
+	Locations      int `json:"locations"`
+	FilesCompacted int `json:"files_compacted"`
+	FilesRemoved   int `json:"files_removed"`
+	PointsBefore   int `json:"points_before"`
+	PointsAfter    int `json:"points_after"`
+}
+
+// Apply enforces the policy on every location as of now: it merges day files
+// older than CompactAfter into month files, thinning them, and drops points
+// fetched more than Retention ago.
+func (s *Store) Apply(policy Policy, now time.Time) (Stats, error) {
+	policy = policy.withDefaults()
+	expired := now.Add(-time.Duration(policy.Retention))
+	compactBefore := now.Add(-time.Duration(policy.CompactAfter))
+
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats Stats
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		location, ok := parseKey(entry.Name())
+		if !ok {
+			continue
+		}
+		stats.Locations++
+		if err := s.apply(location, policy, expired, compactBefore, &stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// parseKey is the inverse of Location.key.
+func parseKey(key string) (Location, bool) {
+	lat, lon, ok := strings.Cut(key, "_")
+	if !ok {
+		return Location{}, false
+	}
+	latitude, err1 := strconv.ParseFloat(lat, 64)
+	longitude, err2 := strconv.ParseFloat(lon, 64)
+	if err1 != nil || err2 != nil {
+		return Location{}, false
+	}
+	return Location{Latitude: latitude, Longitude: longitude}, true
+}
+
+func (s *Store) apply(location Location, policy Policy, expired, compactBefore time.Time, stats *Stats) error {
+	segments, err := s.segments(location)
+	if err != nil {
+		return err
+	}
+
+	// Group the files to rewrite by month: day files old enough to compact
+	// and month files that hold expired points.
+	months := make(map[time.Time][]segment)
+	for _, seg := range segments {
+		switch {
+		case !seg.end.After(expired):
+			if err := os.Remove(seg.path); err != nil {
+				return fmt.Errorf("failed to remove expired history: %w", err)
+			}
+			stats.FilesRemoved++
+		case seg.monthly && seg.start.Before(expired):
+			months[seg.start] = append(months[seg.start], seg)
+		case !seg.monthly && !seg.end.After(compactBefore):
+			month := time.Date(seg.start.Year(), seg.start.Month(), 1, 0, 0, 0, 0, time.UTC)
+			months[month] = append(months[month], seg)
+		}
+	}
+
+	for month, group := range months {
+		if err := s.compact(location, month, group, policy, expired, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compact rewrites a month's files as one month file.
+func (s *Store) compact(
+	location Location,
+	month time.Time,
+	group []segment,
+	policy Policy,
+	expired time.Time,
+	stats *Stats,
+) error {
+	path := filepath.Join(s.Dir, location.key(), month.Format(monthLayout)+".jsonl")
+
+	// The existing month file is read too, even when only day files changed.
+	files := []string{path}
+	for _, seg := range group {
+		if seg.path != path {
+			files = append(files, seg.path)
+		}
+	}
+
+	type key struct {
+		kind     Kind
+		provider string
+		model    string
+		variable string
+		valid    int64
+		bucket   int64
+	}
+	latest := make(map[key]Point)
+	var order []key
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		err := readSegment(file, func(p Point) {
+			stats.PointsBefore++
+			if p.Issued.Before(expired) {
+				return
+			}
+			k := key{kind: p.Kind, provider: p.Provider, model: p.Model, variable: p.Variable, valid: p.Valid.UnixNano()}
+			if p.Kind == KindHourly || p.Kind == KindDaily {
+				k.bucket = p.Issued.UnixNano() / int64(policy.Resolution)
+			}
+			previous, seen := latest[k]
+			if !seen {
+				order = append(order, k)
+			}
+			if !seen || !p.Issued.Before(previous.Issued) {
+				latest[k] = p
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	points := make([]Point, len(order))
+	for i, k := range order {
+		points[i] = latest[k]
+	}
+	sortPoints(points)
+	stats.PointsAfter += len(points)
+
+	if err := writeSegment(path, points); err != nil {
+		return err
+	}
+	for _, file := range files[1:] {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove compacted history: %w", err)
+		}
+		stats.FilesCompacted++
+	}
+	return nil
+}
+
+// writeSegment replaces a file with the points, through a temporary file so
+// that a crash cannot lose what was there.
+func writeSegment(path string, points []Point) error {
+	if len(points) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove empty history: %w", err)
+		}
+		return nil
+	}
+
+	var data []byte
+	for _, p := range points {
+		line, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to encode history point: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/daemon"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for input, want := range tests {
+		got, err := ParseAge(input)
+		if err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "d", "-3d", "1.5d", "week"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("Expected ParseAge(%q) to fail", input)
+		}
+	}
+}
+
+func TestStore_ApplyCompactsAndThins(t *testing.T) {
+	store := openTestStore(t)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// Fetch the same 3 hours every hour for two days.
+	for i := range 48 {
+		if err := store.Append(berlin, hourlyPoints(start.Add(time.Duration(i)*time.Hour), 3, 10)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	before, err := store.Query(Query{Location: berlin})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	stats, err := store.Apply(Policy{}, start.AddDate(0, 0, 10))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if stats.FilesCompacted != 2 || stats.PointsBefore != len(before) {
+		t.Errorf("Expected both day files to be compacted, got %+v", stats)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(store.Dir, berlin.key()))
+	if len(entries) != 1 || entries[0].Name() != "2024-05.jsonl" {
+		t.Errorf("Expected a single month file, got %v", entries)
+	}
+
+	// With a 6h resolution each valid time keeps at most one value per
+	// 6 hours of fetches.
+	after, err := store.Query(Query{Location: berlin, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(after) >= len(before)/11 || len(after) != stats.PointsAfter/11 {
+		t.Errorf("Expected thinned temperatures, got %d of %d", len(after), len(before)/11)
+	}
+	seen := make(map[[2]int64]bool)
+	for _, p := range after {
+		k := [2]int64{p.Valid.Unix(), p.Issued.Unix() / int64(DefaultResolution/time.Second)}
+		if seen[k] {
+			t.Errorf("Expected one value per valid time and 6h of fetches, got another for %v", p.Valid)
+		}
+		seen[k] = true
+	}
+}
+
+func TestStore_ApplyKeepsProviders(t *testing.T) {
+	store := openTestStore(t)
+	issued := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	nws := hourlyPoints(issued, 1, 12)
+	for i := range nws {
+		nws[i].Provider = "nws"
+	}
+	if err := store.Append(berlin, append(hourlyPoints(issued, 1, 10), nws...)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	if _, err := store.Apply(Policy{}, issued.AddDate(0, 0, 10)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	points, err := store.Query(Query{Location: berlin, Variables: []string{"temperature"}})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(points) != 2 || points[0].Provider == points[1].Provider {
+		t.Errorf("Expected the forecasts of both providers to be kept, got %+v", points)
+	}
+}
+
+func TestStore_ApplyRetention(t *testing.T) {
+	store := openTestStore(t)
+	old := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, issued := range []time.Time{old, recent} {
+		if err := store.Append(berlin, hourlyPoints(issued, 1, 10)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	policy := Policy{Retention: daemon.Duration(90 * 24 * time.Hour)}
+	stats, err := store.Apply(policy, recent.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if stats.FilesRemoved != 1 {
+		t.Errorf("Expected the January file to be removed, got %+v", stats)
+	}
+
+	points, err := store.Query(Query{Location: berlin})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for _, p := range points {
+		if p.Issued.Before(recent) {
+			t.Errorf("Expected expired points to be gone, got %+v", p)
+		}
+	}
+	if len(points) != 11 {
+		t.Errorf("Expected the recent fetch to be kept, got %d points", len(points))
+	}
+}
+
+func TestStore_AppendAppliesPolicy(t *testing.T) {
+	store := openTestStore(t)
+	store.Policy = &Policy{Retention: daemon.Duration(30 * 24 * time.Hour)}
+	now := time.Now()
+	old := now.AddDate(0, 0, -60)
+	fetch := len(hourlyPoints(now, 1, 10))
+	count := func() int {
+		t.Helper()
+		points, err := store.Query(Query{Location: berlin})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return len(points)
+	}
+
+	// The policy has never been applied, so the first Append applies it.
+	if err := store.Append(berlin, hourlyPoints(old, 1, 10)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("Expected the expired points to be dropped, got %d", n)
+	}
+
+	// Until a day has passed, it is not applied again.
+	if err := store.Append(berlin, hourlyPoints(old, 1, 10)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if n := count(); n != fetch {
+		t.Errorf("Expected the policy to wait a day, got %d points", n)
+	}
+
+	applied := filepath.Join(store.Dir, appliedFile)
+	if err := os.Chtimes(applied, now.Add(-ApplyEvery), now.Add(-ApplyEvery)); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if err := store.Append(berlin, hourlyPoints(now, 1, 10)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if n := count(); n != fetch {
+		t.Errorf("Expected only the recent fetch to be kept, got %d points", n)
+	}
+}
@@ -0,0 +1,148 @@
+package i18n
+
+// descriptions holds the per-code WMO weather descriptions for each
+// supported language.
+var descriptions = map[string]map[int]string{
+	"en": {
+		0:  "Clear sky",
+		1:  "Mainly clear",
+		2:  "Partly cloudy",
+		3:  "Overcast",
+		45: "Fog",
+		48: "Depositing rime fog",
+		51: "Light drizzle",
+		53: "Moderate drizzle",
+		55: "Dense drizzle",
+		56: "Light freezing drizzle",
+		57: "Dense freezing drizzle",
+		61: "Slight rain",
+		63: "Moderate rain",
+		65: "Heavy rain",
+		66: "Light freezing rain",
+		67: "Heavy freezing rain",
+		71: "Slight snow fall",
+		73: "Moderate snow fall",
+		75: "Heavy snow fall",
+		77: "Snow grains",
+		80: "Slight rain showers",
+		81: "Moderate rain showers",
+		82: "Violent rain showers",
+		85: "Slight snow showers",
+		86: "Heavy snow showers",
+		95: "Thunderstorm",
+		96: "Thunderstorm with slight hail",
+		99: "Thunderstorm with heavy hail",
+	},
+	"de": {
+		0:  "Klarer Himmel",
+		1:  "Überwiegend klar",
+		2:  "Teilweise bewölkt",
+		3:  "Bedeckt",
+		45: "Nebel",
+		48: "Nebel mit Reifablagerung",
+		51: "Leichter Nieselregen",
+		53: "Mäßiger Nieselregen",
+		55: "Starker Nieselregen",
+		56: "Leichter gefrierender Nieselregen",
+		57: "Starker gefrierender Nieselregen",
+		61: "Leichter Regen",
+		63: "Mäßiger Regen",
+		65: "Starker Regen",
+		66: "Leichter gefrierender Regen",
+		67: "Starker gefrierender Regen",
+		71: "Leichter Schneefall",
+		73: "Mäßiger Schneefall",
+		75: "Starker Schneefall",
+		77: "Schneegriesel",
+		80: "Leichte Regenschauer",
+		81: "Mäßige Regenschauer",
+		82: "Heftige Regenschauer",
+		85: "Leichte Schneeschauer",
+		86: "Starke Schneeschauer",
+		95: "Gewitter",
+		96: "Gewitter mit leichtem Hagel",
+		99: "Gewitter mit starkem Hagel",
+	},
+	"fr": {
+		0:  "Ciel dégagé",
+		1:  "Plutôt dégagé",
+		2:  "Partiellement nuageux",
+		3:  "Couvert",
+		45: "Brouillard",
+		48: "Brouillard givrant",
+		51: "Bruine légère",
+		53: "Bruine modérée",
+		55: "Bruine dense",
+		56: "Bruine verglaçante légère",
+		57: "Bruine verglaçante dense",
+		61: "Pluie faible",
+		63: "Pluie modérée",
+		65: "Pluie forte",
+		66: "Pluie verglaçante faible",
+		67: "Pluie verglaçante forte",
+		71: "Chute de neige faible",
+		73: "Chute de neige modérée",
+		75: "Chute de neige forte",
+		77: "Neige en grains",
+		80: "Averses de pluie faibles",
+		81: "Averses de pluie modérées",
+		82: "Averses de pluie violentes",
+		85: "Averses de neige faibles",
+		86: "Averses de neige fortes",
+		95: "Orage",
+		96: "Orage avec grêle faible",
+		99: "Orage avec grêle forte",
+	},
+	"es": {
+		0:  "Cielo despejado",
+		1:  "Mayormente despejado",
+		2:  "Parcialmente nublado",
+		3:  "Cubierto",
+		45: "Niebla",
+		48: "Niebla con escarcha",
+		51: "Llovizna ligera",
+		53: "Llovizna moderada",
+		55: "Llovizna densa",
+		56: "Llovizna helada ligera",
+		57: "Llovizna helada densa",
+		61: "Lluvia ligera",
+		63: "Lluvia moderada",
+		65: "Lluvia fuerte",
+		66: "Lluvia helada ligera",
+		67: "Lluvia helada fuerte",
+		71: "Nevada ligera",
+		73: "Nevada moderada",
+		75: "Nevada fuerte",
+		77: "Granos de nieve",
+		80: "Chubascos ligeros",
+		81: "Chubascos moderados",
+		82: "Chubascos violentos",
+		85: "Chubascos de nieve ligeros",
+		86: "Chubascos de nieve fuertes",
+		95: "Tormenta",
+		96: "Tormenta con granizo ligero",
+		99: "Tormenta con granizo fuerte",
+	},
+}
+
+// unknownCode is the format used for codes missing from the catalog.
+var unknownCode = map[string]string{
+	"en": "Unknown weather code: %d",
+	"de": "Unbekannter Wettercode: %d",
+	"fr": "Code météo inconnu : %d",
+	"es": "Código meteorológico desconocido: %d",
+}
+
+var weekdays = map[string][7]string{
+	"en": {"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	"de": {"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	"fr": {"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	"es": {"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+}
+
+var months = map[string][12]string{
+	"en": {"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	"de": {"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sep.", "Okt.", "Nov.", "Dez."},
+	"fr": {"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+	"es": {"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+}
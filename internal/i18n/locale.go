@@ -0,0 +1,143 @@
+// Package i18n provides localized weather descriptions together with
+// locale-aware date, time and number formatting.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale formats weather data for one language and region.
+type Locale struct {
+	tag      string
+	language string
+	hour12   bool
+	decimal  string
+	// dateLayout selects the order of weekday, day and month.
+	dateLayout dateLayout
+}
+
+type dateLayout int
+
+const (
+	// "Sun 01 Jan"
+	layoutDayMonth dateLayout = iota
+	// "Sun Jan 1"
+	layoutMonthDay
+	// "So 1. Jan."
+	layoutDayDotMonth
+)
+
+// locales lists the supported locales keyed by their lower-case tag.
+var locales = map[string]*Locale{
+	"en":    {tag: "en", language: "en", decimal: ".", dateLayout: layoutDayMonth},
+	"en-us": {tag: "en-US", language: "en", hour12: true, decimal: ".", dateLayout: layoutMonthDay},
+	"en-gb": {tag: "en-GB", language: "en", decimal: ".", dateLayout: layoutDayMonth},
+	"de":    {tag: "de", language: "de", decimal: ",", dateLayout: layoutDayDotMonth},
+	"fr":    {tag: "fr", language: "fr", decimal: ",", dateLayout: layoutDayMonth},
+	"es":    {tag: "es", language: "es", decimal: ",", dateLayout: layoutDayMonth},
+}
+
+// Default is the locale used when none is requested: English with a 24-hour
+// clock.
+var Default = locales["en"]
+
+// Lookup returns the locale for a language tag such as "de", "en-GB" or a
+// POSIX locale name like "fr_FR.UTF-8". Unsupported regions fall back to the
+// language, and unsupported languages fall back to Default.
+func Lookup(tag string) *Locale {
+	tag = strings.ToLower(tag)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(tag, "_", "-")
+
+	if locale, ok := locales[tag]; ok {
+		return locale
+	}
+	if language, _, ok := strings.Cut(tag, "-"); ok {
+		if locale, ok := locales[language]; ok {
+			return locale
+		}
+	}
+	return Default
+}
+
+// FromEnvironment returns the locale name from LC_ALL, LC_MESSAGES or LANG,
+// in that order of precedence.
+func FromEnvironment() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Tag returns the locale's language tag, e.g. "en-GB".
+func (l *Locale) Tag() string {
+	return l.tag
+}
+
+// Language returns the ISO 639-1 language code, e.g. "en".
+func (l *Locale) Language() string {
+	return l.language
+}
+
+// WeatherDescription returns the description of a WMO weather code.
+func (l *Locale) WeatherDescription(code int) string {
+	if description, ok := descriptions[l.language][code]; ok {
+		return description
+	}
+	return fmt.Sprintf(unknownCode[l.language], code)
+}
+
+// FormatNumber formats a value with the given number of decimals using the
+// locale's decimal separator.
+func (l *Locale) FormatNumber(value float64, decimals int) string {
+	formatted := strconv.FormatFloat(value, 'f', decimals, 64)
+	if formatted == "-"+strconv.FormatFloat(0, 'f', decimals, 64) {
+		formatted = formatted[1:]
+	}
+	if l.decimal != "." {
+		formatted = strings.Replace(formatted, ".", l.decimal, 1)
+	}
+	return formatted
+}
+
+// FormatClock formats the time of day, e.g. "15:04" or "3:04 PM".
+func (l *Locale) FormatClock(t time.Time) string {
+	if l.hour12 {
+		return t.Format("3:04 PM")
+	}
+	return t.Format("15:04")
+}
+
+// FormatDate formats a date with a short weekday, e.g. "Sun 01 Jan".
+func (l *Locale) FormatDate(t time.Time) string {
+	weekday := weekdays[l.language][t.Weekday()]
+	month := months[l.language][t.Month()-1]
+
+	switch l.dateLayout {
+	case layoutMonthDay:
+		return fmt.Sprintf("%s %s %d", weekday, month, t.Day())
+	case layoutDayDotMonth:
+		return fmt.Sprintf("%s %d. %s", weekday, t.Day(), month)
+	default:
+		return fmt.Sprintf("%s %02d %s", weekday, t.Day(), month)
+	}
+}
+
+// FormatWeekdayClock formats a short weekday and the time of day, e.g.
+// "Sun 15:04".
+func (l *Locale) FormatWeekdayClock(t time.Time) string {
+	return weekdays[l.language][t.Weekday()] + " " + l.FormatClock(t)
+}
+
+// FormatDateTime formats a date and the time of day.
+func (l *Locale) FormatDateTime(t time.Time) string {
+	return l.FormatDate(t) + " " + l.FormatClock(t)
+}
@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"":            "en",
+		"C":           "en",
+		"de":          "de",
+		"de_DE.UTF-8": "de",
+		"en_US.UTF-8": "en-US",
+		"en-GB":       "en-GB",
+		"en_AU":       "en",
+		"fr_CA@euro":  "fr",
+		"xx":          "en",
+	}
+	for input, expected := range tests {
+		if tag := Lookup(input).Tag(); tag != expected {
+			t.Errorf("Expected Lookup(%q) to be '%s', got '%s'", input, expected, tag)
+		}
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+	if env := FromEnvironment(); env != "es_ES.UTF-8" {
+		t.Errorf("Expected LANG to be used, got '%s'", env)
+	}
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if env := FromEnvironment(); env != "de_DE.UTF-8" {
+		t.Errorf("Expected LC_ALL to take precedence, got '%s'", env)
+	}
+}
+
+func TestWeatherDescription(t *testing.T) {
+	if description := Lookup("en").WeatherDescription(61); description != "Slight rain" {
+		t.Errorf("Expected 'Slight rain', got '%s'", description)
+	}
+	if description := Lookup("en").WeatherDescription(65); description != "Heavy rain" {
+		t.Errorf("Expected 'Heavy rain', got '%s'", description)
+	}
+	if description := Lookup("de").WeatherDescription(95); description != "Gewitter" {
+		t.Errorf("Expected 'Gewitter', got '%s'", description)
+	}
+	if description := Lookup("fr").WeatherDescription(42); description != "Code météo inconnu : 42" {
+		t.Errorf("Expected the unknown code message, got '%s'", description)
+	}
+}
+
+func TestEveryLanguageDescribesEveryCode(t *testing.T) {
+	for language, catalog := range descriptions {
+		for code := range descriptions["en"] {
+			if _, ok := catalog[code]; !ok {
+				t.Errorf("Language '%s' is missing a description for code %d", language, code)
+			}
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	if formatted := Lookup("en").FormatNumber(12.345, 1); formatted != "12.3" {
+		t.Errorf("Expected '12.3', got '%s'", formatted)
+	}
+	if formatted := Lookup("de").FormatNumber(12.345, 1); formatted != "12,3" {
+		t.Errorf("Expected '12,3', got '%s'", formatted)
+	}
+	if formatted := Lookup("fr").FormatNumber(-0.01, 1); formatted != "0,0" {
+		t.Errorf("Expected negative zero to be formatted as '0,0', got '%s'", formatted)
+	}
+}
+
+func TestFormatDateAndClock(t *testing.T) {
+	moment := time.Date(2023, 3, 5, 15, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		tag      string
+		datetime string
+	}{
+		{"en", "Sun 05 Mar 15:04"},
+		{"en-US", "Sun Mar 5 3:04 PM"},
+		{"de", "So 5. März 15:04"},
+		{"fr", "dim. 05 mars 15:04"},
+		{"es", "dom 05 mar 15:04"},
+	}
+	for _, test := range tests {
+		if formatted := Lookup(test.tag).FormatDateTime(moment); formatted != test.datetime {
+			t.Errorf("Expected %s date time '%s', got '%s'", test.tag, test.datetime, formatted)
+		}
+	}
+
+	if formatted := Lookup("en-US").FormatWeekdayClock(moment); formatted != "Sun 3:04 PM" {
+		t.Errorf("Expected 'Sun 3:04 PM', got '%s'", formatted)
+	}
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.Counter("sky_requests_total", "Requests made.", "endpoint", "status")
+	temperature := registry.Gauge("sky_temperature_celsius", "Air temperature.", "name")
+	latency := registry.Histogram("sky_latency_seconds", "Latency.", []float64{0.1, 1}, "endpoint")
+
+	requests.Inc("forecast", "200")
+	requests.Add(2, "forecast", "200")
+	requests.Inc("search", "500")
+	temperature.Set(21.5, `Say "hi"`)
+	latency.Observe(0.05, "forecast")
+	latency.Observe(0.5, "forecast")
+	latency.Observe(3, "forecast")
+
+	var b strings.Builder
+	if err := registry.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `# HELP sky_requests_total Requests made.
+# TYPE sky_requests_total counter
+sky_requests_total{endpoint="forecast",status="200"} 3
+sky_requests_total{endpoint="search",status="500"} 1
+# HELP sky_temperature_celsius Air temperature.
+# TYPE sky_temperature_celsius gauge
+sky_temperature_celsius{name="Say \"hi\""} 21.5
+# HELP sky_latency_seconds Latency.
+# TYPE sky_latency_seconds histogram
+sky_latency_seconds_bucket{endpoint="forecast",le="0.1"} 1
+sky_latency_seconds_bucket{endpoint="forecast",le="1"} 2
+sky_latency_seconds_bucket{endpoint="forecast",le="+Inf"} 3
+sky_latency_seconds_sum{endpoint="forecast"} 3.55
+sky_latency_seconds_count{endpoint="forecast"} 3
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestGauge_Delete(t *testing.T) {
+	registry := NewRegistry()
+	gauge := registry.Gauge("sky_up", "Whether the last fetch worked.", "name")
+	gauge.Set(1, "Berlin")
+	gauge.Set(1, "Paris")
+	gauge.Delete("Paris")
+
+	var b strings.Builder
+	_ = registry.Write(&b)
+	if strings.Contains(b.String(), "Paris") || !strings.Contains(b.String(), `sky_up{name="Berlin"} 1`) {
+		t.Errorf("Expected only Berlin to remain, got:\n%s", b.String())
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("sky_scrapes_total", "Scrapes.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Expected content type %q, got %q", ContentType, recorder.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(recorder.Body.String(), "sky_scrapes_total 1\n") {
+		t.Errorf("Expected the counter without labels, got:\n%s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+)
+
+// Headers set on every request.
+const (
+	HeaderSignature = "X-Sky-Signature" // "sha256=" and the hex HMAC, see Sign
+	HeaderTimestamp = "X-Sky-Timestamp" // Unix seconds the request was signed at
+	HeaderEvent     = "X-Sky-Event"     // The rule name
+)
+
+// Sign returns the signature sent in HeaderSignature: the hex HMAC-SHA256,
+// keyed with the webhook secret, of the timestamp, a ".", and the body.
+// Including the timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// maxRetryAfter caps how long a Retry-After header can delay a retry.
+const maxRetryAfter = time.Minute
+
+// Notifier delivers fired alerts to webhooks.
+type Notifier struct {
+	Webhooks []Webhook
+	Client   *http.Client
+
+	// Retries is how many more times a delivery is attempted after a
+	// network error, a 429 or a 5xx response, waiting Backoff before the
+	// first retry and twice as long before each one after.
+	Retries int
+	Backoff time.Duration
+
+	// State suppresses alerts that were already delivered. Nil sends every
+	// fired alert every time.
+	State *State
+	// RepeatAfter resends an alert that is still firing once this long has
+	// passed since it was delivered. Zero sends it once until it clears.
+	RepeatAfter time.Duration
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewNotifier returns a Notifier that retries three times and keeps its
+// deduplication state in memory.
+func NewNotifier(webhooks []Webhook, httpClient *http.Client) *Notifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Notifier{
+		Webhooks: webhooks,
+		Client:   httpClient,
+		Retries:  3,
+		Backoff:  time.Second,
+		State:    NewState(),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// Delivery is the outcome of sending one alert to one webhook.
+type Delivery struct {
+	Webhook  string `json:"webhook"`
+	Rule     string `json:"rule"`
+	Skipped  bool   `json:"skipped,omitempty"` // Already delivered
+	Attempts int    `json:"attempts,omitempty"`
+	Status   int    `json:"status,omitempty"` // HTTP status of the last attempt
+	Error    string `json:"error,omitempty"`
+}
+
+// Notify sends every fired result to every webhook, skipping the ones already
+// delivered. Results that did not fire clear their state so that they are
+// sent again when they next fire. The error joins every failed delivery.
+func (n *Notifier) Notify(location Location, results []alerts.Result) ([]Delivery, error) {
+	now := n.now()
+	var deliveries []Delivery
+	var errs []error
+
+	for _, result := range results {
+		prefix := stateKey(location, result.Rule, "")
+		if !result.Fired {
+			if n.State != nil && result.Error == "" {
+				n.State.clear(prefix)
+			}
+			continue
+		}
+
+		event := NewEvent(location, result, now)
+		for _, webhook := range n.Webhooks {
+			delivery := Delivery{Webhook: webhook.ID(), Rule: result.Rule}
+			key := prefix + webhook.ID()
+
+			if n.State != nil {
+				if sent, ok := n.State.sentAt(key); ok &&
+					(n.RepeatAfter <= 0 || now.Sub(sent) < n.RepeatAfter) {
+					delivery.Skipped = true
+					deliveries = append(deliveries, delivery)
+					continue
+				}
+			}
+
+			delivery.Attempts, delivery.Status, delivery.Error = n.deliver(webhook, event)
+			if delivery.Error != "" {
+				errs = append(errs, fmt.Errorf("failed to notify %s of %q: %s", webhook.ID(), result.Rule, delivery.Error))
+			} else if n.State != nil {
+				n.State.mark(key, now)
+			}
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, errors.Join(errs...)
+}
+
+// stateKey identifies an alert at a location for a webhook.
+func stateKey(location Location, rule, webhook string) string {
+	return fmt.Sprintf("%.4f,%.4f|%s|%s", location.Latitude, location.Longitude, rule, webhook)
+}
+
+// deliver posts the event, retrying transient failures. It returns the number
+// of attempts, the last HTTP status and an error message.
+func (n *Notifier) deliver(webhook Webhook, event Event) (int, int, string) {
+	body, err := webhook.Payload(event)
+	if err != nil {
+		return 0, 0, err.Error()
+	}
+
+	backoff := n.Backoff
+	for attempt := 1; ; attempt++ {
+		status, retryAfter, err := n.post(webhook, event, body)
+		if err == nil {
+			return attempt, status, ""
+		}
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= 500
+		if !retryable || attempt > n.Retries {
+			return attempt, status, err.Error()
+		}
+
+		// Honour Retry-After, but not so far that a run hangs on one webhook.
+		n.sleep(max(backoff, min(retryAfter, maxRetryAfter)))
+		backoff *= 2
+	}
+}
+
+// post sends one request and returns the status, any Retry-After delay and an
+// error for anything but a 2xx response.
+func (n *Notifier) post(webhook Webhook, event Event, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sky")
+	req.Header.Set(HeaderEvent, event.Rule)
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	if webhook.Secret != "" {
+		timestamp := n.now().Unix()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to POST: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return resp.StatusCode, retryAfter, fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return resp.StatusCode, 0, nil
+}
@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+)
+
+var (
+	testNow      = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	testLocation = Location{Name: "Berlin", Latitude: 52.52, Longitude: 13.41}
+)
+
+func firedResult(rule string) alerts.Result {
+	return alerts.Result{
+		Rule:       rule,
+		Expression: "precipitation_probability > 60 within next 3h",
+		Severity:   "warning",
+		Source:     alerts.SourceHourly,
+		Fired:      true,
+		Value:      75,
+		Threshold:  60,
+		Unit:       "%",
+		Margin:     15,
+		First:      time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Last:       time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC),
+		Matches:    2,
+	}
+}
+
+// recorder is a webhook endpoint that records requests and answers with the
+// queued statuses, then 200.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, body)
+	if len(rec.statuses) > 0 {
+		status := rec.statuses[0]
+		rec.statuses = rec.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestNotifier(server *httptest.Server, webhooks ...Webhook) (*Notifier, *[]time.Duration) {
+	var sleeps []time.Duration
+	n := NewNotifier(webhooks, server.Client())
+	n.now = func() time.Time { return testNow }
+	n.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return n, &sleeps
+}
+
+func TestNotify_JSONPayloadAndSignature(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	n, _ := newTestNotifier(server, Webhook{
+		Name:    "ops",
+		URL:     server.URL,
+		Secret:  "s3cret",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	deliveries, err := n.Notify(testLocation, []alerts.Result{firedResult("rain soon")})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Attempts != 1 || deliveries[0].Status != 200 {
+		t.Fatalf("Expected one delivery in one attempt, got %+v", deliveries)
+	}
+	if len(rec.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(rec.requests))
+	}
+
+	req, body := rec.requests[0], rec.bodies[0]
+	if req.Method != http.MethodPost {
+		t.Errorf("Expected POST, got %s", req.Method)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected the configured Authorization header, got %q", got)
+	}
+	if got := req.Header.Get(HeaderEvent); got != "rain soon" {
+		t.Errorf("Expected %s to be the rule name, got %q", HeaderEvent, got)
+	}
+	if got := req.Header.Get(HeaderTimestamp); got != "1717232400" {
+		t.Errorf("Expected %s to be 1717232400, got %q", HeaderTimestamp, got)
+	}
+	if got, want := req.Header.Get(HeaderSignature), Sign("s3cret", testNow.Unix(), body); got != want {
+		t.Errorf("Expected signature %q, got %q", want, got)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if event.Rule != "rain soon" || event.Location.Name != "Berlin" || event.Value != 75 || event.Unit != "%" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if !event.First.Equal(firedResult("").First) || !event.FiredAt.Equal(testNow) {
+		t.Errorf("Expected the forecast and firing times in the event, got %+v", event)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1700000000.{}' | openssl dgst -sha256 -hmac key
+	want := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	if got := Sign("key", 1700000000, []byte("{}")); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestWebhook_ChatPayloads(t *testing.T) {
+	event := NewEvent(testLocation, firedResult("rain soon"), testNow)
+	message := "[warning] rain soon in Berlin: 75 % (precipitation_probability > 60 within next 3h) from Sat 10:00 until Sat 11:00"
+
+	tests := []struct {
+		format Format
+		field  string
+	}{
+		{FormatSlack, "text"},
+		{FormatDiscord, "content"},
+		{FormatTeams, "text"},
+	}
+	for _, tt := range tests {
+		payload, err := Webhook{URL: "https://example.com", Format: tt.format}.Payload(event)
+		if err != nil {
+			t.Fatalf("%s: Payload failed: %v", tt.format, err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("%s: failed to decode payload: %v", tt.format, err)
+		}
+		if decoded[tt.field] != message {
+			t.Errorf("%s: expected %s to be %q, got %q", tt.format, tt.field, message, decoded[tt.field])
+		}
+	}
+
+	payload, _ := Webhook{URL: "https://example.com", Format: FormatTeams}.Payload(event)
+	if !strings.Contains(string(payload), `"@type":"MessageCard"`) || !strings.Contains(string(payload), `"themeColor":"F57C00"`) {
+		t.Errorf("Expected a MessageCard coloured for a warning, got %s", payload)
+	}
+
+	custom := Webhook{URL: "https://example.com", Format: FormatSlack, Template: "{{.Rule}}: {{num .Value}}{{.Unit}}"}
+	payload, err := custom.Payload(event)
+	if err != nil {
+		t.Fatalf("Payload failed: %v", err)
+	}
+	if string(payload) != `{"text":"rain soon: 75%"}` {
+		t.Errorf("Expected the custom template to be used, got %s", payload)
+	}
+}
+
+func TestWebhook_Validate(t *testing.T) {
+	valid := Webhook{URL: "https://hooks.example.com/x", Format: FormatDiscord}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected %+v to be valid, got %v", valid, err)
+	}
+	for _, w := range []Webhook{
+		{URL: "hooks.example.com/x"},
+		{URL: "ftp://hooks.example.com/x"},
+		{URL: "https://hooks.example.com/x", Format: "irc"},
+		{URL: "https://hooks.example.com/x", Format: FormatSlack, Template: "{{.Rule"},
+	} {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", w)
+		}
+	}
+}
+
+func TestNotify_RetriesTransientFailures(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	n, sleeps := newTestNotifier(server, Webhook{URL: server.URL})
+	deliveries, err := n.Notify(testLocation, []alerts.Result{firedResult("rain soon")})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if deliveries[0].Attempts != 3 || deliveries[0].Status != 200 {
+		t.Errorf("Expected success on the third attempt, got %+v", deliveries[0])
+	}
+	if len(*sleeps) != 2 || (*sleeps)[0] != time.Second || (*sleeps)[1] != 2*time.Second {
+		t.Errorf("Expected backoffs of 1s and 2s, got %v", *sleeps)
+	}
+}
+
+func TestNotify_GivesUp(t *testing.T) {
+	rec := &recorder{statuses: []int{500, 500, 500, 500, 500}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	n, _ := newTestNotifier(server, Webhook{URL: server.URL})
+	deliveries, err := n.Notify(testLocation, []alerts.Result{firedResult("rain soon")})
+	if err == nil {
+		t.Fatal("Expected an error after the retries ran out")
+	}
+	if deliveries[0].Attempts != 4 || deliveries[0].Status != 500 {
+		t.Errorf("Expected 4 attempts ending in 500, got %+v", deliveries[0])
+	}
+
+	// A failed delivery is not remembered, so the next poll tries again.
+	rec.statuses = nil
+	deliveries, err = n.Notify(testLocation, []alerts.Result{firedResult("rain soon")})
+	if err != nil || deliveries[0].Skipped {
+		t.Errorf("Expected the alert to be retried on the next run, got %+v, %v", deliveries, err)
+	}
+}
+
+func TestNotify_DoesNotRetryClientErrors(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	n, sleeps := newTestNotifier(server, Webhook{URL: server.URL})
+	deliveries, err := n.Notify(testLocation, []alerts.Result{firedResult("rain soon")})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected a 400 error, got %v", err)
+	}
+	if deliveries[0].Attempts != 1 || len(*sleeps) != 0 {
+		t.Errorf("Expected a single attempt, got %+v", deliveries[0])
+	}
+}
+
+func TestNotify_Deduplicates(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	n, _ := newTestNotifier(server, Webhook{URL: server.URL})
+	fired := []alerts.Result{firedResult("rain soon")}
+
+	notify := func(results []alerts.Result) []Delivery {
+		t.Helper()
+		deliveries, err := n.Notify(testLocation, results)
+		if err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+		return deliveries
+	}
+
+	notify(fired)
+	if deliveries := notify(fired); !deliveries[0].Skipped {
+		t.Errorf("Expected the second notification to be skipped, got %+v", deliveries[0])
+	}
+	if len(rec.requests) != 1 {
+		t.Errorf("Expected 1 request, got %d", len(rec.requests))
+	}
+
+	// Once the rule stops firing, the next firing is sent again.
+	cleared := firedResult("rain soon")
+	cleared.Fired = false
+	notify([]alerts.Result{cleared})
+	notify(fired)
+	if len(rec.requests) != 2 {
+		t.Errorf("Expected the alert to be sent again after clearing, got %d requests", len(rec.requests))
+	}
+
+	// With RepeatAfter, an alert that is still firing is sent again later.
+	n.RepeatAfter = 6 * time.Hour
+	n.now = func() time.Time { return testNow.Add(5 * time.Hour) }
+	notify(fired)
+	n.now = func() time.Time { return testNow.Add(7 * time.Hour) }
+	notify(fired)
+	if len(rec.requests) != 3 {
+		t.Errorf("Expected the alert to be repeated after 6h only, got %d requests", len(rec.requests))
+	}
+}
+
+func TestState_SaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sky", "notify.json")
+	state, err := OpenState(path)
+	if err != nil {
+		t.Fatalf("OpenState failed for a missing file: %v", err)
+	}
+	key := stateKey(testLocation, "rain soon", "ops")
+	state.mark(key, testNow)
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reopened, err := OpenState(path)
+	if err != nil {
+		t.Fatalf("OpenState failed: %v", err)
+	}
+	if sent, ok := reopened.sentAt(key); !ok || !sent.Equal(testNow) {
+		t.Errorf("Expected %s to be remembered as sent at %v, got %v, %v", key, testNow, sent, ok)
+	}
+}
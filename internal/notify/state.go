@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State remembers which alerts have been delivered to which webhooks, so that
+// an alert that keeps firing on every poll is only sent once. It can be
+// persisted between runs with OpenState and Save.
+type State struct {
+	path string
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewState returns an empty state that is kept in memory only.
+func NewState() *State {
+	return &State{sent: make(map[string]time.Time)}
+}
+
+// DefaultStatePath returns the state file in the user's sky cache directory
+// (e.g. ~/.cache/sky/notify.json on Linux).
+func DefaultStatePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	return filepath.Join(dir, "sky", "notify.json"), nil
+}
+
+// OpenState loads the state saved at path. A missing file yields an empty
+// state that Save will create.
+func OpenState(path string) (*State, error) {
+	s := NewState()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.sent); err != nil {
+		return nil, fmt.Errorf("failed to parse notification state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state back to the file it was opened from. It does nothing
+// for a state created with NewState.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.sent, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save notification state: %w", err)
+	}
+	// Write a temporary file and rename it so that a crash cannot leave a
+	// truncated state behind.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save notification state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save notification state: %w", err)
+	}
+	return nil
+}
+
+// sentAt returns when key was last delivered.
+func (s *State) sentAt(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.sent[key]
+	return t, ok
+}
+
+func (s *State) mark(key string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent[key] = t
+}
+
+// clear forgets every key with the prefix, so that an alert that stopped
+// firing is sent again the next time it fires.
+func (s *State) clear(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.sent {
+		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+			delete(s.sent, key)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package notify delivers fired alerts to webhooks.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+)
+
+// Format is the shape of the JSON a webhook is sent.
+type Format string
+
+const (
+	FormatJSON    Format = "json"    // The Event itself
+	FormatSlack   Format = "slack"   // Slack incoming webhook message
+	FormatDiscord Format = "discord" // Discord webhook message
+	FormatTeams   Format = "teams"   // Microsoft Teams connector card
+)
+
+// Webhook is a URL that fired alerts are posted to, e.g.
+//
+//	{"name": "team", "url": "https://hooks.slack.com/services/...", "format": "slack"}
+type Webhook struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url"`
+	Format Format `json:"format,omitempty"` // Defaults to FormatJSON
+
+	// Secret, when set, signs each request with HMAC-SHA256. See Sign.
+	Secret string `json:"secret,omitempty"`
+
+	// Headers are added to every request, e.g. for an Authorization token.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Template overrides the message text of the chat formats. It is a
+	// text/template executed with the Event.
+	Template string `json:"template,omitempty"`
+}
+
+// ID returns the name of the webhook, or its URL when it has none.
+func (w Webhook) ID() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return w.URL
+}
+
+// Validate checks the URL, format and template.
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %q: invalid URL %q", w.ID(), w.URL)
+	}
+	switch w.Format {
+	case "", FormatJSON, FormatSlack, FormatDiscord, FormatTeams:
+	default:
+		return fmt.Errorf("webhook %q: unknown format %q (want json, slack, discord or teams)", w.ID(), w.Format)
+	}
+	if _, err := w.template(); err != nil {
+		return fmt.Errorf("webhook %q: invalid template: %w", w.ID(), err)
+	}
+	return nil
+}
+
+// Location is the place an alert fired for.
+type Location struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Event is the payload of the json format and the data of message
+// templates.
+type Event struct {
+	Rule       string    `json:"rule"`
+	Expression string    `json:"expression"`
+	Severity   string    `json:"severity,omitempty"`
+	Location   Location  `json:"location"`
+	Value      float64   `json:"value"`
+	Threshold  float64   `json:"threshold"`
+	Unit       string    `json:"unit,omitempty"`
+	Margin     float64   `json:"margin"`
+	First      time.Time `json:"first,omitzero"` // When the condition first holds in the forecast
+	Last       time.Time `json:"last,omitzero"`  // When it last holds
+	FiredAt    time.Time `json:"fired_at"`
+}
+
+// NewEvent describes a fired result at a location.
+func NewEvent(location Location, result alerts.Result, firedAt time.Time) Event {
+	return Event{
+		Rule:       result.Rule,
+		Expression: result.Expression,
+		Severity:   result.Severity,
+		Location:   location,
+		Value:      result.Value,
+		Threshold:  result.Threshold,
+		Unit:       result.Unit,
+		Margin:     result.Margin,
+		First:      result.First,
+		Last:       result.Last,
+		FiredAt:    firedAt,
+	}
+}
+
+// defaultTemplate is the message text of the chat formats.
+const defaultTemplate = `{{if .Severity}}[{{.Severity}}] {{end}}{{.Rule}} in {{.Location.Name}}: ` +
+	`{{num .Value}}{{with .Unit}} {{.}}{{end}} ({{.Expression}})` +
+	`{{if not .First.IsZero}} from {{.First.Format "Mon 15:04"}}{{end}}` +
+	`{{if and (not .Last.IsZero) (.Last.After .First)}} until {{.Last.Format "Mon 15:04"}}{{end}}`
+
+var templateFuncs = template.FuncMap{
+	"num": func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	},
+}
+
+func (w Webhook) template() (*template.Template, error) {
+	text := w.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	return template.New("message").Funcs(templateFuncs).Parse(text)
+}
+
+// Message returns the text of the event for the chat formats.
+func (w Webhook) Message(event Event) (string, error) {
+	tmpl, err := w.template()
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, event); err != nil {
+		return "", fmt.Errorf("failed to render message: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Payload returns the request body for the event in the webhook's format.
+func (w Webhook) Payload(event Event) ([]byte, error) {
+	if w.Format == "" || w.Format == FormatJSON {
+		return json.Marshal(event)
+	}
+
+	message, err := w.Message(event)
+	if err != nil {
+		return nil, err
+	}
+	switch w.Format {
+	case FormatSlack:
+		return json.Marshal(slackMessage{Text: message})
+	case FormatDiscord:
+		return json.Marshal(discordMessage{Username: "sky", Content: message})
+	case FormatTeams:
+		return json.Marshal(teamsCard{
+			Type:       "MessageCard",
+			Context:    "https://schema.org/extensions",
+			Summary:    event.Rule,
+			ThemeColor: severityColor(event.Severity),
+			Title:      fmt.Sprintf("%s in %s", event.Rule, event.Location.Name),
+			Text:       message,
+		})
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", w.Format)
+	}
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+type discordMessage struct {
+	Username string `json:"username,omitempty"`
+	Content  string `json:"content"`
+}
+
+type teamsCard struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor,omitempty"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// severityColor returns the card colour for a rule severity.
+func severityColor(severity string) string {
+	switch severity {
+	case "critical", "severe", "danger":
+		return "D32F2F"
+	case "warning":
+		return "F57C00"
+	default:
+		return "1976D2"
+	}
+}
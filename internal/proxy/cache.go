@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+// entry is an upstream response.
+type entry struct {
+	status      int
+	contentType string
+	body        []byte
+	stored      time.Time
+	expires     time.Time
+}
+
+// cache holds responses until they expire, up to a number of entries.
+type cache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*entry
+}
+
+func newCache(max int) *cache {
+	return &cache{max: max, entries: make(map[string]*entry)}
+}
+
+func (c *cache) get(key string, now time.Time) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+	return e, true
+}
+
+// put stores an entry. When the cache is full, expired entries are dropped
+// and, if that is not enough, the one closest to expiring.
+func (c *cache) put(key string, e *entry, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.max {
+		for k, old := range c.entries {
+			if !now.Before(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.max {
+			c.evictOldest()
+		}
+	}
+	c.entries[key] = e
+}
+
+func (c *cache) evictOldest() {
+	var oldest string
+	var soonest time.Time
+	for k, e := range c.entries {
+		if oldest == "" || e.expires.Before(soonest) {
+			oldest, soonest = k, e.expires
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+// call is an upstream request that others wait for.
+type call struct {
+	wg    sync.WaitGroup
+	entry *entry
+	err   error
+}
+
+// flightGroup coalesces concurrent requests for the same key into one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do calls fn, unless a call for key is already running, in which case it
+// waits for that one and reports that its result was shared.
+func (g *flightGroup) do(key string, fn func() (*entry, error)) (*entry, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.entry, true, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.entry, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return c.entry, false, c.err
+}
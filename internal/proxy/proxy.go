@@ -0,0 +1,284 @@
+// Package proxy is a caching reverse proxy for the Open-Meteo APIs. It
+// serves the forecast, geocoding and air-quality endpoints under one base
+// URL, so the openmateo clients can point their BaseURL at it unchanged.
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultListen is the address the proxy listens on by default.
+const DefaultListen = ":8090"
+
+// EnvURL names the environment variable that points sky's own clients at a
+// proxy, e.g. "http://proxy:8090/v1/".
+const EnvURL = "SKY_OPENMETEO_URL"
+
+// DefaultUpstreams maps each endpoint to the base URL of the API serving it.
+// They match the defaults of the openmateo clients.
+var DefaultUpstreams = map[string]string{
+	"forecast":    "https://api.open-meteo.com/v1/",
+	"search":      "https://geocoding-api.open-meteo.com/v1/",
+	"air-quality": "https://air-quality-api.open-meteo.com/v1/",
+}
+
+// Defaults of Options.
+const (
+	DefaultTTL        = 10 * time.Minute
+	DefaultSearchTTL  = 24 * time.Hour
+	DefaultMaxEntries = 10000
+)
+
+// Options tune the proxy. Zero values take the defaults.
+type Options struct {
+	// TTL is how long forecasts and air quality are cached. Open-Meteo
+	// updates its models hourly at most.
+	TTL time.Duration
+	// SearchTTL is how long geocoding results are cached.
+	SearchTTL time.Duration
+	// MaxEntries bounds the number of cached responses.
+	MaxEntries int
+	// Rate is how many requests per second each client may make, with
+	// bursts of up to Burst. Zero turns rate limiting off.
+	Rate  float64
+	Burst int
+}
+
+// maxClients is how many clients stats are kept for before the one with
+// the fewest requests is forgotten.
+const maxClients = 10000
+
+// ClientStats counts the requests of one client.
+type ClientStats struct {
+	Requests  int64 `json:"requests"`
+	Hits      int64 `json:"cache_hits"`
+	Misses    int64 `json:"cache_misses"`
+	Coalesced int64 `json:"coalesced"`
+	Limited   int64 `json:"rate_limited"`
+	Errors    int64 `json:"errors"`
+}
+
+// Proxy forwards GET requests to the upstream of their endpoint. Successful
+// responses are cached by endpoint and query, concurrent requests for the
+// same URL share one upstream request, and each client, identified by its
+// IP address, is rate limited. Responses carry X-Cache: HIT or MISS.
+type Proxy struct {
+	Upstreams map[string]string
+	Client    *http.Client
+	Log       *log.Logger
+
+	opts    Options
+	cache   *cache
+	flights flightGroup
+	limiter *limiter
+	now     func() time.Time
+
+	mu    sync.Mutex
+	stats map[string]*ClientStats
+}
+
+// New returns a Proxy to the DefaultUpstreams that logs each request to
+// logger.
+func New(opts Options, logger *log.Logger) *Proxy {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.SearchTTL <= 0 {
+		opts.SearchTTL = DefaultSearchTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	p := &Proxy{
+		Upstreams: DefaultUpstreams,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Log:       logger,
+		opts:      opts,
+		cache:     newCache(opts.MaxEntries),
+		now:       time.Now,
+		stats:     make(map[string]*ClientStats),
+	}
+	if opts.Rate > 0 {
+		p.limiter = newLimiter(opts.Rate, max(opts.Burst, 1))
+	}
+	return p
+}
+
+// Stats returns a copy of the counts of every client seen.
+func (p *Proxy) Stats() map[string]ClientStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make(map[string]ClientStats, len(p.stats))
+	for client, s := range p.stats {
+		stats[client] = *s
+	}
+	return stats
+}
+
+func (p *Proxy) count(client string, update func(*ClientStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.stats[client]
+	if !ok {
+		if len(p.stats) >= maxClients {
+			p.forgetQuietest()
+		}
+		s = &ClientStats{}
+		p.stats[client] = s
+	}
+	update(s)
+}
+
+func (p *Proxy) forgetQuietest() {
+	var quietest string
+	var fewest int64
+	for client, s := range p.stats {
+		if quietest == "" || s.Requests < fewest {
+			quietest, fewest = client, s.Requests
+		}
+	}
+	delete(p.stats, quietest)
+}
+
+// apiError writes an error in the shape the Open-Meteo APIs use, which the
+// openmateo clients report with its reason.
+func apiError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error  bool   `json:"error"`
+		Reason string `json:"reason"`
+	}{true, reason})
+}
+
+// ServeHTTP proxies a request. GET /stats reports the client stats as JSON.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	client := clientAddr(r)
+	endpoint := path.Base(path.Clean("/" + r.URL.Path))
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		apiError(w, http.StatusMethodNotAllowed, "only GET requests are proxied")
+		return
+	}
+	if endpoint == "stats" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p.Stats())
+		return
+	}
+	upstream, ok := p.Upstreams[endpoint]
+	if !ok {
+		apiError(w, http.StatusNotFound, fmt.Sprintf("unknown endpoint %q", endpoint))
+		return
+	}
+
+	p.count(client, func(s *ClientStats) { s.Requests++ })
+	now := p.now()
+	if p.limiter != nil && !p.limiter.allow(client, now) {
+		p.count(client, func(s *ClientStats) { s.Limited++ })
+		w.Header().Set("Retry-After", strconv.Itoa(p.limiter.retryAfter()))
+		apiError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		p.Log.Printf("%s %s %s limited", client, r.Method, r.URL.RequestURI())
+		return
+	}
+
+	// Encoding sorts the parameters, so the same query in another order
+	// shares the cache entry.
+	key := endpoint + "?" + r.URL.Query().Encode()
+	result := "hit"
+	e, ok := p.cache.get(key, now)
+	if !ok {
+		var shared bool
+		var err error
+		e, shared, err = p.flights.do(key, func() (*entry, error) {
+			return p.fetch(key, upstream+endpoint+"?"+r.URL.RawQuery, p.ttl(endpoint))
+		})
+		if err != nil {
+			p.count(client, func(s *ClientStats) { s.Errors++ })
+			apiError(w, http.StatusBadGateway, err.Error())
+			p.Log.Printf("%s %s %s error: %v", client, r.Method, r.URL.RequestURI(), err)
+			return
+		}
+		result = "miss"
+		if shared {
+			result = "coalesced"
+		}
+	}
+	p.count(client, func(s *ClientStats) {
+		switch result {
+		case "hit":
+			s.Hits++
+		case "miss":
+			s.Misses++
+		default:
+			s.Coalesced++
+		}
+	})
+
+	if result == "hit" {
+		w.Header().Set("X-Cache", "HIT")
+		w.Header().Set("Age", strconv.Itoa(int(now.Sub(e.stored).Seconds())))
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.Header().Set("Content-Type", e.contentType)
+	w.WriteHeader(e.status)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(e.body)
+	}
+	p.Log.Printf("%s %s %s %s %d %s", client, r.Method, r.URL.RequestURI(), result, e.status, time.Since(start).Round(time.Millisecond))
+}
+
+// ttl returns how long responses of an endpoint are cached.
+func (p *Proxy) ttl(endpoint string) time.Duration {
+	if endpoint == "search" {
+		return p.opts.SearchTTL
+	}
+	return p.opts.TTL
+}
+
+// fetch gets a URL from upstream and caches it under key. Only successful
+// responses are cached; errors of the API are passed on as they are.
+func (p *Proxy) fetch(key, url string, ttl time.Duration) (*entry, error) {
+	resp, err := p.Client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach upstream: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream response: %w", err)
+	}
+
+	now := p.now()
+	e := &entry{
+		status:      resp.StatusCode,
+		contentType: resp.Header.Get("Content-Type"),
+		body:        body,
+		stored:      now,
+		expires:     now.Add(ttl),
+	}
+	if resp.StatusCode == http.StatusOK {
+		p.cache.put(key, e, now)
+	}
+	return e, nil
+}
+
+// clientAddr returns the IP address of the client of a request.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,268 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+)
+
+// upstream is a fake Open-Meteo API that counts its requests. While block
+// is set, requests wait for it to be closed.
+type upstream struct {
+	*httptest.Server
+	requests atomic.Int32
+	lastPath atomic.Value
+	block    chan struct{}
+}
+
+func newUpstream(t *testing.T) *upstream {
+	u := &upstream{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.requests.Add(1)
+		u.lastPath.Store(r.URL.Path)
+		if u.block != nil {
+			<-u.block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("latitude") == "-1":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`))
+		case strings.HasSuffix(r.URL.Path, "/search"):
+			_, _ = w.Write([]byte(`{"results":[{"name":"Berlin","latitude":52.52,"longitude":13.41}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"latitude":52.52,"generationtime_ms":0.5}`))
+		}
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func newTestProxy(t *testing.T, u *upstream, opts Options) (*Proxy, *httptest.Server) {
+	p := New(opts, log.New(io.Discard, "", 0))
+	p.Upstreams = map[string]string{
+		"forecast":    u.URL + "/forecast-api/v1/",
+		"search":      u.URL + "/geocoding-api/v1/",
+		"air-quality": u.URL + "/air-quality-api/v1/",
+	}
+	server := httptest.NewServer(p)
+	t.Cleanup(server.Close)
+	return p, server
+}
+
+func get(t *testing.T, url string) *http.Response {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp
+}
+
+func TestProxy_Cache(t *testing.T) {
+	u := newUpstream(t)
+	p, server := newTestProxy(t, u, Options{TTL: time.Minute})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	if resp := get(t, server.URL+"/v1/forecast?latitude=52.52&longitude=13.41"); resp.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("Expected a miss, got %q", resp.Header.Get("X-Cache"))
+	}
+	// The same query in another order is served from the cache.
+	resp := get(t, server.URL+"/v1/forecast?longitude=13.41&latitude=52.52")
+	if resp.Header.Get("X-Cache") != "HIT" {
+		t.Errorf("Expected a hit, got %q", resp.Header.Get("X-Cache"))
+	}
+	if u.requests.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", u.requests.Load())
+	}
+	if path := u.lastPath.Load(); path != "/forecast-api/v1/forecast" {
+		t.Errorf("Expected the forecast upstream, got %v", path)
+	}
+
+	now = now.Add(time.Minute)
+	if resp := get(t, server.URL+"/v1/forecast?latitude=52.52&longitude=13.41"); resp.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("Expected the entry to expire, got %q", resp.Header.Get("X-Cache"))
+	}
+	if u.requests.Load() != 2 {
+		t.Errorf("Expected 2 upstream requests, got %d", u.requests.Load())
+	}
+}
+
+func TestProxy_Errors(t *testing.T) {
+	u := newUpstream(t)
+	_, server := newTestProxy(t, u, Options{})
+
+	for range 2 {
+		if resp := get(t, server.URL+"/v1/forecast?latitude=-1"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected the upstream status, got %d", resp.StatusCode)
+		}
+	}
+	if u.requests.Load() != 2 {
+		t.Errorf("Expected errors not to be cached, got %d upstream requests", u.requests.Load())
+	}
+	if resp := get(t, server.URL+"/v1/archive"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown endpoint, got %d", resp.StatusCode)
+	}
+}
+
+func TestProxy_Coalescing(t *testing.T) {
+	u := newUpstream(t)
+	u.block = make(chan struct{})
+	p, server := newTestProxy(t, u, Options{})
+
+	const clients = 5
+	var wg sync.WaitGroup
+	for range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get(t, server.URL+"/v1/air-quality?latitude=52.52")
+		}()
+	}
+	// Let the upstream request finish once every request has reached the
+	// proxy, so that the others wait for it.
+	deadline := time.Now().Add(5 * time.Second)
+	for u.requests.Load() < 1 || p.Stats()["127.0.0.1"].Requests < clients {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the requests")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(u.block)
+	wg.Wait()
+
+	if u.requests.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", u.requests.Load())
+	}
+	stats := p.Stats()["127.0.0.1"]
+	if stats.Requests != clients || stats.Misses != 1 || stats.Coalesced != clients-1 {
+		t.Errorf("Expected 1 miss and %d coalesced requests, got %+v", clients-1, stats)
+	}
+}
+
+func TestProxy_RateLimit(t *testing.T) {
+	u := newUpstream(t)
+	p, server := newTestProxy(t, u, Options{Rate: 1, Burst: 2})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if resp := get(t, server.URL+"/v1/forecast?latitude=52.52"); resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected request %d to pass, got %d", i+1, resp.StatusCode)
+		}
+	}
+	resp := get(t, server.URL+"/v1/forecast?latitude=52.52")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("Expected 429 with Retry-After 1, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	now = now.Add(time.Second)
+	if resp := get(t, server.URL+"/v1/forecast?latitude=52.52"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a token after a second, got %d", resp.StatusCode)
+	}
+
+	// Another client has its own bucket.
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/v1/forecast?latitude=52.52", nil)
+	request.RemoteAddr = "10.0.0.2:4321"
+	p.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected another client to pass, got %d", recorder.Code)
+	}
+
+	var stats map[string]ClientStats
+	resp, err := http.Get(server.URL + "/stats")
+	if err != nil {
+		t.Fatalf("GET /stats failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if local := stats["127.0.0.1"]; local.Requests != 4 || local.Limited != 1 || local.Hits != 2 {
+		t.Errorf("Expected 4 requests, 1 limited and 2 hits, got %+v", local)
+	}
+	if other := stats["10.0.0.2"]; other.Requests != 1 {
+		t.Errorf("Expected 1 request from the other client, got %+v", other)
+	}
+}
+
+func TestProxy_StatsBounded(t *testing.T) {
+	p := New(Options{}, log.New(io.Discard, "", 0))
+	p.count("busy", func(s *ClientStats) { s.Requests += 2 })
+	for i := range maxClients {
+		p.count(fmt.Sprintf("10.0.%d.%d", i/256, i%256), func(s *ClientStats) { s.Requests++ })
+	}
+
+	stats := p.Stats()
+	if len(stats) != maxClients {
+		t.Errorf("Expected stats of %d clients, got %d", maxClients, len(stats))
+	}
+	if stats["busy"].Requests != 2 {
+		t.Errorf("Expected the busiest client to be kept, got %+v", stats["busy"])
+	}
+}
+
+func TestProxy_Client(t *testing.T) {
+	u := newUpstream(t)
+	_, server := newTestProxy(t, u, Options{})
+
+	// The geocoding client joins its base URL with a slash of its own.
+	gc := openmateo.NewGeocodingClient(nil)
+	gc.BaseURL = server.URL + "/v1/"
+	observer := &cacheObserver{}
+	gc.Observer = observer
+	for range 2 {
+		location, err := gc.Search("Berlin")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if location.Name != "Berlin" {
+			t.Errorf("Expected Berlin, got %+v", location)
+		}
+	}
+	if u.lastPath.Load() != "/geocoding-api/v1/search" {
+		t.Errorf("Expected the geocoding upstream, got %v", u.lastPath.Load())
+	}
+	if len(observer.hits) != 2 || observer.hits[0] || !observer.hits[1] {
+		t.Errorf("Expected a miss and then a hit, got %v", observer.hits)
+	}
+}
+
+type cacheObserver struct {
+	hits []bool
+}
+
+func (o *cacheObserver) ObserveRequest(stats openmateo.RequestStats) {
+	o.hits = append(o.hits, stats.CacheHit)
+}
+
+func TestCache_Eviction(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	c := newCache(2)
+	c.put("a", &entry{expires: now.Add(time.Minute)}, now)
+	c.put("b", &entry{expires: now.Add(time.Hour)}, now)
+	c.put("c", &entry{expires: now.Add(time.Hour)}, now)
+
+	if _, ok := c.get("a", now); ok {
+		t.Errorf("Expected the entry closest to expiring to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := c.get(key, now); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/astro"
+)
+
+// funcs returns the functions available to every template.
+func (r *Renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"compact":  func() bool { return r.opts.Compact },
+		"icon":     r.icon,
+		"aqiIcon":  r.aqiIcon,
+		"num":      r.formatNumber,
+		"delta":    r.formatDelta,
+		"fixed":    r.opts.Locale.FormatNumber,
+		"pct":      formatPercent,
+		"share":    formatShare,
+		"hour":     r.opts.Locale.FormatWeekdayClock,
+		"clock":    r.formatClock,
+		"span":     r.formatSpan,
+		"duration": formatDuration,
+		"lead":     formatLead,
+		"date":     r.opts.Locale.FormatDate,
+		"datetime": r.opts.Locale.FormatDateTime,
+		"md":       escapeMarkdown,
+
+		"moon":         r.moonIcon,
+		"astro":        astroDay,
+		"sunElevation": sunElevation,
+	}
+}
+
+// icon returns the emoji for a WMO weather code followed by a space, or an
+// empty string when emoji are disabled.
+func (r *Renderer) icon(code, isDay int) string {
+	if !r.opts.Emoji {
+		return ""
+	}
+	return weatherIcon(code, isDay == 1) + " "
+}
+
+// aqiIcon returns a coloured circle for a US AQI value followed by a space,
+// or an empty string when emoji are disabled.
+func (r *Renderer) aqiIcon(usAQI float64) string {
+	if !r.opts.Emoji {
+		return ""
+	}
+	switch {
+	case usAQI <= 50:
+		return "🟢 "
+	case usAQI <= 100:
+		return "🟡 "
+	case usAQI <= 150:
+		return "🟠 "
+	case usAQI <= 200:
+		return "🔴 "
+	case usAQI <= 300:
+		return "🟣 "
+	default:
+		return "🟤 "
+	}
+}
+
+// moonIcon returns the emoji for a moon phase followed by a space, or an
+// empty string when emoji are disabled.
+func (r *Renderer) moonIcon(phase float64) string {
+	if !r.opts.Emoji {
+		return ""
+	}
+	return moonIcons[astro.PhaseIndex(phase)] + " "
+}
+
+var moonIcons = [8]string{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"}
+
+// astroDay calculates the sun and moon events at a place for the day
+// containing date.
+func astroDay(place Place, date time.Time) astro.Day {
+	return astro.ForDay(place.AstroLocation(), date)
+}
+
+// sunElevation returns the sun's elevation in degrees at a place and time.
+func sunElevation(place Place, t time.Time) float64 {
+	return astro.SunElevation(place.AstroLocation(), t)
+}
+
+// weatherIcon maps a WMO weather code to an emoji.
+func weatherIcon(code int, isDay bool) string {
+	switch code {
+	case 0, 1:
+		if !isDay {
+			return "🌙"
+		}
+		if code == 0 {
+			return "☀️"
+		}
+		return "🌤️"
+	case 2:
+		return "⛅"
+	case 3:
+		return "☁️"
+	case 45, 48:
+		return "🌫️"
+	case 51, 53, 55, 56, 57, 80, 81, 82:
+		return "🌦️"
+	case 61, 63, 65, 66, 67:
+		return "🌧️"
+	case 71, 73, 75, 77, 85, 86:
+		return "🌨️"
+	case 95, 96, 99:
+		return "⛈️"
+	default:
+		return "❔"
+	}
+}
+
+// formatNumber formats a value with one decimal place using the locale's
+// decimal separator, followed by its unit when one is given.
+func (r *Renderer) formatNumber(value float64, unit ...string) string {
+	formatted := r.opts.Locale.FormatNumber(value, 1)
+	if len(unit) > 0 && unit[0] != "" {
+		formatted += " " + unit[0]
+	}
+	return formatted
+}
+
+// formatDelta formats a change like formatNumber, with a sign in front of
+// increases.
+func (r *Renderer) formatDelta(value float64, unit string) string {
+	if value > 0 {
+		return "+" + r.formatNumber(value, unit)
+	}
+	return r.formatNumber(value, unit)
+}
+
+// formatClock formats the time of day, or a dash for an event that does not
+// occur.
+func (r *Renderer) formatClock(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+	return r.opts.Locale.FormatClock(t)
+}
+
+// formatSpan formats an interval as "start–end", or a dash when it does not
+// occur.
+func (r *Renderer) formatSpan(interval astro.Interval) string {
+	if interval.IsZero() {
+		return "—"
+	}
+	return r.formatClock(interval.Start) + "–" + r.formatClock(interval.End)
+}
+
+// formatDuration formats a duration in hours and minutes, e.g. "16h 38m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatLead formats how far ahead of its fetch a forecast applies, e.g.
+// "+2d 6h" or "-3h".
+func formatLead(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Round(time.Hour)
+	days, hours := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour)
+	if days > 0 {
+		return fmt.Sprintf("%s%dd %dh", sign, days, hours)
+	}
+	return fmt.Sprintf("%s%dh", sign, hours)
+}
+
+// formatPercent formats a 0-100 value as a whole percentage.
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.0f%%", value)
+}
+
+// formatShare formats a 0-1 fraction as a whole percentage.
+func formatShare(fraction float64) string {
+	return formatPercent(fraction * 100)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+)
+
+// escapeMarkdown escapes characters that would otherwise be interpreted as
+// Markdown formatting or break table cells.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
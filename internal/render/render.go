@@ -0,0 +1,120 @@
+// Package render turns weather reports into text, JSON or Markdown output.
+package render
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/mohithbuilds/sky/internal/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Format is an output format supported by the Renderer.
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+)
+
+// ParseFormat converts a user supplied format name into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want text, json or markdown)", name)
+	}
+}
+
+// Options controls how reports are rendered.
+type Options struct {
+	Format Format
+	// Compact renders fewer details, e.g. fewer table columns in Markdown.
+	Compact bool
+	// Emoji prefixes conditions with weather icons.
+	Emoji bool
+	// Locale formats dates, times and numbers. It defaults to i18n.Default.
+	Locale *i18n.Locale
+}
+
+// Report is implemented by every value that can be rendered. View names the
+// template used for the text and Markdown formats.
+type Report interface {
+	View() string
+}
+
+// Renderer renders reports in a single output format.
+type Renderer struct {
+	opts      Options
+	templates *template.Template
+}
+
+// New creates a Renderer for the given options.
+func New(opts Options) (*Renderer, error) {
+	if opts.Format == "" {
+		opts.Format = FormatText
+	}
+	if opts.Locale == nil {
+		opts.Locale = i18n.Default
+	}
+
+	r := &Renderer{opts: opts}
+	if opts.Format == FormatJSON {
+		return r, nil
+	}
+
+	tmpl, err := template.New(string(opts.Format)).
+		Funcs(r.funcs()).
+		ParseFS(templateFS, "templates/"+string(opts.Format)+"/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s templates: %w", opts.Format, err)
+	}
+	r.templates = tmpl
+
+	return r, nil
+}
+
+// Render writes the report to w.
+func (r *Renderer) Render(w io.Writer, report Report) error {
+	if r.opts.Format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode %s report: %w", report.View(), err)
+		}
+		return nil
+	}
+
+	tmpl := r.templates.Lookup(report.View() + ".tmpl")
+	if tmpl == nil {
+		return fmt.Errorf("no %s template for %s report", r.opts.Format, report.View())
+	}
+
+	// Text templates separate columns with tabs and rely on the tabwriter
+	// to align them.
+	if r.opts.Format == FormatText {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if err := tmpl.Execute(tw, report); err != nil {
+			return fmt.Errorf("failed to render %s report: %w", report.View(), err)
+		}
+		return tw.Flush()
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render %s report: %w", report.View(), err)
+	}
+	return nil
+}
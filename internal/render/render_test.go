@@ -0,0 +1,606 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/astro"
+	"github.com/mohithbuilds/sky/internal/diff"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/i18n"
+	"github.com/mohithbuilds/sky/internal/verify"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+var testPlace = Place{
+	Name:      "Berlin",
+	Country:   "Deutschland",
+	Latitude:  52.52,
+	Longitude: 13.41,
+	Timezone:  "Europe/Berlin",
+}
+
+var testUnits = weather.Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"}
+
+func testHourlyReport() HourlyReport {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	return HourlyReport{
+		Place: testPlace,
+		Hours: []weather.HourlyForecast{
+			{
+				DateTime:           start,
+				Temperature:        10.0,
+				PrecipitationProb:  20,
+				WeatherDescription: "Overcast",
+				Condition:          weather.ConditionForCode(3, ""),
+				IsDay:              1,
+				Units:              testUnits,
+			},
+			{
+				DateTime:           start.Add(time.Hour),
+				Temperature:        11.5,
+				PrecipitationProb:  70,
+				WeatherDescription: "Rain | moderate",
+				Condition:          weather.ConditionForCode(63, ""),
+				IsDay:              1,
+				Units:              testUnits,
+			},
+		},
+	}
+}
+
+func renderString(t *testing.T, opts Options, report Report) string {
+	t.Helper()
+	renderer, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, report); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"":         FormatText,
+		"text":     FormatText,
+		"JSON":     FormatJSON,
+		"markdown": FormatMarkdown,
+		"md":       FormatMarkdown,
+	}
+	for input, expected := range tests {
+		format, err := ParseFormat(input)
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("Expected ParseFormat(%q) to be '%s', got '%s'", input, expected, format)
+		}
+	}
+
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("Expected an error for unknown format, but got nil")
+	}
+}
+
+func TestRenderMarkdown_HourlyDetailed(t *testing.T) {
+	output := renderString(t, Options{Format: FormatMarkdown}, testHourlyReport())
+
+	if !strings.HasPrefix(output, "### Hourly forecast for Berlin, Deutschland\n") {
+		t.Errorf("Expected a heading, got:\n%s", output)
+	}
+	if !strings.Contains(output, "| Time | Conditions | Temp | Feels like |") {
+		t.Errorf("Expected the detailed table header, got:\n%s", output)
+	}
+	if !strings.Contains(output, `| Sun 13:00 | Rain \| moderate | 11.5 °C |`) {
+		t.Errorf("Expected an escaped row for 13:00, got:\n%s", output)
+	}
+	if strings.Contains(output, "🌧️") {
+		t.Errorf("Expected no emoji without the Emoji option, got:\n%s", output)
+	}
+}
+
+func TestRenderMarkdown_HourlyCompactWithEmoji(t *testing.T) {
+	output := renderString(
+		t,
+		Options{Format: FormatMarkdown, Compact: true, Emoji: true},
+		testHourlyReport(),
+	)
+
+	if !strings.Contains(output, "| Time | Conditions | Temp | Chance |\n") {
+		t.Errorf("Expected the compact table header, got:\n%s", output)
+	}
+	if strings.Contains(output, "Feels like") {
+		t.Errorf("Expected no detailed columns in compact output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "| Sun 12:00 | ☁️ Overcast | 10.0 °C | 20% |") {
+		t.Errorf("Expected an emoji row for 12:00, got:\n%s", output)
+	}
+	if !strings.Contains(output, "🌧️ Rain") {
+		t.Errorf("Expected a rain emoji, got:\n%s", output)
+	}
+}
+
+func TestRenderMarkdown_CurrentHeadline(t *testing.T) {
+	report := CurrentReport{
+		Place: testPlace,
+		Current: &weather.CurrentWeather{
+			Temperature:         10.0,
+			ApparentTemperature: 8.0,
+			WindSpeed:           5.0,
+			WindDirection:       225,
+			WeatherDescription:  "Clear sky",
+			Condition:           weather.ConditionForCode(0, ""),
+			IsDay:               1,
+			Units:               testUnits,
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown, Compact: true, Emoji: true}, report)
+	expected := "**Berlin, Deutschland** — ☀️ Clear sky, **10.0 °C** (feels like 8.0 °C), wind 5.0 km/h SW\n"
+	if output != expected {
+		t.Errorf("Expected headline %q, got %q", expected, output)
+	}
+}
+
+func TestRenderMarkdown_AirDetailed(t *testing.T) {
+	report := AirReport{
+		Place: testPlace,
+		AirQuality: &weather.AirQuality{
+			PM25:  8.0,
+			PM10:  12.0,
+			USAQI: 42,
+			Units: weather.AirQualityUnits{PM25: "μg/m³", PM10: "μg/m³"},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown}, report)
+	if !strings.Contains(output, "**Good** (US AQI 42, European AQI 0)") {
+		t.Errorf("Expected the AQI headline, got:\n%s", output)
+	}
+	if !strings.Contains(output, "- PM2.5: 8.0 μg/m³\n") {
+		t.Errorf("Expected a PM2.5 bullet, got:\n%s", output)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	output := renderString(t, Options{Format: FormatJSON}, testHourlyReport())
+
+	var decoded struct {
+		Place  Place `json:"place"`
+		Hourly []struct {
+			Temperature float64 `json:"temperature"`
+		} `json:"hourly"`
+	}
+	if err := json.Unmarshal([]byte(output), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if decoded.Place.Name != "Berlin" {
+		t.Errorf("Expected place name 'Berlin', got '%s'", decoded.Place.Name)
+	}
+	if len(decoded.Hourly) != 2 || decoded.Hourly[1].Temperature != 11.5 {
+		t.Errorf("Expected two hours with 11.5 last, got %+v", decoded.Hourly)
+	}
+}
+
+func TestRenderText_Daily(t *testing.T) {
+	report := DailyReport{
+		Place: testPlace,
+		Days: []weather.DailyForecast{
+			{
+				Date:               time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				MaxTemperature:     12.0,
+				MinTemperature:     2.0,
+				WeatherDescription: "Overcast",
+				Sunrise:            time.Date(2023, 1, 1, 7, 0, 0, 0, time.UTC),
+				Sunset:             time.Date(2023, 1, 1, 17, 0, 0, 0, time.UTC),
+				Units:              testUnits,
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	if !strings.Contains(output, "Sun 01 Jan") || !strings.Contains(output, "12.0 °C") {
+		t.Errorf("Expected the day row, got:\n%s", output)
+	}
+	if !strings.Contains(output, "07:00") || !strings.Contains(output, "17:00") {
+		t.Errorf("Expected sunrise and sunset, got:\n%s", output)
+	}
+	if !strings.Contains(output, "—") {
+		t.Errorf("Expected a dash for the missing golden hour, got:\n%s", output)
+	}
+}
+
+func TestRenderText_Source(t *testing.T) {
+	day := weather.DailyForecast{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Units: testUnits}
+	if output := renderString(t, Options{Format: FormatText}, DailyReport{Place: testPlace, Days: []weather.DailyForecast{day}}); strings.Contains(output, "Source") {
+		t.Errorf("Expected no source without a failover chain, got:\n%s", output)
+	}
+
+	day.Source = "metno"
+	output := renderString(t, Options{Format: FormatText}, DailyReport{Place: testPlace, Days: []weather.DailyForecast{day}})
+	if !strings.Contains(output, "Source: metno") {
+		t.Errorf("Expected the provider that served the forecast, got:\n%s", output)
+	}
+	output = renderString(t, Options{Format: FormatMarkdown}, CurrentReport{
+		Place:   testPlace,
+		Current: &weather.CurrentWeather{Units: testUnits, Source: "nws"},
+	})
+	if !strings.Contains(output, "- Source: nws") {
+		t.Errorf("Expected the provider of the current weather, got:\n%s", output)
+	}
+}
+
+func TestRenderText_Providers(t *testing.T) {
+	report := ProvidersReport{
+		Place: testPlace,
+		Providers: []weather.ProviderHealth{
+			{Name: "openmeteo", State: weather.CircuitOpen, Failures: 3, LastError: "connection refused"},
+			{Name: "metno", State: weather.CircuitClosed},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a title, a header and two rows, got:\n%s", output)
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 6 || fields[1] != "failing" || fields[2] != "open" || fields[3] != "3" {
+		t.Errorf("Expected a failing provider with an open circuit, got %q", lines[2])
+	}
+	if fields := strings.Fields(lines[3]); len(fields) != 5 || fields[1] != "ok" || fields[4] != "—" {
+		t.Errorf("Expected a healthy provider, got %q", lines[3])
+	}
+}
+
+func TestRenderText_Consensus(t *testing.T) {
+	hour := weather.ConsensusHour{
+		DateTime:      time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+		Sources:       []string{"openmeteo/icon_seamless", "metno"},
+		Temperature:   weather.Spread{Value: 21, Min: 18, Max: 24},
+		Precipitation: weather.Spread{Value: 1, Min: 0, Max: 2},
+		Condition:     weather.ConditionForCode(61, ""),
+		Disagreement:  []string{"temperature", "precipitation"},
+		Units:         testUnits,
+	}
+	calm := hour
+	calm.DateTime = hour.DateTime.Add(time.Hour)
+	calm.Disagreement = nil
+	report := ConsensusReport{
+		Place:   testPlace,
+		Sources: hour.Sources,
+		Blend:   "mean",
+		Hours:   []weather.ConsensusHour{hour, calm},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	if !strings.Contains(output, "consensus of 2 forecasts (mean)") || !strings.Contains(output, "Sources: openmeteo/icon_seamless, metno") {
+		t.Errorf("Expected the sources and blend, got:\n%s", output)
+	}
+	if !strings.Contains(output, "18.0–24.0") || !strings.Contains(output, "temperature, precipitation") {
+		t.Errorf("Expected the range and the disagreement, got:\n%s", output)
+	}
+
+	output = renderString(t, Options{Format: FormatMarkdown}, report)
+	if !strings.Contains(output, "| **temperature, precipitation** |") || !strings.Contains(output, "| — |") {
+		t.Errorf("Expected the disagreement to be highlighted, got:\n%s", output)
+	}
+}
+
+func TestRenderMarkdown_DailyAstronomy(t *testing.T) {
+	date := time.Date(2024, 4, 23, 0, 0, 0, 0, time.UTC)
+	report := DailyReport{
+		Place: testPlace,
+		Days: []weather.DailyForecast{
+			{
+				Date:      date,
+				Astronomy: astro.ForDay(testPlace.AstroLocation(), date),
+				Units:     testUnits,
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown, Emoji: true}, report)
+	if !strings.Contains(output, "🌕 Full moon (100%)") {
+		t.Errorf("Expected the full moon, got:\n%s", output)
+	}
+	golden := report.Days[0].Astronomy.Sun.EveningGoldenHour
+	span := golden.Start.Format("15:04") + "–" + golden.End.Format("15:04")
+	if !strings.Contains(output, span) {
+		t.Errorf("Expected the evening golden hour %s, got:\n%s", span, output)
+	}
+}
+
+func TestRenderText_CurrentComfort(t *testing.T) {
+	report := CurrentReport{
+		Place: testPlace,
+		Current: &weather.CurrentWeather{
+			Temperature:        -10.0,
+			Humidity:           70,
+			WindSpeed:          20.0,
+			WeatherDescription: "Snow",
+			Condition:          weather.ConditionForCode(73, ""),
+			Units:              testUnits,
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	if !strings.Contains(output, "Wind chill:") || !strings.Contains(output, "-17.9 °C (Moderate)") {
+		t.Errorf("Expected the wind chill row, got:\n%s", output)
+	}
+	if strings.Contains(output, "Heat index:") {
+		t.Errorf("Expected no heat index row in the cold, got:\n%s", output)
+	}
+}
+
+func TestRenderMarkdown_StarsCompact(t *testing.T) {
+	best := weather.ObservingHour{
+		Time:   time.Date(2024, 4, 8, 23, 0, 0, 0, time.UTC),
+		Score:  97,
+		Rating: "Excellent",
+	}
+	report := StarsReport{
+		Place: testPlace,
+		Nights: []weather.ObservingNight{
+			{
+				Date:  time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC),
+				Score: 88,
+				Hours: []weather.ObservingHour{best},
+				Best:  best,
+				Moon:  astro.Phase(time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown, Compact: true, Emoji: true}, report)
+	expected := "- Mon 08 Apr: **88** (Excellent), best at 23:00, 🌑 New moon\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected %q, got:\n%s", expected, output)
+	}
+
+	empty := renderString(t, Options{Format: FormatText}, StarsReport{Place: testPlace})
+	if !strings.Contains(empty, "No dark hours") {
+		t.Errorf("Expected a note about missing dark hours, got:\n%s", empty)
+	}
+}
+
+func TestRender_OfficialAlerts(t *testing.T) {
+	report := OfficialAlertsReport{
+		Place: testPlace,
+		Alerts: []weather.Alert{{
+			Event:    "Flood Watch",
+			Severity: "Severe",
+			Urgency:  "Expected",
+			Sender:   "NWS New York NY",
+			Onset:    time.Date(2023, 6, 1, 11, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	for _, expected := range []string{"Flood Watch", "Thu 01 Jun 11:00 – further notice", "NWS New York NY"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+
+	output = renderString(t, Options{Format: FormatMarkdown}, report)
+	if !strings.Contains(output, "| Flood Watch | Severe | Expected |") {
+		t.Errorf("Expected a markdown row for the alert, got:\n%s", output)
+	}
+
+	empty := renderString(t, Options{Format: FormatText}, OfficialAlertsReport{Place: testPlace})
+	if !strings.Contains(empty, "No official alerts in effect.") {
+		t.Errorf("Expected a note that there are no alerts, got:\n%s", empty)
+	}
+}
+
+func TestRenderText_Alerts(t *testing.T) {
+	report := AlertsReport{
+		Place: testPlace,
+		Results: []alerts.Result{
+			{
+				Rule:      "rain soon",
+				Source:    alerts.SourceHourly,
+				Fired:     true,
+				Value:     75,
+				Threshold: 60,
+				Unit:      "%",
+				Margin:    15,
+				First:     time.Date(2023, 6, 1, 11, 0, 0, 0, time.UTC),
+				Last:      time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+			},
+			{
+				Rule:      "heavy rain",
+				Source:    alerts.SourceDaily,
+				Value:     4,
+				Threshold: 10,
+				Unit:      "mm",
+				Margin:    -6,
+				First:     time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC),
+				Last:      time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC),
+			},
+			{Rule: "smog", Source: alerts.SourceAir, Error: "no air quality reading available"},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatText}, report)
+	for _, expected := range []string{
+		"FIRED",
+		"75.0 %",
+		"Thu 11:00 – Thu 12:00",
+		"Fri 02 Jun\n",
+		"no air quality reading available",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "smog") && strings.ContainsAny(line, "0123456789") {
+			t.Errorf("Expected no values for the rule that failed, got %q", line)
+		}
+	}
+}
+
+func TestRenderMarkdown_History(t *testing.T) {
+	issued := time.Date(2023, 6, 1, 6, 0, 0, 0, time.UTC)
+	report := HistoryReport{
+		Place: testPlace,
+		Since: issued.Add(-24 * time.Hour),
+		Points: []history.Point{
+			{
+				Kind:     history.KindObservation,
+				Variable: "temperature",
+				Issued:   issued,
+				Valid:    issued,
+				Value:    14.2,
+				Unit:     "°C",
+			},
+			{
+				Kind:     history.KindHourly,
+				Variable: "temperature",
+				Issued:   issued,
+				Valid:    issued.Add(30 * time.Hour),
+				Value:    19,
+				Unit:     "°C",
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown}, report)
+	for _, expected := range []string{
+		"| Valid | Kind | Variable | Value | Fetched | Lead |",
+		"| observation | temperature | 14.2 °C |",
+		"| hourly | temperature | 19.0 °C |",
+		"| +1d 6h |",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderMarkdown_Verify(t *testing.T) {
+	report := VerifyReport{
+		Place: testPlace,
+		Since: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		Report: verify.Report{
+			Models:  []string{"", "gfs_seamless"},
+			Matched: 40,
+			Scores: []verify.Score{
+				{Variable: "temperature", Lead: "0-6h", Unit: "°C", Count: 24, Bias: -0.4, MAE: 1.2, RMSE: 1.5},
+				{Model: "gfs_seamless", Variable: "temperature", Lead: "0-6h", Unit: "°C", Count: 16, Bias: 0.8},
+			},
+			Precipitation: []verify.Contingency{
+				{Lead: "0-6h", Hits: 3, Misses: 1, FalseAlarms: 1, CorrectNegatives: 19},
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown}, report)
+	for _, expected := range []string{
+		"40 forecasts compared",
+		"| default | temperature | 0-6h | 24 | -0.4 °C | 1.2 °C | 1.5 °C |",
+		"| gfs\\_seamless | temperature |",
+		"| default | 0-6h | 3 | 1 | 1 | 19 | 75% | 25% | 25% |",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderMarkdown_Diff(t *testing.T) {
+	saturday := time.Date(2023, 6, 17, 0, 0, 0, 0, time.UTC)
+	report := DiffReport{
+		Place:    testPlace,
+		Kind:     diff.KindDaily,
+		Previous: true,
+		Result: diff.Result{
+			Before:   saturday.AddDate(0, 0, -2),
+			After:    saturday.AddDate(0, 0, -1),
+			Compared: 7,
+			Changes: []diff.Change{
+				{Time: saturday, Variable: "temperature_max", Before: 22, After: 18, Unit: "°C"},
+				{Time: saturday, Variable: "precipitation_probability", Before: 10, After: 70, Unit: "%"},
+				{
+					Time:            saturday,
+					Variable:        diff.VariableCondition,
+					Before:          1,
+					After:           63,
+					BeforeCondition: "Mainly clear",
+					AfterCondition:  "Moderate rain",
+				},
+			},
+		},
+	}
+
+	output := renderString(t, Options{Format: FormatMarkdown}, report)
+	for _, expected := range []string{
+		"### Daily forecast changes for",
+		"(7 days)",
+		"| temperature\\_max | 22.0 °C | 18.0 °C | -4.0 °C |",
+		"| precipitation\\_probability | 10.0 % | 70.0 % | +60.0 % |",
+		"| condition | Mainly clear | Moderate rain | — |",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+
+	report.Previous = false
+	output = renderString(t, Options{Format: FormatText}, report)
+	if !strings.Contains(output, "No earlier forecast to compare with yet.") {
+		t.Errorf("Expected a note that there is nothing to compare with, got:\n%s", output)
+	}
+}
+
+func TestTemplatesExistForEveryReport(t *testing.T) {
+	reports := []Report{
+		CurrentReport{},
+		HourlyReport{},
+		DailyReport{},
+		AirReport{},
+		SearchReport{},
+		SummaryReport{},
+		StarsReport{},
+		PhotoReport{},
+		AlertsReport{},
+		OfficialAlertsReport{},
+		HistoryReport{},
+		VerifyReport{},
+		DiffReport{},
+		ProvidersReport{},
+		ConsensusReport{},
+	}
+
+	for _, format := range []Format{FormatText, FormatMarkdown} {
+		renderer, err := New(Options{Format: format})
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", format, err)
+		}
+		for _, report := range reports {
+			if renderer.templates.Lookup(report.View()+".tmpl") == nil {
+				t.Errorf("Missing %s template for the %s report", format, report.View())
+			}
+		}
+	}
+}
+
+func TestRenderMarkdown_Localized(t *testing.T) {
+	output := renderString(
+		t,
+		Options{Format: FormatMarkdown, Compact: true, Locale: i18n.Lookup("de")},
+		testHourlyReport(),
+	)
+
+	if !strings.Contains(output, "| So 13:00 | Rain \\| moderate | 11,5 °C | 70% |") {
+		t.Errorf("Expected a German weekday and decimal comma, got:\n%s", output)
+	}
+}
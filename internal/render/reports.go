@@ -0,0 +1,194 @@
+package render
+
+import (
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/alerts"
+	"github.com/mohithbuilds/sky/internal/astro"
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/diff"
+	"github.com/mohithbuilds/sky/internal/history"
+	"github.com/mohithbuilds/sky/internal/verify"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Place describes the location a report was produced for.
+type Place struct {
+	Name      string  `json:"name"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone,omitempty"`
+}
+
+// PlaceFromLocation converts a geocoding result into a Place.
+func PlaceFromLocation(location *openmateo.Location) Place {
+	return Place{
+		Name:      location.Name,
+		Country:   location.Country,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Timezone:  location.Timezone,
+	}
+}
+
+// Title returns "Name, Country", or just the name when the country is unknown.
+func (p Place) Title() string {
+	if p.Country == "" {
+		return p.Name
+	}
+	return p.Name + ", " + p.Country
+}
+
+// AstroLocation returns the place's coordinates for astronomical calculations.
+func (p Place) AstroLocation() astro.Location {
+	return astro.Location{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// CurrentReport holds the current conditions for a place.
+type CurrentReport struct {
+	Place   Place                   `json:"place"`
+	Current *weather.CurrentWeather `json:"current"`
+}
+
+func (CurrentReport) View() string { return "current" }
+
+// HourlyReport holds the hourly forecast for a place.
+type HourlyReport struct {
+	Place Place                    `json:"place"`
+	Hours []weather.HourlyForecast `json:"hourly"`
+}
+
+func (HourlyReport) View() string { return "hourly" }
+
+// Source returns the provider that served the forecast, if known.
+func (r HourlyReport) Source() string {
+	if len(r.Hours) == 0 {
+		return ""
+	}
+	return r.Hours[0].Source
+}
+
+// DailyReport holds the daily forecast for a place.
+type DailyReport struct {
+	Place Place                   `json:"place"`
+	Days  []weather.DailyForecast `json:"daily"`
+}
+
+func (DailyReport) View() string { return "daily" }
+
+// Source returns the provider that served the forecast, if known.
+func (r DailyReport) Source() string {
+	if len(r.Days) == 0 {
+		return ""
+	}
+	return r.Days[0].Source
+}
+
+// AirReport holds the current air quality for a place.
+type AirReport struct {
+	Place      Place               `json:"place"`
+	AirQuality *weather.AirQuality `json:"air_quality"`
+}
+
+func (AirReport) View() string { return "air" }
+
+// SearchReport holds the result of a location search.
+type SearchReport struct {
+	Place      Place   `json:"place"`
+	Elevation  float64 `json:"elevation"`
+	Population int     `json:"population"`
+}
+
+func (SearchReport) View() string { return "search" }
+
+// SummaryReport holds a natural-language summary of the hourly forecast.
+type SummaryReport struct {
+	Place   Place           `json:"place"`
+	Summary weather.Summary `json:"summary"`
+}
+
+func (SummaryReport) View() string { return "summary" }
+
+// StarsReport ranks the coming nights at a place for stargazing.
+type StarsReport struct {
+	Place  Place                    `json:"place"`
+	Nights []weather.ObservingNight `json:"nights"`
+}
+
+func (StarsReport) View() string { return "stars" }
+
+// PhotoReport scores the coming golden hours at a place for photography.
+type PhotoReport struct {
+	Place   Place                      `json:"place"`
+	Windows []weather.GoldenHourWindow `json:"golden_hours"`
+}
+
+func (PhotoReport) View() string { return "photo" }
+
+// AlertsReport lists the outcome of evaluating alert rules for a place.
+type AlertsReport struct {
+	Place   Place           `json:"place"`
+	Results []alerts.Result `json:"results"`
+}
+
+func (AlertsReport) View() string { return "alerts" }
+
+// OfficialAlertsReport lists the alerts a weather service has issued for a
+// place.
+type OfficialAlertsReport struct {
+	Place  Place           `json:"place"`
+	Alerts []weather.Alert `json:"alerts"`
+}
+
+func (OfficialAlertsReport) View() string { return "official" }
+
+// HistoryReport lists recorded observations and forecasts for a place.
+type HistoryReport struct {
+	Place  Place           `json:"place"`
+	Since  time.Time       `json:"since"`
+	Points []history.Point `json:"points"`
+}
+
+func (HistoryReport) View() string { return "history" }
+
+// VerifyReport scores the forecasts recorded for a place against what
+// happened.
+type VerifyReport struct {
+	Place  Place         `json:"place"`
+	Since  time.Time     `json:"since"`
+	Report verify.Report `json:"verification"`
+}
+
+func (VerifyReport) View() string { return "verify" }
+
+// DiffReport lists how the forecast for a place changed since an earlier
+// fetch. Previous is false when there was nothing to compare with yet.
+type DiffReport struct {
+	Place    Place       `json:"place"`
+	Kind     diff.Kind   `json:"kind"`
+	Previous bool        `json:"previous"`
+	Result   diff.Result `json:"result"`
+}
+
+func (DiffReport) View() string { return "diff" }
+
+// ProvidersReport holds the health of the weather providers after checking
+// them at a place.
+type ProvidersReport struct {
+	Place     Place                    `json:"place"`
+	Providers []weather.ProviderHealth `json:"providers"`
+}
+
+func (ProvidersReport) View() string { return "providers" }
+
+// ConsensusReport holds a forecast blended from several providers and
+// models.
+type ConsensusReport struct {
+	Place   Place                   `json:"place"`
+	Sources []string                `json:"sources"`
+	Blend   string                  `json:"blend"` // "mean" or "median"
+	Hours   []weather.ConsensusHour `json:"hourly"`
+}
+
+func (ConsensusReport) View() string { return "consensus" }
@@ -0,0 +1,375 @@
+// Package server exposes the weather package over an HTTP JSON API.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// Weather fetches the weather. *weather.WeatherClient implements it.
+type Weather interface {
+	GetCurrentWeather(
+		latitude, longitude float64,
+		tempUnit, windUnit, precipUnit string,
+	) (*weather.CurrentWeather, error)
+	GetHourlyForecast(
+		latitude, longitude float64,
+		numHours int64,
+		tempUnit, windUnit, precipUnit string,
+	) ([]weather.HourlyForecast, error)
+	GetDailyForecast(
+		latitude, longitude float64,
+		numDays int64,
+		tempUnit, windUnit, precipUnit string,
+	) ([]weather.DailyForecast, error)
+	GetCurrentAirQuality(latitude, longitude float64) (*weather.AirQuality, error)
+}
+
+// Geocoder looks up places by name. *openmateo.GeocodingClient implements it.
+type Geocoder interface {
+	Search(name string) (*openmateo.Location, error)
+}
+
+// Providers reports the health of the weather providers the server fails
+// over between. *weather.Failover implements it.
+type Providers interface {
+	Health() []weather.ProviderHealth
+}
+
+// DefaultListen is the address the API is served on by default.
+const DefaultListen = ":8080"
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Limits of the horizon query parameters, matching the forecast API.
+const (
+	maxHours = 16 * 24
+	maxDays  = 16
+)
+
+// Server serves the API. It is ready from the start; SetReady(false) makes
+// /readyz fail so that load balancers stop sending requests before a
+// shutdown.
+type Server struct {
+	Weather  Weather
+	Geocoder Geocoder
+	Log      *log.Logger
+	// Providers, when set, is reported by /v1/providers.
+	Providers Providers
+
+	mux   *http.ServeMux
+	ready atomic.Bool
+}
+
+// New returns a Server that logs each request to logger.
+func New(w Weather, geocoder Geocoder, logger *log.Logger) *Server {
+	s := &Server{Weather: w, Geocoder: geocoder, Log: logger, mux: http.NewServeMux()}
+	s.ready.Store(true)
+
+	s.mux.HandleFunc("GET /v1/current", s.handleCurrent)
+	s.mux.HandleFunc("GET /v1/hourly", s.handleHourly)
+	s.mux.HandleFunc("GET /v1/daily", s.handleDaily)
+	s.mux.HandleFunc("GET /v1/air", s.handleAir)
+	s.mux.HandleFunc("GET /v1/search", s.handleSearch)
+	s.mux.HandleFunc("GET /v1/providers", s.handleProviders)
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, status{Status: "ok"})
+	})
+	s.mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			writeJSON(w, http.StatusServiceUnavailable, status{Status: "shutting down"})
+			return
+		}
+		writeJSON(w, http.StatusOK, status{Status: "ready"})
+	})
+	return s
+}
+
+// SetReady sets what /readyz reports.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// ServeHTTP serves a request and logs it.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(recorder, r)
+	s.Log.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), recorder.status, time.Since(start).Round(time.Millisecond))
+}
+
+// statusRecorder remembers the status written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+type status struct {
+	Status string `json:"status"`
+}
+
+// ProvidersResponse is the body of /v1/providers.
+type ProvidersResponse struct {
+	Providers []weather.ProviderHealth `json:"providers"`
+}
+
+// Error is the body of every failed request.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Location is the place a response is for.
+type Location struct {
+	Name      string  `json:"name,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone,omitempty"`
+}
+
+// Response is the body of the weather endpoints. Only the field of the
+// endpoint is set.
+type Response struct {
+	Location Location                 `json:"location"`
+	Units    string                   `json:"units,omitempty"`
+	Current  *weather.CurrentWeather  `json:"current,omitempty"`
+	Hourly   []weather.HourlyForecast `json:"hourly,omitempty"`
+	Daily    []weather.DailyForecast  `json:"daily,omitempty"`
+	Air      *weather.AirQuality      `json:"air_quality,omitempty"`
+}
+
+// httpError is an error with the status it is reported with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+// fail writes an error. Errors from the upstream API are reported as a bad
+// gateway.
+func fail(w http.ResponseWriter, err error) {
+	var he *httpError
+	switch {
+	case errors.As(err, &he):
+		writeJSON(w, he.status, Error{he.Error()})
+	case errors.Is(err, openmateo.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, Error{err.Error()})
+	default:
+		writeJSON(w, http.StatusBadGateway, Error{err.Error()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// request is the parsed query of a weather endpoint.
+type request struct {
+	location                       Location
+	units                          string
+	tempUnit, windUnit, precipUnit string
+}
+
+// parse reads the location and units of a weather request: either place, or
+// latitude and longitude, and units of metric (the default) or imperial.
+func (s *Server) parse(r *http.Request) (*request, error) {
+	query := r.URL.Query()
+	req := &request{}
+
+	switch units := strings.ToLower(query.Get("units")); units {
+	case "", "metric":
+		req.units, req.tempUnit, req.windUnit, req.precipUnit = "metric", "celsius", "kmh", "mm"
+	case "imperial":
+		req.units, req.tempUnit, req.windUnit, req.precipUnit = "imperial", "fahrenheit", "mph", "inch"
+	default:
+		return nil, badRequest("unknown units %q (want metric or imperial)", units)
+	}
+
+	if place := strings.TrimSpace(query.Get("place")); place != "" {
+		found, err := s.Geocoder.Search(place)
+		if err != nil {
+			return nil, err
+		}
+		req.location = Location{
+			Name:      found.Name,
+			Country:   found.Country,
+			Latitude:  found.Latitude,
+			Longitude: found.Longitude,
+			Timezone:  found.Timezone,
+		}
+		return req, nil
+	}
+
+	if query.Get("latitude") == "" || query.Get("longitude") == "" {
+		return nil, badRequest("pass place, or latitude and longitude")
+	}
+	latitude, err := floatParam(query.Get("latitude"), "latitude", -90, 90)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := floatParam(query.Get("longitude"), "longitude", -180, 180)
+	if err != nil {
+		return nil, err
+	}
+	req.location = Location{Latitude: latitude, Longitude: longitude}
+	return req, nil
+}
+
+func floatParam(value, name string, min, max float64) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < min || f > max {
+		return 0, badRequest("%s must be a number from %g to %g, got %q", name, min, max, value)
+	}
+	return f, nil
+}
+
+// intParam reads an optional whole number query parameter.
+func intParam(r *http.Request, name string, fallback, min, max int64) (int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < min || n > max {
+		return 0, badRequest("%s must be a whole number from %d to %d, got %q", name, min, max, value)
+	}
+	return n, nil
+}
+
+func (s *Server) handleCurrent(w http.ResponseWriter, r *http.Request) {
+	req, err := s.parse(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	current, err := s.Weather.GetCurrentWeather(
+		req.location.Latitude,
+		req.location.Longitude,
+		req.tempUnit,
+		req.windUnit,
+		req.precipUnit,
+	)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Location: req.location, Units: req.units, Current: current})
+}
+
+func (s *Server) handleHourly(w http.ResponseWriter, r *http.Request) {
+	hours, err := intParam(r, "hours", 24, 1, maxHours)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	req, err := s.parse(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	hourly, err := s.Weather.GetHourlyForecast(
+		req.location.Latitude,
+		req.location.Longitude,
+		hours,
+		req.tempUnit,
+		req.windUnit,
+		req.precipUnit,
+	)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Location: req.location, Units: req.units, Hourly: hourly})
+}
+
+func (s *Server) handleDaily(w http.ResponseWriter, r *http.Request) {
+	days, err := intParam(r, "days", 7, 1, maxDays)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	req, err := s.parse(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	daily, err := s.Weather.GetDailyForecast(
+		req.location.Latitude,
+		req.location.Longitude,
+		days,
+		req.tempUnit,
+		req.windUnit,
+		req.precipUnit,
+	)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Location: req.location, Units: req.units, Daily: daily})
+}
+
+func (s *Server) handleAir(w http.ResponseWriter, r *http.Request) {
+	req, err := s.parse(r)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	air, err := s.Weather.GetCurrentAirQuality(req.location.Latitude, req.location.Longitude)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Location: req.location, Air: air})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		fail(w, badRequest("name is required"))
+		return
+	}
+	found, err := s.Geocoder.Search(name)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	response := ProvidersResponse{Providers: []weather.ProviderHealth{}}
+	if s.Providers != nil {
+		response.Providers = s.Providers.Health()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
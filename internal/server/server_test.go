@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/weather"
+)
+
+// mockWeather returns fixed weather, or err when set, and remembers the
+// arguments of the last call.
+type mockWeather struct {
+	err        error
+	latitude   float64
+	horizon    int64
+	tempUnit   string
+	windUnit   string
+	precipUnit string
+}
+
+func (m *mockWeather) GetCurrentWeather(lat, lon float64, tempUnit, windUnit, precipUnit string) (*weather.CurrentWeather, error) {
+	m.latitude, m.tempUnit, m.windUnit, m.precipUnit = lat, tempUnit, windUnit, precipUnit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &weather.CurrentWeather{Temperature: 21.5, WeatherDescription: "Clear sky"}, nil
+}
+
+func (m *mockWeather) GetHourlyForecast(lat, lon float64, numHours int64, tempUnit, windUnit, precipUnit string) ([]weather.HourlyForecast, error) {
+	m.latitude, m.horizon, m.tempUnit = lat, numHours, tempUnit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return make([]weather.HourlyForecast, numHours), nil
+}
+
+func (m *mockWeather) GetDailyForecast(lat, lon float64, numDays int64, tempUnit, windUnit, precipUnit string) ([]weather.DailyForecast, error) {
+	m.latitude, m.horizon, m.tempUnit = lat, numDays, tempUnit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return make([]weather.DailyForecast, numDays), nil
+}
+
+func (m *mockWeather) GetCurrentAirQuality(lat, lon float64) (*weather.AirQuality, error) {
+	m.latitude = lat
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &weather.AirQuality{USAQI: 33}, nil
+}
+
+// mockGeocoder knows only Berlin.
+type mockGeocoder struct{}
+
+func (mockGeocoder) Search(name string) (*openmateo.Location, error) {
+	if name != "Berlin" {
+		return nil, fmt.Errorf("%w for %s", openmateo.ErrNotFound, name)
+	}
+	return &openmateo.Location{Name: "Berlin", Country: "Germany", Latitude: 52.52, Longitude: 13.41, Timezone: "Europe/Berlin"}, nil
+}
+
+func newTestServer(w Weather) *Server {
+	return New(w, mockGeocoder{}, log.New(io.Discard, "", 0))
+}
+
+func get(t *testing.T, s *Server, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+	return recorder
+}
+
+func decode[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	t.Helper()
+	var body T
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode %q: %v", recorder.Body.String(), err)
+	}
+	return body
+}
+
+func TestServer_Current(t *testing.T) {
+	w := &mockWeather{}
+	s := newTestServer(w)
+
+	recorder := get(t, s, "/v1/current?place=Berlin")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON, got %q", recorder.Header().Get("Content-Type"))
+	}
+	response := decode[Response](t, recorder)
+	if response.Location.Name != "Berlin" || response.Location.Timezone != "Europe/Berlin" {
+		t.Errorf("Expected the location of Berlin, got %+v", response.Location)
+	}
+	if response.Units != "metric" || w.tempUnit != "celsius" || w.windUnit != "kmh" || w.precipUnit != "mm" {
+		t.Errorf("Expected metric units, got %q (%s, %s, %s)", response.Units, w.tempUnit, w.windUnit, w.precipUnit)
+	}
+	if response.Current == nil || response.Current.Temperature != 21.5 {
+		t.Errorf("Expected the current weather, got %+v", response.Current)
+	}
+	if response.Hourly != nil || response.Air != nil {
+		t.Errorf("Expected only the current weather, got %+v", response)
+	}
+}
+
+func TestServer_Coordinates(t *testing.T) {
+	w := &mockWeather{}
+	s := newTestServer(w)
+
+	recorder := get(t, s, "/v1/daily?latitude=48.85&longitude=2.35&units=imperial&days=3")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	response := decode[Response](t, recorder)
+	if response.Location.Latitude != 48.85 || response.Location.Name != "" {
+		t.Errorf("Expected the coordinates without a name, got %+v", response.Location)
+	}
+	if w.latitude != 48.85 || w.horizon != 3 || w.tempUnit != "fahrenheit" {
+		t.Errorf("Expected 3 days in Fahrenheit at 48.85, got %d in %s at %g", w.horizon, w.tempUnit, w.latitude)
+	}
+	if len(response.Daily) != 3 {
+		t.Errorf("Expected 3 days, got %d", len(response.Daily))
+	}
+}
+
+func TestServer_Defaults(t *testing.T) {
+	w := &mockWeather{}
+	s := newTestServer(w)
+
+	if recorder := get(t, s, "/v1/hourly?place=Berlin"); recorder.Code != http.StatusOK || w.horizon != 24 {
+		t.Errorf("Expected 24 hours by default, got %d hours (status %d)", w.horizon, recorder.Code)
+	}
+	if recorder := get(t, s, "/v1/daily?place=Berlin"); recorder.Code != http.StatusOK || w.horizon != 7 {
+		t.Errorf("Expected 7 days by default, got %d days (status %d)", w.horizon, recorder.Code)
+	}
+	recorder := get(t, s, "/v1/air?place=Berlin")
+	if response := decode[Response](t, recorder); response.Air == nil || response.Air.USAQI != 33 || response.Units != "" {
+		t.Errorf("Expected the air quality without units, got %+v", response)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	upstream := &mockWeather{err: errors.New("API error (500 Internal Server Error)")}
+	tests := []struct {
+		name    string
+		weather *mockWeather
+		target  string
+		status  int
+		message string
+	}{
+		{"no location", &mockWeather{}, "/v1/current", http.StatusBadRequest, "pass place"},
+		{"bad latitude", &mockWeather{}, "/v1/current?latitude=91&longitude=0", http.StatusBadRequest, "latitude must be"},
+		{"bad units", &mockWeather{}, "/v1/current?place=Berlin&units=kelvin", http.StatusBadRequest, "unknown units"},
+		{"too many hours", &mockWeather{}, "/v1/hourly?place=Berlin&hours=1000", http.StatusBadRequest, "hours must be"},
+		{"no days", &mockWeather{}, "/v1/daily?place=Berlin&days=0", http.StatusBadRequest, "days must be"},
+		{"no name", &mockWeather{}, "/v1/search", http.StatusBadRequest, "name is required"},
+		{"unknown place", &mockWeather{}, "/v1/current?place=Atlantis", http.StatusNotFound, "no location found"},
+		{"upstream failure", upstream, "/v1/current?place=Berlin", http.StatusBadGateway, "500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := get(t, newTestServer(tt.weather), tt.target)
+			if recorder.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, recorder.Code)
+			}
+			if body := decode[Error](t, recorder); !strings.Contains(body.Error, tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, body.Error)
+			}
+		})
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	recorder := get(t, newTestServer(&mockWeather{}), "/v1/search?name=Berlin")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if location := decode[openmateo.Location](t, recorder); location.Country != "Germany" {
+		t.Errorf("Expected Berlin in Germany, got %+v", location)
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	s := newTestServer(&mockWeather{})
+
+	if recorder := get(t, s, "/healthz"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected healthz to pass, got %d", recorder.Code)
+	}
+	if recorder := get(t, s, "/readyz"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected readyz to pass, got %d", recorder.Code)
+	}
+	s.SetReady(false)
+	if recorder := get(t, s, "/readyz"); recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readyz to fail while shutting down, got %d", recorder.Code)
+	}
+	if recorder := get(t, s, "/healthz"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected healthz to pass while shutting down, got %d", recorder.Code)
+	}
+}
+
+// mockProviders reports fixed provider health.
+type mockProviders []weather.ProviderHealth
+
+func (m mockProviders) Health() []weather.ProviderHealth {
+	return m
+}
+
+func TestServer_Providers(t *testing.T) {
+	s := newTestServer(&mockWeather{})
+	recorder := get(t, s, "/v1/providers")
+	if recorder.Code != http.StatusOK || strings.TrimSpace(recorder.Body.String()) != `{"providers":[]}` {
+		t.Errorf("Expected no providers without a failover chain, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	s.Providers = mockProviders{
+		{Name: "openmeteo", State: weather.CircuitOpen, Failures: 3, LastError: "connection refused"},
+		{Name: "metno", State: weather.CircuitClosed},
+	}
+	body := decode[ProvidersResponse](t, get(t, s, "/v1/providers"))
+	if len(body.Providers) != 2 || body.Providers[0].State != "open" || body.Providers[0].LastError != "connection refused" {
+		t.Errorf("Expected the health of both providers, got %+v", body.Providers)
+	}
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	recorder := get(t, newTestServer(&mockWeather{}), "/openapi.json")
+	document := decode[struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}](t, recorder)
+	if document.OpenAPI == "" {
+		t.Errorf("Expected an OpenAPI version")
+	}
+	for _, path := range []string{"/v1/current", "/v1/hourly", "/v1/daily", "/v1/air", "/v1/search", "/v1/providers", "/healthz", "/readyz"} {
+		if _, ok := document.Paths[path]; !ok {
+			t.Errorf("Expected %s to be documented", path)
+		}
+	}
+}
+
+func TestServer_Logging(t *testing.T) {
+	var b strings.Builder
+	s := New(&mockWeather{}, mockGeocoder{}, log.New(&b, "", 0))
+	get(t, s, "/v1/current?place=Atlantis")
+	if !strings.HasPrefix(b.String(), "GET /v1/current?place=Atlantis 404 ") {
+		t.Errorf("Expected the request to be logged, got %q", b.String())
+	}
+}
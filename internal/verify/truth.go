@@ -0,0 +1,112 @@
+package verify
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/history"
+)
+
+// truth looks up what happened, from observations and analyses.
+type truth struct {
+	tolerance    time.Duration
+	observations map[string][]history.Point         // By variable, in valid time order
+	analyses     map[string]map[int64]history.Point // By variable and valid Unix time
+}
+
+func newTruth(points []history.Point, tolerance time.Duration) *truth {
+	t := &truth{
+		tolerance:    tolerance,
+		observations: make(map[string][]history.Point),
+		analyses:     make(map[string]map[int64]history.Point),
+	}
+	for _, p := range points {
+		switch p.Kind {
+		case history.KindObservation:
+			t.observations[p.Variable] = append(t.observations[p.Variable], p)
+		case history.KindAnalysis:
+			if t.analyses[p.Variable] == nil {
+				t.analyses[p.Variable] = make(map[int64]history.Point)
+			}
+			// The latest fetch of an hour wins.
+			key := p.Valid.Unix()
+			if previous, ok := t.analyses[p.Variable][key]; !ok || !p.Issued.Before(previous.Issued) {
+				t.analyses[p.Variable][key] = p
+			}
+		}
+	}
+	for _, points := range t.observations {
+		sort.Slice(points, func(i, j int) bool { return points[i].Valid.Before(points[j].Valid) })
+	}
+	return t
+}
+
+// observe returns what was observed for a forecast point.
+func (t *truth) observe(f history.Point) (history.Point, bool) {
+	switch f.Variable {
+	case "temperature_max":
+		return t.daily(f, "temperature", math.Max)
+	case "temperature_min":
+		return t.daily(f, "temperature", math.Min)
+	case "precipitation_sum":
+		return t.daily(f, "precipitation", func(a, b float64) float64 { return a + b })
+	case "precipitation":
+		return t.analysis(f.Variable, f.Valid)
+	}
+	if p, ok := t.nearest(f.Variable, f.Valid); ok {
+		return p, true
+	}
+	return t.analysis(f.Variable, f.Valid)
+}
+
+// nearest returns the observation closest to valid within the tolerance.
+func (t *truth) nearest(variable string, valid time.Time) (history.Point, bool) {
+	points := t.observations[variable]
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Valid.Before(valid) })
+
+	var best history.Point
+	found := false
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(points) {
+			continue
+		}
+		gap := points[j].Valid.Sub(valid).Abs()
+		if gap <= t.tolerance && (!found || gap < best.Valid.Sub(valid).Abs()) {
+			best, found = points[j], true
+		}
+	}
+	return best, found
+}
+
+func (t *truth) analysis(variable string, valid time.Time) (history.Point, bool) {
+	p, ok := t.analyses[variable][valid.Unix()]
+	return p, ok
+}
+
+// minDayHours is how many of a day's hours must have an analysis for the
+// day to be compared.
+const minDayHours = 20
+
+// daily combines the analyses of the day starting at f.Valid.
+func (t *truth) daily(f history.Point, variable string, combine func(a, b float64) float64) (history.Point, bool) {
+	var result history.Point
+	hours := 0
+	for hour := f.Valid; hour.Before(f.Valid.AddDate(0, 0, 1)); hour = hour.Add(time.Hour) {
+		p, ok := t.analysis(variable, hour)
+		if !ok {
+			continue
+		}
+		if hours == 0 {
+			result = p
+		} else {
+			result.Value = combine(result.Value, p.Value)
+		}
+		hours++
+	}
+	if hours < minDayHours {
+		return history.Point{}, false
+	}
+	result.Variable, result.Valid = f.Variable, f.Valid
+	return result, true
+}
@@ -0,0 +1,279 @@
+// Package verify measures how well recorded forecasts matched what
+// happened.
+package verify
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/history"
+)
+
+// Lead is a range of lead times that forecasts are scored together in.
+type Lead struct {
+	Label    string
+	Min, Max time.Duration // Min inclusive, Max exclusive
+}
+
+// Leads are the lead time ranges of a report.
+var Leads = []Lead{
+	{"0-6h", 0, 6 * time.Hour},
+	{"6-12h", 6 * time.Hour, 12 * time.Hour},
+	{"12-24h", 12 * time.Hour, 24 * time.Hour},
+	{"1-2d", 24 * time.Hour, 48 * time.Hour},
+	{"2-3d", 48 * time.Hour, 72 * time.Hour},
+	{"3-5d", 72 * time.Hour, 120 * time.Hour},
+	{"5-7d", 120 * time.Hour, 168 * time.Hour},
+	{"7d+", 168 * time.Hour, math.MaxInt64},
+}
+
+func leadIndex(lead time.Duration) int {
+	for i, l := range Leads {
+		if lead >= l.Min && lead < l.Max {
+			return i
+		}
+	}
+	return -1
+}
+
+// variables are the forecast variables that are scored, in report order.
+// Daily variables are compared with a day of past hours.
+var variables = []string{
+	"temperature",
+	"apparent_temperature",
+	"humidity",
+	"cloud_cover",
+	"wind_speed",
+	"wind_gusts",
+	"precipitation",
+	"temperature_max",
+	"temperature_min",
+	"precipitation_sum",
+}
+
+// Score is the error of the forecasts of one variable at one range of lead
+// times. Errors are forecast minus observed.
+type Score struct {
+	Model    string  `json:"model,omitempty"` // The history.Point Source
+	Variable string  `json:"variable"`
+	Lead     string  `json:"lead"`
+	Unit     string  `json:"unit,omitempty"`
+	Count    int     `json:"count"`
+	Bias     float64 `json:"bias"` // Mean error; positive when forecasts run high
+	MAE      float64 `json:"mae"`  // Mean absolute error
+	RMSE     float64 `json:"rmse"` // Root mean square error
+
+	sum, sumAbs, sumSquares float64
+}
+
+func (s *Score) add(forecast, observed float64) {
+	e := forecast - observed
+	s.Count++
+	s.sum += e
+	s.sumAbs += math.Abs(e)
+	s.sumSquares += e * e
+	n := float64(s.Count)
+	s.Bias, s.MAE, s.RMSE = s.sum/n, s.sumAbs/n, math.Sqrt(s.sumSquares/n)
+}
+
+// Contingency counts yes/no rain forecasts against whether it rained.
+type Contingency struct {
+	Model            string `json:"model,omitempty"` // The history.Point Source
+	Lead             string `json:"lead"`
+	Hits             int    `json:"hits"`
+	Misses           int    `json:"misses"`
+	FalseAlarms      int    `json:"false_alarms"`
+	CorrectNegatives int    `json:"correct_negatives"`
+}
+
+// Total returns the number of forecasts counted.
+func (c Contingency) Total() int {
+	return c.Hits + c.Misses + c.FalseAlarms + c.CorrectNegatives
+}
+
+// HitRate is the probability of detection: the share of rain that was
+// forecast.
+func (c Contingency) HitRate() float64 {
+	return ratio(c.Hits, c.Hits+c.Misses)
+}
+
+// FalseAlarmRate is the share of rain forecasts that stayed dry.
+func (c Contingency) FalseAlarmRate() float64 {
+	return ratio(c.FalseAlarms, c.Hits+c.FalseAlarms)
+}
+
+// MissRate is the share of rain that was not forecast.
+func (c Contingency) MissRate() float64 {
+	return ratio(c.Misses, c.Hits+c.Misses)
+}
+
+// CSI is the critical success index: hits over everything but correct
+// negatives.
+func (c Contingency) CSI() float64 {
+	return ratio(c.Hits, c.Hits+c.Misses+c.FalseAlarms)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Options tune the comparison. Zero fields take the defaults.
+type Options struct {
+	// Tolerance is how far from a forecast's valid time an observation may
+	// be. The default is 30 minutes.
+	Tolerance time.Duration
+	// RainProbability is the precipitation probability, in percent, at which
+	// a forecast counts as forecasting rain. The default is 50.
+	RainProbability float64
+}
+
+// Report is the outcome of a verification.
+type Report struct {
+	Models        []string      `json:"models"`    // Sources with scores, "" for the API's choice
+	Matched       int           `json:"matched"`   // Forecasts compared with an observation
+	Unmatched     int           `json:"unmatched"` // Forecasts without one yet
+	Scores        []Score       `json:"scores"`
+	Precipitation []Contingency `json:"precipitation"`
+}
+
+// Verify compares forecasts with observations. Forecasts are the hourly and
+// daily points of a history; observations are its observation and analysis
+// points. Observations are preferred where one is close enough in time, then
+// the analysis of that hour. Precipitation and daily values are compared
+// with analyses only, since an observation is a single moment.
+//
+// Forecasts are scored by Source, and compared with the observations of
+// their own provider only, so that providers are not scored against each
+// other.
+func Verify(forecasts, observations []history.Point, opts Options) Report {
+	if opts.Tolerance == 0 {
+		opts.Tolerance = 30 * time.Minute
+	}
+	if opts.RainProbability == 0 {
+		opts.RainProbability = 50
+	}
+	byProvider := make(map[string][]history.Point)
+	for _, p := range observations {
+		byProvider[p.Provider] = append(byProvider[p.Provider], p)
+	}
+	truths := make(map[string]*truth, len(byProvider))
+	for provider, points := range byProvider {
+		truths[provider] = newTruth(points, opts.Tolerance)
+	}
+
+	type scoreKey struct {
+		model, variable string
+		lead            int
+	}
+	type rainKey struct {
+		model string
+		lead  int
+	}
+	scores := make(map[scoreKey]*Score)
+	rain := make(map[rainKey]*Contingency)
+
+	var report Report
+	for _, f := range forecasts {
+		lead := leadIndex(f.Lead())
+		if lead < 0 || (f.Kind != history.KindHourly && f.Kind != history.KindDaily) {
+			continue
+		}
+		truth := truths[f.Provider]
+		if truth == nil {
+			truth = newTruth(nil, opts.Tolerance)
+			truths[f.Provider] = truth
+		}
+
+		if f.Variable == "precipitation_probability" && f.Kind == history.KindHourly {
+			observed, ok := truth.analysis("precipitation", f.Valid)
+			if !ok {
+				continue
+			}
+			k := rainKey{f.Source(), lead}
+			if rain[k] == nil {
+				rain[k] = &Contingency{Model: f.Source(), Lead: Leads[lead].Label}
+			}
+			rain[k].add(f.Value >= opts.RainProbability, observed.Value >= rainThreshold(observed.Unit))
+			continue
+		}
+		if !slices.Contains(variables, f.Variable) {
+			continue
+		}
+
+		observed, ok := truth.observe(f)
+		if !ok || observed.Unit != f.Unit {
+			report.Unmatched++
+			continue
+		}
+		report.Matched++
+		k := scoreKey{f.Source(), f.Variable, lead}
+		if scores[k] == nil {
+			scores[k] = &Score{Model: f.Source(), Variable: f.Variable, Lead: Leads[lead].Label, Unit: f.Unit}
+		}
+		scores[k].add(f.Value, observed.Value)
+	}
+
+	models := make(map[string]bool)
+	for k, s := range scores {
+		report.Scores = append(report.Scores, *s)
+		models[k.model] = true
+	}
+	for k, c := range rain {
+		report.Precipitation = append(report.Precipitation, *c)
+		models[k.model] = true
+	}
+	for model := range models {
+		report.Models = append(report.Models, model)
+	}
+	sort.Strings(report.Models)
+
+	sort.Slice(report.Scores, func(i, j int) bool {
+		a, b := report.Scores[i], report.Scores[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		if a.Variable != b.Variable {
+			return slices.Index(variables, a.Variable) < slices.Index(variables, b.Variable)
+		}
+		return leadOrder(a.Lead) < leadOrder(b.Lead)
+	})
+	sort.Slice(report.Precipitation, func(i, j int) bool {
+		a, b := report.Precipitation[i], report.Precipitation[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return leadOrder(a.Lead) < leadOrder(b.Lead)
+	})
+	return report
+}
+
+func (c *Contingency) add(forecast, observed bool) {
+	switch {
+	case forecast && observed:
+		c.Hits++
+	case observed:
+		c.Misses++
+	case forecast:
+		c.FalseAlarms++
+	default:
+		c.CorrectNegatives++
+	}
+}
+
+func leadOrder(label string) int {
+	return slices.IndexFunc(Leads, func(l Lead) bool { return l.Label == label })
+}
+
+// rainThreshold is the smallest hourly amount that counts as rain: 0.1 mm,
+// or the same in inches.
+func rainThreshold(unit string) float64 {
+	if unit == "inch" || unit == "in" {
+		return 0.004
+	}
+	return 0.1
+}
@@ -0,0 +1,190 @@
+package verify
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/history"
+)
+
+var issued = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func point(kind history.Kind, variable string, issued, valid time.Time, value float64, unit string) history.Point {
+	return history.Point{
+		Kind:     kind,
+		Variable: variable,
+		Issued:   issued,
+		Valid:    valid,
+		Value:    value,
+		Unit:     unit,
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestVerify_Scores(t *testing.T) {
+	forecasts := []history.Point{
+		point(history.KindHourly, "temperature", issued, issued.Add(2*time.Hour), 20, "°C"),
+		point(history.KindHourly, "temperature", issued, issued.Add(3*time.Hour), 18, "°C"),
+		point(history.KindHourly, "temperature", issued, issued.Add(30*time.Hour), 25, "°C"),
+		// Nothing was observed at this hour yet.
+		point(history.KindHourly, "temperature", issued, issued.Add(40*time.Hour), 25, "°C"),
+	}
+	observations := []history.Point{
+		// The observation 10 minutes off wins over the analysis of the hour.
+		point(history.KindObservation, "temperature", issued, issued.Add(2*time.Hour+10*time.Minute), 19, "°C"),
+		point(history.KindAnalysis, "temperature", issued, issued.Add(2*time.Hour), 10, "°C"),
+		point(history.KindAnalysis, "temperature", issued, issued.Add(3*time.Hour), 21, "°C"),
+		point(history.KindAnalysis, "temperature", issued, issued.Add(30*time.Hour), 24, "°C"),
+	}
+
+	report := Verify(forecasts, observations, Options{})
+	if report.Matched != 3 || report.Unmatched != 1 {
+		t.Errorf("Expected 3 matched and 1 unmatched, got %d and %d", report.Matched, report.Unmatched)
+	}
+	if len(report.Scores) != 2 {
+		t.Fatalf("Expected 2 scores, got %+v", report.Scores)
+	}
+
+	short := report.Scores[0]
+	if short.Lead != "0-6h" || short.Count != 2 {
+		t.Fatalf("Expected 2 forecasts at 0-6h first, got %+v", short)
+	}
+	// Errors are +1 and -3.
+	if !near(short.Bias, -1) || !near(short.MAE, 2) || !near(short.RMSE, math.Sqrt(5)) {
+		t.Errorf("Expected bias -1, MAE 2 and RMSE √5, got %+v", short)
+	}
+	if long := report.Scores[1]; long.Lead != "1-2d" || !near(long.Bias, 1) {
+		t.Errorf("Expected a bias of 1 at 1-2d, got %+v", long)
+	}
+}
+
+func TestVerify_UnitMismatch(t *testing.T) {
+	forecasts := []history.Point{
+		point(history.KindHourly, "temperature", issued, issued.Add(time.Hour), 68, "°F"),
+	}
+	observations := []history.Point{
+		point(history.KindAnalysis, "temperature", issued, issued.Add(time.Hour), 20, "°C"),
+	}
+
+	report := Verify(forecasts, observations, Options{})
+	if len(report.Scores) != 0 || report.Unmatched != 1 {
+		t.Errorf("Expected forecasts in other units to go unmatched, got %+v", report)
+	}
+}
+
+func TestVerify_Precipitation(t *testing.T) {
+	var forecasts, observations []history.Point
+	add := func(hour int, probability, rain float64) {
+		valid := issued.Add(time.Duration(hour) * time.Hour)
+		forecasts = append(forecasts,
+			point(history.KindHourly, "precipitation_probability", issued, valid, probability, "%"))
+		observations = append(observations,
+			point(history.KindAnalysis, "precipitation", issued, valid, rain, "mm"))
+	}
+	add(1, 80, 1.2) // Hit
+	add(2, 60, 0)   // False alarm
+	add(3, 20, 0.5) // Miss
+	add(4, 10, 0)   // Correct negative
+	add(5, 90, 0.3) // Hit
+
+	report := Verify(forecasts, observations, Options{})
+	if len(report.Precipitation) != 1 {
+		t.Fatalf("Expected 1 contingency table, got %+v", report.Precipitation)
+	}
+	c := report.Precipitation[0]
+	if c.Hits != 2 || c.Misses != 1 || c.FalseAlarms != 1 || c.CorrectNegatives != 1 {
+		t.Errorf("Expected 2 hits, 1 miss, 1 false alarm and 1 correct negative, got %+v", c)
+	}
+	if !near(c.HitRate(), 2.0/3) || !near(c.FalseAlarmRate(), 1.0/3) || !near(c.CSI(), 0.5) {
+		t.Errorf("Expected POD 2/3, FAR 1/3 and CSI 1/2, got %v, %v and %v", c.HitRate(), c.FalseAlarmRate(), c.CSI())
+	}
+}
+
+func TestVerify_Daily(t *testing.T) {
+	day := issued.AddDate(0, 0, 2)
+	forecasts := []history.Point{
+		point(history.KindDaily, "temperature_max", issued, day, 26, "°C"),
+		point(history.KindDaily, "precipitation_sum", issued, day, 3, "mm"),
+		// Too few hours of the next day are known.
+		point(history.KindDaily, "temperature_min", issued, day.AddDate(0, 0, 1), 12, "°C"),
+	}
+	var observations []history.Point
+	for hour := range 24 {
+		valid := day.Add(time.Duration(hour) * time.Hour)
+		observations = append(observations,
+			point(history.KindAnalysis, "temperature", valid, valid, 10+float64(hour)/2, "°C"),
+			point(history.KindAnalysis, "precipitation", valid, valid, 0.25, "mm"))
+	}
+	for hour := range 10 {
+		valid := day.AddDate(0, 0, 1).Add(time.Duration(hour) * time.Hour)
+		observations = append(observations,
+			point(history.KindAnalysis, "temperature", valid, valid, 10, "°C"))
+	}
+
+	report := Verify(forecasts, observations, Options{})
+	if report.Matched != 2 || report.Unmatched != 1 {
+		t.Fatalf("Expected 2 matched and 1 unmatched, got %+v", report)
+	}
+	for _, s := range report.Scores {
+		switch s.Variable {
+		case "temperature_max":
+			// The warmest hour is 10 + 23/2.
+			if !near(s.Bias, 26-21.5) {
+				t.Errorf("Expected a temperature_max bias of 4.5, got %v", s.Bias)
+			}
+		case "precipitation_sum":
+			if !near(s.Bias, -3) {
+				t.Errorf("Expected a precipitation_sum bias of -3, got %v", s.Bias)
+			}
+		default:
+			t.Errorf("Unexpected score %+v", s)
+		}
+	}
+}
+
+func TestVerify_Models(t *testing.T) {
+	valid := issued.Add(time.Hour)
+	gfs := point(history.KindHourly, "temperature", issued, valid, 22, "°C")
+	gfs.Model = "gfs_seamless"
+	forecasts := []history.Point{
+		point(history.KindHourly, "temperature", issued, valid, 21, "°C"),
+		gfs,
+	}
+	observations := []history.Point{
+		point(history.KindAnalysis, "temperature", issued, valid, 20, "°C"),
+	}
+
+	report := Verify(forecasts, observations, Options{})
+	if len(report.Models) != 2 || report.Models[0] != "" || report.Models[1] != "gfs_seamless" {
+		t.Fatalf("Expected the default model and gfs_seamless, got %q", report.Models)
+	}
+	if report.Scores[0].Bias != 1 || report.Scores[1].Bias != 2 {
+		t.Errorf("Expected biases 1 and 2, got %+v", report.Scores)
+	}
+}
+
+func TestVerify_Providers(t *testing.T) {
+	valid := issued.Add(time.Hour)
+	nws := point(history.KindHourly, "temperature", issued, valid, 25, "°C")
+	nws.Provider = "nws"
+	metno := point(history.KindHourly, "temperature", issued, valid, 18, "°C")
+	metno.Provider = "metno"
+	nwsTruth := point(history.KindAnalysis, "temperature", issued, valid, 24, "°C")
+	nwsTruth.Provider = "nws"
+	forecasts := []history.Point{point(history.KindHourly, "temperature", issued, valid, 21, "°C"), nws, metno}
+	observations := []history.Point{point(history.KindAnalysis, "temperature", issued, valid, 20, "°C"), nwsTruth}
+
+	report := Verify(forecasts, observations, Options{})
+	if len(report.Models) != 2 || report.Models[0] != "" || report.Models[1] != "nws" {
+		t.Fatalf("Expected Open-Meteo and nws, got %q", report.Models)
+	}
+	// Each provider is scored against its own analysis, and MET Norway has
+	// none.
+	if report.Scores[0].Bias != 1 || report.Scores[1].Bias != 1 || report.Unmatched != 1 {
+		t.Errorf("Expected biases of 1 against each provider's analysis and one unmatched, got %+v", report)
+	}
+}
@@ -0,0 +1,156 @@
+package weather
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+)
+
+// now is the clock used to pick the "current" reading out of time series.
+// Tests override it to get deterministic results.
+var now = time.Now
+
+// AirQualityUnits holds the unit strings for the air quality data.
+type AirQualityUnits struct {
+	PM10            string `json:"pm10"`
+	PM25            string `json:"pm2_5"`
+	CarbonMonoxide  string `json:"carbon_monoxide"`
+	NitrogenDioxide string `json:"nitrogen_dioxide"`
+	Ozone           string `json:"ozone"`
+}
+
+// AirQuality represents the simplified air quality reading for one hour.
+type AirQuality struct {
+	Time            time.Time       `json:"time"`
+	PM10            float64         `json:"pm10"`
+	PM25            float64         `json:"pm2_5"`
+	CarbonMonoxide  float64         `json:"carbon_monoxide"`
+	NitrogenDioxide float64         `json:"nitrogen_dioxide"`
+	Ozone           float64         `json:"ozone"`
+	UVIndex         float64         `json:"uv_index"`
+	EuropeanAQI     float64         `json:"european_aqi"`
+	USAQI           float64         `json:"us_aqi"`
+	Units           AirQualityUnits `json:"units"`
+}
+
+// Category returns the US EPA category name for the reading's US AQI.
+func (aq *AirQuality) Category() string {
+	switch {
+	case aq.USAQI <= 50:
+		return "Good"
+	case aq.USAQI <= 100:
+		return "Moderate"
+	case aq.USAQI <= 150:
+		return "Unhealthy for sensitive groups"
+	case aq.USAQI <= 200:
+		return "Unhealthy"
+	case aq.USAQI <= 300:
+		return "Very unhealthy"
+	default:
+		return "Hazardous"
+	}
+}
+
+// AirQualityClient is an interface for a client that can fetch air quality data.
+type AirQualityClient interface {
+	GetAirQuality(
+		latitude, longitude float64,
+		hourlyAirQualityParameters []string,
+	) (*openmateo.AirQualityResult, error)
+}
+
+// GetCurrentAirQuality fetches the air quality reading for the current hour.
+// It requires the WeatherClient to have an AirQualityClient configured.
+func (w *WeatherClient) GetCurrentAirQuality(latitude, longitude float64) (*AirQuality, error) {
+	if w.AirQualityClient == nil {
+		return nil, fmt.Errorf("no air quality client configured")
+	}
+
+	hourlyParams := []string{
+		"pm10",
+		"pm2_5",
+		"carbon_monoxide",
+		"nitrogen_dioxide",
+		"ozone",
+		"uv_index",
+		"european_aqi",
+		"us_aqi",
+	}
+
+	result, err := w.AirQualityClient.GetAirQuality(latitude, longitude, hourlyParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw air quality data: %w", err)
+	}
+
+	hourly := result.Hourly
+	if hourly == nil || hourly.Time == nil || hourly.PM10 == nil || hourly.PM25 == nil ||
+		hourly.CarbonMonoxide == nil || hourly.NitrogenDioxide == nil ||
+		hourly.Ozone == nil || hourly.UVIndex == nil ||
+		hourly.EuropeanAQI == nil || hourly.USAQI == nil {
+		return nil, fmt.Errorf("air quality data is incomplete or missing from API response")
+	}
+
+	numHoursReturned := len(hourly.Time)
+	if len(hourly.PM10) != numHoursReturned ||
+		len(hourly.PM25) != numHoursReturned ||
+		len(hourly.CarbonMonoxide) != numHoursReturned ||
+		len(hourly.NitrogenDioxide) != numHoursReturned ||
+		len(hourly.Ozone) != numHoursReturned ||
+		len(hourly.UVIndex) != numHoursReturned ||
+		len(hourly.EuropeanAQI) != numHoursReturned ||
+		len(hourly.USAQI) != numHoursReturned {
+		return nil, fmt.Errorf("API returned air quality data with inconsistent lengths")
+	}
+
+	if numHoursReturned == 0 {
+		return nil, fmt.Errorf(
+			"no air quality data returned for %.2f, %.2f",
+			latitude,
+			longitude,
+		)
+	}
+
+	// The air quality API reports times in GMT. Pick the latest reading
+	// that is not in the future, falling back to the first one.
+	current := now().UTC()
+	index := 0
+	for i := range hourly.Time {
+		readingTime, err := parseTime(hourly.Time[i], "")
+		if err != nil {
+			return nil, err
+		}
+		if readingTime.After(current) {
+			break
+		}
+		index = i
+	}
+
+	readingTime, err := parseTime(hourly.Time[index], "")
+	if err != nil {
+		return nil, err
+	}
+
+	airQuality := &AirQuality{
+		Time:            readingTime,
+		PM10:            hourly.PM10[index],
+		PM25:            hourly.PM25[index],
+		CarbonMonoxide:  hourly.CarbonMonoxide[index],
+		NitrogenDioxide: hourly.NitrogenDioxide[index],
+		Ozone:           hourly.Ozone[index],
+		UVIndex:         hourly.UVIndex[index],
+		EuropeanAQI:     hourly.EuropeanAQI[index],
+		USAQI:           hourly.USAQI[index],
+	}
+	if result.HourlyUnits != nil {
+		airQuality.Units = AirQualityUnits{
+			PM10:            result.HourlyUnits.PM10,
+			PM25:            result.HourlyUnits.PM25,
+			CarbonMonoxide:  result.HourlyUnits.CarbonMonoxide,
+			NitrogenDioxide: result.HourlyUnits.NitrogenDioxide,
+			Ozone:           result.HourlyUnits.Ozone,
+		}
+	}
+
+	return airQuality, nil
+}
@@ -0,0 +1,102 @@
+package weather
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+)
+
+// mockAirQualityClient is a mock implementation of the AirQualityClient interface.
+type mockAirQualityClient struct {
+	GetAirQualityFunc func(
+		latitude, longitude float64,
+		hourlyAirQualityParameters []string,
+	) (*openmateo.AirQualityResult, error)
+}
+
+// GetAirQuality is the mock implementation of the GetAirQuality method.
+func (m *mockAirQualityClient) GetAirQuality(
+	latitude, longitude float64,
+	hourlyAirQualityParameters []string,
+) (*openmateo.AirQualityResult, error) {
+	if m.GetAirQualityFunc != nil {
+		return m.GetAirQualityFunc(latitude, longitude, hourlyAirQualityParameters)
+	}
+	return nil, errors.New("GetAirQualityFunc is not implemented")
+}
+
+func airQualityResult() *openmateo.AirQualityResult {
+	return &openmateo.AirQualityResult{
+		Hourly: &openmateo.AirQualityHourly{
+			Time:            []string{"2023-01-01T11:00", "2023-01-01T12:00", "2023-01-01T13:00"},
+			PM10:            []float64{10.0, 11.0, 12.0},
+			PM25:            []float64{8.0, 9.0, 10.0},
+			CarbonMonoxide:  []float64{200.0, 210.0, 220.0},
+			NitrogenDioxide: []float64{20.0, 21.0, 22.0},
+			Ozone:           []float64{50.0, 51.0, 52.0},
+			UVIndex:         []float64{1.0, 1.5, 2.0},
+			EuropeanAQI:     []float64{30.0, 31.0, 32.0},
+			USAQI:           []float64{40.0, 41.0, 42.0},
+		},
+		HourlyUnits: &openmateo.AirQualityHourlyUnits{
+			PM10: "μg/m³",
+			PM25: "μg/m³",
+		},
+	}
+}
+
+func TestGetCurrentAirQuality_Success(t *testing.T) {
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC) }
+
+	weatherClient := NewWeatherClient(&mockForecastClient{})
+	weatherClient.AirQualityClient = &mockAirQualityClient{
+		GetAirQualityFunc: func(latitude, longitude float64, hourlyAirQualityParameters []string) (*openmateo.AirQualityResult, error) {
+			return airQualityResult(), nil
+		},
+	}
+
+	airQuality, err := weatherClient.GetCurrentAirQuality(52.52, 13.41)
+	if err != nil {
+		t.Fatalf("GetCurrentAirQuality failed: %v", err)
+	}
+
+	if airQuality.PM25 != 9.0 {
+		t.Errorf("Expected PM25 of the 12:00 reading to be 9.0, got %f", airQuality.PM25)
+	}
+	if airQuality.USAQI != 41.0 {
+		t.Errorf("Expected USAQI to be 41.0, got %f", airQuality.USAQI)
+	}
+	if airQuality.Category() != "Good" {
+		t.Errorf("Expected category 'Good', got '%s'", airQuality.Category())
+	}
+	if airQuality.Units.PM25 != "μg/m³" {
+		t.Errorf("Expected PM25 unit to be 'μg/m³', got '%s'", airQuality.Units.PM25)
+	}
+}
+
+func TestGetCurrentAirQuality_NoClient(t *testing.T) {
+	weatherClient := NewWeatherClient(&mockForecastClient{})
+	_, err := weatherClient.GetCurrentAirQuality(52.52, 13.41)
+	if err == nil {
+		t.Fatal("Expected an error without an air quality client, but got nil")
+	}
+}
+
+func TestGetCurrentAirQuality_InconsistentLengths(t *testing.T) {
+	weatherClient := NewWeatherClient(&mockForecastClient{})
+	weatherClient.AirQualityClient = &mockAirQualityClient{
+		GetAirQualityFunc: func(latitude, longitude float64, hourlyAirQualityParameters []string) (*openmateo.AirQualityResult, error) {
+			result := airQualityResult()
+			result.Hourly.USAQI = result.Hourly.USAQI[:1]
+			return result, nil
+		},
+	}
+
+	_, err := weatherClient.GetCurrentAirQuality(52.52, 13.41)
+	if err == nil {
+		t.Fatal("Expected an error for inconsistent air quality lengths, but got nil")
+	}
+}
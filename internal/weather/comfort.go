@@ -0,0 +1,223 @@
+package weather
+
+import (
+	"math"
+	"strings"
+)
+
+// ComfortMetrics are "feels like" quantities derived from temperature,
+// relative humidity and wind speed. Temperatures are in the same unit as the
+// input. HeatIndexApplies and WindChillApplies report whether the conditions
+// are inside the range the respective formula is defined for; outside it the
+// value equals the air temperature.
+type ComfortMetrics struct {
+	DewPoint         float64 `json:"dew_point"`
+	HeatIndex        float64 `json:"heat_index"`
+	HeatIndexApplies bool    `json:"heat_index_applies"`
+	WindChill        float64 `json:"wind_chill"`
+	WindChillApplies bool    `json:"wind_chill_applies"`
+	Humidex          float64 `json:"humidex"` // Dimensionless, comparable to °C
+	WetBulb          float64 `json:"wet_bulb"`
+	Unit             string  `json:"unit"`
+
+	DewPointComfort string `json:"dew_point_comfort"`
+	HeatIndexRisk   string `json:"heat_index_risk"`
+	WindChillRisk   string `json:"wind_chill_risk"`
+	HumidexComfort  string `json:"humidex_comfort"`
+}
+
+// Comfort derives comfort metrics from a temperature, relative humidity (%)
+// and wind speed expressed in the given units.
+func Comfort(temperature, humidity, windSpeed float64, units Units) ComfortMetrics {
+	fahrenheit := isFahrenheit(units.Temperature)
+	celsius := temperature
+	if fahrenheit {
+		celsius = fahrenheitToCelsius(temperature)
+	}
+	windKmh := toMetersPerSecond(windSpeed, units.WindSpeed) * 3.6
+
+	dewPoint := DewPoint(celsius, humidity)
+	heatIndex, heatIndexApplies := HeatIndex(celsius, humidity)
+	windChill, windChillApplies := WindChill(celsius, windKmh)
+	humidex := Humidex(celsius, dewPoint)
+	wetBulb := WetBulb(celsius, humidity)
+
+	metrics := ComfortMetrics{
+		DewPoint:         dewPoint,
+		HeatIndex:        heatIndex,
+		HeatIndexApplies: heatIndexApplies,
+		WindChill:        windChill,
+		WindChillApplies: windChillApplies,
+		Humidex:          humidex,
+		WetBulb:          wetBulb,
+		Unit:             units.Temperature,
+		DewPointComfort:  dewPointComfort(dewPoint),
+		HeatIndexRisk:    heatIndexRisk(heatIndex, heatIndexApplies),
+		WindChillRisk:    windChillRisk(windChill, windChillApplies),
+		HumidexComfort:   humidexComfort(humidex),
+	}
+
+	if fahrenheit {
+		metrics.DewPoint = celsiusToFahrenheit(metrics.DewPoint)
+		metrics.HeatIndex = celsiusToFahrenheit(metrics.HeatIndex)
+		metrics.WindChill = celsiusToFahrenheit(metrics.WindChill)
+		metrics.WetBulb = celsiusToFahrenheit(metrics.WetBulb)
+	}
+
+	return metrics
+}
+
+// Comfort derives comfort metrics for the current conditions.
+func (c CurrentWeather) Comfort() ComfortMetrics {
+	return Comfort(c.Temperature, c.Humidity, c.WindSpeed, c.Units)
+}
+
+// Comfort derives comfort metrics for the forecast hour.
+func (h HourlyForecast) Comfort() ComfortMetrics {
+	return Comfort(h.Temperature, h.Humidity, h.WindSpeed, h.Units)
+}
+
+// DewPoint returns the dew point in °C using the Magnus formula with the
+// Alduchov and Eskridge (1996) coefficients.
+func DewPoint(celsius, humidity float64) float64 {
+	const a, b = 17.625, 243.04
+	humidity = math.Max(humidity, 1)
+	gamma := math.Log(humidity/100) + a*celsius/(b+celsius)
+	return b * gamma / (a - gamma)
+}
+
+// HeatIndex returns the US National Weather Service heat index in °C and
+// whether it applies. As in the NWS algorithm, Steadman's simple formula is
+// averaged with the air temperature first; when that is below 80 °F
+// (26.7 °C) the heat index does not apply and the air temperature is
+// returned. Otherwise it is the Rothfusz regression with the NWS adjustments.
+func HeatIndex(celsius, humidity float64) (float64, bool) {
+	t := celsiusToFahrenheit(celsius)
+	rh := humidity
+
+	simple := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (simple+t)/2 < 80 {
+		return celsius, false
+	}
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t -
+		0.05481717*rh*rh + 0.00122874*t*t*rh +
+		0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	switch {
+	case rh < 13 && t >= 80 && t <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t >= 80 && t <= 87:
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+
+	return fahrenheitToCelsius(hi), true
+}
+
+// WindChill returns the North American wind chill index in °C for a wind
+// speed in km/h, and whether it applies. The index is only defined at or
+// below 10 °C with wind above 4.8 km/h.
+func WindChill(celsius, windKmh float64) (float64, bool) {
+	if celsius > 10 || windKmh <= 4.8 {
+		return celsius, false
+	}
+	v := math.Pow(windKmh, 0.16)
+	return 13.12 + 0.6215*celsius - 11.37*v + 0.3965*celsius*v, true
+}
+
+// Humidex returns the Environment Canada humidex for a temperature and dew
+// point in °C.
+func Humidex(celsius, dewPoint float64) float64 {
+	vapourPressure := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewPoint)))
+	return celsius + 0.5555*(vapourPressure-10)
+}
+
+// WetBulb returns the wet-bulb temperature in °C using Stull's (2011)
+// empirical formula, valid for 5-99 % humidity between -20 and 50 °C at
+// sea-level pressure.
+func WetBulb(celsius, humidity float64) float64 {
+	return celsius*math.Atan(0.151977*math.Sqrt(humidity+8.313659)) +
+		math.Atan(celsius+humidity) -
+		math.Atan(humidity-1.676331) +
+		0.00391838*math.Pow(humidity, 1.5)*math.Atan(0.023101*humidity) -
+		4.686035
+}
+
+// dewPointComfort classifies how humid the air feels from its dew point in °C.
+func dewPointComfort(dewPoint float64) string {
+	switch {
+	case dewPoint < 10:
+		return "Dry"
+	case dewPoint < 16:
+		return "Comfortable"
+	case dewPoint < 18:
+		return "Slightly humid"
+	case dewPoint < 21:
+		return "Humid"
+	case dewPoint < 24:
+		return "Muggy"
+	default:
+		return "Oppressive"
+	}
+}
+
+// heatIndexRisk uses the NWS heat index categories (°C).
+func heatIndexRisk(heatIndex float64, applies bool) string {
+	switch {
+	case !applies || heatIndex < 26.7:
+		return "None"
+	case heatIndex < 32.2:
+		return "Caution"
+	case heatIndex < 39.4:
+		return "Extreme caution"
+	case heatIndex < 51.7:
+		return "Danger"
+	default:
+		return "Extreme danger"
+	}
+}
+
+// windChillRisk uses the Environment Canada wind chill risk levels (°C).
+func windChillRisk(windChill float64, applies bool) string {
+	switch {
+	case !applies || windChill > -10:
+		return "Low"
+	case windChill > -28:
+		return "Moderate"
+	case windChill > -40:
+		return "High: frostbite possible in 10-30 minutes"
+	case windChill > -48:
+		return "Very high: frostbite possible in 5-10 minutes"
+	case windChill > -55:
+		return "Severe: frostbite possible in 2-5 minutes"
+	default:
+		return "Extreme: frostbite possible in under 2 minutes"
+	}
+}
+
+// humidexComfort uses the Environment Canada humidex comfort ranges.
+func humidexComfort(humidex float64) string {
+	switch {
+	case humidex < 30:
+		return "Little or no discomfort"
+	case humidex < 40:
+		return "Some discomfort"
+	case humidex < 46:
+		return "Great discomfort; avoid exertion"
+	default:
+		return "Dangerous; heat stroke possible"
+	}
+}
+
+func isFahrenheit(unit string) bool {
+	return strings.Contains(unit, "F") || strings.EqualFold(unit, "fahrenheit")
+}
+
+func celsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
+
+func fahrenheitToCelsius(fahrenheit float64) float64 {
+	return (fahrenheit - 32) * 5 / 9
+}
@@ -0,0 +1,214 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values are read from the published charts, which are rounded to
+// whole degrees, so comparisons allow for that rounding.
+
+func TestHeatIndex_NWSChart(t *testing.T) {
+	tests := []struct {
+		fahrenheit, humidity, expected float64
+	}{
+		{90, 60, 100},
+		{100, 40, 109},
+		{86, 90, 105},
+		{96, 65, 121},
+	}
+	for _, test := range tests {
+		heatIndex, applies := HeatIndex(fahrenheitToCelsius(test.fahrenheit), test.humidity)
+		if !applies {
+			t.Errorf("Expected the heat index to apply at %v°F/%v%%", test.fahrenheit, test.humidity)
+		}
+		if got := celsiusToFahrenheit(heatIndex); math.Abs(got-test.expected) > 0.5 {
+			t.Errorf(
+				"Expected heat index at %v°F/%v%% to be %v°F, got %.1f°F",
+				test.fahrenheit,
+				test.humidity,
+				test.expected,
+				got,
+			)
+		}
+	}
+
+	if heatIndex, applies := HeatIndex(20, 50); applies || heatIndex != 20 {
+		t.Errorf("Expected no heat index at 20°C, got %.1f (applies=%v)", heatIndex, applies)
+	}
+	// 80°F at 40% feels slightly cooler than the air, so the index is not used.
+	if _, applies := HeatIndex(fahrenheitToCelsius(80), 40); applies {
+		t.Error("Expected no heat index at 80°F/40%")
+	}
+}
+
+func TestHeatIndex_Threshold(t *testing.T) {
+	// The 80-84 °F rows of the NWS heat index chart, and the air temperature
+	// where the index does not apply.
+	tests := []struct {
+		fahrenheit, humidity, expected float64
+		applies                        bool
+	}{
+		{78, 90, 78, false},
+		{80, 40, 80, false},
+		{80, 45, 80, false},
+		{80, 50, 81, true},
+		{80, 60, 82, true},
+		{80, 70, 83, true},
+		{80, 80, 84, true},
+		{82, 40, 81, true},
+		{82, 50, 83, true},
+		{82, 70, 86, true},
+		{84, 40, 83, true},
+		{84, 60, 88, true},
+	}
+	for _, test := range tests {
+		heatIndex, applies := HeatIndex(fahrenheitToCelsius(test.fahrenheit), test.humidity)
+		if applies != test.applies {
+			t.Errorf("Expected the heat index to apply at %v°F/%v%%: %v, got %v", test.fahrenheit, test.humidity, test.applies, applies)
+		}
+		if got := celsiusToFahrenheit(heatIndex); math.Abs(got-test.expected) > 0.5 {
+			t.Errorf(
+				"Expected heat index at %v°F/%v%% to be %v°F, got %.1f°F",
+				test.fahrenheit,
+				test.humidity,
+				test.expected,
+				got,
+			)
+		}
+	}
+}
+
+func TestWindChill_NWSChart(t *testing.T) {
+	tests := []struct {
+		fahrenheit, mph, expected float64
+	}{
+		{0, 15, -19},
+		{20, 10, 9},
+		{-10, 30, -39},
+		{5, 60, -26},
+	}
+	for _, test := range tests {
+		windChill, applies := WindChill(fahrenheitToCelsius(test.fahrenheit), test.mph*1.609344)
+		if !applies {
+			t.Errorf("Expected the wind chill to apply at %v°F/%v mph", test.fahrenheit, test.mph)
+		}
+		if got := celsiusToFahrenheit(windChill); math.Abs(got-test.expected) > 0.6 {
+			t.Errorf(
+				"Expected wind chill at %v°F/%v mph to be %v°F, got %.1f°F",
+				test.fahrenheit,
+				test.mph,
+				test.expected,
+				got,
+			)
+		}
+	}
+}
+
+func TestWindChill_EnvironmentCanadaChart(t *testing.T) {
+	tests := []struct {
+		celsius, kmh, expected float64
+	}{
+		{-10, 20, -18},
+		{-20, 30, -33},
+		{0, 10, -3},
+		{-30, 50, -49},
+	}
+	for _, test := range tests {
+		windChill, _ := WindChill(test.celsius, test.kmh)
+		if math.Abs(windChill-test.expected) > 0.6 {
+			t.Errorf(
+				"Expected wind chill at %v°C/%v km/h to be %v°C, got %.1f°C",
+				test.celsius,
+				test.kmh,
+				test.expected,
+				windChill,
+			)
+		}
+	}
+
+	if windChill, applies := WindChill(15, 30); applies || windChill != 15 {
+		t.Errorf("Expected no wind chill above 10°C, got %.1f (applies=%v)", windChill, applies)
+	}
+	if _, applies := WindChill(-5, 3); applies {
+		t.Error("Expected no wind chill in calm air")
+	}
+}
+
+func TestHumidex_EnvironmentCanadaTable(t *testing.T) {
+	tests := []struct {
+		celsius, dewPoint, expected float64
+	}{
+		{30, 15, 34},
+		{30, 20, 37},
+		{35, 25, 48},
+		{25, 15, 29},
+	}
+	for _, test := range tests {
+		if got := Humidex(test.celsius, test.dewPoint); math.Abs(got-test.expected) > 1 {
+			t.Errorf(
+				"Expected humidex at %v°C/dew point %v°C to be %v, got %.1f",
+				test.celsius,
+				test.dewPoint,
+				test.expected,
+				got,
+			)
+		}
+	}
+}
+
+func TestDewPointAndWetBulb(t *testing.T) {
+	if dewPoint := DewPoint(20, 50); math.Abs(dewPoint-9.3) > 0.1 {
+		t.Errorf("Expected dew point at 20°C/50%% to be 9.3°C, got %.2f", dewPoint)
+	}
+	if dewPoint := DewPoint(0, 100); math.Abs(dewPoint) > 0.01 {
+		t.Errorf("Expected dew point at saturation to equal the temperature, got %.2f", dewPoint)
+	}
+	// Stull (2011) gives 13.7°C for 20°C and 50% humidity.
+	if wetBulb := WetBulb(20, 50); math.Abs(wetBulb-13.7) > 0.05 {
+		t.Errorf("Expected wet bulb at 20°C/50%% to be 13.7°C, got %.2f", wetBulb)
+	}
+}
+
+func TestComfort_UnitAware(t *testing.T) {
+	metric := Comfort(32, 60, 10, Units{Temperature: "°C", WindSpeed: "km/h"})
+	imperial := Comfort(89.6, 60, 6.2137, Units{Temperature: "°F", WindSpeed: "mp/h"})
+
+	if math.Abs(celsiusToFahrenheit(metric.DewPoint)-imperial.DewPoint) > 0.01 {
+		t.Errorf("Expected matching dew points, got %.2f°C and %.2f°F", metric.DewPoint, imperial.DewPoint)
+	}
+	if math.Abs(celsiusToFahrenheit(metric.HeatIndex)-imperial.HeatIndex) > 0.01 {
+		t.Errorf("Expected matching heat indices, got %.2f°C and %.2f°F", metric.HeatIndex, imperial.HeatIndex)
+	}
+	if math.Abs(metric.Humidex-imperial.Humidex) > 0.01 {
+		t.Errorf("Expected the humidex to be unit independent, got %.2f and %.2f", metric.Humidex, imperial.Humidex)
+	}
+	if imperial.Unit != "°F" {
+		t.Errorf("Expected unit '°F', got '%s'", imperial.Unit)
+	}
+	if metric.HeatIndexRisk != "Extreme caution" {
+		t.Errorf("Expected heat index risk 'Extreme caution', got '%s'", metric.HeatIndexRisk)
+	}
+	if metric.DewPointComfort != "Muggy" {
+		t.Errorf("Expected dew point comfort 'Muggy', got '%s'", metric.DewPointComfort)
+	}
+}
+
+func TestComfort_OnWeatherTypes(t *testing.T) {
+	current := CurrentWeather{
+		Temperature: -10,
+		Humidity:    70,
+		WindSpeed:   20,
+		Units:       Units{Temperature: "°C", WindSpeed: "km/h"},
+	}
+	metrics := current.Comfort()
+	if !metrics.WindChillApplies || math.Round(metrics.WindChill) != -18 {
+		t.Errorf("Expected a wind chill of -18°C, got %.1f", metrics.WindChill)
+	}
+	if metrics.WindChillRisk != "Moderate" {
+		t.Errorf("Expected wind chill risk 'Moderate', got '%s'", metrics.WindChillRisk)
+	}
+	if metrics.HeatIndexApplies {
+		t.Error("Expected no heat index at -10°C")
+	}
+}
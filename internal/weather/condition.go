@@ -0,0 +1,114 @@
+package weather
+
+// Category groups weather codes into broad kinds of weather.
+type Category string
+
+const (
+	CategoryUnknown Category = "unknown"
+	CategoryClear   Category = "clear"
+	CategoryCloud   Category = "cloud"
+	CategoryFog     Category = "fog"
+	CategoryDrizzle Category = "drizzle"
+	CategoryRain    Category = "rain"
+	CategorySnow    Category = "snow"
+	CategoryThunder Category = "thunder"
+)
+
+// Intensity is the strength of the weather described by a code.
+type Intensity string
+
+const (
+	IntensityNone     Intensity = "none"
+	IntensityLight    Intensity = "light"
+	IntensityModerate Intensity = "moderate"
+	IntensityHeavy    Intensity = "heavy"
+	IntensityViolent  Intensity = "violent"
+)
+
+// PrecipitationType is the kind of precipitation falling, if any.
+type PrecipitationType string
+
+const (
+	PrecipitationNone            PrecipitationType = "none"
+	PrecipitationDrizzle         PrecipitationType = "drizzle"
+	PrecipitationFreezingDrizzle PrecipitationType = "freezing_drizzle"
+	PrecipitationRain            PrecipitationType = "rain"
+	PrecipitationFreezingRain    PrecipitationType = "freezing_rain"
+	PrecipitationSnow            PrecipitationType = "snow"
+	PrecipitationSnowGrains      PrecipitationType = "snow_grains"
+	PrecipitationHail            PrecipitationType = "hail"
+)
+
+// Condition describes a WMO weather code in detail. Severity ranks conditions
+// from 0 (clear or cloudy) to 5 (thunderstorm with hail) so that the worst
+// condition in a period can be picked by comparison.
+type Condition struct {
+	Code              int               `json:"code"`
+	Description       string            `json:"description"`
+	Category          Category          `json:"category"`
+	Intensity         Intensity         `json:"intensity"`
+	PrecipitationType PrecipitationType `json:"precipitation_type"`
+	Severity          int               `json:"severity"`
+}
+
+// IsPrecipitation reports whether the condition has anything falling from the sky.
+func (c Condition) IsPrecipitation() bool {
+	return c.PrecipitationType != PrecipitationNone && c.PrecipitationType != ""
+}
+
+// conditionTraits holds everything but the description for one code.
+type conditionTraits struct {
+	category          Category
+	intensity         Intensity
+	precipitationType PrecipitationType
+	severity          int
+}
+
+var conditionsByCode = map[int]conditionTraits{
+	0:  {CategoryClear, IntensityNone, PrecipitationNone, 0},
+	1:  {CategoryClear, IntensityNone, PrecipitationNone, 0},
+	2:  {CategoryCloud, IntensityNone, PrecipitationNone, 0},
+	3:  {CategoryCloud, IntensityNone, PrecipitationNone, 0},
+	45: {CategoryFog, IntensityModerate, PrecipitationNone, 1},
+	48: {CategoryFog, IntensityHeavy, PrecipitationNone, 2},
+	51: {CategoryDrizzle, IntensityLight, PrecipitationDrizzle, 1},
+	53: {CategoryDrizzle, IntensityModerate, PrecipitationDrizzle, 1},
+	55: {CategoryDrizzle, IntensityHeavy, PrecipitationDrizzle, 2},
+	56: {CategoryDrizzle, IntensityLight, PrecipitationFreezingDrizzle, 3},
+	57: {CategoryDrizzle, IntensityHeavy, PrecipitationFreezingDrizzle, 3},
+	61: {CategoryRain, IntensityLight, PrecipitationRain, 1},
+	63: {CategoryRain, IntensityModerate, PrecipitationRain, 2},
+	65: {CategoryRain, IntensityHeavy, PrecipitationRain, 3},
+	66: {CategoryRain, IntensityLight, PrecipitationFreezingRain, 3},
+	67: {CategoryRain, IntensityHeavy, PrecipitationFreezingRain, 4},
+	71: {CategorySnow, IntensityLight, PrecipitationSnow, 1},
+	73: {CategorySnow, IntensityModerate, PrecipitationSnow, 2},
+	75: {CategorySnow, IntensityHeavy, PrecipitationSnow, 3},
+	77: {CategorySnow, IntensityLight, PrecipitationSnowGrains, 1},
+	80: {CategoryRain, IntensityLight, PrecipitationRain, 1},
+	81: {CategoryRain, IntensityModerate, PrecipitationRain, 2},
+	82: {CategoryRain, IntensityViolent, PrecipitationRain, 3},
+	85: {CategorySnow, IntensityLight, PrecipitationSnow, 2},
+	86: {CategorySnow, IntensityHeavy, PrecipitationSnow, 3},
+	95: {CategoryThunder, IntensityModerate, PrecipitationRain, 4},
+	96: {CategoryThunder, IntensityModerate, PrecipitationHail, 5},
+	99: {CategoryThunder, IntensityHeavy, PrecipitationHail, 5},
+}
+
+// ConditionForCode returns the Condition for a WMO weather code with its
+// description in the given language. Unknown codes get CategoryUnknown.
+func ConditionForCode(code int, language string) Condition {
+	traits, ok := conditionsByCode[code]
+	if !ok {
+		traits = conditionTraits{CategoryUnknown, IntensityNone, PrecipitationNone, 0}
+	}
+
+	return Condition{
+		Code:              code,
+		Description:       mapWeatherCodeToDescription(code, language),
+		Category:          traits.category,
+		Intensity:         traits.intensity,
+		PrecipitationType: traits.precipitationType,
+		Severity:          traits.severity,
+	}
+}
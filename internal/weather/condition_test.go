@@ -0,0 +1,103 @@
+package weather
+
+import "testing"
+
+func TestConditionForCode_DistinguishesIntensity(t *testing.T) {
+	slight := ConditionForCode(61, "")
+	heavy := ConditionForCode(65, "")
+
+	if slight.Description == heavy.Description {
+		t.Errorf("Expected different descriptions for 61 and 65, got '%s'", slight.Description)
+	}
+	if slight.Intensity != IntensityLight || heavy.Intensity != IntensityHeavy {
+		t.Errorf(
+			"Expected light and heavy intensities, got '%s' and '%s'",
+			slight.Intensity,
+			heavy.Intensity,
+		)
+	}
+	if slight.Category != CategoryRain || heavy.Category != CategoryRain {
+		t.Errorf("Expected the rain category, got '%s' and '%s'", slight.Category, heavy.Category)
+	}
+	if heavy.Severity <= slight.Severity {
+		t.Errorf(
+			"Expected heavy rain (%d) to be more severe than slight rain (%d)",
+			heavy.Severity,
+			slight.Severity,
+		)
+	}
+}
+
+func TestConditionForCode_Thunderstorms(t *testing.T) {
+	thunder := ConditionForCode(95, "")
+	hail := ConditionForCode(99, "")
+
+	if thunder.Category != CategoryThunder || hail.Category != CategoryThunder {
+		t.Errorf("Expected the thunder category, got '%s' and '%s'", thunder.Category, hail.Category)
+	}
+	if thunder.PrecipitationType != PrecipitationRain {
+		t.Errorf("Expected rain with code 95, got '%s'", thunder.PrecipitationType)
+	}
+	if hail.PrecipitationType != PrecipitationHail {
+		t.Errorf("Expected hail with code 99, got '%s'", hail.PrecipitationType)
+	}
+	if hail.Severity != 5 {
+		t.Errorf("Expected code 99 to have the highest severity, got %d", hail.Severity)
+	}
+	if hail.Description != "Thunderstorm with heavy hail" {
+		t.Errorf("Expected 'Thunderstorm with heavy hail', got '%s'", hail.Description)
+	}
+}
+
+func TestConditionForCode_CategoriesAndPrecipitation(t *testing.T) {
+	tests := []struct {
+		code          int
+		category      Category
+		precipitation PrecipitationType
+	}{
+		{0, CategoryClear, PrecipitationNone},
+		{3, CategoryCloud, PrecipitationNone},
+		{48, CategoryFog, PrecipitationNone},
+		{56, CategoryDrizzle, PrecipitationFreezingDrizzle},
+		{66, CategoryRain, PrecipitationFreezingRain},
+		{77, CategorySnow, PrecipitationSnowGrains},
+		{86, CategorySnow, PrecipitationSnow},
+	}
+	for _, test := range tests {
+		condition := ConditionForCode(test.code, "")
+		if condition.Category != test.category {
+			t.Errorf("Expected code %d to be '%s', got '%s'", test.code, test.category, condition.Category)
+		}
+		if condition.PrecipitationType != test.precipitation {
+			t.Errorf(
+				"Expected code %d to have precipitation '%s', got '%s'",
+				test.code,
+				test.precipitation,
+				condition.PrecipitationType,
+			)
+		}
+		if condition.IsPrecipitation() != (test.precipitation != PrecipitationNone) {
+			t.Errorf("Unexpected IsPrecipitation for code %d", test.code)
+		}
+	}
+}
+
+func TestConditionForCode_Unknown(t *testing.T) {
+	condition := ConditionForCode(42, "")
+	if condition.Category != CategoryUnknown {
+		t.Errorf("Expected the unknown category, got '%s'", condition.Category)
+	}
+	if condition.Description != "Unknown weather code: 42" {
+		t.Errorf("Expected the unknown description, got '%s'", condition.Description)
+	}
+}
+
+func TestConditionForCode_Language(t *testing.T) {
+	condition := ConditionForCode(73, "fr")
+	if condition.Description != "Chute de neige modérée" {
+		t.Errorf("Expected a French description, got '%s'", condition.Description)
+	}
+	if condition.Intensity != IntensityModerate {
+		t.Errorf("Expected a moderate intensity, got '%s'", condition.Intensity)
+	}
+}
@@ -0,0 +1,160 @@
+package weather
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Defaults of ConsensusOptions, in °C and mm.
+const (
+	DefaultTemperatureSpread   = 3.0
+	DefaultPrecipitationSpread = 1.0
+)
+
+// Member is one forecast blended into a consensus, e.g. of a provider or an
+// Open-Meteo model.
+type Member struct {
+	Name  string
+	Hours []HourlyForecast
+}
+
+// ConsensusOptions tune Consensus.
+type ConsensusOptions struct {
+	// Median blends with the median of the members rather than the mean.
+	Median bool
+	// TemperatureSpread and PrecipitationSpread are the ranges, in the
+	// members' units, beyond which the members are flagged as disagreeing.
+	// Zero values take the defaults, which are in °C and mm.
+	TemperatureSpread   float64
+	PrecipitationSpread float64
+}
+
+// Spread summarises the values the members forecast for a variable.
+// Value is the blend: the mean or the median.
+type Spread struct {
+	Value  float64 `json:"value"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+}
+
+// Range returns how far apart the members are.
+func (s Spread) Range() float64 {
+	return s.Max - s.Min
+}
+
+// ConsensusHour is the blended forecast for an hour.
+type ConsensusHour struct {
+	DateTime          time.Time `json:"date_time"`
+	Sources           []string  `json:"sources"` // The members forecasting the hour
+	Temperature       Spread    `json:"temperature"`
+	Precipitation     Spread    `json:"precipitation"`
+	PrecipitationProb Spread    `json:"precipitation_probability"`
+	WindSpeed         Spread    `json:"wind_speed"`
+	// Condition is the one most members forecast, the most severe of
+	// those tied.
+	Condition Condition `json:"condition"`
+	IsDay     int       `json:"is_day"`
+	// Disagreement names the variables the members disagree strongly on:
+	// "temperature" and "precipitation".
+	Disagreement []string `json:"disagreement,omitempty"`
+	Units        Units    `json:"units"`
+}
+
+// Consensus blends the hours of members, which must be in the same units,
+// into one forecast. Hours are matched by time; each hour is blended from the
+// members that forecast it, in the zone of the first of them.
+func Consensus(members []Member, opts ConsensusOptions) []ConsensusHour {
+	if opts.TemperatureSpread == 0 {
+		opts.TemperatureSpread = DefaultTemperatureSpread
+	}
+	if opts.PrecipitationSpread == 0 {
+		opts.PrecipitationSpread = DefaultPrecipitationSpread
+	}
+
+	type source struct {
+		name string
+		hour HourlyForecast
+	}
+	byTime := make(map[int64][]source)
+	for _, member := range members {
+		for _, hour := range member.Hours {
+			key := hour.DateTime.Unix()
+			byTime[key] = append(byTime[key], source{member.Name, hour})
+		}
+	}
+
+	var consensus []ConsensusHour
+	for _, key := range slices.Sorted(maps.Keys(byTime)) {
+		sources := byTime[key]
+		first := sources[0].hour
+		hour := ConsensusHour{DateTime: first.DateTime, IsDay: first.IsDay, Units: first.Units}
+
+		var temperatures, precipitation, probabilities, wind []float64
+		conditions := make(map[int]int)
+		for _, s := range sources {
+			hour.Sources = append(hour.Sources, s.name)
+			temperatures = append(temperatures, s.hour.Temperature)
+			precipitation = append(precipitation, s.hour.Precipitation)
+			probabilities = append(probabilities, s.hour.PrecipitationProb)
+			wind = append(wind, s.hour.WindSpeed)
+			conditions[s.hour.Condition.Code]++
+		}
+		hour.Temperature = spread(temperatures, opts.Median)
+		hour.Precipitation = spread(precipitation, opts.Median)
+		hour.PrecipitationProb = spread(probabilities, opts.Median)
+		hour.WindSpeed = spread(wind, opts.Median)
+
+		best := first.Condition
+		for _, s := range sources {
+			c := s.hour.Condition
+			if votes, most := conditions[c.Code], conditions[best.Code]; votes > most || votes == most && c.Severity > best.Severity {
+				best = c
+			}
+		}
+		hour.Condition = best
+
+		if hour.Temperature.Range() > opts.TemperatureSpread {
+			hour.Disagreement = append(hour.Disagreement, "temperature")
+		}
+		if hour.Precipitation.Range() > opts.PrecipitationSpread {
+			hour.Disagreement = append(hour.Disagreement, "precipitation")
+		}
+		consensus = append(consensus, hour)
+	}
+	return consensus
+}
+
+// spread summarises values, blending them with the median or the mean.
+func spread(values []float64, median bool) Spread {
+	sorted := slices.Sorted(slices.Values(values))
+	s := Spread{Min: sorted[0], Max: sorted[len(sorted)-1]}
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	s.Mean = sum / float64(len(sorted))
+
+	middle := len(sorted) / 2
+	s.Median = sorted[middle]
+	if len(sorted)%2 == 0 {
+		s.Median = (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	squares := 0.0
+	for _, v := range sorted {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(squares / float64(len(sorted)))
+
+	s.Value = s.Mean
+	if median {
+		s.Value = s.Median
+	}
+	return s
+}
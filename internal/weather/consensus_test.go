@@ -0,0 +1,77 @@
+package weather
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConsensus(t *testing.T) {
+	start := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	hour := func(offset int, temperature, precipitation float64, code int) HourlyForecast {
+		return HourlyForecast{
+			DateTime:      start.Add(time.Duration(offset) * time.Hour),
+			Temperature:   temperature,
+			Precipitation: precipitation,
+			WindSpeed:     10,
+			Condition:     ConditionForCode(code, ""),
+		}
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	members := []Member{
+		{Name: "openmeteo/icon_seamless", Hours: []HourlyForecast{hour(0, 20, 0, 3), hour(1, 21, 0, 3)}},
+		{Name: "openmeteo/gfs_seamless", Hours: []HourlyForecast{hour(0, 21, 0, 2), hour(1, 26, 2.5, 63)}},
+		// MET Norway's times are in another zone but the same instants,
+		// and it forecasts an hour more.
+		{Name: "metno", Hours: []HourlyForecast{
+			hour(0, 22, 0.2, 2),
+			{DateTime: start.Add(time.Hour).In(berlin), Temperature: 22, Condition: ConditionForCode(61, "")},
+			hour(2, 18, 0, 0),
+		}},
+	}
+
+	consensus := Consensus(members, ConsensusOptions{})
+	if len(consensus) != 3 {
+		t.Fatalf("Expected 3 hours, got %d", len(consensus))
+	}
+
+	first := consensus[0]
+	if len(first.Sources) != 3 || first.Temperature.Value != 21 || first.Temperature.Min != 20 || first.Temperature.Max != 22 {
+		t.Errorf("Expected the mean of three members, got %+v", first)
+	}
+	if first.Temperature.StdDev < 0.81 || first.Temperature.StdDev > 0.82 {
+		t.Errorf("Expected a standard deviation of 0.82, got %v", first.Temperature.StdDev)
+	}
+	if first.Condition.Code != 2 || first.Disagreement != nil {
+		t.Errorf("Expected partly cloudy by two votes and agreement, got %+v", first)
+	}
+
+	second := consensus[1]
+	if !slices.Equal(second.Disagreement, []string{"temperature", "precipitation"}) {
+		t.Errorf("Expected disagreement on 21 to 26 °C and 0 to 2.5 mm, got %v", second.Disagreement)
+	}
+	// Three conditions with one vote each: the most severe wins.
+	if second.Condition.Code != 63 {
+		t.Errorf("Expected moderate rain, got %+v", second.Condition)
+	}
+	if !second.DateTime.Equal(start.Add(time.Hour)) || second.DateTime.Location() != time.UTC {
+		t.Errorf("Expected the time of the first member, got %s", second.DateTime)
+	}
+
+	last := consensus[2]
+	if !slices.Equal(last.Sources, []string{"metno"}) || last.Temperature.StdDev != 0 {
+		t.Errorf("Expected an hour forecast by metno alone, got %+v", last)
+	}
+
+	median := Consensus(members, ConsensusOptions{Median: true, TemperatureSpread: 10, PrecipitationSpread: 5})
+	if median[1].Temperature.Value != 22 || median[1].Temperature.Mean != 23 || median[1].Disagreement != nil {
+		t.Errorf("Expected the median and wider spreads, got %+v", median[1])
+	}
+}
+
+func TestSpread_Median(t *testing.T) {
+	s := spread([]float64{4, 1, 3, 2}, true)
+	if s.Median != 2.5 || s.Value != 2.5 || s.Mean != 2.5 || s.Range() != 3 {
+		t.Errorf("Expected the median of an even count, got %+v", s)
+	}
+}
@@ -0,0 +1,318 @@
+package weather
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults of Failover's circuit breakers.
+const (
+	DefaultFailureThreshold = 3
+	DefaultCooldown         = time.Minute
+)
+
+// ErrCircuitOpen is returned for a backend that is skipped because it
+// failed too often recently.
+var ErrCircuitOpen = errors.New("circuit open after repeated failures")
+
+// Backend is a Provider in a Failover chain.
+type Backend struct {
+	// Name identifies the backend in Source fields and health reports,
+	// e.g. "openmeteo".
+	Name     string
+	Provider Provider
+	// Timeout bounds each call. Zero leaves it to the provider's client.
+	Timeout time.Duration
+}
+
+// Failover is a Provider that tries its backends in order until one
+// succeeds, and sets the Source of what it returns to that backend's name.
+//
+// Each backend has a circuit breaker: after FailureThreshold failures in a
+// row the backend is skipped for Cooldown, then a single call is let through
+// to try it again. Success closes the circuit and failure opens it for
+// another Cooldown. A Failover is safe for concurrent use.
+type Failover struct {
+	// FailureThreshold and Cooldown tune the circuit breakers. Zero values
+	// take the defaults.
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	// OnFailure, when set, is called with each failure of a backend that
+	// the Failover moves on from, e.g. to log it.
+	OnFailure func(name string, err error)
+
+	backends []*backend
+}
+
+// NewFailover creates a Failover that tries backends in order.
+func NewFailover(backends ...Backend) *Failover {
+	f := &Failover{}
+	for _, b := range backends {
+		f.backends = append(f.backends, &backend{Backend: b})
+	}
+	return f
+}
+
+// Circuit states of ProviderHealth.
+const (
+	CircuitClosed   = "closed"    // Calls go through
+	CircuitOpen     = "open"      // Calls are skipped until RetryAt
+	CircuitHalfOpen = "half-open" // The next call tries the backend again
+)
+
+// ProviderHealth is the state of a Failover backend.
+type ProviderHealth struct {
+	Name        string    `json:"name"`
+	State       string    `json:"state"`
+	Failures    int       `json:"consecutive_failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastFailure time.Time `json:"last_failure,omitzero"`
+	RetryAt     time.Time `json:"retry_at,omitzero"` // When an open circuit lets a call through
+}
+
+// Health returns the state of every backend, in order.
+func (f *Failover) Health() []ProviderHealth {
+	current := now()
+	health := make([]ProviderHealth, len(f.backends))
+	for i, b := range f.backends {
+		health[i] = b.health(current, f.threshold())
+	}
+	return health
+}
+
+// Check asks every backend for the current weather at a location, whatever
+// the state of its circuit, and returns their health afterwards. The
+// outcomes count towards the circuit breakers like any other call.
+func (f *Failover) Check(latitude, longitude float64) []ProviderHealth {
+	var wg sync.WaitGroup
+	for _, b := range f.backends {
+		wg.Go(func() {
+			_, err := call(b, func(p Provider) (*CurrentWeather, error) {
+				return p.Current(latitude, longitude, RequestUnits{})
+			})
+			b.record(err, now(), f.threshold(), f.cooldown())
+		})
+	}
+	wg.Wait()
+	return f.Health()
+}
+
+// Current implements Provider.
+func (f *Failover) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	current, name, err := failover(f, func(p Provider) (*CurrentWeather, error) {
+		return p.Current(latitude, longitude, units)
+	})
+	if err != nil {
+		return nil, err
+	}
+	current.Source = name
+	return current, nil
+}
+
+// Hourly implements Provider.
+func (f *Failover) Hourly(
+	latitude, longitude float64,
+	pastHours, forecastHours int64,
+	units RequestUnits,
+) ([]HourlyForecast, error) {
+	hourly, name, err := failover(f, func(p Provider) ([]HourlyForecast, error) {
+		return p.Hourly(latitude, longitude, pastHours, forecastHours, units)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range hourly {
+		hourly[i].Source = name
+	}
+	return hourly, nil
+}
+
+// Daily implements Provider.
+func (f *Failover) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	daily, name, err := failover(f, func(p Provider) ([]DailyForecast, error) {
+		return p.Daily(latitude, longitude, numDays, units)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daily {
+		daily[i].Source = name
+	}
+	return daily, nil
+}
+
+// Alerts implements AlertProvider with the backends that publish alerts.
+func (f *Failover) Alerts(latitude, longitude float64) ([]Alert, error) {
+	alerts, _, err := failover(f, func(p Provider) ([]Alert, error) {
+		provider, ok := p.(AlertProvider)
+		if !ok {
+			return nil, errNoAlerts
+		}
+		return provider.Alerts(latitude, longitude)
+	})
+	return alerts, err
+}
+
+// errNoAlerts skips backends without alerts. It does not count as a failure.
+var errNoAlerts = errors.New("the weather provider has no alerts")
+
+func (f *Failover) threshold() int {
+	return cmp.Or(f.FailureThreshold, DefaultFailureThreshold)
+}
+
+func (f *Failover) cooldown() time.Duration {
+	return cmp.Or(f.Cooldown, DefaultCooldown)
+}
+
+// failover calls fn with each backend whose circuit allows it until one
+// succeeds, and returns the result and the backend's name. It fails with
+// the errors of every backend when none succeeds.
+func failover[T any](f *Failover, fn func(Provider) (T, error)) (T, string, error) {
+	var zero T
+	var errs []error
+	for _, b := range f.backends {
+		if !b.allow(now(), f.threshold()) {
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, ErrCircuitOpen))
+			continue
+		}
+		value, err := call(b, fn)
+		if errors.Is(err, errNoAlerts) {
+			b.release()
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+			continue
+		}
+		b.record(err, now(), f.threshold(), f.cooldown())
+		if err == nil {
+			return value, b.Name, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+		if f.OnFailure != nil {
+			f.OnFailure(b.Name, err)
+		}
+	}
+	if len(errs) == 0 {
+		return zero, "", fmt.Errorf("no weather providers are configured")
+	}
+	return zero, "", fmt.Errorf("all weather providers failed: %w", errors.Join(errs...))
+}
+
+// call calls fn with a backend's Provider, giving up after its Timeout. A
+// call that times out is left to finish in the background.
+func call[T any](b *backend, fn func(Provider) (T, error)) (T, error) {
+	if b.Timeout <= 0 {
+		return fn(b.Provider)
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(b.Provider)
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(b.Timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, fmt.Errorf("timed out after %s", b.Timeout)
+	}
+}
+
+// backend is a Backend with its circuit breaker. The circuit is open while
+// failures reach the threshold; once openUntil passes it is half-open, and
+// trial is set while the one call it lets through is in flight.
+type backend struct {
+	Backend
+
+	mu          sync.Mutex
+	failures    int
+	openUntil   time.Time
+	trial       bool
+	lastError   string
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+// allow reports whether a call may go through at time t, and starts the
+// trial call of a half-open circuit.
+func (b *backend) allow(t time.Time, threshold int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state(t, threshold) {
+	case CircuitClosed:
+		return true
+	case CircuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return false
+	}
+}
+
+// release ends a call that neither succeeded nor failed.
+func (b *backend) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record records the outcome of a call that ended at time t.
+func (b *backend) record(err error, t time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		b.lastSuccess = t
+		return
+	}
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailure = t
+	if b.failures >= threshold {
+		b.openUntil = t.Add(cooldown)
+	}
+}
+
+// state returns the circuit state at time t. b.mu must be held.
+func (b *backend) state(t time.Time, threshold int) string {
+	switch {
+	case b.failures < threshold:
+		return CircuitClosed
+	case t.Before(b.openUntil):
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+func (b *backend) health(t time.Time, threshold int) ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	health := ProviderHealth{
+		Name:        b.Name,
+		State:       b.state(t, threshold),
+		Failures:    b.failures,
+		LastError:   b.lastError,
+		LastSuccess: b.lastSuccess,
+		LastFailure: b.lastFailure,
+	}
+	if health.State == CircuitOpen {
+		health.RetryAt = b.openUntil
+	}
+	return health
+}
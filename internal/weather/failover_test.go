@@ -0,0 +1,208 @@
+package weather
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyProvider is a mockProvider that fails with err or answers after a
+// delay, counting its calls.
+type flakyProvider struct {
+	mockProvider
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (p *flakyProvider) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &CurrentWeather{Temperature: 20}, nil
+}
+
+func (p *flakyProvider) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	p.calls.Add(1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.mockProvider.Daily(latitude, longitude, numDays, units)
+}
+
+func TestFailover_Order(t *testing.T) {
+	primary := &flakyProvider{err: errors.New("API returned non-OK status: 503")}
+	day := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	secondary := &flakyProvider{mockProvider: mockProvider{daily: []DailyForecast{{Date: day}, {Date: day.AddDate(0, 0, 1)}}}}
+	failover := NewFailover(Backend{Name: "openmeteo", Provider: primary}, Backend{Name: "metno", Provider: secondary})
+	var failed []string
+	failover.OnFailure = func(name string, err error) {
+		failed = append(failed, name+": "+err.Error())
+	}
+
+	current, err := failover.Current(52.52, 13.41, RequestUnits{})
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	if current.Source != "metno" || current.Temperature != 20 {
+		t.Errorf("Expected the second backend to serve, got %+v", current)
+	}
+	if len(failed) != 1 || !strings.Contains(failed[0], "openmeteo: API returned non-OK status") {
+		t.Errorf("Expected the failure of the first backend to be reported, got %v", failed)
+	}
+
+	// The client keeps the source of each day.
+	daily, err := NewWeatherClientFrom(failover).GetDailyForecast(52.52, 13.41, 2, "", "", "")
+	if err != nil {
+		t.Fatalf("GetDailyForecast failed: %v", err)
+	}
+	if len(daily) != 2 || daily[0].Source != "metno" || daily[1].Source != "metno" {
+		t.Errorf("Expected every day to come from metno, got %+v", daily)
+	}
+
+	// Once the first backend recovers it serves again.
+	primary.err = nil
+	if current, err := failover.Current(52.52, 13.41, RequestUnits{}); err != nil || current.Source != "openmeteo" {
+		t.Errorf("Expected the first backend to serve again, got %+v, %v", current, err)
+	}
+}
+
+func TestFailover_CircuitBreaker(t *testing.T) {
+	current := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = original })
+
+	primary := &flakyProvider{err: errors.New("connection refused")}
+	secondary := &flakyProvider{}
+	failover := NewFailover(Backend{Name: "openmeteo", Provider: primary}, Backend{Name: "metno", Provider: secondary})
+	failover.FailureThreshold = 2
+	failover.Cooldown = time.Minute
+
+	for range 2 {
+		if _, err := failover.Current(52.52, 13.41, RequestUnits{}); err != nil {
+			t.Fatalf("Current failed: %v", err)
+		}
+	}
+	health := failover.Health()
+	if health[0].State != CircuitOpen || health[0].Failures != 2 || !health[0].RetryAt.Equal(current.Add(time.Minute)) {
+		t.Errorf("Expected the first circuit to open for a minute, got %+v", health[0])
+	}
+	if health[0].LastError != "connection refused" || !health[0].LastFailure.Equal(current) {
+		t.Errorf("Expected the last failure, got %+v", health[0])
+	}
+	if health[1].State != CircuitClosed || !health[1].LastSuccess.Equal(current) {
+		t.Errorf("Expected the second circuit to be closed, got %+v", health[1])
+	}
+
+	// An open circuit is skipped.
+	if _, err := failover.Current(52.52, 13.41, RequestUnits{}); err != nil || primary.calls.Load() != 2 {
+		t.Errorf("Expected the first backend to be skipped, got %d calls, %v", primary.calls.Load(), err)
+	}
+
+	// After the cooldown one call tries it again, and a failure reopens it.
+	current = current.Add(time.Minute)
+	if state := failover.Health()[0].State; state != CircuitHalfOpen {
+		t.Errorf("Expected a half-open circuit after the cooldown, got %s", state)
+	}
+	if _, err := failover.Current(52.52, 13.41, RequestUnits{}); err != nil || primary.calls.Load() != 3 {
+		t.Errorf("Expected a trial call, got %d calls, %v", primary.calls.Load(), err)
+	}
+	if health := failover.Health()[0]; health.State != CircuitOpen || !health.RetryAt.Equal(current.Add(time.Minute)) {
+		t.Errorf("Expected the failed trial to reopen the circuit, got %+v", health)
+	}
+
+	// A successful trial closes it.
+	current = current.Add(time.Minute)
+	primary.err = nil
+	result, err := failover.Current(52.52, 13.41, RequestUnits{})
+	if err != nil || result.Source != "openmeteo" {
+		t.Errorf("Expected the recovered backend to serve, got %+v, %v", result, err)
+	}
+	if health := failover.Health()[0]; health.State != CircuitClosed || health.Failures != 0 {
+		t.Errorf("Expected a closed circuit, got %+v", health)
+	}
+}
+
+func TestFailover_Timeout(t *testing.T) {
+	slow := &flakyProvider{delay: 200 * time.Millisecond}
+	failover := NewFailover(
+		Backend{Name: "openmeteo", Provider: slow, Timeout: 10 * time.Millisecond},
+		Backend{Name: "metno", Provider: &flakyProvider{}},
+	)
+
+	start := time.Now()
+	current, err := failover.Current(52.52, 13.41, RequestUnits{})
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	if current.Source != "metno" || time.Since(start) >= 200*time.Millisecond {
+		t.Errorf("Expected the slow backend to be abandoned, got %+v after %s", current, time.Since(start))
+	}
+	if health := failover.Health()[0]; !strings.Contains(health.LastError, "timed out after 10ms") {
+		t.Errorf("Expected the timeout to be recorded, got %+v", health)
+	}
+}
+
+func TestFailover_AllFail(t *testing.T) {
+	failover := NewFailover(
+		Backend{Name: "openmeteo", Provider: &flakyProvider{err: errors.New("connection refused")}},
+		Backend{Name: "metno", Provider: &flakyProvider{err: errors.New("API returned non-OK status: 429")}},
+	)
+	failover.FailureThreshold = 1
+
+	_, err := failover.Current(52.52, 13.41, RequestUnits{})
+	if err == nil || !strings.Contains(err.Error(), "openmeteo: connection refused") || !strings.Contains(err.Error(), "metno: API returned non-OK status: 429") {
+		t.Errorf("Expected the errors of both backends, got %v", err)
+	}
+
+	_, err = failover.Current(52.52, 13.41, RequestUnits{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected both circuits to be open, got %v", err)
+	}
+}
+
+func TestFailover_Check(t *testing.T) {
+	primary := &flakyProvider{err: errors.New("connection refused")}
+	failover := NewFailover(Backend{Name: "openmeteo", Provider: primary}, Backend{Name: "metno", Provider: &flakyProvider{}})
+	failover.FailureThreshold = 1
+
+	health := failover.Check(52.52, 13.41)
+	if len(health) != 2 || health[0].State != CircuitOpen || health[1].State != CircuitClosed || health[1].LastSuccess.IsZero() {
+		t.Fatalf("Expected the health of both backends, got %+v", health)
+	}
+
+	// Checks call backends whatever the state of their circuit.
+	primary.err = nil
+	if health := failover.Check(52.52, 13.41); health[0].State != CircuitClosed {
+		t.Errorf("Expected the check to close the circuit, got %+v", health[0])
+	}
+}
+
+func TestFailover_Alerts(t *testing.T) {
+	nws := &mockAlertProvider{alerts: []Alert{{Event: "Heat Advisory"}}}
+	failover := NewFailover(Backend{Name: "openmeteo", Provider: &flakyProvider{}}, Backend{Name: "nws", Provider: nws})
+	failover.FailureThreshold = 1
+
+	alerts, err := NewWeatherClientFrom(failover).GetActiveAlerts(40.71, -74.01)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("Expected the alerts of the second backend, got %v, %v", alerts, err)
+	}
+	if health := failover.Health()[0]; health.State != CircuitClosed {
+		t.Errorf("Expected a backend without alerts not to count as failing, got %+v", health)
+	}
+}
+
+// mockAlertProvider is a Provider that publishes alerts.
+type mockAlertProvider struct {
+	mockProvider
+	alerts []Alert
+}
+
+func (m *mockAlertProvider) Alerts(latitude, longitude float64) ([]Alert, error) {
+	return m.alerts, nil
+}
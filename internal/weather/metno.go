@@ -0,0 +1,320 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/metno"
+)
+
+// MetNorwayClient is an interface for a client of MET Norway's
+// Locationforecast API. *metno.Client implements it.
+type MetNorwayClient interface {
+	GetComplete(latitude, longitude float64) (*metno.Forecast, error)
+}
+
+// MetNorway is the Provider backed by MET Norway's Locationforecast, which
+// covers the whole world. Its times are in UTC and it does not know the
+// time zone of a location, so days run from midnight to midnight in the
+// solar time zone of the longitude, within about an hour of local time.
+// It has no past hours, and forecasts hourly for about two and a half days,
+// then every six hours.
+type MetNorway struct {
+	Client MetNorwayClient
+}
+
+// Current implements Provider with the latest timestep, which starts the
+// current hour.
+func (m *MetNorway) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	forecast, err := m.Client.GetComplete(latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+
+	current := now()
+	var step *metno.Timestep
+	index := 0
+	for i, s := range forecast.Timeseries {
+		if s.Time.After(current) {
+			break
+		}
+		step, index = &forecast.Timeseries[i], i
+	}
+	if step == nil || current.Sub(step.Time) >= time.Hour {
+		return nil, fmt.Errorf(
+			"no current weather data returned for %.2f, %.2f",
+			latitude,
+			longitude,
+		)
+	}
+
+	hour := metNorwayHour(latitude, longitude, forecast.Timeseries, index, units)
+	return &CurrentWeather{
+		Temperature:         hour.Temperature,
+		Humidity:            hour.Humidity,
+		ApparentTemperature: hour.ApparentTemperature,
+		Precipitation:       hour.Precipitation,
+		WindSpeed:           hour.WindSpeed,
+		WindGusts:           hour.WindGusts,
+		WindDirection:       hour.WindDirection,
+		Condition:           hour.Condition,
+		ObservationTime:     hour.DateTime,
+		IsDay:               hour.IsDay,
+		Units:               hour.Units,
+	}, nil
+}
+
+// Hourly implements Provider with the hourly timesteps in the period, so
+// past hours and hours beyond the hourly part of the forecast are left out.
+func (m *MetNorway) Hourly(
+	latitude, longitude float64,
+	pastHours, forecastHours int64,
+	units RequestUnits,
+) ([]HourlyForecast, error) {
+	forecast, err := m.Client.GetComplete(latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+
+	start := now().Truncate(time.Hour).Add(-time.Duration(pastHours) * time.Hour)
+	end := start.Add(time.Duration(pastHours+forecastHours) * time.Hour)
+	var hourlyForecasts []HourlyForecast
+	for i, step := range forecast.Timeseries {
+		if step.Time.Before(start) || !step.Time.Before(end) || step.Data.Next1Hours == nil {
+			continue
+		}
+		hourlyForecasts = append(hourlyForecasts, metNorwayHour(latitude, longitude, forecast.Timeseries, i, units))
+	}
+	return hourlyForecasts, nil
+}
+
+// Daily implements Provider. The API forecasts about nine days, so fewer
+// than numDays may be returned.
+func (m *MetNorway) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	forecast, err := m.Client.GetComplete(latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+
+	loc := solarZone(longitude)
+	current := now().In(loc)
+	today := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, loc)
+	var dailyForecasts []DailyForecast
+	for i := range int(numDays) {
+		day, ok := metNorwayDay(forecast.Timeseries, today.AddDate(0, 0, i), units)
+		if !ok {
+			break
+		}
+		dailyForecasts = append(dailyForecasts, day)
+	}
+	return dailyForecasts, nil
+}
+
+// metNorwayHour builds the forecast for the hour of the timestep at index.
+// Its precipitation is that of the hour before, which the previous timestep
+// forecasts, so the first timestep has none.
+func metNorwayHour(
+	latitude, longitude float64,
+	timeseries []metno.Timestep,
+	index int,
+	units RequestUnits,
+) HourlyForecast {
+	step := timeseries[index]
+	instant := step.Data.Instant.Details
+	windKmh := instant.WindSpeed * 3.6
+	gustKmh := windKmh
+	if instant.WindSpeedOfGust != nil {
+		gustKmh = *instant.WindSpeedOfGust * 3.6
+	}
+
+	t := step.Time.In(solarZone(longitude))
+	hour := HourlyForecast{
+		DateTime:            t,
+		Temperature:         convertTemperature(instant.AirTemperature, units.Temperature),
+		Humidity:            instant.RelativeHumidity,
+		ApparentTemperature: convertTemperature(apparentTemperature(instant.AirTemperature, instant.RelativeHumidity, windKmh), units.Temperature),
+		Cloudy:              instant.CloudAreaFraction,
+		WindSpeed:           convertWindSpeed(windKmh, units.WindSpeed),
+		WindGusts:           convertWindSpeed(gustKmh, units.WindSpeed),
+		WindDirection:       Direction(instant.WindFromDirection),
+		Units:               unitsFor(units),
+	}
+
+	if index > 0 {
+		previous := timeseries[index-1]
+		if before := previous.Data.Next1Hours; before != nil && step.Time.Sub(previous.Time) == time.Hour {
+			amount := before.Details.PrecipitationAmount
+			hour.Precipitation = convertPrecipitation(amount, units.Precipitation)
+			if code, _ := symbolCode(before.Summary.SymbolCode); conditionsByCode[code].precipitationType == PrecipitationSnow {
+				hour.SnowFall = convertSnowfall(amount*snowRatio, units.Precipitation)
+			}
+		}
+	}
+
+	period := step.Data.Next1Hours
+	if period == nil {
+		return hour
+	}
+	code, variant := symbolCode(period.Summary.SymbolCode)
+	hour.Condition = Condition{Code: code}
+	if period.Details.ProbabilityOfPrecipitation != nil {
+		hour.PrecipitationProb = *period.Details.ProbabilityOfPrecipitation
+	}
+	switch variant {
+	case "day":
+		hour.IsDay = 1
+	case "night", "polartwilight":
+		hour.IsDay = 0
+	default:
+		hour.IsDay = boolToInt(isDaytime(latitude, longitude, t))
+	}
+	return hour
+}
+
+// snowRatio is the depth of fresh snow per depth of water it melts to, as
+// Open-Meteo assumes.
+const snowRatio = 7
+
+// metNorwayDay builds the forecast for the day starting at midnight date,
+// and returns false when no timestep falls in it.
+func metNorwayDay(timeseries []metno.Timestep, date time.Time, units RequestUnits) (DailyForecast, bool) {
+	end := date.AddDate(0, 0, 1)
+	day := DailyForecast{Date: date, Units: unitsFor(units)}
+
+	found := false
+	maxCelsius, minCelsius := math.Inf(-1), math.Inf(1)
+	maxWind, maxGust, direction := 0.0, 0.0, 0.0
+	precipitation, probabilities, probabilitySum := 0.0, 0, 0.0
+	code, severity := -1, -1
+	// covered is when the precipitation counted so far ends, so that the
+	// hourly and six-hourly periods are not counted twice.
+	covered := date
+	for _, step := range timeseries {
+		if step.Time.Before(date) || !step.Time.Before(end) {
+			continue
+		}
+		found = true
+		instant := step.Data.Instant.Details
+		maxCelsius = max(maxCelsius, instant.AirTemperature)
+		minCelsius = min(minCelsius, instant.AirTemperature)
+		if wind := instant.WindSpeed * 3.6; wind >= maxWind {
+			maxWind = wind
+			direction = instant.WindFromDirection
+		}
+		if instant.WindSpeedOfGust != nil {
+			maxGust = max(maxGust, *instant.WindSpeedOfGust*3.6)
+		}
+
+		period, length := step.Data.Next1Hours, time.Hour
+		if period == nil {
+			period, length = step.Data.Next6Hours, 6*time.Hour
+		}
+		if period == nil {
+			continue
+		}
+		if six := step.Data.Next6Hours; six != nil && !step.Time.Add(6*time.Hour).After(end) {
+			if six.Details.AirTemperatureMax != nil {
+				maxCelsius = max(maxCelsius, *six.Details.AirTemperatureMax)
+			}
+			if six.Details.AirTemperatureMin != nil {
+				minCelsius = min(minCelsius, *six.Details.AirTemperatureMin)
+			}
+		}
+		if !step.Time.Before(covered) {
+			precipitation += period.Details.PrecipitationAmount
+			covered = step.Time.Add(length)
+		}
+		if period.Details.ProbabilityOfPrecipitation != nil {
+			probabilities++
+			probabilitySum += *period.Details.ProbabilityOfPrecipitation
+		}
+		// Like Open-Meteo's daily weather code, the day gets its most
+		// severe condition, or its cloudiest when none is severe.
+		c, _ := symbolCode(period.Summary.SymbolCode)
+		if s := conditionsByCode[c].severity; s > severity || s == severity && c > code {
+			code, severity = c, s
+		}
+	}
+	if !found {
+		return DailyForecast{}, false
+	}
+
+	day.MaxTemperature = convertTemperature(maxCelsius, units.Temperature)
+	day.MinTemperature = convertTemperature(minCelsius, units.Temperature)
+	day.PrecipitationSum = convertPrecipitation(precipitation, units.Precipitation)
+	if probabilities > 0 {
+		day.PrecipitationProb = probabilitySum / float64(probabilities)
+	}
+	day.MaxWindSpeed = convertWindSpeed(maxWind, units.WindSpeed)
+	day.WindGusts = convertWindSpeed(max(maxGust, maxWind), units.WindSpeed)
+	day.WindDirection = Direction(direction)
+	day.Condition = Condition{Code: max(code, 0)}
+	return day, true
+}
+
+// metNorwaySymbols maps MET Norway's symbols, without their variant, to
+// WMO weather codes. Sleet has no code of its own and maps to snow.
+var metNorwaySymbols = map[string]int{
+	"clearsky":     0,
+	"fair":         1,
+	"partlycloudy": 2,
+	"cloudy":       3,
+	"fog":          45,
+
+	"lightrain":        61,
+	"rain":             63,
+	"heavyrain":        65,
+	"lightrainshowers": 80,
+	"rainshowers":      81,
+	"heavyrainshowers": 82,
+
+	"lightsleet":        71,
+	"sleet":             73,
+	"heavysleet":        75,
+	"lightsleetshowers": 85,
+	"sleetshowers":      85,
+	"heavysleetshowers": 86,
+
+	"lightsnow":        71,
+	"snow":             73,
+	"heavysnow":        75,
+	"lightsnowshowers": 85,
+	"snowshowers":      85,
+	"heavysnowshowers": 86,
+}
+
+// symbolCode returns the WMO code of a symbol code, e.g. 2 for
+// "partlycloudy_day", and the symbol's variant. Thunder is 95 whatever
+// falls with it; unknown symbols are 3.
+func symbolCode(symbol string) (int, string) {
+	weather, variant := metno.SplitSymbol(symbol)
+	if code, ok := metNorwaySymbols[weather]; ok {
+		return code, variant
+	}
+	if strings.HasSuffix(weather, "andthunder") {
+		return 95, variant
+	}
+	return 3, variant
+}
+
+// apparentTemperature estimates the apparent temperature, which the API
+// does not forecast, as the heat index when hot, the wind chill when cold
+// and the temperature otherwise.
+func apparentTemperature(celsius, humidity, windKmh float64) float64 {
+	if heatIndex, ok := HeatIndex(celsius, humidity); ok {
+		return heatIndex
+	}
+	if windChill, ok := WindChill(celsius, windKmh); ok {
+		return windChill
+	}
+	return celsius
+}
+
+// solarZone returns a fixed time zone for a longitude, an hour per fifteen
+// degrees from UTC.
+func solarZone(longitude float64) *time.Location {
+	hours := int(math.Round(longitude / 15))
+	return time.FixedZone(fmt.Sprintf("UTC%+d", hours), hours*3600)
+}
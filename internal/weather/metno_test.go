@@ -0,0 +1,221 @@
+package weather
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/metno"
+)
+
+type mockMetNorwayClient struct {
+	forecast *metno.Forecast
+	err      error
+}
+
+func (m *mockMetNorwayClient) GetComplete(latitude, longitude float64) (*metno.Forecast, error) {
+	return m.forecast, m.err
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+// testMetNorwayForecast returns a forecast like the API's from midnight UTC
+// on 15 June 2026: hourly for 60 hours, then six-hourly until the 23rd.
+// Every hour has 0.1 mm of rain; the symbols are partly cloudy, except for
+// a thunderstorm at 15:00 and snow at 03:00 on the 16th.
+func testMetNorwayForecast() *metno.Forecast {
+	start := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	forecast := &metno.Forecast{UpdatedAt: start}
+	step := func(t time.Time, temperature float64) metno.Timestep {
+		var s metno.Timestep
+		s.Time = t
+		s.Data.Instant.Details = metno.Instant{
+			AirTemperature:    temperature,
+			RelativeHumidity:  60,
+			WindSpeed:         5,
+			WindSpeedOfGust:   float(10),
+			WindFromDirection: 270,
+			CloudAreaFraction: 40,
+		}
+		return s
+	}
+	period := func(symbol string, precipitation float64) *metno.Period {
+		p := &metno.Period{}
+		p.Summary.SymbolCode = symbol
+		p.Details.PrecipitationAmount = precipitation
+		p.Details.ProbabilityOfPrecipitation = float(20)
+		return p
+	}
+
+	for h := range 60 {
+		t := start.Add(time.Duration(h) * time.Hour)
+		s := step(t, 15+float64(t.Hour()%12))
+		symbol := "partlycloudy_day"
+		switch {
+		case t.Equal(start.Add(15 * time.Hour)):
+			symbol = "heavyrainandthunder"
+		case t.Equal(start.Add(27 * time.Hour)):
+			symbol = "lightsnow"
+		}
+		s.Data.Next1Hours = period(symbol, 0.1)
+		s.Data.Next6Hours = period("partlycloudy_day", 0.6)
+		s.Data.Next6Hours.Details.AirTemperatureMax = float(30)
+		s.Data.Next6Hours.Details.AirTemperatureMin = float(10)
+		forecast.Timeseries = append(forecast.Timeseries, s)
+	}
+	for t := start.Add(60 * time.Hour); t.Before(start.Add(9 * 24 * time.Hour)); t = t.Add(6 * time.Hour) {
+		s := step(t, 18)
+		s.Data.Next6Hours = period("cloudy", 1.2)
+		forecast.Timeseries = append(forecast.Timeseries, s)
+	}
+	return forecast
+}
+
+func newMetNorwayClient(t *testing.T) *WeatherClient {
+	t.Helper()
+	original := now
+	now = func() time.Time { return time.Date(2026, 6, 15, 12, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = original })
+	return NewWeatherClientFrom(&MetNorway{Client: &mockMetNorwayClient{forecast: testMetNorwayForecast()}})
+}
+
+func TestMetNorway_Current(t *testing.T) {
+	weatherClient := newMetNorwayClient(t)
+
+	current, err := weatherClient.GetCurrentWeather(59.91, 10.75, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if !current.ObservationTime.Equal(time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)) || current.ObservationTime.Hour() != 13 {
+		t.Errorf("Expected noon UTC in the solar time zone, got %s", current.ObservationTime)
+	}
+	if current.Temperature != 15 || current.WindSpeed != 18 || current.WindGusts != 36 || current.WindDirection != 270 {
+		t.Errorf("Expected the instant values in km/h, got %+v", current)
+	}
+	if current.Condition.Code != 2 || current.WeatherDescription == "" || current.IsDay != 1 {
+		t.Errorf("Expected a partly cloudy day, got %+v", current)
+	}
+	if current.Units != (Units{"°C", "km/h", "mm"}) {
+		t.Errorf("Expected metric units, got %+v", current.Units)
+	}
+
+	imperial, err := weatherClient.GetCurrentWeather(59.91, 10.75, "fahrenheit", "mph", "inch")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if imperial.Temperature != 59 || imperial.Units != (Units{"°F", "mp/h", "inch"}) {
+		t.Errorf("Expected 59°F in imperial units, got %+v", imperial)
+	}
+}
+
+func TestMetNorway_Hourly(t *testing.T) {
+	weatherClient := newMetNorwayClient(t)
+
+	hourly, err := weatherClient.GetHourlyForecast(59.91, 10.75, 24, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(hourly) != 24 || !hourly[0].DateTime.Equal(time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected 24 hours from noon UTC, got %d", len(hourly))
+	}
+	if hourly[3].Condition.Code != 95 || hourly[3].Precipitation != 0.1 || hourly[3].PrecipitationProb != 20 {
+		t.Errorf("Expected a thunderstorm at 15:00 UTC, got %+v", hourly[3])
+	}
+	// Precipitation is that of the hour before, and snow melts to a
+	// seventh of its depth.
+	if hourly[15].Condition.Code != 71 || hourly[15].SnowFall != 0 {
+		t.Errorf("Expected snow to start at 03:00 UTC, got %+v", hourly[15])
+	}
+	if hourly[16].SnowFall < 0.069 || hourly[16].SnowFall > 0.071 || hourly[16].Precipitation != 0.1 {
+		t.Errorf("Expected 0.07 cm of snow by 04:00 UTC, got %+v", hourly[16])
+	}
+
+	// Only the first 60 hours are hourly.
+	long, err := weatherClient.GetHourlyForecast(59.91, 10.75, 120, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(long) != 48 {
+		t.Errorf("Expected the 48 remaining hourly timesteps, got %d", len(long))
+	}
+}
+
+func TestMetNorway_Daily(t *testing.T) {
+	weatherClient := newMetNorwayClient(t)
+
+	daily, err := weatherClient.GetDailyForecast(59.91, 10.75, 16, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetDailyForecast failed: %v", err)
+	}
+	if len(daily) != 9 {
+		t.Fatalf("Expected 9 days, got %d", len(daily))
+	}
+	if daily[0].Date.Day() != 15 || daily[0].Date.Hour() != 0 || daily[0].Date.Format("-07") != "+01" {
+		t.Errorf("Expected today at midnight UTC+1, got %s", daily[0].Date)
+	}
+	// The 16th runs from 23:00 UTC with 24 hourly timesteps, one of them
+	// snowing; the six-hourly extremes are included.
+	if daily[1].PrecipitationSum < 2.39 || daily[1].PrecipitationSum > 2.41 {
+		t.Errorf("Expected 2.4 mm on the 16th, got %v", daily[1].PrecipitationSum)
+	}
+	if daily[1].Condition.Code != 71 || daily[1].MaxTemperature != 30 || daily[1].MinTemperature != 10 {
+		t.Errorf("Expected a snowy day from 10 to 30 °C, got %+v", daily[1])
+	}
+	if daily[0].Condition.Code != 95 {
+		t.Errorf("Expected the thunderstorm to be the condition of the 15th, got %+v", daily[0].Condition)
+	}
+	// The 17th turns six-hourly at 12:00 UTC without counting any hour twice.
+	if daily[2].PrecipitationSum < 3.69 || daily[2].PrecipitationSum > 3.71 {
+		t.Errorf("Expected 3.7 mm on the 17th, got %v", daily[2].PrecipitationSum)
+	}
+	if daily[4].PrecipitationSum < 4.79 || daily[4].PrecipitationSum > 4.81 || daily[4].Condition.Code != 3 {
+		t.Errorf("Expected a cloudy 19th with 4.8 mm, got %+v", daily[4])
+	}
+	if daily[4].Sunrise.IsZero() || daily[4].MaxWindSpeed != 18 || daily[4].WindGusts != 36 {
+		t.Errorf("Expected the sunrise, wind and gusts in km/h, got %+v", daily[4])
+	}
+}
+
+func TestMetNorway_Error(t *testing.T) {
+	weatherClient := NewWeatherClientFrom(&MetNorway{Client: &mockMetNorwayClient{err: errors.New("API error")}})
+
+	_, err := weatherClient.GetCurrentWeather(59.91, 10.75, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "failed to get raw weather data") {
+		t.Errorf("Expected the error to be wrapped, got %v", err)
+	}
+}
+
+func TestSymbolCode(t *testing.T) {
+	tests := []struct {
+		symbol  string
+		code    int
+		variant string
+	}{
+		{"clearsky_day", 0, "day"},
+		{"fair_night", 1, "night"},
+		{"cloudy", 3, ""},
+		{"fog", 45, ""},
+		{"heavyrainshowers_polartwilight", 82, "polartwilight"},
+		{"lightsleet", 71, ""},
+		{"lightssnowshowersandthunder_day", 95, "day"},
+		{"rainandthunder", 95, ""},
+		{"mystery", 3, ""},
+	}
+	for _, tt := range tests {
+		code, variant := symbolCode(tt.symbol)
+		if code != tt.code || variant != tt.variant {
+			t.Errorf("symbolCode(%q): expected %d, %q, got %d, %q", tt.symbol, tt.code, tt.variant, code, variant)
+		}
+	}
+}
+
+func TestSolarZone(t *testing.T) {
+	for longitude, expected := range map[float64]string{-74.01: "UTC-5", 10.75: "UTC+1", 0: "UTC+0", 139.69: "UTC+9"} {
+		if got := solarZone(longitude).String(); got != expected {
+			t.Errorf("solarZone(%v): expected %s, got %s", longitude, expected, got)
+		}
+	}
+}
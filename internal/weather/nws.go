@@ -0,0 +1,461 @@
+package weather
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/nws"
+)
+
+// NWSClient is an interface for a client of the US National Weather Service
+// API. *nws.Client implements it.
+type NWSClient interface {
+	GetPoint(latitude, longitude float64) (*nws.Point, error)
+	GetForecast(point *nws.Point) (*nws.Forecast, error)
+	GetHourlyForecast(point *nws.Point) (*nws.Forecast, error)
+	GetGridData(point *nws.Point) (*nws.GridData, error)
+	GetActiveAlerts(latitude, longitude float64) ([]nws.Alert, error)
+}
+
+// NWS is the Provider backed by the US National Weather Service, which only
+// covers the United States. Quantities come from the gridpoint data and
+// conditions from the forecast periods; hours without a period, such as
+// past ones, take their condition from the cloud cover and precipitation.
+// It also implements AlertProvider.
+type NWS struct {
+	Client NWSClient
+
+	mu sync.Mutex
+	// points caches the grids of locations, which do not change.
+	points map[string]*nws.Point
+}
+
+// point returns the grid covering a location and its time zone.
+func (n *NWS) point(latitude, longitude float64) (*nws.Point, *time.Location, error) {
+	key := fmt.Sprintf("%.4f,%.4f", latitude, longitude)
+	n.mu.Lock()
+	point, ok := n.points[key]
+	n.mu.Unlock()
+	if !ok {
+		var err error
+		point, err = n.Client.GetPoint(latitude, longitude)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get NWS grid: %w", err)
+		}
+		n.mu.Lock()
+		if n.points == nil {
+			n.points = make(map[string]*nws.Point)
+		}
+		n.points[key] = point
+		n.mu.Unlock()
+	}
+	loc, err := loadTimezone(point.TimeZone)
+	if err != nil {
+		return nil, nil, err
+	}
+	return point, loc, nil
+}
+
+// hourlyData fetches what hours are built from.
+func (n *NWS) hourlyData(latitude, longitude float64) (*nws.GridData, []nws.Period, *time.Location, error) {
+	point, loc, err := n.point(latitude, longitude)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	grid, err := n.Client.GetGridData(point)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+	forecast, err := n.Client.GetHourlyForecast(point)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+	return grid, forecast.Periods, loc, nil
+}
+
+// Current implements Provider with the forecast for the current hour; the
+// gridpoints have no observations.
+func (n *NWS) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	grid, periods, loc, err := n.hourlyData(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	hour, ok := nwsHour(latitude, longitude, grid, periods, now().In(loc).Truncate(time.Hour), units)
+	if !ok {
+		return nil, fmt.Errorf(
+			"no current weather data returned for %.2f, %.2f",
+			latitude,
+			longitude,
+		)
+	}
+	return &CurrentWeather{
+		Temperature:         hour.Temperature,
+		Humidity:            hour.Humidity,
+		ApparentTemperature: hour.ApparentTemperature,
+		Precipitation:       hour.Precipitation,
+		WindSpeed:           hour.WindSpeed,
+		WindGusts:           hour.WindGusts,
+		WindDirection:       hour.WindDirection,
+		Condition:           hour.Condition,
+		ObservationTime:     hour.DateTime,
+		IsDay:               hour.IsDay,
+		Units:               hour.Units,
+	}, nil
+}
+
+// Hourly implements Provider. Hours the gridpoint data does not cover are
+// left out.
+func (n *NWS) Hourly(
+	latitude, longitude float64,
+	pastHours, forecastHours int64,
+	units RequestUnits,
+) ([]HourlyForecast, error) {
+	grid, periods, loc, err := n.hourlyData(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	start := now().In(loc).Truncate(time.Hour).Add(-time.Duration(pastHours) * time.Hour)
+	var hourlyForecasts []HourlyForecast
+	for i := range pastHours + forecastHours {
+		hour, ok := nwsHour(latitude, longitude, grid, periods, start.Add(time.Duration(i)*time.Hour), units)
+		if ok {
+			hourlyForecasts = append(hourlyForecasts, hour)
+		}
+	}
+	return hourlyForecasts, nil
+}
+
+// Daily implements Provider. The API forecasts about seven days, so fewer
+// than numDays may be returned.
+func (n *NWS) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	point, loc, err := n.point(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := n.Client.GetGridData(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+	forecast, err := n.Client.GetForecast(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+
+	current := now().In(loc)
+	today := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, loc)
+	var dailyForecasts []DailyForecast
+	for i := range int(numDays) {
+		day, ok := nwsDay(grid, forecast.Periods, today.AddDate(0, 0, i), units)
+		if !ok {
+			break
+		}
+		dailyForecasts = append(dailyForecasts, day)
+	}
+	return dailyForecasts, nil
+}
+
+// Alerts implements AlertProvider.
+func (n *NWS) Alerts(latitude, longitude float64) ([]Alert, error) {
+	active, err := n.Client.GetActiveAlerts(latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw alert data: %w", err)
+	}
+
+	alerts := make([]Alert, 0, len(active))
+	for _, a := range active {
+		ends := a.Ends
+		if ends.IsZero() {
+			ends = a.Expires
+		}
+		alerts = append(alerts, Alert{
+			Event:       a.Event,
+			Headline:    a.Headline,
+			Description: a.Description,
+			Instruction: a.Instruction,
+			Severity:    a.Severity,
+			Urgency:     a.Urgency,
+			Sender:      a.SenderName,
+			Onset:       a.Onset,
+			Ends:        ends,
+		})
+	}
+	return alerts, nil
+}
+
+// nwsHour builds the forecast for the hour at t, with the precipitation of
+// the hour before it, and returns false when there is no temperature for it.
+func nwsHour(
+	latitude, longitude float64,
+	grid *nws.GridData,
+	periods []nws.Period,
+	t time.Time,
+	units RequestUnits,
+) (HourlyForecast, bool) {
+	period, hasPeriod := periodAt(periods, t)
+
+	celsius, ok := layerCelsius(grid.Temperature, t)
+	if !ok && hasPeriod {
+		celsius, ok = periodCelsius(period), true
+	}
+	if !ok {
+		return HourlyForecast{}, false
+	}
+	apparent, ok := layerCelsius(grid.ApparentTemperature, t)
+	if !ok {
+		apparent = celsius
+	}
+	humidity, ok := grid.RelativeHumidity.At(t)
+	if !ok && hasPeriod && period.RelativeHumidity.Value != nil {
+		humidity = *period.RelativeHumidity.Value
+	}
+	windKmh, ok := layerKmh(grid.WindSpeed, t)
+	if !ok && hasPeriod {
+		windKmh = periodKmh(period)
+	}
+	gustKmh, ok := layerKmh(grid.WindGust, t)
+	if !ok {
+		gustKmh = windKmh
+	}
+	direction, _ := grid.WindDirection.At(t)
+	cloud, _ := grid.SkyCover.At(t)
+	probability, ok := grid.ProbabilityOfPrecipitation.At(t)
+	if !ok && hasPeriod && period.ProbabilityOfPrecipitation.Value != nil {
+		probability = *period.ProbabilityOfPrecipitation.Value
+	}
+	precipitation, _ := grid.QuantitativePrecipitation.Amount(t.Add(-time.Hour), t)
+	snowfall, _ := grid.SnowfallAmount.Amount(t.Add(-time.Hour), t)
+
+	hour := HourlyForecast{
+		DateTime:            t,
+		Temperature:         convertTemperature(celsius, units.Temperature),
+		Humidity:            humidity,
+		ApparentTemperature: convertTemperature(apparent, units.Temperature),
+		Cloudy:              cloud,
+		WindSpeed:           convertWindSpeed(windKmh, units.WindSpeed),
+		WindGusts:           convertWindSpeed(gustKmh, units.WindSpeed),
+		WindDirection:       Direction(direction),
+		Precipitation:       convertPrecipitation(precipitation, units.Precipitation),
+		SnowFall:            convertSnowfall(snowfall, units.Precipitation),
+		PrecipitationProb:   probability,
+		Units:               unitsFor(units),
+	}
+	if hasPeriod {
+		hour.Condition = Condition{Code: iconCode(period.Icon, cloud, precipitation, snowfall)}
+		hour.IsDay = boolToInt(period.IsDaytime)
+	} else {
+		hour.Condition = Condition{Code: gridCode(cloud, precipitation, snowfall)}
+		hour.IsDay = boolToInt(isDaytime(latitude, longitude, t))
+	}
+	return hour, true
+}
+
+// nwsDay builds the forecast for the day starting at midnight date, and
+// returns false when there are no temperatures for it.
+func nwsDay(grid *nws.GridData, periods []nws.Period, date time.Time, units RequestUnits) (DailyForecast, bool) {
+	end := date.AddDate(0, 0, 1)
+
+	// The hourly values stand in for missing extremes, e.g. for the part of
+	// today that has passed.
+	var hourly []float64
+	maxWind, maxGust, direction, probabilities, probabilitySum := 0.0, 0.0, 0.0, 0, 0.0
+	for t := date; t.Before(end); t = t.Add(time.Hour) {
+		if celsius, ok := layerCelsius(grid.Temperature, t); ok {
+			hourly = append(hourly, celsius)
+		}
+		if wind, ok := layerKmh(grid.WindSpeed, t); ok && wind >= maxWind {
+			maxWind = wind
+			direction, _ = grid.WindDirection.At(t)
+		}
+		if gust, ok := layerKmh(grid.WindGust, t); ok {
+			maxGust = max(maxGust, gust)
+		}
+		if probability, ok := grid.ProbabilityOfPrecipitation.At(t); ok {
+			probabilities++
+			probabilitySum += probability
+		}
+	}
+	maxCelsius, hasMax := layerStartingIn(grid.MaxTemperature, date, end)
+	minCelsius, hasMin := layerStartingIn(grid.MinTemperature, date, end)
+	if len(hourly) == 0 && !hasMax && !hasMin {
+		return DailyForecast{}, false
+	}
+	if len(hourly) > 0 {
+		if !hasMax {
+			maxCelsius = hourly[0]
+			for _, c := range hourly {
+				maxCelsius = max(maxCelsius, c)
+			}
+		}
+		if !hasMin {
+			minCelsius = hourly[0]
+			for _, c := range hourly {
+				minCelsius = min(minCelsius, c)
+			}
+		}
+	}
+	maxGust = max(maxGust, maxWind)
+
+	precipitation, _ := grid.QuantitativePrecipitation.Amount(date, end)
+	snowfall, _ := grid.SnowfallAmount.Amount(date, end)
+	probability := 0.0
+	if probabilities > 0 {
+		probability = probabilitySum / float64(probabilities)
+	}
+
+	day := DailyForecast{
+		Date:              date,
+		MaxTemperature:    convertTemperature(maxCelsius, units.Temperature),
+		MinTemperature:    convertTemperature(minCelsius, units.Temperature),
+		PrecipitationSum:  convertPrecipitation(precipitation, units.Precipitation),
+		PrecipitationProb: probability,
+		MaxWindSpeed:      convertWindSpeed(maxWind, units.WindSpeed),
+		WindGusts:         convertWindSpeed(maxGust, units.WindSpeed),
+		WindDirection:     Direction(direction),
+		Units:             unitsFor(units),
+	}
+	if period, ok := dayPeriod(periods, date, end); ok {
+		day.Condition = Condition{Code: iconCode(period.Icon, 0, precipitation, snowfall)}
+	} else {
+		cloud, _ := grid.SkyCover.At(date.Add(12 * time.Hour))
+		day.Condition = Condition{Code: gridCode(cloud, precipitation, snowfall)}
+	}
+	return day, true
+}
+
+// periodAt returns the period containing t.
+func periodAt(periods []nws.Period, t time.Time) (nws.Period, bool) {
+	for _, p := range periods {
+		if !t.Before(p.StartTime) && t.Before(p.EndTime) {
+			return p, true
+		}
+	}
+	return nws.Period{}, false
+}
+
+// dayPeriod returns the daytime period of a day, or any period overlapping
+// it, e.g. the night when the day has passed.
+func dayPeriod(periods []nws.Period, from, to time.Time) (nws.Period, bool) {
+	var fallback *nws.Period
+	for i, p := range periods {
+		if !p.StartTime.Before(to) || !p.EndTime.After(from) {
+			continue
+		}
+		if p.IsDaytime {
+			return p, true
+		}
+		if fallback == nil {
+			fallback = &periods[i]
+		}
+	}
+	if fallback == nil {
+		return nws.Period{}, false
+	}
+	return *fallback, true
+}
+
+// layerStartingIn returns the value of a layer whose interval starts
+// between from and to, like the daytime maximum of a day.
+func layerStartingIn(layer nws.Layer, from, to time.Time) (float64, bool) {
+	for _, v := range layer.Values {
+		start, _, err := v.Interval()
+		if err != nil || v.Value == nil || start.Before(from) || !start.Before(to) {
+			continue
+		}
+		return toCelsius(*v.Value, layer.UOM), true
+	}
+	return 0, false
+}
+
+func layerCelsius(layer nws.Layer, t time.Time) (float64, bool) {
+	v, ok := layer.At(t)
+	return toCelsius(v, layer.UOM), ok
+}
+
+func layerKmh(layer nws.Layer, t time.Time) (float64, bool) {
+	v, ok := layer.At(t)
+	if layer.UOM == "wmoUnit:m_s-1" {
+		v *= 3.6
+	}
+	return v, ok
+}
+
+func toCelsius(v float64, uom string) float64 {
+	if uom == "wmoUnit:degF" {
+		return fahrenheitToCelsius(v)
+	}
+	return v
+}
+
+func periodCelsius(p nws.Period) float64 {
+	if p.TemperatureUnit == "F" {
+		return fahrenheitToCelsius(p.Temperature)
+	}
+	return p.Temperature
+}
+
+func periodKmh(p nws.Period) float64 {
+	speed, unit, err := p.Wind()
+	if err != nil {
+		return 0
+	}
+	return toMetersPerSecond(speed, unit) * 3.6
+}
+
+// nwsIconCodes maps the API's icon names to WMO weather codes.
+var nwsIconCodes = map[string]int{
+	"skc": 0, "few": 1, "sct": 2, "bkn": 3, "ovc": 3,
+	"wind_skc": 0, "wind_few": 1, "wind_sct": 2, "wind_bkn": 3, "wind_ovc": 3,
+	"fog": 45, "haze": 45, "smoke": 45, "dust": 45,
+	"rain": 63, "rain_showers": 80, "rain_showers_hi": 80,
+	"fzra": 67, "rain_fzra": 66, "snow_fzra": 67,
+	"snow": 73, "rain_snow": 71, "blizzard": 75,
+	"sleet": 77, "rain_sleet": 77, "snow_sleet": 77,
+	"tsra": 95, "tsra_sct": 95, "tsra_hi": 95, "tropical_storm": 95,
+	"hurricane": 99, "tornado": 99,
+	"hot": 0, "cold": 0,
+}
+
+// iconCode returns the WMO code of a period's icon, e.g.
+// https://api.weather.gov/icons/land/day/tsra,40?size=medium. Icons can
+// show two conditions in turn; the first one is used. Unknown icons fall
+// back to gridCode.
+func iconCode(icon string, cloud, precipitation, snowfall float64) int {
+	if u, err := url.Parse(icon); err == nil {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		for i, segment := range segments {
+			if (segment == "day" || segment == "night") && i+1 < len(segments) {
+				name, _, _ := strings.Cut(path.Base(segments[i+1]), ",")
+				if code, ok := nwsIconCodes[name]; ok {
+					return code
+				}
+				break
+			}
+		}
+	}
+	return gridCode(cloud, precipitation, snowfall)
+}
+
+// gridCode returns a WMO code for cloud cover in percent and amounts of
+// precipitation and snow in mm.
+func gridCode(cloud, precipitation, snowfall float64) int {
+	switch {
+	case snowfall > 0:
+		return 71
+	case precipitation > 0:
+		return 61
+	case cloud < 20:
+		return 0
+	case cloud < 50:
+		return 1
+	case cloud < 85:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,193 @@
+package weather
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/nws"
+	"github.com/mohithbuilds/sky/internal/client/nws/nwstest"
+)
+
+// newNWSClient returns a WeatherClient backed by a fake NWS API, at the
+// fake's time.
+func newNWSClient(t *testing.T) (*WeatherClient, *nwstest.Server) {
+	t.Helper()
+	fake := nwstest.NewServer()
+	t.Cleanup(fake.Close)
+	original := now
+	now = func() time.Time { return fake.Now }
+	t.Cleanup(func() { now = original })
+
+	client := nws.NewClient(nil)
+	client.BaseURL = fake.BaseURL()
+	return NewWeatherClientFrom(&NWS{Client: client}), fake
+}
+
+func TestNWS_Current(t *testing.T) {
+	weatherClient, fake := newNWSClient(t)
+
+	current, err := weatherClient.GetCurrentWeather(40.71, -74.01, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if !current.ObservationTime.Equal(nwstest.DefaultNow) || current.ObservationTime.Location().String() != nwstest.DefaultTimeZone {
+		t.Errorf("Expected the current hour in the point's time zone, got %s", current.ObservationTime)
+	}
+	if current.Units != (Units{"°C", "km/h", "mm"}) {
+		t.Errorf("Expected metric units, got %+v", current.Units)
+	}
+	// The fake's day is mostly cloudy.
+	if current.Condition.Code != 3 || current.WeatherDescription == "" || current.IsDay != 1 {
+		t.Errorf("Expected a cloudy day, got %+v", current)
+	}
+	if current.WindGusts <= current.WindSpeed || current.WindDirection != 225 {
+		t.Errorf("Expected gusts above the wind from the southwest, got %+v", current)
+	}
+
+	imperial, err := weatherClient.GetCurrentWeather(40.71, -74.01, "fahrenheit", "mph", "inch")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if diff := imperial.Temperature - (current.Temperature*9/5 + 32); diff < -0.01 || diff > 0.01 {
+		t.Errorf("Expected %.1f°F, got %v", current.Temperature*9/5+32, imperial.Temperature)
+	}
+	if imperial.Units != (Units{"°F", "mp/h", "inch"}) {
+		t.Errorf("Expected imperial units, got %+v", imperial.Units)
+	}
+
+	// Grids do not change, so the point is only looked up once.
+	points := 0
+	for _, r := range fake.Requests() {
+		if r.Endpoint == "points" {
+			points++
+		}
+	}
+	if points != 1 {
+		t.Errorf("Expected 1 point request, got %d", points)
+	}
+}
+
+func TestNWS_Hourly(t *testing.T) {
+	weatherClient, _ := newNWSClient(t)
+
+	hourly, err := weatherClient.GetHourlyForecast(40.71, -74.01, 48, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(hourly) != 48 || !hourly[0].DateTime.Equal(nwstest.DefaultNow) {
+		t.Fatalf("Expected 48 hours from now, got %d", len(hourly))
+	}
+	// The next day rains in the afternoon, 7.2 mm in six hours.
+	rainy := hourly[29]
+	if rainy.DateTime.Hour() != 13 || rainy.Condition.Code != 63 {
+		t.Errorf("Expected rain at 13:00, got %+v", rainy)
+	}
+	if rainy.Precipitation < 1.19 || rainy.Precipitation > 1.21 || rainy.PrecipitationProb != 80 {
+		t.Errorf("Expected 1.2 mm an hour, got %+v", rainy)
+	}
+	// Precipitation is that of the hour before, so it starts an hour after
+	// the rain does and ends with it.
+	if hourly[28].Precipitation != 0 || hourly[34].DateTime.Hour() != 18 || hourly[34].Precipitation < 1.19 {
+		t.Errorf("Expected the rain from 12:00 to 18:00 at 13:00 to 18:00, got %+v and %+v", hourly[28], hourly[34])
+	}
+
+	// Past hours have no periods; their conditions come from the grid.
+	past, err := weatherClient.GetPastHourly(40.71, -74.01, 3, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetPastHourly failed: %v", err)
+	}
+	if len(past) != 3 || !past[2].DateTime.Equal(nwstest.DefaultNow.Add(-time.Hour)) {
+		t.Fatalf("Expected the 3 hours before now, got %+v", past)
+	}
+	if past[0].Condition.Code != 2 || past[0].IsDay != 0 || past[2].IsDay != 1 {
+		t.Errorf("Expected a partly cloudy morning from before sunrise, got %+v", past)
+	}
+}
+
+func TestNWS_Daily(t *testing.T) {
+	weatherClient, _ := newNWSClient(t)
+
+	daily, err := weatherClient.GetDailyForecast(40.71, -74.01, 10, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetDailyForecast failed: %v", err)
+	}
+	// The gridpoint data runs out after a week.
+	if len(daily) != 7 {
+		t.Fatalf("Expected 7 days, got %d", len(daily))
+	}
+	if daily[0].Date.Day() != 15 || daily[0].Date.Hour() != 0 {
+		t.Errorf("Expected today at midnight, got %s", daily[0].Date)
+	}
+	for _, day := range daily {
+		if day.MaxTemperature <= day.MinTemperature {
+			t.Errorf("Expected the maximum above the minimum, got %+v", day)
+		}
+		if day.Sunrise.IsZero() || !day.Sunset.After(day.Sunrise) {
+			t.Errorf("Expected the sunrise and sunset to be calculated, got %s and %s", day.Sunrise, day.Sunset)
+		}
+	}
+	if daily[1].Condition.Code != 63 || daily[1].PrecipitationSum < 7.19 || daily[1].PrecipitationSum > 7.21 {
+		t.Errorf("Expected 7.2 mm of rain tomorrow, got %+v", daily[1])
+	}
+	if daily[2].Condition.Code != 0 || daily[2].PrecipitationSum != 0 {
+		t.Errorf("Expected a clear day after, got %+v", daily[2])
+	}
+}
+
+func TestNWS_Alerts(t *testing.T) {
+	weatherClient, fake := newNWSClient(t)
+	expires := nwstest.DefaultNow.Add(8 * time.Hour)
+	fake.Alerts = []nws.Alert{{
+		Event:      "Heat Advisory",
+		Headline:   "Heat Advisory until 8PM EDT",
+		Severity:   "Moderate",
+		SenderName: "NWS New York NY",
+		Onset:      nwstest.DefaultNow,
+		Expires:    expires,
+	}}
+
+	alerts, err := weatherClient.GetActiveAlerts(40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Event != "Heat Advisory" || alerts[0].Sender != "NWS New York NY" {
+		t.Fatalf("Expected the heat advisory, got %+v", alerts)
+	}
+	if !alerts[0].Ends.Equal(expires) {
+		t.Errorf("Expected an alert without an end to end when it expires, got %s", alerts[0].Ends)
+	}
+
+	if _, err := NewWeatherClient(&mockForecastClient{}).GetActiveAlerts(40.71, -74.01); err == nil {
+		t.Errorf("Expected Open-Meteo to have no alerts")
+	}
+}
+
+func TestNWS_OutsideUS(t *testing.T) {
+	weatherClient, _ := newNWSClient(t)
+
+	_, err := weatherClient.GetCurrentWeather(51.5, -0.12, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "Data Unavailable For Requested Point") {
+		t.Errorf("Expected a location outside the US to fail, got %v", err)
+	}
+}
+
+func TestIconCode(t *testing.T) {
+	tests := []struct {
+		icon     string
+		cloud    float64
+		expected int
+	}{
+		{"https://api.weather.gov/icons/land/day/skc?size=medium", 0, 0},
+		{"https://api.weather.gov/icons/land/day/tsra,40?size=medium", 0, 95},
+		{"https://api.weather.gov/icons/land/night/rain_showers,30/tsra,60?size=small", 0, 80},
+		{"https://api.weather.gov/icons/land/day/wind_bkn", 0, 3},
+		{"https://api.weather.gov/icons/land/day/unknown", 40, 1},
+		{"", 95, 3},
+	}
+	for _, tt := range tests {
+		if got := iconCode(tt.icon, tt.cloud, 0, 0); got != tt.expected {
+			t.Errorf("iconCode(%q): expected %d, got %d", tt.icon, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package weather
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+)
+
+const openMeteoLayout = "2006-01-02T15:04"
+
+// ForecastClient is an interface for a client that can fetch weather data.
+type ForecastClient interface {
+	GetWeather(
+		latitude, longitude float64,
+		currentParameters []string,
+		hourlyParameters []string,
+		dailyParameters []string,
+		temperatureUnit string,
+		windSpeedUnit string,
+		precipitationUnit string,
+		pastDays int64,
+		forecastDays int64,
+		pastHours int64,
+		forecastHours int64,
+	) (*openmateo.ForecastResult, error)
+}
+
+// OpenMeteo is the Provider backed by the Open-Meteo forecast API.
+type OpenMeteo struct {
+	Client ForecastClient
+}
+
+// Current implements Provider.
+func (o *OpenMeteo) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	// Define the specific current parameters we want from the Open-Meteo API
+	currentParams := []string{
+		"temperature_2m",
+		"relative_humidity_2m",
+		"weather_code",
+		"is_day",
+		"apparent_temperature",
+		"precipitation",
+		"wind_speed_10m",
+		"wind_direction_10m",
+		"wind_gusts_10m",
+	}
+
+	// Call the low-level openmateo client's GetWeather function
+	// We only care about current data, so other slices are empty.
+	forecast, err := o.Client.GetWeather(
+		latitude,
+		longitude,
+		currentParams,
+		[]string{}, // No hourly data
+		[]string{}, // No daily data
+		units.Temperature,
+		units.WindSpeed,
+		units.Precipitation,
+		0,
+		0,
+		0,
+		0,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	}
+
+	if forecast.Current == nil {
+		return nil, fmt.Errorf(
+			"no current weather data returned for %.2f, %.2f",
+			latitude,
+			longitude,
+		)
+	}
+
+	obsTime, err := parseTime(forecast.Current.Time, forecast.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CurrentWeather{
+		Temperature:         forecast.Current.Temperature2m,
+		Humidity:            forecast.Current.RelativeHumidity2m,
+		ApparentTemperature: forecast.Current.ApparentTemperature,
+		Precipitation:       forecast.Current.Precipitation,
+		WindSpeed:           forecast.Current.WindSpeed10m,
+		WindGusts:           forecast.Current.WindGusts10m,
+		WindDirection:       Direction(forecast.Current.WindDirection10m),
+		Condition:           Condition{Code: forecast.Current.WeatherCode},
+		ObservationTime:     obsTime,
+		IsDay:               forecast.Current.IsDay,
+		Units: Units{
+			Temperature:   forecast.CurrentUnits.Temperature2m,
+			Precipitation: forecast.CurrentUnits.Precipitation,
+			WindSpeed:     forecast.CurrentUnits.WindSpeed10m,
+		},
+	}, nil
+}
+
+// Hourly implements Provider.
+func (o *OpenMeteo) Hourly(
+	latitude, longitude float64,
+	pastHours, forecastHours int64,
+	units RequestUnits,
+) ([]HourlyForecast, error) {
+	hourlyParams := []string{
+		"temperature_2m",
+		"relative_humidity_2m",
+		"apparent_temperature",
+		"cloud_cover",
+		"wind_speed_10m",
+		"wind_direction_10m",
+		"wind_gusts_10m",
+		"precipitation",
+		"snowfall",
+		"precipitation_probability",
+		"weather_code",
+		"is_day",
+	}
+
+	forecast, err := o.Client.GetWeather(
+		latitude,
+		longitude,
+		[]string{},
+		hourlyParams,
+		[]string{},
+		units.Temperature,
+		units.WindSpeed,
+		units.Precipitation,
+		0,
+		0,
+		pastHours,
+		forecastHours,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw hourly forecast data: %w", err)
+	}
+
+	// Add comprehensive nil checks for all slices we will access.
+	if forecast.Hourly == nil || forecast.Hourly.Time == nil ||
+		forecast.Hourly.Temperature2m == nil || forecast.Hourly.RelativeHumidity2m == nil ||
+		forecast.Hourly.ApparentTemperature == nil || forecast.Hourly.CloudCover == nil ||
+		forecast.Hourly.WindSpeed10m == nil || forecast.Hourly.WindDirection10m == nil ||
+		forecast.Hourly.WindGusts10m == nil || forecast.Hourly.Precipitation == nil ||
+		forecast.Hourly.Snowfall == nil || forecast.Hourly.PrecipitationProbability == nil ||
+		forecast.Hourly.WeatherCode == nil || forecast.Hourly.IsDay == nil {
+		return nil, fmt.Errorf("hourly forecast data is incomplete or missing from API response")
+	}
+
+	// Check that all hourly slices have the same length
+	numHoursReturned := len(forecast.Hourly.Time)
+	if len(forecast.Hourly.Temperature2m) != numHoursReturned ||
+		len(forecast.Hourly.RelativeHumidity2m) != numHoursReturned ||
+		len(forecast.Hourly.ApparentTemperature) != numHoursReturned ||
+		len(forecast.Hourly.CloudCover) != numHoursReturned ||
+		len(forecast.Hourly.WindSpeed10m) != numHoursReturned ||
+		len(forecast.Hourly.WindDirection10m) != numHoursReturned ||
+		len(forecast.Hourly.WindGusts10m) != numHoursReturned ||
+		len(forecast.Hourly.Precipitation) != numHoursReturned ||
+		len(forecast.Hourly.Snowfall) != numHoursReturned ||
+		len(forecast.Hourly.PrecipitationProbability) != numHoursReturned ||
+		len(forecast.Hourly.WeatherCode) != numHoursReturned ||
+		len(forecast.Hourly.IsDay) != numHoursReturned {
+		return nil, fmt.Errorf("API returned hourly forecast data with inconsistent lengths")
+	}
+
+	hourlyForecasts := make([]HourlyForecast, len(forecast.Hourly.Time))
+	resultUnits := Units{
+		Temperature:   forecast.HourlyUnits.Temperature2m,
+		Precipitation: forecast.HourlyUnits.Precipitation,
+		WindSpeed:     forecast.HourlyUnits.WindSpeed10m,
+	}
+
+	for i := range forecast.Hourly.Time {
+		forecastTime, err := parseTime(forecast.Hourly.Time[i], forecast.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		hourlyForecasts[i] = HourlyForecast{
+			DateTime:            forecastTime,
+			Temperature:         forecast.Hourly.Temperature2m[i],
+			Humidity:            forecast.Hourly.RelativeHumidity2m[i],
+			ApparentTemperature: forecast.Hourly.ApparentTemperature[i],
+			Cloudy:              forecast.Hourly.CloudCover[i],
+			WindSpeed:           forecast.Hourly.WindSpeed10m[i],
+			WindGusts:           forecast.Hourly.WindGusts10m[i],
+			WindDirection:       Direction(forecast.Hourly.WindDirection10m[i]),
+			Precipitation:       forecast.Hourly.Precipitation[i],
+			SnowFall:            forecast.Hourly.Snowfall[i],
+			PrecipitationProb:   forecast.Hourly.PrecipitationProbability[i],
+			Condition:           Condition{Code: forecast.Hourly.WeatherCode[i]},
+			IsDay:               forecast.Hourly.IsDay[i],
+			Units:               resultUnits,
+		}
+	}
+
+	return hourlyForecasts, nil
+}
+
+// Daily implements Provider.
+func (o *OpenMeteo) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	// Define the specific daily parameters we want from the Open-Meteo API
+	dailyParams := []string{
+		"temperature_2m_max",
+		"temperature_2m_min",
+		"weather_code",
+		"sunrise",
+		"sunset",
+		"precipitation_sum",
+		"precipitation_probability_mean",
+		"wind_speed_10m_max",
+		"wind_gusts_10m_max",
+		"wind_direction_10m_dominant",
+		"snow_depth",
+	}
+
+	forecast, err := o.Client.GetWeather(
+		latitude,
+		longitude,
+		[]string{}, // No current data
+		[]string{}, // No hourly data
+		dailyParams,
+		units.Temperature,
+		units.WindSpeed,
+		units.Precipitation,
+		0,       // No past days
+		numDays, // Request numDays of forecast
+		0,
+		0,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw daily forecast data: %w", err)
+	}
+
+	// Add comprehensive nil checks for all slices we will access.
+	if forecast.Daily == nil || forecast.Daily.Time == nil ||
+		forecast.Daily.Temperature2mMax == nil || forecast.Daily.Temperature2mMin == nil ||
+		forecast.Daily.WeatherCode == nil || forecast.Daily.Sunrise == nil ||
+		forecast.Daily.Sunset == nil || forecast.Daily.PrecipitationSum == nil ||
+		forecast.Daily.PrecipitationProbabilityMean == nil || forecast.Daily.WindSpeed10mMax == nil ||
+		forecast.Daily.WindGusts10mMax == nil || forecast.Daily.WindDirection10mDominant == nil {
+		return nil, fmt.Errorf("daily forecast data is incomplete or missing from API response")
+	}
+
+	// Check that all daily slices have the same length
+	numDaysReturned := len(forecast.Daily.Time)
+	if len(forecast.Daily.Temperature2mMax) != numDaysReturned ||
+		len(forecast.Daily.Temperature2mMin) != numDaysReturned ||
+		len(forecast.Daily.WeatherCode) != numDaysReturned ||
+		len(forecast.Daily.Sunrise) != numDaysReturned ||
+		len(forecast.Daily.Sunset) != numDaysReturned ||
+		len(forecast.Daily.PrecipitationSum) != numDaysReturned ||
+		len(forecast.Daily.PrecipitationProbabilityMean) != numDaysReturned ||
+		len(forecast.Daily.WindSpeed10mMax) != numDaysReturned ||
+		len(forecast.Daily.WindGusts10mMax) != numDaysReturned ||
+		len(forecast.Daily.WindDirection10mDominant) != numDaysReturned {
+		return nil, fmt.Errorf("API returned daily forecast data with inconsistent lengths")
+	}
+
+	dailyForecasts := make([]DailyForecast, len(forecast.Daily.Time))
+	resultUnits := Units{
+		Temperature:   forecast.DailyUnits.Temperature2mMax,
+		Precipitation: forecast.DailyUnits.PrecipitationSum,
+		WindSpeed:     forecast.DailyUnits.WindSpeed10mMax,
+	}
+
+	for i := range forecast.Daily.Time {
+		forecastDate, err := parseTime(forecast.Daily.Time[i], forecast.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		sunriseTime, err := parseTime(forecast.Daily.Sunrise[i], forecast.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		sunsetTime, err := parseTime(forecast.Daily.Sunset[i], forecast.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		dailyForecasts[i] = DailyForecast{
+			Date:              forecastDate,
+			MaxTemperature:    forecast.Daily.Temperature2mMax[i],
+			MinTemperature:    forecast.Daily.Temperature2mMin[i],
+			Condition:         Condition{Code: forecast.Daily.WeatherCode[i]},
+			Sunrise:           sunriseTime,
+			Sunset:            sunsetTime,
+			PrecipitationSum:  forecast.Daily.PrecipitationSum[i],
+			PrecipitationProb: forecast.Daily.PrecipitationProbabilityMean[i],
+			MaxWindSpeed:      forecast.Daily.WindSpeed10mMax[i],
+			WindGusts:         forecast.Daily.WindGusts10mMax[i],
+			WindDirection:     Direction(forecast.Daily.WindDirection10mDominant[i]),
+			Units:             resultUnits,
+		}
+	}
+
+	return dailyForecasts, nil
+}
+
+func parseTime(timeStr, timezoneStr string) (time.Time, error) {
+	location, err := loadTimezone(timezoneStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	parsedTime, err := time.ParseInLocation(openMeteoLayout, timeStr, location)
+	if err != nil {
+		parsedTime, err = time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			parsedTime, err = time.ParseInLocation("2006-01-02", timeStr, location)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("failed to parse time: %w", err)
+			}
+		}
+	}
+	return parsedTime, nil
+}
+
+func loadTimezone(timezoneStr string) (*time.Location, error) {
+	if timezoneStr == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezoneStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location from timezone: %w", err)
+	}
+	return location, nil
+}
@@ -0,0 +1,141 @@
+package weather
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/astro"
+)
+
+// RequestUnits names the units a Provider returns values in, in the
+// vocabulary of the Open-Meteo API: "celsius" or "fahrenheit", "kmh", "ms",
+// "mph" or "kn", and "mm" or "inch". Empty names select the first.
+type RequestUnits struct {
+	Temperature   string
+	WindSpeed     string
+	Precipitation string
+}
+
+// Provider is a source of weather data. Implementations return values in the
+// requested units, with Units naming them and Condition.Code set to the WMO
+// weather code of each entry. The WeatherClient fills in everything derived
+// from that: conditions and descriptions in its language, astronomy, the
+// sunrise and sunset when a Provider has none, and recording.
+type Provider interface {
+	// Current returns the latest observed or analysed conditions.
+	Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error)
+	// Hourly returns pastHours hours before the current hour and
+	// forecastHours hours from it.
+	Hourly(latitude, longitude float64, pastHours, forecastHours int64, units RequestUnits) ([]HourlyForecast, error)
+	// Daily returns numDays days starting today.
+	Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error)
+}
+
+// Alert is an official watch, warning or advisory in effect at a location.
+type Alert struct {
+	Event       string    `json:"event"` // e.g. "Heat Advisory"
+	Headline    string    `json:"headline"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction,omitempty"`
+	Severity    string    `json:"severity"` // Extreme, Severe, Moderate, Minor or Unknown
+	Urgency     string    `json:"urgency"`
+	Sender      string    `json:"sender"`
+	Onset       time.Time `json:"onset"`
+	Ends        time.Time `json:"ends"`
+}
+
+// AlertProvider is implemented by Providers that also publish official
+// alerts.
+type AlertProvider interface {
+	Alerts(latitude, longitude float64) ([]Alert, error)
+}
+
+// GetActiveAlerts fetches the official alerts in effect at a location. It
+// fails when the client's Provider publishes none.
+func (w *WeatherClient) GetActiveAlerts(latitude, longitude float64) ([]Alert, error) {
+	provider, ok := w.provider.(AlertProvider)
+	if !ok {
+		return nil, fmt.Errorf("the weather provider has no alerts")
+	}
+	return provider.Alerts(latitude, longitude)
+}
+
+// unitsFor returns the unit strings of values converted to units, as
+// Open-Meteo names them.
+func unitsFor(units RequestUnits) Units {
+	result := Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"}
+	if units.Temperature == "fahrenheit" {
+		result.Temperature = "°F"
+	}
+	switch units.WindSpeed {
+	case "ms":
+		result.WindSpeed = "m/s"
+	case "mph":
+		result.WindSpeed = "mp/h"
+	case "kn":
+		result.WindSpeed = "kn"
+	}
+	if units.Precipitation == "inch" {
+		result.Precipitation = "inch"
+	}
+	return result
+}
+
+// convertTemperature, convertWindSpeed and convertPrecipitation convert
+// values in °C, km/h and mm to the requested units.
+func convertTemperature(celsius float64, unit string) float64 {
+	if unit == "fahrenheit" {
+		return celsiusToFahrenheit(celsius)
+	}
+	return celsius
+}
+
+func convertWindSpeed(kmh float64, unit string) float64 {
+	switch unit {
+	case "ms":
+		return kmh / 3.6
+	case "mph":
+		return kmh / 1.609344
+	case "kn":
+		return kmh / 1.852
+	default:
+		return kmh
+	}
+}
+
+func convertPrecipitation(mm float64, unit string) float64 {
+	if unit == "inch" {
+		return mm / 25.4
+	}
+	return mm
+}
+
+// convertSnowfall converts mm of snow to cm or inches, the units Open-Meteo
+// reports snowfall in.
+func convertSnowfall(mm float64, unit string) float64 {
+	if unit == "inch" {
+		return mm / 25.4
+	}
+	return mm / 10
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// isDaytime reports whether the sun is up at a location at t, for
+// providers that do not say. During the midnight sun and the polar night
+// there is no sunrise or sunset to compare with.
+func isDaytime(latitude, longitude float64, t time.Time) bool {
+	sun := astro.Sun(astro.Location{Latitude: latitude, Longitude: longitude}, t)
+	switch {
+	case sun.MidnightSun:
+		return true
+	case sun.PolarNight:
+		return false
+	}
+	return !t.Before(sun.Sunrise) && t.Before(sun.Sunset)
+}
@@ -0,0 +1,106 @@
+package weather
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockProvider is a Provider returning fixed data, standing in for a
+// source other than Open-Meteo.
+type mockProvider struct {
+	current *CurrentWeather
+	hourly  []HourlyForecast
+	daily   []DailyForecast
+	units   RequestUnits
+}
+
+func (m *mockProvider) Current(latitude, longitude float64, units RequestUnits) (*CurrentWeather, error) {
+	m.units = units
+	return m.current, nil
+}
+
+func (m *mockProvider) Hourly(latitude, longitude float64, pastHours, forecastHours int64, units RequestUnits) ([]HourlyForecast, error) {
+	m.units = units
+	return m.hourly, nil
+}
+
+func (m *mockProvider) Daily(latitude, longitude float64, numDays int64, units RequestUnits) ([]DailyForecast, error) {
+	m.units = units
+	return m.daily, nil
+}
+
+func TestWeatherClient_Provider(t *testing.T) {
+	day := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	provider := &mockProvider{
+		current: &CurrentWeather{Temperature: 20, Condition: Condition{Code: 61}},
+		hourly:  []HourlyForecast{{DateTime: day, Condition: Condition{Code: 0}}},
+		daily:   []DailyForecast{{Date: day, Condition: Condition{Code: 95}}},
+	}
+	weatherClient := NewWeatherClientFrom(provider)
+	weatherClient.Language = "de"
+
+	current, err := weatherClient.GetCurrentWeather(52.52, 13.41, "fahrenheit", "mph", "inch")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if provider.units != (RequestUnits{"fahrenheit", "mph", "inch"}) {
+		t.Errorf("Expected the requested units to reach the provider, got %+v", provider.units)
+	}
+	if current.WeatherDescription == "" || current.WeatherDescription != current.Condition.Description {
+		t.Errorf("Expected a description from the condition code, got %+v", current)
+	}
+	if current.WeatherDescription != mapWeatherCodeToDescription(61, "de") {
+		t.Errorf("Expected a German description, got %q", current.WeatherDescription)
+	}
+
+	hourly, err := weatherClient.GetHourlyForecast(52.52, 13.41, 1, "", "", "")
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if hourly[0].WeatherDescription != mapWeatherCodeToDescription(0, "de") {
+		t.Errorf("Expected the hourly description, got %q", hourly[0].WeatherDescription)
+	}
+
+	daily, err := weatherClient.GetDailyForecast(52.52, 13.41, 1, "", "", "")
+	if err != nil {
+		t.Fatalf("GetDailyForecast failed: %v", err)
+	}
+	if daily[0].WeatherDescription != mapWeatherCodeToDescription(95, "de") {
+		t.Errorf("Expected the daily description, got %q", daily[0].WeatherDescription)
+	}
+	if daily[0].Astronomy.Sun.SolarNoon.IsZero() {
+		t.Errorf("Expected the astronomy to be calculated, got %+v", daily[0].Astronomy)
+	}
+}
+
+func TestWeatherClient_ProviderEmpty(t *testing.T) {
+	weatherClient := NewWeatherClientFrom(&mockProvider{})
+
+	if _, err := weatherClient.GetHourlyForecast(52.52, 13.41, 1, "", "", ""); err == nil || !strings.Contains(err.Error(), "no hourly forecast data") {
+		t.Errorf("Expected an error for no hours, got %v", err)
+	}
+	if _, err := weatherClient.GetDailyForecast(52.52, 13.41, 1, "", "", ""); err == nil || !strings.Contains(err.Error(), "no daily forecast data") {
+		t.Errorf("Expected an error for no days, got %v", err)
+	}
+}
+
+func TestIsDaytime(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		t         time.Time
+		expected  bool
+	}{
+		{"Berlin at noon", 52.52, 13.41, time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC), true},
+		{"Berlin at midnight", 52.52, 13.41, time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), false},
+		{"Tromsø at midnight in the midnight sun", 69.65, 18.96, time.Date(2026, 6, 24, 23, 0, 0, 0, time.UTC), true},
+		{"Tromsø at noon in the midnight sun", 69.65, 18.96, time.Date(2026, 6, 24, 11, 0, 0, 0, time.UTC), true},
+		{"Tromsø at noon in the polar night", 69.65, 18.96, time.Date(2026, 12, 20, 11, 0, 0, 0, time.UTC), false},
+	} {
+		if got := isDaytime(tt.latitude, tt.longitude, tt.t); got != tt.expected {
+			t.Errorf("%s: expected daytime %t, got %t", tt.name, tt.expected, got)
+		}
+	}
+}
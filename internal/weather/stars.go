@@ -0,0 +1,245 @@
+package weather
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/astro"
+)
+
+// ObservingHour scores one forecast hour for stargazing from 0 (pointless) to
+// 100 (perfect). Only hours with the sun below nautical twilight are scored.
+type ObservingHour struct {
+	Time             time.Time `json:"time"`
+	Score            int       `json:"score"`
+	Rating           string    `json:"rating"`
+	CloudCover       float64   `json:"cloud_cover"`
+	Humidity         float64   `json:"humidity"`
+	WindSpeed        float64   `json:"wind_speed"`
+	SunElevation     float64   `json:"sun_elevation"`
+	MoonElevation    float64   `json:"moon_elevation"`
+	MoonIllumination float64   `json:"moon_illumination"`
+	AstronomicalDark bool      `json:"astronomical_dark"` // The sun is at least 18° below the horizon
+}
+
+// ObservingNight groups the scored hours of one night. Date is the evening
+// the night starts on, and Score is the mean score of its hours.
+type ObservingNight struct {
+	Date  time.Time       `json:"date"`
+	Score int             `json:"score"`
+	Hours []ObservingHour `json:"hours"`
+	Best  ObservingHour   `json:"best"`
+	Moon  astro.MoonPhase `json:"moon"`
+	Units Units           `json:"units"`
+}
+
+// RankObservingNights scores every dark forecast hour at loc for stargazing,
+// groups them into nights and returns the nights from best to worst.
+//
+// Cloud cover matters most. Moonlight, twilight, humidity (dew and haze) and
+// wind (seeing and telescope shake) reduce the score further.
+func RankObservingNights(hours []HourlyForecast, loc astro.Location) []ObservingNight {
+	var nights []ObservingNight
+	index := make(map[time.Time]int)
+
+	for _, hour := range hours {
+		scored, ok := scoreObservingHour(hour, loc)
+		if !ok {
+			continue
+		}
+
+		// Hours before noon belong to the night that started the evening before.
+		evening := hour.DateTime.Add(-12 * time.Hour)
+		year, month, day := evening.Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, hour.DateTime.Location())
+
+		i, found := index[date]
+		if !found {
+			i = len(nights)
+			index[date] = i
+			nights = append(nights, ObservingNight{
+				Date:  date,
+				Moon:  astro.Phase(date.AddDate(0, 0, 1)), // Midnight
+				Units: hour.Units,
+			})
+		}
+		nights[i].Hours = append(nights[i].Hours, scored)
+	}
+
+	for i := range nights {
+		night := &nights[i]
+		total := 0
+		for _, hour := range night.Hours {
+			total += hour.Score
+			if hour.Score > night.Best.Score || night.Best.Time.IsZero() {
+				night.Best = hour
+			}
+		}
+		night.Score = int(math.Round(float64(total) / float64(len(night.Hours))))
+	}
+
+	sort.SliceStable(nights, func(a, b int) bool {
+		return nights[a].Score > nights[b].Score
+	})
+	return nights
+}
+
+// scoreObservingHour scores a single hour, or reports false when the sky is
+// still too bright to observe.
+func scoreObservingHour(hour HourlyForecast, loc astro.Location) (ObservingHour, bool) {
+	sunElevation := astro.SunElevation(loc, hour.DateTime)
+	if sunElevation > astro.ElevationNautical {
+		return ObservingHour{}, false
+	}
+	moon := astro.MoonPosition(loc, hour.DateTime)
+	phase := astro.Phase(hour.DateTime)
+
+	// Nautical twilight still washes out faint objects; only astronomical
+	// darkness counts fully.
+	darkness := 0.5 + 0.5*math.Min(1, (astro.ElevationNautical-sunElevation)/6)
+
+	clouds := 1 - clamp(hour.Cloudy, 0, 100)/100
+
+	moonlight := 1.0
+	if moon.Elevation > 0 {
+		// A high full moon costs 60% of the score.
+		moonlight = 1 - 0.6*phase.Illumination/100*math.Min(1, 0.5+moon.Elevation/60)
+	}
+
+	humidity := 1.0
+	if hour.Humidity > 70 {
+		humidity = 1 - 0.3*(math.Min(hour.Humidity, 100)-70)/30
+	}
+
+	wind := 1.0
+	switch force := hour.Beaufort().Force; {
+	case force >= 6:
+		wind = 0.5
+	case force >= 4:
+		wind = 0.8
+	}
+
+	score := int(math.Round(100 * darkness * clouds * moonlight * humidity * wind))
+	return ObservingHour{
+		Time:             hour.DateTime,
+		Score:            score,
+		Rating:           scoreRating(score),
+		CloudCover:       hour.Cloudy,
+		Humidity:         hour.Humidity,
+		WindSpeed:        hour.WindSpeed,
+		SunElevation:     sunElevation,
+		MoonElevation:    moon.Elevation,
+		MoonIllumination: phase.Illumination,
+		AstronomicalDark: sunElevation <= astro.ElevationAstronomical,
+	}, true
+}
+
+// GoldenHourWindow scores a golden hour for photography by its cloud cover.
+type GoldenHourWindow struct {
+	astro.Interval
+	Morning           bool    `json:"morning"`
+	CloudCover        float64 `json:"cloud_cover"`               // Mean cloud cover over the window
+	PrecipitationProb float64 `json:"precipitation_probability"` // Highest chance over the window
+	Score             int     `json:"score"`
+	Rating            string  `json:"rating"`
+}
+
+// ScoreGoldenHours scores every morning and evening golden hour covered by
+// the forecast, in chronological order.
+//
+// Some cloud makes for the most colourful light, so the score peaks at 40%
+// cover: a clear sky scores 70 and an overcast one 10. Rain lowers it further.
+func ScoreGoldenHours(hours []HourlyForecast, loc astro.Location) []GoldenHourWindow {
+	if len(hours) == 0 {
+		return nil
+	}
+	first := hours[0].DateTime.Truncate(time.Hour)
+	last := hours[len(hours)-1].DateTime.Add(time.Hour)
+
+	var windows []GoldenHourWindow
+	seen := make(map[time.Time]bool)
+	for _, hour := range hours {
+		year, month, day := hour.DateTime.Date()
+		date := time.Date(year, month, day, 0, 0, 0, 0, hour.DateTime.Location())
+		if seen[date] {
+			continue
+		}
+		seen[date] = true
+
+		sun := astro.Sun(loc, date)
+		for _, golden := range []struct {
+			interval astro.Interval
+			morning  bool
+		}{
+			{sun.MorningGoldenHour, true},
+			{sun.EveningGoldenHour, false},
+		} {
+			if golden.interval.IsZero() || golden.interval.Start.Before(first) ||
+				!golden.interval.End.Before(last) {
+				continue
+			}
+			if window, ok := scoreGoldenHour(hours, golden.interval); ok {
+				window.Morning = golden.morning
+				windows = append(windows, window)
+			}
+		}
+	}
+	return windows
+}
+
+// scoreGoldenHour averages the forecast hours overlapping the interval.
+func scoreGoldenHour(hours []HourlyForecast, interval astro.Interval) (GoldenHourWindow, bool) {
+	from := interval.Start.Truncate(time.Hour)
+	var cloudSum, maxProb float64
+	var count int
+	for _, hour := range hours {
+		if hour.DateTime.Before(from) || hour.DateTime.After(interval.End) {
+			continue
+		}
+		cloudSum += hour.Cloudy
+		maxProb = math.Max(maxProb, hour.PrecipitationProb)
+		count++
+	}
+	if count == 0 {
+		return GoldenHourWindow{}, false
+	}
+
+	cloudCover := cloudSum / float64(count)
+	var score float64
+	if cloudCover <= 40 {
+		score = 70 + cloudCover*0.75
+	} else {
+		score = 100 - (cloudCover-40)*1.5
+	}
+	score *= 1 - 0.5*maxProb/100
+
+	rounded := int(math.Round(score))
+	return GoldenHourWindow{
+		Interval:          interval,
+		CloudCover:        cloudCover,
+		PrecipitationProb: maxProb,
+		Score:             rounded,
+		Rating:            scoreRating(rounded),
+	}, true
+}
+
+// scoreRating names a 0-100 score.
+func scoreRating(score int) string {
+	switch {
+	case score >= 80:
+		return "Excellent"
+	case score >= 60:
+		return "Good"
+	case score >= 40:
+		return "Fair"
+	case score >= 20:
+		return "Poor"
+	default:
+		return "Bad"
+	}
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
@@ -0,0 +1,124 @@
+package weather
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohithbuilds/sky/internal/astro"
+)
+
+var greenwich = astro.Location{Latitude: 51.4769, Longitude: 0}
+
+// clearHours builds calm, dry and cloudless hourly forecasts from start.
+func clearHours(start time.Time, n int) []HourlyForecast {
+	hours := make([]HourlyForecast, n)
+	for i := range hours {
+		hours[i] = HourlyForecast{
+			DateTime:  start.Add(time.Duration(i) * time.Hour),
+			Humidity:  60,
+			WindSpeed: 5,
+			Units:     Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"},
+		}
+	}
+	return hours
+}
+
+func TestRankObservingNights_CloudsDecide(t *testing.T) {
+	// Two nights around the new moon of 8 April 2024.
+	hours := clearHours(time.Date(2024, 4, 7, 12, 0, 0, 0, time.UTC), 48)
+	for i := 24; i < len(hours); i++ {
+		hours[i].Cloudy = 90
+	}
+
+	nights := RankObservingNights(hours, greenwich)
+
+	if len(nights) != 2 {
+		t.Fatalf("Expected 2 nights, got %d", len(nights))
+	}
+	if nights[0].Date.Day() != 7 || nights[1].Date.Day() != 8 {
+		t.Errorf("Expected the clear night of the 7th first, got %s and %s", nights[0].Date, nights[1].Date)
+	}
+	if nights[0].Best.Score < 95 || nights[0].Best.Rating != "Excellent" || !nights[0].Best.AstronomicalDark {
+		t.Errorf("Expected an excellent, fully dark best hour, got %+v", nights[0].Best)
+	}
+	if nights[1].Score > 10 {
+		t.Errorf("Expected a poor score under 90%% cloud, got %d", nights[1].Score)
+	}
+	for _, night := range nights {
+		for _, hour := range night.Hours {
+			if hour.SunElevation > astro.ElevationNautical {
+				t.Errorf("Expected only dark hours, got the sun at %.1f° at %s", hour.SunElevation, hour.Time)
+			}
+		}
+	}
+}
+
+func TestRankObservingNights_MoonlightAndWind(t *testing.T) {
+	newMoon := RankObservingNights(clearHours(time.Date(2024, 4, 8, 12, 0, 0, 0, time.UTC), 24), greenwich)
+	fullMoon := RankObservingNights(clearHours(time.Date(2024, 4, 23, 12, 0, 0, 0, time.UTC), 24), greenwich)
+
+	if len(newMoon) != 1 || len(fullMoon) != 1 {
+		t.Fatalf("Expected one night each, got %d and %d", len(newMoon), len(fullMoon))
+	}
+	if fullMoon[0].Score >= newMoon[0].Score-20 {
+		t.Errorf(
+			"Expected the full moon night (%d) to score well below the new moon night (%d)",
+			fullMoon[0].Score,
+			newMoon[0].Score,
+		)
+	}
+	if fullMoon[0].Moon.Name != "Full moon" {
+		t.Errorf("Expected a full moon, got '%s'", fullMoon[0].Moon.Name)
+	}
+
+	windy := clearHours(time.Date(2024, 4, 8, 12, 0, 0, 0, time.UTC), 24)
+	for i := range windy {
+		windy[i].WindSpeed = 45
+	}
+	if scored := RankObservingNights(windy, greenwich); scored[0].Score > newMoon[0].Score/2+1 {
+		t.Errorf("Expected a near gale to halve the score, got %d vs %d", scored[0].Score, newMoon[0].Score)
+	}
+}
+
+func TestScoreGoldenHours(t *testing.T) {
+	hours := clearHours(time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC), 24)
+	for i := range hours {
+		if hours[i].DateTime.Hour() < 12 {
+			hours[i].Cloudy = 40
+		} else {
+			hours[i].Cloudy = 100
+			hours[i].PrecipitationProb = 80
+		}
+	}
+
+	windows := ScoreGoldenHours(hours, greenwich)
+
+	if len(windows) != 2 {
+		t.Fatalf("Expected a morning and an evening golden hour, got %d", len(windows))
+	}
+	morning, evening := windows[0], windows[1]
+	if !morning.Morning || evening.Morning {
+		t.Errorf("Expected the morning window first, got %+v", windows)
+	}
+	if morning.Score != 100 || morning.Rating != "Excellent" {
+		t.Errorf("Expected a perfect morning at 40%% cloud, got %d (%s)", morning.Score, morning.Rating)
+	}
+	if evening.Score != 6 || evening.Rating != "Bad" {
+		t.Errorf("Expected a bad evening under rain clouds, got %d (%s)", evening.Score, evening.Rating)
+	}
+	if sun := astro.Sun(greenwich, hours[0].DateTime); !morning.Start.Equal(sun.MorningGoldenHour.Start) {
+		t.Errorf("Expected the window to match the golden hour, got %s", morning.Start)
+	}
+}
+
+func TestScoreGoldenHours_OutsideForecast(t *testing.T) {
+	// Starting at noon, the morning golden hour is already over.
+	hours := clearHours(time.Date(2024, 4, 8, 12, 0, 0, 0, time.UTC), 12)
+	windows := ScoreGoldenHours(hours, greenwich)
+	if len(windows) != 1 || windows[0].Morning {
+		t.Errorf("Expected only the evening golden hour, got %+v", windows)
+	}
+	if windows[0].Score != 70 {
+		t.Errorf("Expected a clear sky to score 70, got %d", windows[0].Score)
+	}
+}
@@ -0,0 +1,305 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Conditions used to segment an hourly forecast into periods.
+const (
+	summaryDry           = "dry"
+	summaryDrizzle       = "drizzle"
+	summaryRain          = "rain"
+	summarySnow          = "snow"
+	summaryThunderstorms = "thunderstorms"
+)
+
+// SummaryPeriod is a run of consecutive hours sharing the same precipitation
+// condition.
+type SummaryPeriod struct {
+	Start                time.Time `json:"start"`
+	End                  time.Time `json:"end"` // Exclusive, one hour after the last hour
+	Condition            string    `json:"condition"`
+	MaxPrecipitationProb float64   `json:"max_precipitation_probability"`
+	MinTemperature       float64   `json:"min_temperature"`
+	MaxTemperature       float64   `json:"max_temperature"`
+	MaxWindSpeed         float64   `json:"max_wind_speed"`
+}
+
+// Summary is a natural-language description of an hourly forecast together
+// with the periods it was derived from.
+type Summary struct {
+	Text    string          `json:"text"`
+	Periods []SummaryPeriod `json:"periods"`
+}
+
+// SummarizeHourly segments an hourly forecast into periods and describes it
+// in a few concise English clauses, e.g.
+// "Dry until 3pm, then rain (70%) clearing by 9pm; turning windy overnight."
+func SummarizeHourly(hours []HourlyForecast) Summary {
+	if len(hours) == 0 {
+		return Summary{Text: "No forecast data."}
+	}
+
+	conditions := make([]string, len(hours))
+	for i, hour := range hours {
+		conditions[i] = hourCondition(hour)
+	}
+	smoothConditions(conditions)
+
+	periods := buildPeriods(hours, conditions)
+	ref := hours[0].DateTime
+
+	clauses := []string{describePrecipitation(periods, ref)}
+	if clause := describeWind(hours, ref); clause != "" {
+		clauses = append(clauses, clause)
+	}
+	if clause := describeTemperature(hours, ref); clause != "" {
+		clauses = append(clauses, clause)
+	}
+
+	text := strings.Join(clauses, "; ") + "."
+	return Summary{
+		Text:    strings.ToUpper(text[:1]) + text[1:],
+		Periods: periods,
+	}
+}
+
+// hourCondition classifies an hour by its precipitation.
+func hourCondition(hour HourlyForecast) string {
+	switch hour.Condition.Category {
+	case CategoryThunder:
+		return summaryThunderstorms
+	case CategorySnow:
+		return summarySnow
+	case CategoryDrizzle:
+		return summaryDrizzle
+	case CategoryRain:
+		return summaryRain
+	}
+
+	switch {
+	case hour.SnowFall > 0:
+		return summarySnow
+	case hour.Precipitation >= 0.1:
+		return summaryRain
+	default:
+		return summaryDry
+	}
+}
+
+// smoothConditions removes single-hour blips so that "rain, dry, rain" is
+// described as one period of rain.
+func smoothConditions(conditions []string) {
+	for i := 1; i < len(conditions)-1; i++ {
+		if conditions[i-1] == conditions[i+1] && conditions[i] != conditions[i-1] {
+			conditions[i] = conditions[i-1]
+		}
+	}
+}
+
+func buildPeriods(hours []HourlyForecast, conditions []string) []SummaryPeriod {
+	var periods []SummaryPeriod
+	for i, hour := range hours {
+		if len(periods) == 0 || periods[len(periods)-1].Condition != conditions[i] {
+			periods = append(periods, SummaryPeriod{
+				Start:          hour.DateTime,
+				Condition:      conditions[i],
+				MinTemperature: hour.Temperature,
+				MaxTemperature: hour.Temperature,
+			})
+		}
+
+		period := &periods[len(periods)-1]
+		period.End = hour.DateTime.Add(time.Hour)
+		period.MaxPrecipitationProb = max(period.MaxPrecipitationProb, hour.PrecipitationProb)
+		period.MinTemperature = min(period.MinTemperature, hour.Temperature)
+		period.MaxTemperature = max(period.MaxTemperature, hour.Temperature)
+		period.MaxWindSpeed = max(period.MaxWindSpeed, hour.WindSpeed)
+	}
+	return periods
+}
+
+func describePrecipitation(periods []SummaryPeriod, ref time.Time) string {
+	if len(periods) == 1 {
+		if periods[0].Condition == summaryDry {
+			return "dry throughout"
+		}
+		return wetPhrase(periods[0]) + " throughout"
+	}
+
+	var b strings.Builder
+	if periods[0].Condition == summaryDry {
+		fmt.Fprintf(&b, "dry until %s", clockPhrase(periods[1].Start, ref))
+	} else {
+		b.WriteString(wetPhrase(periods[0]))
+	}
+
+	for i := 1; i < len(periods); i++ {
+		period := periods[i]
+		switch {
+		case period.Condition == summaryDry:
+			fmt.Fprintf(&b, " clearing by %s", clockPhrase(period.Start, ref))
+		case periods[i-1].Condition != summaryDry:
+			fmt.Fprintf(&b, " turning to %s by %s", wetPhrase(period), clockPhrase(period.Start, ref))
+		case i == 1:
+			fmt.Fprintf(&b, ", then %s", wetPhrase(period))
+		default:
+			fmt.Fprintf(&b, ", then %s from %s", wetPhrase(period), clockPhrase(period.Start, ref))
+		}
+	}
+	return b.String()
+}
+
+func wetPhrase(period SummaryPeriod) string {
+	if period.MaxPrecipitationProb <= 0 {
+		return period.Condition
+	}
+	return fmt.Sprintf("%s (%.0f%%)", period.Condition, period.MaxPrecipitationProb)
+}
+
+func describeWind(hours []HourlyForecast, ref time.Time) string {
+	start, end := -1, len(hours)
+	for i, hour := range hours {
+		windy := hour.Beaufort().Force >= windyForce
+		if windy && start < 0 {
+			start = i
+		} else if !windy && start >= 0 {
+			end = i
+			break
+		}
+	}
+
+	switch {
+	case start < 0:
+		return ""
+	case start == 0 && end == len(hours):
+		return "windy throughout"
+	case start == 0:
+		return "windy until " + clockPhrase(hours[end].DateTime, ref)
+	default:
+		return "turning windy " + timeOfDayPhrase(hours[start].DateTime, ref)
+	}
+}
+
+// windyForce is the Beaufort force ("fresh breeze") from which an hour is
+// described as windy.
+const windyForce = 5
+
+func describeTemperature(hours []HourlyForecast, ref time.Time) string {
+	threshold := 3.0
+	if isFahrenheit(hours[0].Units.Temperature) {
+		threshold = 5.0
+	}
+
+	first, last := hours[0], hours[len(hours)-1]
+	warmest, coldest := first, first
+	for _, hour := range hours {
+		if hour.Temperature > warmest.Temperature {
+			warmest = hour
+		}
+		if hour.Temperature < coldest.Temperature {
+			coldest = hour
+		}
+	}
+
+	unit := first.Units.Temperature
+	switch {
+	case warmest.Temperature-first.Temperature >= threshold &&
+		warmest.Temperature-last.Temperature >= threshold:
+		return fmt.Sprintf(
+			"peaking at %.0f%s around %s",
+			warmest.Temperature,
+			unit,
+			clockPhrase(warmest.DateTime, ref),
+		)
+	case warmest.Temperature-first.Temperature >= threshold:
+		return fmt.Sprintf(
+			"temperatures rising to %.0f%s by %s",
+			warmest.Temperature,
+			unit,
+			clockPhrase(warmest.DateTime, ref),
+		)
+	case first.Temperature-coldest.Temperature >= threshold:
+		return fmt.Sprintf(
+			"temperatures falling to %.0f%s by %s",
+			coldest.Temperature,
+			unit,
+			clockPhrase(coldest.DateTime, ref),
+		)
+	default:
+		return ""
+	}
+}
+
+// clockPhrase formats t as "3pm", "noon" or "midnight", qualified with
+// "tomorrow" or a weekday when it is not on the same day as ref.
+func clockPhrase(t, ref time.Time) string {
+	var clock string
+	switch hour := t.Hour(); {
+	case hour == 0:
+		clock = "midnight"
+	case hour == 12:
+		clock = "noon"
+	case hour < 12:
+		clock = fmt.Sprintf("%dam", hour)
+	default:
+		clock = fmt.Sprintf("%dpm", hour-12)
+	}
+
+	switch daysBetween(ref, t) {
+	case 0:
+		return clock
+	case 1:
+		if clock == "midnight" {
+			return clock
+		}
+		return clock + " tomorrow"
+	default:
+		return t.Format("Mon ") + clock
+	}
+}
+
+// timeOfDayPhrase describes when t falls relative to ref, e.g. "this
+// afternoon", "overnight" or "tomorrow morning".
+func timeOfDayPhrase(t, ref time.Time) string {
+	hour := t.Hour()
+	days := daysBetween(ref, t)
+
+	if (days == 0 && hour >= 22) || (days == 1 && hour < 5) {
+		return "overnight"
+	}
+
+	var part string
+	switch {
+	case hour < 5:
+		part = "night"
+	case hour < 12:
+		part = "morning"
+	case hour < 18:
+		part = "afternoon"
+	default:
+		part = "evening"
+	}
+
+	switch days {
+	case 0:
+		if part == "night" {
+			return "overnight"
+		}
+		return "this " + part
+	case 1:
+		return "tomorrow " + part
+	default:
+		return t.Format("Monday ") + part
+	}
+}
+
+// daysBetween returns the number of calendar days from ref to t in t's location.
+func daysBetween(ref, t time.Time) int {
+	ref = ref.In(t.Location())
+	refDay := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(refDay).Hours() / 24)
+}
@@ -0,0 +1,111 @@
+package weather
+
+import (
+	"testing"
+	"time"
+)
+
+// summaryHours builds a run of hourly forecasts starting at 09:00 with mild,
+// calm and dry weather that tests can then modify.
+func summaryHours(n int) []HourlyForecast {
+	start := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	hours := make([]HourlyForecast, n)
+	for i := range hours {
+		hours[i] = HourlyForecast{
+			DateTime:    start.Add(time.Duration(i) * time.Hour),
+			Temperature: 15.0,
+			WindSpeed:   10.0,
+			Condition:   ConditionForCode(2, ""),
+			Units:       Units{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"},
+		}
+	}
+	return hours
+}
+
+func TestSummarizeHourly_RainThenWind(t *testing.T) {
+	hours := summaryHours(18)
+	// Rain from 15:00 until 21:00.
+	for i := 6; i < 12; i++ {
+		hours[i].Condition = ConditionForCode(61, "")
+		hours[i].Precipitation = 0.8
+		hours[i].PrecipitationProb = 50
+	}
+	hours[8].PrecipitationProb = 70
+	// Windy from 23:00 onwards.
+	for i := 14; i < len(hours); i++ {
+		hours[i].WindSpeed = 40
+	}
+
+	summary := SummarizeHourly(hours)
+
+	expected := "Dry until 3pm, then rain (70%) clearing by 9pm; turning windy overnight."
+	if summary.Text != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary.Text)
+	}
+	if len(summary.Periods) != 3 {
+		t.Fatalf("Expected 3 periods, got %d", len(summary.Periods))
+	}
+	if summary.Periods[1].Condition != "rain" || summary.Periods[1].MaxPrecipitationProb != 70 {
+		t.Errorf("Expected the second period to be rain at 70%%, got %+v", summary.Periods[1])
+	}
+}
+
+func TestSummarizeHourly_DryWithRisingTemperature(t *testing.T) {
+	hours := summaryHours(6)
+	for i := range hours {
+		hours[i].Temperature = 12.0 + float64(i)*1.5
+	}
+
+	summary := SummarizeHourly(hours)
+
+	expected := "Dry throughout; temperatures rising to 20°C by 2pm."
+	if summary.Text != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary.Text)
+	}
+}
+
+func TestSummarizeHourly_SmoothsSingleHourBreaks(t *testing.T) {
+	hours := summaryHours(5)
+	for i := range hours {
+		hours[i].Condition = ConditionForCode(73, "")
+		hours[i].PrecipitationProb = 80
+	}
+	hours[2].Condition = ConditionForCode(3, "")
+
+	summary := SummarizeHourly(hours)
+
+	if len(summary.Periods) != 1 {
+		t.Fatalf("Expected a single smoothed period, got %+v", summary.Periods)
+	}
+	expected := "Snow (80%) throughout."
+	if summary.Text != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary.Text)
+	}
+}
+
+func TestSummarizeHourly_TurningToSnowTomorrow(t *testing.T) {
+	hours := summaryHours(20)
+	for i := range hours {
+		hours[i].Condition = ConditionForCode(63, "")
+		hours[i].PrecipitationProb = 60
+	}
+	// Snow from 01:00 the next day.
+	for i := 16; i < len(hours); i++ {
+		hours[i].Condition = ConditionForCode(71, "")
+		hours[i].PrecipitationProb = 90
+	}
+
+	summary := SummarizeHourly(hours)
+
+	expected := "Rain (60%) turning to snow (90%) by 1am tomorrow."
+	if summary.Text != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary.Text)
+	}
+}
+
+func TestSummarizeHourly_Empty(t *testing.T) {
+	summary := SummarizeHourly(nil)
+	if summary.Text == "" {
+		t.Error("Expected a placeholder text for an empty forecast")
+	}
+}
@@ -4,164 +4,169 @@ import (
 	"fmt"
 	"time"
 
-	"sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/astro"
+	"github.com/mohithbuilds/sky/internal/i18n"
 )
 
-const openMeteoLayout = "2006-01-02T15:04"
-
 // Units holds the unit strings for the weather data.
 type Units struct {
-	Temperature   string
-	WindSpeed     string
-	Precipitation string
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	Precipitation string `json:"precipitation"`
+}
+
+// Snowfall returns the unit of snowfall, which is measured in centimetres
+// rather than millimetres in metric units.
+func (u Units) Snowfall() string {
+	if u.Precipitation == "inch" {
+		return "inch"
+	}
+	return "cm"
 }
 
 // CurrentWeather represents the simplified current weather information
 // that your application cares about.
 type CurrentWeather struct {
-	Temperature         float64
-	Humidity            float64
-	ApparentTemperature float64
-	Precipitation       float64
-	WindSpeed           float64
-	WeatherDescription  string
-	ObservationTime     time.Time
-	IsDay               int
-	Units               Units
+	Temperature         float64   `json:"temperature"`
+	Humidity            float64   `json:"humidity"`
+	ApparentTemperature float64   `json:"apparent_temperature"`
+	Precipitation       float64   `json:"precipitation"`
+	WindSpeed           float64   `json:"wind_speed"`
+	WindGusts           float64   `json:"wind_gusts"`
+	WindDirection       Direction `json:"wind_direction"`
+	WeatherDescription  string    `json:"weather_description"`
+	Condition           Condition `json:"condition"`
+	ObservationTime     time.Time `json:"observation_time"`
+	IsDay               int       `json:"is_day"`
+	Units               Units     `json:"units"`
+	Source              string    `json:"source,omitempty"` // The Failover backend that served it
 }
 
 // HourlyForecast represents the simplified hourly forecast information.
+// Precipitation and SnowFall are the sums over the hour before DateTime, as
+// in Open-Meteo; the other values are those at DateTime.
 type HourlyForecast struct {
-	DateTime            time.Time
-	Temperature         float64
-	Humidity            float64
-	ApparentTemperature float64
-	Cloudy              float64
-	WindSpeed           float64
-	Precipitation       float64
-	SnowFall            float64
-	PrecipitationProb   float64
-	WeatherDescription  string
-	IsDay               int
-	Units               Units
+	DateTime            time.Time `json:"date_time"`
+	Temperature         float64   `json:"temperature"`
+	Humidity            float64   `json:"humidity"`
+	ApparentTemperature float64   `json:"apparent_temperature"`
+	Cloudy              float64   `json:"cloud_cover"`
+	WindSpeed           float64   `json:"wind_speed"`
+	WindGusts           float64   `json:"wind_gusts"`
+	WindDirection       Direction `json:"wind_direction"`
+	Precipitation       float64   `json:"precipitation"`
+	SnowFall            float64   `json:"snowfall"`
+	PrecipitationProb   float64   `json:"precipitation_probability"`
+	WeatherDescription  string    `json:"weather_description"`
+	Condition           Condition `json:"condition"`
+	IsDay               int       `json:"is_day"`
+	Units               Units     `json:"units"`
+	Source              string    `json:"source,omitempty"` // The Failover backend that served it
 }
 
 // DailyForecast represents the simplified daily forecast information.
 type DailyForecast struct {
-	Date               time.Time
-	MaxTemperature     float64
-	MinTemperature     float64
-	WeatherDescription string
-	Sunrise            time.Time
-	Sunset             time.Time
-	PrecipitationSum   float64
-	PrecipitationProb  float64 // Mean daily precipitation probability
-	WindGusts          float64 // Max daily 10m wind speed
-	Units              Units
-}
-
-// ForecastClient is an interface for a client that can fetch weather data.
-type ForecastClient interface {
-	GetWeather(
-		latitude, longitude float64,
-		currentParameters []string,
-		hourlyParameters []string,
-		dailyParameters []string,
-		temperatureUnit string,
-		windSpeedUnit string,
-		precipitationUnit string,
-		pastDays int64,
-		forecastDays int64,
-		pastHours int64,
-		forecastHours int64,
-	) (*openmateo.ForecastResult, error)
+	Date               time.Time `json:"date"`
+	MaxTemperature     float64   `json:"max_temperature"`
+	MinTemperature     float64   `json:"min_temperature"`
+	WeatherDescription string    `json:"weather_description"`
+	Condition          Condition `json:"condition"`
+	Sunrise            time.Time `json:"sunrise"`
+	Sunset             time.Time `json:"sunset"`
+	PrecipitationSum   float64   `json:"precipitation_sum"`
+	PrecipitationProb  float64   `json:"precipitation_probability"` // Mean daily precipitation probability
+	MaxWindSpeed       float64   `json:"max_wind_speed"`            // Max daily 10m wind speed
+	WindGusts          float64   `json:"wind_gusts"`                // Max daily 10m wind gusts
+	WindDirection      Direction `json:"wind_direction"`            // Dominant daily 10m wind direction
+	Astronomy          astro.Day `json:"astronomy"`                 // Calculated locally, not by the API
+	Units              Units     `json:"units"`
+	Source             string    `json:"source,omitempty"` // The Failover backend that served it
 }
 
 // WeatherClient is your application's client for weather-related operations.
-// It composes a ForecastClient.
+// It composes a Provider and, optionally, an AirQualityClient.
 type WeatherClient struct {
-	openmateoClient ForecastClient
+	provider Provider
+
+	// AirQualityClient is used by GetCurrentAirQuality. It may be nil when
+	// air quality data is not needed.
+	AirQualityClient AirQualityClient
+
+	// Language selects the language of weather descriptions, e.g. "de".
+	// An empty Language means English.
+	Language string
+
+	// Recorder, when set, receives everything the client fetches, e.g. to
+	// keep a history of forecasts.
+	Recorder Recorder
 }
 
-// NewWeatherClient creates a new instance of the WeatherClient.
+// Recorder receives the weather a WeatherClient fetches. Recording must not
+// fail a fetch, so the methods return nothing.
+type Recorder interface {
+	RecordCurrent(latitude, longitude float64, current *CurrentWeather)
+	RecordHourly(latitude, longitude float64, hourly []HourlyForecast)
+	RecordDaily(latitude, longitude float64, daily []DailyForecast)
+	RecordPast(latitude, longitude float64, past []HourlyForecast)
+}
+
+// NewWeatherClient creates a new instance of the WeatherClient that fetches
+// from Open-Meteo through fc.
 func NewWeatherClient(fc ForecastClient) *WeatherClient {
+	return NewWeatherClientFrom(&OpenMeteo{Client: fc})
+}
+
+// NewWeatherClientFrom creates a WeatherClient that fetches from a Provider.
+func NewWeatherClientFrom(p Provider) *WeatherClient {
 	return &WeatherClient{
-		openmateoClient: fc,
+		provider: p,
 	}
 }
 
 // GetCurrentWeather fetches the current weather conditions for a given location.
-// This function abstracts away the specific parameters needed by the openmateo API.
 func (w *WeatherClient) GetCurrentWeather(
 	latitude, longitude float64,
 	tempUnit, windUnit, precipUnit string,
 ) (*CurrentWeather, error) {
-	// Define the specific current parameters we want from the Open-Meteo API
-	currentParams := []string{
-		"temperature_2m",
-		"relative_humidity_2m",
-		"weather_code",
-		"is_day",
-		"apparent_temperature",
-		"precipitation",
-		"wind_speed_10m",
+	current, err := w.provider.Current(latitude, longitude, RequestUnits{tempUnit, windUnit, precipUnit})
+	if err != nil {
+		return nil, err
 	}
 
-	// Call the low-level openmateo client's GetWeather function
-	// We only care about current data, so other slices are empty.
-	forecast, err := w.openmateoClient.GetWeather(
-		latitude,
-		longitude,
-		currentParams,
-		[]string{}, // No hourly data
-		[]string{}, // No daily data
-		tempUnit,
-		windUnit,
-		precipUnit,
-		0,
-		0,
-		0,
-		0,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get raw weather data: %w", err)
+	current.Condition = ConditionForCode(current.Condition.Code, w.Language)
+	current.WeatherDescription = current.Condition.Description
+
+	if w.Recorder != nil {
+		w.Recorder.RecordCurrent(latitude, longitude, current)
 	}
+	return current, nil
+}
 
-	if forecast.Current == nil {
-		return nil, fmt.Errorf(
-			"no current weather data returned for %.2f, %.2f",
-			latitude,
-			longitude,
-		)
+// GetHourlyForecast fetches the hourly forecast for a given location.
+func (w *WeatherClient) GetHourlyForecast(
+	latitude, longitude float64,
+	numHours int64,
+	tempUnit, windUnit, precipUnit string,
+) ([]HourlyForecast, error) {
+	if numHours < 1 {
+		numHours = 1
 	}
 
-	obsTime, err := parseTime(forecast.Current.Time, forecast.Timezone)
+	hourlyForecasts, err := w.hourly(latitude, longitude, 0, numHours, tempUnit, windUnit, precipUnit)
 	if err != nil {
 		return nil, err
 	}
 
-	weatherDesc := mapWeatherCodeToDescription(forecast.Current.WeatherCode)
-	current := &CurrentWeather{
-		Temperature:         forecast.Current.Temperature2m,
-		Humidity:            forecast.Current.RelativeHumidity2m,
-		ApparentTemperature: forecast.Current.ApparentTemperature,
-		Precipitation:       forecast.Current.Precipitation,
-		WindSpeed:           forecast.Current.WindSpeed10m,
-		WeatherDescription:  weatherDesc,
-		ObservationTime:     obsTime,
-		IsDay:               forecast.Current.IsDay,
-		Units: Units{
-			Temperature:   forecast.CurrentUnits.Temperature2m,
-			Precipitation: forecast.CurrentUnits.Precipitation,
-			WindSpeed:     forecast.CurrentUnits.WindSpeed10m,
-		},
+	if w.Recorder != nil {
+		w.Recorder.RecordHourly(latitude, longitude, hourlyForecasts)
 	}
-
-	return current, nil
+	return hourlyForecasts, nil
 }
 
-// GetHourlyForecast fetches the hourly forecast for a given location.
-func (w *WeatherClient) GetHourlyForecast(
+// GetPastHourly fetches the model's values for the hours that ended in the
+// last numHours hours. They stand in for observations where the API has no
+// others, e.g. when verifying forecasts.
+func (w *WeatherClient) GetPastHourly(
 	latitude, longitude float64,
 	numHours int64,
 	tempUnit, windUnit, precipUnit string,
@@ -170,63 +175,42 @@ func (w *WeatherClient) GetHourlyForecast(
 		numHours = 1
 	}
 
-	hourlyParams := []string{
-		"temperature_2m",
-		"relative_humidity_2m",
-		"apparent_temperature",
-		"cloud_cover",
-		"wind_speed_10m",
-		"precipitation",
-		"snowfall",
-		"precipitation_probability",
-		"weather_code",
-		"is_day",
-	}
-
-	forecast, err := w.openmateoClient.GetWeather(
-		latitude,
-		longitude,
-		[]string{},
-		hourlyParams,
-		[]string{},
-		tempUnit,
-		windUnit,
-		precipUnit,
-		0,
-		0,
-		0,
-		numHours,
-	)
+	hours, err := w.hourly(latitude, longitude, numHours, 1, tempUnit, windUnit, precipUnit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get raw hourly forecast data: %w", err)
+		return nil, err
 	}
 
-	// Add comprehensive nil checks for all slices we will access.
-	if forecast.Hourly == nil || forecast.Hourly.Time == nil ||
-		forecast.Hourly.Temperature2m == nil || forecast.Hourly.RelativeHumidity2m == nil ||
-		forecast.Hourly.ApparentTemperature == nil || forecast.Hourly.CloudCover == nil ||
-		forecast.Hourly.WindSpeed10m == nil || forecast.Hourly.Precipitation == nil ||
-		forecast.Hourly.Snowfall == nil || forecast.Hourly.PrecipitationProbability == nil ||
-		forecast.Hourly.WeatherCode == nil || forecast.Hourly.IsDay == nil {
-		return nil, fmt.Errorf("hourly forecast data is incomplete or missing from API response")
+	current := now()
+	past := hours[:0]
+	for _, hour := range hours {
+		if !hour.DateTime.Add(time.Hour).After(current) {
+			past = append(past, hour)
+		}
 	}
 
-	// Check that all hourly slices have the same length
-	numHoursReturned := len(forecast.Hourly.Time)
-	if len(forecast.Hourly.Temperature2m) != numHoursReturned ||
-		len(forecast.Hourly.RelativeHumidity2m) != numHoursReturned ||
-		len(forecast.Hourly.ApparentTemperature) != numHoursReturned ||
-		len(forecast.Hourly.CloudCover) != numHoursReturned ||
-		len(forecast.Hourly.WindSpeed10m) != numHoursReturned ||
-		len(forecast.Hourly.Precipitation) != numHoursReturned ||
-		len(forecast.Hourly.Snowfall) != numHoursReturned ||
-		len(forecast.Hourly.PrecipitationProbability) != numHoursReturned ||
-		len(forecast.Hourly.WeatherCode) != numHoursReturned ||
-		len(forecast.Hourly.IsDay) != numHoursReturned {
-		return nil, fmt.Errorf("API returned hourly forecast data with inconsistent lengths")
+	if w.Recorder != nil {
+		w.Recorder.RecordPast(latitude, longitude, past)
 	}
+	return past, nil
+}
 
-	if len(forecast.Hourly.Time) == 0 {
+// hourly fetches pastHours before and forecastHours from the current hour.
+func (w *WeatherClient) hourly(
+	latitude, longitude float64,
+	pastHours, forecastHours int64,
+	tempUnit, windUnit, precipUnit string,
+) ([]HourlyForecast, error) {
+	hourlyForecasts, err := w.provider.Hourly(
+		latitude,
+		longitude,
+		pastHours,
+		forecastHours,
+		RequestUnits{tempUnit, windUnit, precipUnit},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(hourlyForecasts) == 0 {
 		return nil, fmt.Errorf(
 			"no hourly forecast data returned for %.2f, %.2f",
 			latitude,
@@ -234,35 +218,11 @@ func (w *WeatherClient) GetHourlyForecast(
 		)
 	}
 
-	hourlyForecasts := make([]HourlyForecast, len(forecast.Hourly.Time))
-	units := Units{
-		Temperature:   forecast.HourlyUnits.Temperature2m,
-		Precipitation: forecast.HourlyUnits.Precipitation,
-		WindSpeed:     forecast.HourlyUnits.WindSpeed10m,
-	}
-
-	for i := range forecast.Hourly.Time {
-		forecastTime, err := parseTime(forecast.Hourly.Time[i], forecast.Timezone)
-		if err != nil {
-			return nil, err
-		}
-
-		hourlyForecasts[i] = HourlyForecast{
-			DateTime:            forecastTime,
-			Temperature:         forecast.Hourly.Temperature2m[i],
-			Humidity:            forecast.Hourly.RelativeHumidity2m[i],
-			ApparentTemperature: forecast.Hourly.ApparentTemperature[i],
-			Cloudy:              forecast.Hourly.CloudCover[i],
-			WindSpeed:           forecast.Hourly.WindSpeed10m[i],
-			Precipitation:       forecast.Hourly.Precipitation[i],
-			SnowFall:            forecast.Hourly.Snowfall[i],
-			PrecipitationProb:   forecast.Hourly.PrecipitationProbability[i],
-			WeatherDescription:  mapWeatherCodeToDescription(forecast.Hourly.WeatherCode[i]),
-			IsDay:               forecast.Hourly.IsDay[i],
-			Units:               units,
-		}
+	for i := range hourlyForecasts {
+		hour := &hourlyForecasts[i]
+		hour.Condition = ConditionForCode(hour.Condition.Code, w.Language)
+		hour.WeatherDescription = hour.Condition.Description
 	}
-
 	return hourlyForecasts, nil
 }
 
@@ -276,60 +236,11 @@ func (w *WeatherClient) GetDailyForecast(
 		numDays = 1 // Default to 1 day
 	}
 
-	// Define the specific daily parameters we want from the Open-Meteo API
-	dailyParams := []string{
-		"temperature_2m_max",
-		"temperature_2m_min",
-		"weather_code",
-		"sunrise",
-		"sunset",
-		"precipitation_sum",
-		"precipitation_probability_mean",
-		"wind_speed_10m_max",
-		"snow_depth",
-	}
-
-	forecast, err := w.openmateoClient.GetWeather(
-		latitude,
-		longitude,
-		[]string{}, // No current data
-		[]string{}, // No hourly data
-		dailyParams,
-		tempUnit,
-		windUnit,
-		precipUnit,
-		0,       // No past days
-		numDays, // Request numDays of forecast
-		0,
-		0,
-	)
+	dailyForecasts, err := w.provider.Daily(latitude, longitude, numDays, RequestUnits{tempUnit, windUnit, precipUnit})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get raw daily forecast data: %w", err)
-	}
-
-	// Add comprehensive nil checks for all slices we will access.
-	if forecast.Daily == nil || forecast.Daily.Time == nil ||
-		forecast.Daily.Temperature2mMax == nil || forecast.Daily.Temperature2mMin == nil ||
-		forecast.Daily.WeatherCode == nil || forecast.Daily.Sunrise == nil ||
-		forecast.Daily.Sunset == nil || forecast.Daily.PrecipitationSum == nil ||
-		forecast.Daily.PrecipitationProbabilityMean == nil || forecast.Daily.WindSpeed10mMax == nil {
-		return nil, fmt.Errorf("daily forecast data is incomplete or missing from API response")
-	}
-
-	// Check that all daily slices have the same length
-	numDaysReturned := len(forecast.Daily.Time)
-	if len(forecast.Daily.Temperature2mMax) != numDaysReturned ||
-		len(forecast.Daily.Temperature2mMin) != numDaysReturned ||
-		len(forecast.Daily.WeatherCode) != numDaysReturned ||
-		len(forecast.Daily.Sunrise) != numDaysReturned ||
-		len(forecast.Daily.Sunset) != numDaysReturned ||
-		len(forecast.Daily.PrecipitationSum) != numDaysReturned ||
-		len(forecast.Daily.PrecipitationProbabilityMean) != numDaysReturned ||
-		len(forecast.Daily.WindSpeed10mMax) != numDaysReturned {
-		return nil, fmt.Errorf("API returned daily forecast data with inconsistent lengths")
+		return nil, err
 	}
-
-	if len(forecast.Daily.Time) == 0 {
+	if len(dailyForecasts) == 0 {
 		return nil, fmt.Errorf(
 			"no daily forecast data returned for %.2f, %.2f",
 			latitude,
@@ -337,108 +248,25 @@ func (w *WeatherClient) GetDailyForecast(
 		)
 	}
 
-	dailyForecasts := make([]DailyForecast, len(forecast.Daily.Time))
-	units := Units{
-		Temperature:   forecast.DailyUnits.Temperature2mMax,
-		Precipitation: forecast.DailyUnits.PrecipitationSum,
-		WindSpeed:     forecast.DailyUnits.WindSpeed10mMax,
-	}
-
-	for i := range forecast.Daily.Time {
-		forecastDate, err := parseTime(forecast.Daily.Time[i], forecast.Timezone)
-		if err != nil {
-			return nil, err
-		}
-
-		sunriseTime, err := parseTime(forecast.Daily.Sunrise[i], forecast.Timezone)
-		if err != nil {
-			return nil, err
-		}
-		sunsetTime, err := parseTime(forecast.Daily.Sunset[i], forecast.Timezone)
-		if err != nil {
-			return nil, err
-		}
-
-		dailyForecasts[i] = DailyForecast{
-			Date:               forecastDate,
-			MaxTemperature:     forecast.Daily.Temperature2mMax[i],
-			MinTemperature:     forecast.Daily.Temperature2mMin[i],
-			WeatherDescription: mapWeatherCodeToDescription(forecast.Daily.WeatherCode[i]),
-			Sunrise:            sunriseTime,
-			Sunset:             sunsetTime,
-			PrecipitationSum:   forecast.Daily.PrecipitationSum[i],
-			PrecipitationProb:  forecast.Daily.PrecipitationProbabilityMean[i],
-			WindGusts:          forecast.Daily.WindSpeed10mMax[i],
-			Units:              units,
+	place := astro.Location{Latitude: latitude, Longitude: longitude}
+	for i := range dailyForecasts {
+		day := &dailyForecasts[i]
+		day.Condition = ConditionForCode(day.Condition.Code, w.Language)
+		day.WeatherDescription = day.Condition.Description
+		day.Astronomy = astro.ForDay(place, day.Date)
+		if day.Sunrise.IsZero() && day.Sunset.IsZero() {
+			day.Sunrise, day.Sunset = day.Astronomy.Sun.Sunrise, day.Astronomy.Sun.Sunset
 		}
 	}
 
-	return dailyForecasts, nil
-}
-
-// mapWeatherCodeToDescription is a helper function to convert Open-Meteo
-// weather codes into more human-readable descriptions.
-// This would typically be more extensive.
-func mapWeatherCodeToDescription(code int) string {
-	switch code {
-	case 0:
-		return "Clear sky"
-	case 1, 2, 3:
-		return "Mainly clear, partly cloudy, and overcast"
-	case 45, 48:
-		return "Fog and depositing rime fog"
-	case 51, 53, 55:
-		return "Drizzle: Light, moderate, and dense intensity"
-	case 56, 57:
-		return "Freezing Drizzle: Light and dense intensity"
-	case 61, 63, 65:
-		return "Rain: Slight, moderate and heavy intensity"
-	case 66, 67:
-		return "Freezing Rain: Light and heavy intensity"
-	case 71, 73, 75:
-		return "Snow fall: Slight, moderate, and heavy intensity"
-	case 77:
-		return "Snow grains"
-	case 80, 81, 82:
-		return "Rain showers: Slight, moderate, and violent"
-	case 85, 86:
-		return "Snow showers: Slight and heavy"
-	case 95:
-		return "Thunderstorm: Slight or moderate"
-	case 96, 99:
-		return "Thunderstorm with slight and heavy hail"
-	default:
-		return fmt.Sprintf("Unknown weather code: %d", code)
-	}
-}
-
-func parseTime(timeStr, timezoneStr string) (time.Time, error) {
-	location, err := loadTimezone(timezoneStr)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	parsedTime, err := time.ParseInLocation(openMeteoLayout, timeStr, location)
-	if err != nil {
-		parsedTime, err = time.Parse(time.RFC3339, timeStr)
-		if err != nil {
-			parsedTime, err = time.ParseInLocation("2006-01-02", timeStr, location)
-			if err != nil {
-				return time.Time{}, fmt.Errorf("failed to parse time: %w", err)
-			}
-		}
+	if w.Recorder != nil {
+		w.Recorder.RecordDaily(latitude, longitude, dailyForecasts)
 	}
-	return parsedTime, nil
+	return dailyForecasts, nil
 }
 
-func loadTimezone(timezoneStr string) (*time.Location, error) {
-	if timezoneStr == "" {
-		return time.UTC, nil
-	}
-	location, err := time.LoadLocation(timezoneStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load location from timezone: %w", err)
-	}
-	return location, nil
+// mapWeatherCodeToDescription converts an Open-Meteo (WMO) weather code into
+// a human-readable description in the given language.
+func mapWeatherCodeToDescription(code int, language string) string {
+	return i18n.Lookup(language).WeatherDescription(code)
 }
-
@@ -2,7 +2,7 @@ package weather
 
 import (
 	"errors"
-	"sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
 	"testing"
 )
 
@@ -123,6 +123,8 @@ func TestGetDailyForecast_TimeParseError(t *testing.T) {
 					PrecipitationProbabilityMean: []float64{10.0},
 					WeatherCode:                  []int{3},
 					WindSpeed10mMax:              []float64{15.0},
+					WindGusts10mMax:              []float64{25.0},
+					WindDirection10mDominant:     []float64{270.0},
 				},
 				DailyUnits: &openmateo.ForecastDailyUnits{},
 			}, nil
@@ -181,6 +183,8 @@ func TestGetDailyForecast_InconsistentLengths(t *testing.T) {
 					PrecipitationProbabilityMean: []float64{10.0},
 					WeatherCode:                  []int{3},
 					WindSpeed10mMax:              []float64{15.0},
+					WindGusts10mMax:              []float64{25.0},
+					WindDirection10mDominant:     []float64{270.0},
 				},
 			}, nil
 		},
@@ -214,17 +218,19 @@ func TestGetHourlyForecast_TimeParseError(t *testing.T) {
 			return &openmateo.ForecastResult{
 				Timezone: "UTC",
 				Hourly: &openmateo.ForecastHourly{
-					Time:                []string{"invalid-time"},
-					Temperature2m:       []float64{10.0},
-					RelativeHumidity2m:  []float64{80.0},
-					ApparentTemperature: []float64{8.0},
-					CloudCover:          []float64{50.0},
-					WindSpeed10m:        []float64{5.0},
-					Precipitation:       []float64{0.5},
-					Snowfall:            []float64{0.0},
+					Time:                     []string{"invalid-time"},
+					Temperature2m:            []float64{10.0},
+					RelativeHumidity2m:       []float64{80.0},
+					ApparentTemperature:      []float64{8.0},
+					CloudCover:               []float64{50.0},
+					WindSpeed10m:             []float64{5.0},
+					WindDirection10m:         []float64{180.0},
+					WindGusts10m:             []float64{9.0},
+					Precipitation:            []float64{0.5},
+					Snowfall:                 []float64{0.0},
 					PrecipitationProbability: []float64{10.0},
-					WeatherCode:         []int{3},
-					IsDay:               []int{1},
+					WeatherCode:              []int{3},
+					IsDay:                    []int{1},
 				},
 				HourlyUnits: &openmateo.ForecastHourlyUnits{},
 			}, nil
@@ -274,17 +280,19 @@ func TestGetHourlyForecast_InconsistentLengths(t *testing.T) {
 		GetWeatherFunc: func(latitude, longitude float64, currentParameters, hourlyParameters, dailyParameters []string, temperatureUnit, windSpeedUnit, precipitationUnit string, pastDays, forecastDays, pastHours, forecastHours int64) (*openmateo.ForecastResult, error) {
 			return &openmateo.ForecastResult{
 				Hourly: &openmateo.ForecastHourly{
-					Time:                []string{"2023-01-01T12:00:00Z"},
-					Temperature2m:       []float64{10.0, 11.0}, // Inconsistent length
-					RelativeHumidity2m:  []float64{80.0},
-					ApparentTemperature: []float64{8.0},
-					CloudCover:          []float64{50.0},
-					WindSpeed10m:        []float64{5.0},
-					Precipitation:       []float64{0.5},
-					Snowfall:            []float64{0.0},
+					Time:                     []string{"2023-01-01T12:00:00Z"},
+					Temperature2m:            []float64{10.0, 11.0}, // Inconsistent length
+					RelativeHumidity2m:       []float64{80.0},
+					ApparentTemperature:      []float64{8.0},
+					CloudCover:               []float64{50.0},
+					WindSpeed10m:             []float64{5.0},
+					WindDirection10m:         []float64{180.0},
+					WindGusts10m:             []float64{9.0},
+					Precipitation:            []float64{0.5},
+					Snowfall:                 []float64{0.0},
 					PrecipitationProbability: []float64{10.0},
-					WeatherCode:         []int{3},
-					IsDay:               []int{1},
+					WeatherCode:              []int{3},
+					IsDay:                    []int{1},
 				},
 			}, nil
 		},
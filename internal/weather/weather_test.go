@@ -2,8 +2,10 @@ package weather
 
 import (
 	"testing"
+	"time"
 
-	"sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/client/openmateo"
+	"github.com/mohithbuilds/sky/internal/client/openmateo/openmateotest"
 )
 
 func TestGetCurrentWeather_Success(t *testing.T) {
@@ -54,12 +56,15 @@ func TestGetCurrentWeather_Success(t *testing.T) {
 	if currentWeather.WindSpeed != 5.0 {
 		t.Errorf("Expected WindSpeed to be 5.0, got %f", currentWeather.WindSpeed)
 	}
-	if currentWeather.WeatherDescription != "Mainly clear, partly cloudy, and overcast" {
+	if currentWeather.WeatherDescription != "Overcast" {
 		t.Errorf(
-			"Expected WeatherDescription to be 'Mainly clear, partly cloudy, and overcast', got '%s'",
+			"Expected WeatherDescription to be 'Overcast', got '%s'",
 			currentWeather.WeatherDescription,
 		)
 	}
+	if currentWeather.Condition.Code != 3 || currentWeather.Condition.Category != CategoryCloud {
+		t.Errorf("Expected an overcast cloud condition, got %+v", currentWeather.Condition)
+	}
 	if currentWeather.IsDay != 1 {
 		t.Errorf("Expected IsDay to be 1, got %d", currentWeather.IsDay)
 	}
@@ -89,6 +94,8 @@ func TestGetDailyForecast_Success(t *testing.T) {
 					PrecipitationProbabilityMean: []float64{10.0, 20.0},
 					WeatherCode:                  []int{3, 1},
 					WindSpeed10mMax:              []float64{15.0, 16.0},
+					WindGusts10mMax:              []float64{25.0, 25.0},
+					WindDirection10mDominant:     []float64{270.0, 270.0},
 				},
 				DailyUnits: &openmateo.ForecastDailyUnits{
 					Temperature2mMax: "°C",
@@ -113,6 +120,25 @@ func TestGetDailyForecast_Success(t *testing.T) {
 	if dailyForecast[1].MaxTemperature != 13.0 {
 		t.Errorf("Expected MaxTemperature to be 13.0, got %f", dailyForecast[1].MaxTemperature)
 	}
+	if dailyForecast[0].MaxWindSpeed != 15.0 {
+		t.Errorf("Expected MaxWindSpeed to be 15.0, got %f", dailyForecast[0].MaxWindSpeed)
+	}
+	if dailyForecast[0].WindGusts != 25.0 {
+		t.Errorf("Expected WindGusts to be 25.0, got %f", dailyForecast[0].WindGusts)
+	}
+	if dailyForecast[0].WindDirection.Compass8() != "W" {
+		t.Errorf("Expected WindDirection to be 'W', got '%s'", dailyForecast[0].WindDirection.Compass8())
+	}
+	if dailyForecast[0].Condition.Severity != 0 || dailyForecast[0].Condition.Code != 3 {
+		t.Errorf("Expected a non-severe code 3 condition, got %+v", dailyForecast[0].Condition)
+	}
+	// Solar noon in Berlin is around 11:10 UTC in January.
+	if noon := dailyForecast[0].Astronomy.Sun.SolarNoon; noon.Hour() != 11 || noon.Day() != 1 {
+		t.Errorf("Expected solar noon at 11:xx UTC on 1 January, got %s", noon)
+	}
+	if dailyForecast[1].Astronomy.Moon.Name == "" {
+		t.Error("Expected a moon phase for the second day")
+	}
 	if dailyForecast[0].Units.Temperature != "°C" {
 		t.Errorf(
 			"Expected Temperature unit to be '°C', got '%s'",
@@ -139,6 +165,8 @@ func TestGetDailyForecast_NumDaysZero(t *testing.T) {
 					PrecipitationProbabilityMean: []float64{10.0},
 					WeatherCode:                  []int{3},
 					WindSpeed10mMax:              []float64{15.0},
+					WindGusts10mMax:              []float64{25.0},
+					WindDirection10mDominant:     []float64{270.0},
 				},
 				DailyUnits: &openmateo.ForecastDailyUnits{
 					Temperature2mMax: "°C",
@@ -178,6 +206,8 @@ func TestGetDailyForecast_NumDaysOutOfRange(t *testing.T) {
 					PrecipitationProbabilityMean: []float64{10.0},
 					WeatherCode:                  []int{3},
 					WindSpeed10mMax:              []float64{15.0},
+					WindGusts10mMax:              []float64{25.0},
+					WindDirection10mDominant:     []float64{270.0},
 				},
 				DailyUnits: &openmateo.ForecastDailyUnits{
 					Temperature2mMax: "°C",
@@ -222,17 +252,19 @@ func TestGetHourlyForecast_Success(t *testing.T) {
 			return &openmateo.ForecastResult{
 				Timezone: "UTC",
 				Hourly: &openmateo.ForecastHourly{
-					Time:                []string{"2023-01-01T12:00:00Z", "2023-01-01T13:00:00Z"},
-					Temperature2m:       []float64{10.0, 11.0},
-					RelativeHumidity2m:  []float64{80.0, 81.0},
-					ApparentTemperature: []float64{8.0, 9.0},
-					CloudCover:          []float64{50.0, 55.0},
-					WindSpeed10m:        []float64{5.0, 6.0},
-					Precipitation:       []float64{0.5, 0.6},
-					Snowfall:            []float64{0.0, 0.0},
+					Time:                     []string{"2023-01-01T12:00:00Z", "2023-01-01T13:00:00Z"},
+					Temperature2m:            []float64{10.0, 11.0},
+					RelativeHumidity2m:       []float64{80.0, 81.0},
+					ApparentTemperature:      []float64{8.0, 9.0},
+					CloudCover:               []float64{50.0, 55.0},
+					WindSpeed10m:             []float64{5.0, 6.0},
+					WindDirection10m:         []float64{180.0, 180.0},
+					WindGusts10m:             []float64{9.0, 9.0},
+					Precipitation:            []float64{0.5, 0.6},
+					Snowfall:                 []float64{0.0, 0.0},
 					PrecipitationProbability: []float64{10.0, 15.0},
-					WeatherCode:         []int{3, 1},
-					IsDay:               []int{1, 1},
+					WeatherCode:              []int{3, 1},
+					IsDay:                    []int{1, 1},
 				},
 				HourlyUnits: &openmateo.ForecastHourlyUnits{
 					Temperature2m: "°C",
@@ -259,6 +291,18 @@ func TestGetHourlyForecast_Success(t *testing.T) {
 	if hourlyForecast[1].Temperature != 11.0 {
 		t.Errorf("Expected Temperature to be 11.0, got %f", hourlyForecast[1].Temperature)
 	}
+	if hourlyForecast[0].WindGusts != 9.0 {
+		t.Errorf("Expected WindGusts to be 9.0, got %f", hourlyForecast[0].WindGusts)
+	}
+	if hourlyForecast[0].WindDirection != 180.0 {
+		t.Errorf("Expected WindDirection to be 180.0, got %f", hourlyForecast[0].WindDirection)
+	}
+	if hourlyForecast[1].Condition.Description != "Mainly clear" {
+		t.Errorf(
+			"Expected Condition description to be 'Mainly clear', got '%s'",
+			hourlyForecast[1].Condition.Description,
+		)
+	}
 	if hourlyForecast[0].Units.Temperature != "°C" {
 		t.Errorf(
 			"Expected Temperature unit to be '°C', got '%s'",
@@ -266,3 +310,175 @@ func TestGetHourlyForecast_Success(t *testing.T) {
 		)
 	}
 }
+
+func TestGetCurrentWeather_Language(t *testing.T) {
+	mockClient := &mockForecastClient{
+		GetWeatherFunc: func(latitude, longitude float64, currentParameters, hourlyParameters, dailyParameters []string, temperatureUnit, windSpeedUnit, precipitationUnit string, pastDays, forecastDays, pastHours, forecastHours int64) (*openmateo.ForecastResult, error) {
+			return &openmateo.ForecastResult{
+				Timezone: "UTC",
+				Current: &openmateo.ForecastCurrent{
+					Time:        "2023-01-01T12:00:00Z",
+					WeatherCode: 65,
+				},
+				CurrentUnits: &openmateo.ForecastCurrentUnits{},
+			}, nil
+		},
+	}
+
+	weatherClient := NewWeatherClient(mockClient)
+	weatherClient.Language = "de"
+	currentWeather, err := weatherClient.GetCurrentWeather(52.52, 13.41, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+
+	if currentWeather.WeatherDescription != "Starker Regen" {
+		t.Errorf(
+			"Expected WeatherDescription to be 'Starker Regen', got '%s'",
+			currentWeather.WeatherDescription,
+		)
+	}
+}
+
+// mockRecorder remembers what it was given.
+type mockRecorder struct {
+	latitude, longitude float64
+	current             *CurrentWeather
+	hourly              []HourlyForecast
+	daily               []DailyForecast
+	past                []HourlyForecast
+}
+
+func (m *mockRecorder) RecordCurrent(latitude, longitude float64, current *CurrentWeather) {
+	m.latitude, m.longitude, m.current = latitude, longitude, current
+}
+
+func (m *mockRecorder) RecordHourly(latitude, longitude float64, hourly []HourlyForecast) {
+	m.latitude, m.longitude, m.hourly = latitude, longitude, hourly
+}
+
+func (m *mockRecorder) RecordDaily(latitude, longitude float64, daily []DailyForecast) {
+	m.latitude, m.longitude, m.daily = latitude, longitude, daily
+}
+
+func (m *mockRecorder) RecordPast(latitude, longitude float64, past []HourlyForecast) {
+	m.latitude, m.longitude, m.past = latitude, longitude, past
+}
+
+func TestGetCurrentWeather_Recorder(t *testing.T) {
+	mockClient := &mockForecastClient{
+		GetWeatherFunc: func(latitude, longitude float64, currentParameters, hourlyParameters, dailyParameters []string, temperatureUnit, windSpeedUnit, precipitationUnit string, pastDays, forecastDays, pastHours, forecastHours int64) (*openmateo.ForecastResult, error) {
+			return &openmateo.ForecastResult{
+				Timezone:     "UTC",
+				Current:      &openmateo.ForecastCurrent{Time: "2023-01-01T12:00", Temperature2m: 10.0},
+				CurrentUnits: &openmateo.ForecastCurrentUnits{Temperature2m: "°C"},
+			}, nil
+		},
+	}
+
+	recorder := &mockRecorder{}
+	weatherClient := NewWeatherClient(mockClient)
+	weatherClient.Recorder = recorder
+	currentWeather, err := weatherClient.GetCurrentWeather(52.52, 13.41, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+
+	if recorder.current != currentWeather {
+		t.Errorf("Expected the fetched weather to be recorded, got %+v", recorder.current)
+	}
+	if recorder.latitude != 52.52 || recorder.longitude != 13.41 {
+		t.Errorf("Expected it to be recorded at 52.52, 13.41, got %f, %f", recorder.latitude, recorder.longitude)
+	}
+}
+
+func TestGetPastHourly(t *testing.T) {
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC) }
+
+	mockClient := &mockForecastClient{
+		GetWeatherFunc: func(latitude, longitude float64, currentParameters, hourlyParameters, dailyParameters []string, temperatureUnit, windSpeedUnit, precipitationUnit string, pastDays, forecastDays, pastHours, forecastHours int64) (*openmateo.ForecastResult, error) {
+			if pastHours != 3 || forecastHours != 1 {
+				t.Errorf("Expected 3 past hours and 1 forecast hour, got %d and %d", pastHours, forecastHours)
+			}
+			return &openmateo.ForecastResult{
+				Timezone: "UTC",
+				Hourly: &openmateo.ForecastHourly{
+					Time:                     []string{"2023-01-01T10:00", "2023-01-01T11:00", "2023-01-01T12:00"},
+					Temperature2m:            []float64{8.0, 9.0, 10.0},
+					RelativeHumidity2m:       []float64{80.0, 81.0, 82.0},
+					ApparentTemperature:      []float64{6.0, 7.0, 8.0},
+					CloudCover:               []float64{50.0, 55.0, 60.0},
+					WindSpeed10m:             []float64{5.0, 6.0, 7.0},
+					WindDirection10m:         []float64{180.0, 180.0, 180.0},
+					WindGusts10m:             []float64{9.0, 9.0, 9.0},
+					Precipitation:            []float64{0.5, 0.6, 0.0},
+					Snowfall:                 []float64{0.0, 0.0, 0.0},
+					PrecipitationProbability: []float64{10.0, 15.0, 20.0},
+					WeatherCode:              []int{3, 1, 1},
+					IsDay:                    []int{1, 1, 1},
+				},
+				HourlyUnits: &openmateo.ForecastHourlyUnits{Temperature2m: "°C"},
+			}, nil
+		},
+	}
+
+	recorder := &mockRecorder{}
+	weatherClient := NewWeatherClient(mockClient)
+	weatherClient.Recorder = recorder
+	past, err := weatherClient.GetPastHourly(52.52, 13.41, 3, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetPastHourly failed: %v", err)
+	}
+
+	// The hour from 12:00 is still under way at 12:30.
+	if len(past) != 2 || past[1].Temperature != 9.0 {
+		t.Fatalf("Expected the two hours that ended, got %+v", past)
+	}
+	if len(recorder.past) != 2 || recorder.hourly != nil {
+		t.Errorf("Expected the past hours to be recorded as past, got %d past and %d hourly", len(recorder.past), len(recorder.hourly))
+	}
+}
+
+func TestWeatherClient_Fake(t *testing.T) {
+	fake := openmateotest.NewServer()
+	defer fake.Close()
+	forecast := openmateo.NewForecastClient(nil)
+	forecast.BaseURL = fake.BaseURL()
+	airQuality := openmateo.NewAirQualityClient(nil)
+	airQuality.BaseURL = fake.BaseURL()
+	weatherClient := NewWeatherClient(forecast)
+	weatherClient.AirQualityClient = airQuality
+
+	current, err := weatherClient.GetCurrentWeather(52.52, 13.41, "fahrenheit", "mph", "inch")
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if current.Units.Temperature != "°F" || current.WeatherDescription == "" {
+		t.Errorf("Expected the current weather in Fahrenheit, got %+v", current)
+	}
+
+	hourly, err := weatherClient.GetHourlyForecast(52.52, 13.41, 12, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(hourly) != 12 {
+		t.Errorf("Expected 12 hours, got %d", len(hourly))
+	}
+
+	daily, err := weatherClient.GetDailyForecast(52.52, 13.41, 5, "celsius", "kmh", "mm")
+	if err != nil {
+		t.Fatalf("GetDailyForecast failed: %v", err)
+	}
+	if len(daily) != 5 || daily[0].Sunrise.IsZero() {
+		t.Errorf("Expected 5 days with sunrise, got %+v", daily)
+	}
+
+	air, err := weatherClient.GetCurrentAirQuality(52.52, 13.41)
+	if err != nil {
+		t.Fatalf("GetCurrentAirQuality failed: %v", err)
+	}
+	if air.PM25 == 0 || air.USAQI == 0 {
+		t.Errorf("Expected air quality readings, got %+v", air)
+	}
+}
@@ -0,0 +1,114 @@
+package weather
+
+import "math"
+
+// Direction is a meteorological wind direction in degrees: the direction the
+// wind is blowing from, clockwise from north.
+type Direction float64
+
+var compass16 = [16]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+var compass8 = [8]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// Arrows point the way the wind is blowing to, so a northerly wind is "↓".
+var arrows8 = [8]string{"↓", "↙", "←", "↖", "↑", "↗", "→", "↘"}
+
+// Degrees returns the direction normalized to [0, 360).
+func (d Direction) Degrees() float64 {
+	degrees := math.Mod(float64(d), 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+// sector returns the index of the compass sector containing the direction
+// when the circle is split into n equal sectors centred on north.
+func (d Direction) sector(n int) int {
+	width := 360 / float64(n)
+	return int(math.Floor((d.Degrees()+width/2)/width)) % n
+}
+
+// Compass8 returns the 8-point compass label, e.g. "SW".
+func (d Direction) Compass8() string {
+	return compass8[d.sector(8)]
+}
+
+// Compass16 returns the 16-point compass label, e.g. "WSW".
+func (d Direction) Compass16() string {
+	return compass16[d.sector(16)]
+}
+
+// Arrow returns an arrow glyph pointing the way the wind is blowing.
+func (d Direction) Arrow() string {
+	return arrows8[d.sector(8)]
+}
+
+// BeaufortForce is a wind speed classified on the Beaufort scale.
+type BeaufortForce struct {
+	Force       int    `json:"force"`
+	Description string `json:"description"`
+}
+
+// beaufortScale lists the upper bound (exclusive, in m/s) and description of
+// each force up to 11. Anything faster is force 12.
+var beaufortScale = []struct {
+	upperBound  float64
+	description string
+}{
+	{0.5, "Calm"},
+	{1.6, "Light air"},
+	{3.4, "Light breeze"},
+	{5.5, "Gentle breeze"},
+	{8.0, "Moderate breeze"},
+	{10.8, "Fresh breeze"},
+	{13.9, "Strong breeze"},
+	{17.2, "Near gale"},
+	{20.8, "Gale"},
+	{24.5, "Strong gale"},
+	{28.5, "Storm"},
+	{32.7, "Violent storm"},
+}
+
+// Beaufort classifies a wind speed given in one of the Open-Meteo wind speed
+// units ("km/h", "mp/h", "m/s" or "kn"). Unknown units are treated as km/h.
+func Beaufort(speed float64, unit string) BeaufortForce {
+	metersPerSecond := toMetersPerSecond(speed, unit)
+	for force, level := range beaufortScale {
+		if metersPerSecond < level.upperBound {
+			return BeaufortForce{Force: force, Description: level.description}
+		}
+	}
+	return BeaufortForce{Force: 12, Description: "Hurricane force"}
+}
+
+func toMetersPerSecond(speed float64, unit string) float64 {
+	switch unit {
+	case "m/s", "ms":
+		return speed
+	case "mp/h", "mph":
+		return speed * 0.44704
+	case "kn":
+		return speed * 0.514444
+	default:
+		return speed / 3.6
+	}
+}
+
+// Beaufort classifies the current sustained wind speed.
+func (c CurrentWeather) Beaufort() BeaufortForce {
+	return Beaufort(c.WindSpeed, c.Units.WindSpeed)
+}
+
+// Beaufort classifies the forecast sustained wind speed.
+func (h HourlyForecast) Beaufort() BeaufortForce {
+	return Beaufort(h.WindSpeed, h.Units.WindSpeed)
+}
+
+// Beaufort classifies the day's maximum sustained wind speed.
+func (d DailyForecast) Beaufort() BeaufortForce {
+	return Beaufort(d.MaxWindSpeed, d.Units.WindSpeed)
+}
@@ -0,0 +1,85 @@
+package weather
+
+import "testing"
+
+func TestDirection_Compass(t *testing.T) {
+	tests := []struct {
+		degrees   float64
+		compass8  string
+		compass16 string
+		arrow     string
+	}{
+		{0, "N", "N", "↓"},
+		{11.2, "N", "N", "↓"},
+		{11.3, "N", "NNE", "↓"},
+		{45, "NE", "NE", "↙"},
+		{90, "E", "E", "←"},
+		{200, "S", "SSW", "↑"},
+		{247.5, "W", "WSW", "→"},
+		{315, "NW", "NW", "↘"},
+		{359, "N", "N", "↓"},
+		{360, "N", "N", "↓"},
+		{-90, "W", "W", "→"},
+	}
+	for _, test := range tests {
+		direction := Direction(test.degrees)
+		if got := direction.Compass8(); got != test.compass8 {
+			t.Errorf("Expected Compass8(%v) to be '%s', got '%s'", test.degrees, test.compass8, got)
+		}
+		if got := direction.Compass16(); got != test.compass16 {
+			t.Errorf("Expected Compass16(%v) to be '%s', got '%s'", test.degrees, test.compass16, got)
+		}
+		if got := direction.Arrow(); got != test.arrow {
+			t.Errorf("Expected Arrow(%v) to be '%s', got '%s'", test.degrees, test.arrow, got)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	tests := []struct {
+		speed float64
+		unit  string
+		force int
+	}{
+		{0, "km/h", 0},
+		{1, "km/h", 0},
+		{5, "km/h", 1},
+		{15, "km/h", 3},
+		{20, "km/h", 4},
+		{40, "km/h", 6},
+		{117, "km/h", 11},
+		{118, "km/h", 12},
+		{10, "m/s", 5},
+		{20, "mp/h", 5},
+		{35, "kn", 8},
+	}
+	for _, test := range tests {
+		force := Beaufort(test.speed, test.unit)
+		if force.Force != test.force {
+			t.Errorf(
+				"Expected %v %s to be force %d, got %d (%s)",
+				test.speed,
+				test.unit,
+				test.force,
+				force.Force,
+				force.Description,
+			)
+		}
+	}
+
+	if description := Beaufort(70, "km/h").Description; description != "Gale" {
+		t.Errorf("Expected 70 km/h to be a 'Gale', got '%s'", description)
+	}
+}
+
+func TestBeaufort_OnWeatherTypes(t *testing.T) {
+	current := CurrentWeather{WindSpeed: 30, Units: Units{WindSpeed: "km/h"}}
+	if force := current.Beaufort().Force; force != 5 {
+		t.Errorf("Expected current force 5, got %d", force)
+	}
+
+	daily := DailyForecast{MaxWindSpeed: 15, WindGusts: 80, Units: Units{WindSpeed: "km/h"}}
+	if force := daily.Beaufort().Force; force != 3 {
+		t.Errorf("Expected daily force from the sustained maximum to be 3, got %d", force)
+	}
+}